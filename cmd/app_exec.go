@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/docker"
+	"golang.org/x/term"
+)
+
+// resolveComposeServiceContainer finds the running container for service
+// using the context's Compose project labels.
+func resolveComposeServiceContainer(ctx context.Context, siteCtx *config.Context, service string) (string, error) {
+	cli, err := docker.GetDockerCli(siteCtx)
+	if err != nil {
+		return "", err
+	}
+	defer cli.Close()
+
+	containerName, err := cli.GetContainerNameContext(ctx, siteCtx, service)
+	if err != nil {
+		return "", fmt.Errorf("find %s container: %w", service, err)
+	}
+	containerName = strings.TrimPrefix(strings.TrimSpace(containerName), "/")
+	if containerName == "" {
+		return "", fmt.Errorf("unable to find a running %s container for context %q", service, siteCtx.Name)
+	}
+	return containerName, nil
+}
+
+// dockerExecArgs builds a docker exec argv. A TTY is only requested when
+// stdin is a terminal so piped invocations keep clean output.
+func dockerExecArgs(containerName, workingDir string, tty bool, command []string) []string {
+	args := []string{"exec", "-i"}
+	if tty {
+		args = append(args, "-t")
+	}
+	if strings.TrimSpace(workingDir) != "" {
+		args = append(args, "-w", workingDir)
+	}
+	args = append(args, containerName)
+	return append(args, command...)
+}
+
+// runInServiceContainer runs command inside the service container of siteCtx
+// through the docker CLI so remote contexts reuse the SSH command path.
+func runInServiceContainer(ctx context.Context, siteCtx *config.Context, service, workingDir string, command []string) error {
+	containerName, err := resolveComposeServiceContainer(ctx, siteCtx, service)
+	if err != nil {
+		return err
+	}
+	tty := term.IsTerminal(int(os.Stdin.Fd()))
+	c := exec.Command("docker", dockerExecArgs(containerName, workingDir, tty, command)...)
+	c.Dir = siteCtx.ProjectDir
+	if tty {
+		_, err = siteCtx.RunCommandContext(ctx, c)
+	} else {
+		_, err = siteCtx.RunNoTTYCommandContext(ctx, c)
+	}
+	return commandExitStatusError(err)
+}
+
+// hasAnyFlag reports whether args already contain one of names, either as a
+// standalone flag or in --name=value form.
+func hasAnyFlag(args []string, names ...string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+		for _, name := range names {
+			if arg == name || strings.HasPrefix(arg, name+"=") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// takeFlag removes the sitectl flag name, in --name value or --name=value
+// form, from args bound for another program and returns its value. Arguments
+// after "--" are left alone.
+func takeFlag(args []string, name string) (string, []string) {
+	value := ""
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return value, append(rest, args[i:]...)
+		case arg == name && i+1 < len(args):
+			value = args[i+1]
+			i++
+		case strings.HasPrefix(arg, name+"="):
+			value = strings.TrimPrefix(arg, name+"=")
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return strings.TrimSpace(value), rest
+}
+
+// execInContainer runs command through the Docker API rather than the docker
+// CLI so env values such as passwords never appear on a command line or in the
+// remote command log. The local terminal is switched to raw mode when stdin is
+// a TTY so interactive clients behave as they would under docker exec -it.
+func execInContainer(ctx context.Context, cli *docker.DockerClient, containerName string, command, env []string) error {
+	fd := int(os.Stdin.Fd())
+	tty := term.IsTerminal(fd)
+	if tty {
+		oldState, err := term.MakeRaw(fd)
+		if err != nil {
+			return fmt.Errorf("failed to set terminal to raw mode: %w", err)
+		}
+		defer func() {
+			_ = term.Restore(fd, oldState)
+		}()
+	}
+	exitCode, err := cli.Exec(ctx, docker.ExecOptions{
+		Container:    containerName,
+		Cmd:          command,
+		Env:          env,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		Tty:          tty,
+	})
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		return &exitStatusError{status: exitCode, err: fmt.Errorf("%s exited with status %d", command[0], exitCode)}
+	}
+	return nil
+}
@@ -0,0 +1,438 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/i18n"
+	corejob "github.com/libops/sitectl/pkg/job"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+const (
+	backupArchiveSuffix = ".sitectl-backup"
+	backupManifestName  = "backup.json"
+	backupPassphraseEnv = "SITECTL_BACKUP_PASSPHRASE"
+)
+
+// backupProjectPaths are copied from the project directory when present, in
+// addition to the context's env files.
+var backupProjectPaths = []string{".env", "secrets"}
+
+// backupManifest is stored at the root of every backup archive. Volumes are
+// recorded without the Compose project prefix so a backup can be restored
+// into a project with another name.
+type backupManifest struct {
+	Context string    `json:"context"`
+	Project string    `json:"project"`
+	Created time.Time `json:"created"`
+	Volumes []string  `json:"volumes"`
+	// UnprefixedVolumes lists the Volumes whose Docker name does not start
+	// with the compose project, such as volumes with an explicit name. They
+	// are restored under the same name instead of <project>_<volume>.
+	UnprefixedVolumes []string `json:"unprefixed_volumes,omitempty"`
+	Files             []string `json:"files"`
+}
+
+// addVolume records the Docker volume in the manifest and returns the name
+// it is stored under in the backup.
+func (m *backupManifest) addVolume(volume string) string {
+	name := strings.TrimPrefix(volume, m.Project+"_")
+	m.Volumes = append(m.Volumes, name)
+	if name == volume {
+		m.UnprefixedVolumes = append(m.UnprefixedVolumes, name)
+	}
+	return name
+}
+
+// volumeName returns the Docker volume that the backed up volume name is
+// restored into for the compose project.
+func (m backupManifest) volumeName(project, name string) string {
+	if containsString(m.UnprefixedVolumes, name) {
+		return name
+	}
+	return project + "_" + name
+}
+
+// backupPassphrase returns the passphrase that encrypts or decrypts a backup.
+// It is a variable so tests can avoid the terminal prompt.
+var backupPassphrase = readBackupPassphrase
+
+var backupCmd = &cobra.Command{
+	Use:     "backup",
+	Short:   "Create and restore backups of a project",
+	GroupID: "ops",
+}
+
+var backupLocalCmd = &cobra.Command{
+	Use:   "local",
+	Short: "Archive the volumes, secrets and env files of the active local context into an encrypted backup",
+	Long: `Archive every named volume of the Compose project, the project's secrets/ directory and its env
+files into a single encrypted file with a manifest. The backup can be restored on any machine
+with sitectl backup local restore.
+
+Volumes are copied with temporary helper containers while services keep running; stop
+services that write to volumes first if you need a consistent backup.
+
+The passphrase is read from --passphrase-file, the SITECTL_BACKUP_PASSPHRASE environment
+variable, or a terminal prompt. A lost passphrase cannot be recovered.
+
+Examples:
+  sitectl backup local
+  sitectl backup local --output /mnt/usb/museum.sitectl-backup --passphrase-file ~/.backup-pass`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, err := backupContext(cmd)
+		if err != nil {
+			return err
+		}
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		if output == "" {
+			output = sanitizeArtifactPart(ctx.Name) + "-" + now.Format(snapshotTimeFormat) + backupArchiveSuffix
+		}
+		passphrase, err := backupPassphrase(cmd, true)
+		if err != nil {
+			return err
+		}
+		manifest, err := createBackup(cmd, ctx, output, passphrase, now)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), i18n.T(i18n.BackupCreated, output, len(manifest.Volumes), len(manifest.Files)))
+		return nil
+	},
+}
+
+var backupLocalRestoreCmd = &cobra.Command{
+	Use:   "restore ARCHIVE",
+	Short: "Restore an encrypted local backup into the active local context",
+	Long: `Restore a backup made by sitectl backup local into the active local context.
+
+The project's secrets and env files are overwritten and every captured volume is emptied and
+refilled. Volumes are created when missing, so a backup can seed a fresh checkout on another
+machine. Stop the project's services before restoring.
+
+Examples:
+  sitectl backup local restore museum-20260101T020000Z.sitectl-backup`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, err := backupContext(cmd)
+		if err != nil {
+			return err
+		}
+		yolo, err := cmd.Flags().GetBool("yolo")
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(args[0]); err != nil {
+			return fmt.Errorf("backup %s: %w", args[0], err)
+		}
+		if err := ctx.RequireUnprotected("restore a backup"); err != nil {
+			return err
+		}
+		passphrase, err := backupPassphrase(cmd, false)
+		if err != nil {
+			return err
+		}
+
+		staging, cleanup, err := corejob.MakeTempWorkDir("sitectl-backup-restore-*")
+		if err != nil {
+			return fmt.Errorf("create temp dir: %w", err)
+		}
+		defer cleanup()
+		manifest, err := openBackup(args[0], passphrase, staging)
+		if err != nil {
+			return err
+		}
+		name := filepath.Base(args[0])
+		if !config.AutoConfirmTyped(fmt.Sprintf("restore backup %s into context %q", name, ctx.Name), yolo) {
+			token := "restore " + name
+			input, err := config.GetInput(
+				i18n.T(i18n.BackupRestoreWarning, ctx.Name, name, manifest.Context, manifest.Created.Local().Format(time.DateTime)),
+				i18n.T(i18n.TypeToContinuePrompt, token),
+			)
+			if err != nil {
+				return err
+			}
+			if strings.TrimSpace(input) != token {
+				return fmt.Errorf("backup restore cancelled")
+			}
+		}
+		if err := restoreBackup(cmd, ctx, manifest, staging); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), i18n.T(i18n.BackupRestored, name, ctx.Name))
+		return nil
+	},
+}
+
+func backupContext(cmd *cobra.Command) (*config.Context, error) {
+	ctx, err := resolveCurrentContext(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if ctx.DockerHostType != config.ContextLocal {
+		return nil, fmt.Errorf("local backups require a local context; %q is %s", ctx.Name, ctx.DockerHostType)
+	}
+	if strings.TrimSpace(ctx.ProjectDir) == "" {
+		return nil, fmt.Errorf("context %q does not define a project directory", ctx.Name)
+	}
+	return ctx, nil
+}
+
+// backupFilePaths returns the project-relative paths a backup copies: the
+// standard paths plus the context's env files inside the project.
+func backupFilePaths(ctx *config.Context) []string {
+	paths := append([]string{}, backupProjectPaths...)
+	for _, envFile := range ctx.EnvFile {
+		rel := envFile
+		if filepath.IsAbs(envFile) {
+			var err error
+			if rel, err = filepath.Rel(ctx.ProjectDir, envFile); err != nil {
+				continue
+			}
+		}
+		rel = filepath.Clean(rel)
+		if rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) || containsString(paths, rel) {
+			continue
+		}
+		paths = append(paths, rel)
+	}
+	return paths
+}
+
+func createBackup(cmd *cobra.Command, ctx *config.Context, output string, passphrase []byte, now time.Time) (backupManifest, error) {
+	manifest := backupManifest{
+		Context: ctx.Name,
+		Project: ctx.EffectiveComposeProjectName(),
+		Created: now,
+		Volumes: []string{},
+		Files:   []string{},
+	}
+	staging, cleanup, err := corejob.MakeTempWorkDir("sitectl-backup-*")
+	if err != nil {
+		return manifest, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer cleanup()
+
+	api, closeAPI, err := volumeClient(ctx)
+	if err != nil {
+		return manifest, err
+	}
+	defer closeAPI()
+	volumes, err := composeProjectVolumes(cmd.Context(), api, manifest.Project)
+	if err != nil {
+		return manifest, err
+	}
+	volumesDir := filepath.Join(staging, "archive", snapshotVolumesDir)
+	if err := os.MkdirAll(volumesDir, 0o750); err != nil {
+		return manifest, err
+	}
+	for _, volume := range snapshotVolumes(volumes, nil) {
+		name := manifest.addVolume(volume)
+		if err := exportVolume(cmd.Context(), api, volume, filepath.Join(volumesDir, name+".tar")); err != nil {
+			return manifest, err
+		}
+	}
+	for _, rel := range backupFilePaths(ctx) {
+		copied, err := copyTree(filepath.Join(ctx.ProjectDir, rel), filepath.Join(staging, "archive", snapshotProjectDir, rel))
+		if err != nil {
+			return manifest, err
+		}
+		if copied {
+			manifest.Files = append(manifest.Files, filepath.ToSlash(rel))
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return manifest, err
+	}
+	if err := os.WriteFile(filepath.Join(staging, "archive", backupManifestName), data, 0o600); err != nil {
+		return manifest, err
+	}
+	archive := filepath.Join(staging, "backup.tar.gz")
+	if err := writeTarGz(filepath.Join(staging, "archive"), archive); err != nil {
+		return manifest, err
+	}
+	return manifest, encryptBackup(archive, output, passphrase)
+}
+
+// encryptBackup encrypts archive into output, writing next to output and
+// renaming into place so a failed backup never leaves a partial file.
+func encryptBackup(archive, output string, passphrase []byte) error {
+	in, err := os.Open(archive) // #nosec G304 -- archive lives in a temp dir created by this process.
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	tmp, err := os.CreateTemp(filepath.Dir(output), ".backup-*")
+	if err != nil {
+		return fmt.Errorf("create backup: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		_ = os.Remove(tmpPath)
+	}()
+	encrypted, err := newBackupEncryptWriter(tmp, passphrase)
+	if err == nil {
+		_, err = io.Copy(encrypted, in)
+	}
+	if err == nil {
+		err = encrypted.Close()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("write backup %s: %w", output, err)
+	}
+	return os.Rename(tmpPath, output)
+}
+
+// openBackup decrypts and unpacks the backup at path into staging and returns
+// its manifest.
+func openBackup(path string, passphrase []byte, staging string) (backupManifest, error) {
+	var manifest backupManifest
+	in, err := os.Open(path) // #nosec G304 -- path is the backup the user asked to restore.
+	if err != nil {
+		return manifest, err
+	}
+	defer in.Close()
+	decrypted, err := newBackupDecryptReader(in, passphrase)
+	if err != nil {
+		return manifest, fmt.Errorf("open backup %s: %w", path, err)
+	}
+	archive := filepath.Join(staging, "backup.tar.gz")
+	out, err := os.OpenFile(archive, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600) // #nosec G304 -- archive lives in a temp dir created by this process.
+	if err != nil {
+		return manifest, err
+	}
+	_, err = io.Copy(out, decrypted)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return manifest, fmt.Errorf("decrypt backup %s: %w", path, err)
+	}
+	dir := filepath.Join(staging, "archive")
+	if err := extractTarGz(archive, dir); err != nil {
+		return manifest, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, backupManifestName)) // #nosec G304 -- manifest was extracted into a temp dir.
+	if err != nil {
+		return manifest, fmt.Errorf("read backup manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("parse backup manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+func restoreBackup(cmd *cobra.Command, ctx *config.Context, manifest backupManifest, staging string) error {
+	dir := filepath.Join(staging, "archive")
+	// check every entry before writing anything
+	targets := make([]string, 0, len(manifest.Files))
+	for _, rel := range manifest.Files {
+		target, err := projectFilePath(ctx.ProjectDir, rel)
+		if err != nil {
+			return fmt.Errorf("backup %w", err)
+		}
+		targets = append(targets, target)
+	}
+	for _, name := range manifest.Volumes {
+		if name == "" || strings.ContainsAny(name, `/\`) {
+			return fmt.Errorf("invalid volume name %q in backup manifest", name)
+		}
+	}
+
+	for i, rel := range manifest.Files {
+		if _, err := copyTree(filepath.Join(dir, snapshotProjectDir, filepath.FromSlash(rel)), targets[i]); err != nil {
+			return err
+		}
+	}
+	if len(manifest.Volumes) == 0 {
+		return nil
+	}
+	api, closeAPI, err := volumeClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeAPI()
+	project := ctx.EffectiveComposeProjectName()
+	for _, name := range manifest.Volumes {
+		if err := importVolume(cmd.Context(), api, project, manifest.volumeName(project, name), filepath.Join(dir, snapshotVolumesDir, name+".tar")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readBackupPassphrase reads the passphrase from --passphrase-file, the
+// environment or the terminal. New backups ask twice on the terminal.
+func readBackupPassphrase(cmd *cobra.Command, confirm bool) ([]byte, error) {
+	path, err := cmd.Flags().GetString("passphrase-file")
+	if err != nil {
+		return nil, err
+	}
+	if path != "" {
+		data, err := os.ReadFile(path) // #nosec G304 -- path is the passphrase file the user passed.
+		if err != nil {
+			return nil, fmt.Errorf("read passphrase file: %w", err)
+		}
+		return backupPassphraseValue(strings.TrimRight(string(data), "\r\n"))
+	}
+	if value, ok := os.LookupEnv(backupPassphraseEnv); ok {
+		return backupPassphraseValue(value)
+	}
+	if config.NonInteractive() || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("backup passphrase required: pass --passphrase-file or set %s", backupPassphraseEnv)
+	}
+	fmt.Fprint(cmd.ErrOrStderr(), "Backup passphrase: ")
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(cmd.ErrOrStderr())
+	if err != nil {
+		return nil, fmt.Errorf("read passphrase: %w", err)
+	}
+	if confirm {
+		fmt.Fprint(cmd.ErrOrStderr(), "Repeat passphrase: ")
+		again, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(cmd.ErrOrStderr())
+		if err != nil {
+			return nil, fmt.Errorf("read passphrase: %w", err)
+		}
+		if string(again) != string(passphrase) {
+			return nil, errors.New("passphrases do not match")
+		}
+	}
+	return backupPassphraseValue(string(passphrase))
+}
+
+func backupPassphraseValue(value string) ([]byte, error) {
+	if value == "" {
+		return nil, errors.New("backup passphrase cannot be empty")
+	}
+	return []byte(value), nil
+}
+
+func init() {
+	backupLocalCmd.Flags().StringP("output", "o", "", "Backup file to write (default: <context>-<timestamp>.sitectl-backup)")
+	backupLocalCmd.Flags().String("passphrase-file", "", "Read the encryption passphrase from this file")
+	backupLocalRestoreCmd.Flags().String("passphrase-file", "", "Read the decryption passphrase from this file")
+	backupLocalRestoreCmd.Flags().Bool("yolo", false, "Skip the confirmation prompt before restoring")
+	backupLocalCmd.AddCommand(backupLocalRestoreCmd)
+	backupCmd.AddCommand(backupLocalCmd)
+	RootCmd.AddCommand(backupCmd)
+}
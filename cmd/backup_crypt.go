@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Encrypted backups start with backupMagic and a random scrypt salt, followed
+// by the archive sealed in backupChunkSize chunks with ChaCha20-Poly1305. Each
+// chunk nonce holds its sequence number and a final-chunk flag, so reordered,
+// dropped or truncated chunks fail to decrypt.
+const (
+	backupMagic     = "sitectl-backup/v1\n"
+	backupSaltSize  = 16
+	backupChunkSize = 64 * 1024
+)
+
+var errBackupPassphrase = errors.New("wrong passphrase or corrupted backup")
+
+func backupKey(passphrase, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, 1<<15, 8, 1, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	return chacha20poly1305.New(key)
+}
+
+func backupNonce(counter uint64, final bool) []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	binary.BigEndian.PutUint64(nonce[3:11], counter)
+	if final {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+type backupEncryptWriter struct {
+	out     io.Writer
+	aead    cipher.AEAD
+	buf     []byte
+	counter uint64
+}
+
+// newBackupEncryptWriter writes the backup header to out and returns a writer
+// that encrypts everything written to it. Close must be called to seal the
+// final chunk; it does not close out.
+func newBackupEncryptWriter(out io.Writer, passphrase []byte) (io.WriteCloser, error) {
+	salt := make([]byte, backupSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := backupKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(out, backupMagic); err != nil {
+		return nil, err
+	}
+	if _, err := out.Write(salt); err != nil {
+		return nil, err
+	}
+	return &backupEncryptWriter{out: out, aead: aead, buf: make([]byte, 0, backupChunkSize)}, nil
+}
+
+func (w *backupEncryptWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		// A full buffer is only sealed once more data arrives, so the last
+		// chunk is always sealed by Close with the final flag.
+		if len(w.buf) == backupChunkSize {
+			if err := w.seal(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(w.buf[len(w.buf):backupChunkSize], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (w *backupEncryptWriter) Close() error {
+	return w.seal(true)
+}
+
+func (w *backupEncryptWriter) seal(final bool) error {
+	sealed := w.aead.Seal(nil, backupNonce(w.counter, final), w.buf, nil)
+	w.counter++
+	w.buf = w.buf[:0]
+	_, err := w.out.Write(sealed)
+	return err
+}
+
+type backupDecryptReader struct {
+	in      io.Reader
+	aead    cipher.AEAD
+	chunk   []byte
+	next    []byte
+	plain   []byte
+	counter uint64
+	done    bool
+}
+
+// newBackupDecryptReader checks the backup header of in and returns a reader
+// of the decrypted archive.
+func newBackupDecryptReader(in io.Reader, passphrase []byte) (io.Reader, error) {
+	header := make([]byte, len(backupMagic)+backupSaltSize)
+	if _, err := io.ReadFull(in, header); err != nil || !bytes.HasPrefix(header, []byte(backupMagic)) {
+		return nil, fmt.Errorf("not a sitectl backup")
+	}
+	aead, err := backupKey(passphrase, header[len(backupMagic):])
+	if err != nil {
+		return nil, err
+	}
+	r := &backupDecryptReader{in: in, aead: aead}
+	size := backupChunkSize + aead.Overhead()
+	r.chunk, r.next = make([]byte, size), make([]byte, size)
+	n, err := io.ReadFull(in, r.next)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, errBackupPassphrase
+	}
+	r.next = r.next[:n]
+	return r, nil
+}
+
+func (r *backupDecryptReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+// open decrypts the buffered chunk. Reading one chunk ahead tells whether it
+// is the last one, which must carry the final flag.
+func (r *backupDecryptReader) open() error {
+	r.chunk, r.next = r.next, r.chunk[:cap(r.chunk)]
+	n, err := io.ReadFull(r.in, r.next)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	r.next = r.next[:n]
+	final := n == 0
+	plain, err := r.aead.Open(r.chunk[:0:0], backupNonce(r.counter, final), r.chunk, nil)
+	if err != nil {
+		return errBackupPassphrase
+	}
+	r.counter++
+	r.plain = plain
+	r.done = final
+	return nil
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func TestBackupEncryptionRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	passphrase := []byte("correct horse")
+	for _, size := range []int{0, 1, backupChunkSize, backupChunkSize + 1, 3*backupChunkSize + 5} {
+		plain := make([]byte, size)
+		if _, err := rand.Read(plain); err != nil {
+			t.Fatal(err)
+		}
+		var sealed bytes.Buffer
+		writer, err := newBackupEncryptWriter(&sealed, passphrase)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Write in odd pieces to cross chunk boundaries.
+		for rest := plain; len(rest) > 0; {
+			n := min(len(rest), 7000)
+			if _, err := writer.Write(rest[:n]); err != nil {
+				t.Fatal(err)
+			}
+			rest = rest[n:]
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+		encrypted := sealed.Bytes()
+
+		reader, err := newBackupDecryptReader(bytes.NewReader(encrypted), passphrase)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		got, err := io.ReadAll(reader)
+		if err != nil || !bytes.Equal(got, plain) {
+			t.Fatalf("size %d: round trip err = %v, equal = %v", size, err, bytes.Equal(got, plain))
+		}
+
+		if reader, err := newBackupDecryptReader(bytes.NewReader(encrypted), []byte("wrong")); err == nil {
+			if _, err := io.ReadAll(reader); !errors.Is(err, errBackupPassphrase) {
+				t.Errorf("size %d: wrong passphrase error = %v", size, err)
+			}
+		}
+		if size > backupChunkSize {
+			truncated := encrypted[:len(backupMagic)+backupSaltSize+backupChunkSize+16]
+			reader, err := newBackupDecryptReader(bytes.NewReader(truncated), passphrase)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := io.ReadAll(reader); !errors.Is(err, errBackupPassphrase) {
+				t.Errorf("size %d: truncated backup error = %v", size, err)
+			}
+		}
+	}
+}
+
+func TestOpenBackup(t *testing.T) {
+	t.Parallel()
+
+	source := t.TempDir()
+	manifest := backupManifest{Context: "museum", Project: "museum", Created: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), Volumes: []string{"solr-data"}, Files: []string{".env"}}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string][]byte{
+		backupManifestName:                           data,
+		filepath.Join(snapshotProjectDir, ".env"):    []byte("DOMAIN=museum.test\n"),
+		filepath.Join(snapshotVolumesDir, "a.tar"):   []byte("tar"),
+		filepath.Join(snapshotProjectDir, "secrets"): []byte("secret"),
+	} {
+		path := filepath.Join(source, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	work := t.TempDir()
+	archive := filepath.Join(work, "backup.tar.gz")
+	if err := writeTarGz(source, archive); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(work, "museum.sitectl-backup")
+	if err := encryptBackup(archive, output, []byte("pass")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := openBackup(output, []byte("nope"), t.TempDir()); !errors.Is(err, errBackupPassphrase) {
+		t.Fatalf("wrong passphrase error = %v", err)
+	}
+	staging := t.TempDir()
+	got, err := openBackup(output, []byte("pass"), staging)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, manifest) {
+		t.Errorf("manifest = %+v, want %+v", got, manifest)
+	}
+	env, err := os.ReadFile(filepath.Join(staging, "archive", snapshotProjectDir, ".env"))
+	if err != nil || string(env) != "DOMAIN=museum.test\n" {
+		t.Errorf(".env = %q, %v", env, err)
+	}
+}
+
+func TestBackupFilePaths(t *testing.T) {
+	t.Parallel()
+
+	ctx := &config.Context{ProjectDir: "/srv/museum", EnvFile: []string{".env", "/srv/museum/config/prod.env", "local.env", "/etc/outside.env"}}
+	want := []string{".env", "secrets", "config/prod.env", "local.env"}
+	if got := backupFilePaths(ctx); !reflect.DeepEqual(got, want) {
+		t.Errorf("backupFilePaths() = %q, want %q", got, want)
+	}
+}
+
+func TestBackupManifestVolumeNames(t *testing.T) {
+	t.Parallel()
+
+	manifest := backupManifest{Project: "museum"}
+	for _, volume := range []string{"museum_solr-data", "shared-files"} {
+		manifest.addVolume(volume)
+	}
+	if want := []string{"solr-data", "shared-files"}; !reflect.DeepEqual(manifest.Volumes, want) {
+		t.Fatalf("Volumes = %q, want %q", manifest.Volumes, want)
+	}
+	for name, want := range map[string]string{"solr-data": "library_solr-data", "shared-files": "shared-files"} {
+		if got := manifest.volumeName("library", name); got != want {
+			t.Errorf("volumeName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestRestoreBackupChecksManifestFirst(t *testing.T) {
+	t.Parallel()
+
+	staging := t.TempDir()
+	source := filepath.Join(staging, "archive", snapshotProjectDir, ".env")
+	if err := os.MkdirAll(filepath.Dir(source), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(source, []byte("DOMAIN=example.test\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ctx := &config.Context{ProjectDir: t.TempDir(), ProjectName: "museum"}
+
+	for _, volumes := range [][]string{{""}, {"../files"}, {`files\..`}} {
+		err := restoreBackup(&cobra.Command{}, ctx, backupManifest{Files: []string{".env"}, Volumes: volumes}, staging)
+		if err == nil || !strings.Contains(err.Error(), "invalid volume name") {
+			t.Errorf("restoreBackup(volumes %q) error = %v, want an invalid volume name", volumes, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(ctx.ProjectDir, ".env")); !os.IsNotExist(err) {
+		t.Fatalf("project file restored despite an invalid manifest: %v", err)
+	}
+
+	// without volumes the Docker daemon is never contacted
+	if err := restoreBackup(&cobra.Command{}, ctx, backupManifest{Files: []string{".env"}}, staging); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(ctx.ProjectDir, ".env")); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,357 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/helpers"
+	"github.com/libops/sitectl/pkg/notify"
+	"github.com/spf13/cobra"
+)
+
+var buildExecCommandContext = exec.CommandContext
+
+var buildBuilderNameUnsafe = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// buildPlan is one docker buildx bake invocation for the buildable services of
+// a compose project.
+type buildPlan struct {
+	Dir       string
+	Builder   string
+	Endpoint  string
+	Platforms []string
+	Push      bool
+	Targets   []buildTarget
+}
+
+type buildTarget struct {
+	Service string
+	Image   string
+}
+
+var buildCmd = &cobra.Command{
+	Use:   "build [SERVICE...]",
+	Short: "Build the site's compose images with docker buildx",
+	Long: `Build the compose services that have a build section with docker buildx bake.
+
+Sources come from the local checkout: the project directory of a local context, or
+--dir (default: the current directory) for a remote context. For a remote context the
+build runs on the context's Docker daemon through a docker-container buildx builder
+named sitectl-CONTEXT that connects over SSH, so slow laptop builds are offloaded to
+the server. Buildx connects with the system ssh client, so the context's SSH key must
+be loaded in ssh-agent or configured for the host in ~/.ssh/config. Contexts that
+reach their host through a jump host need --builder or --local. Pass --builder
+to use another builder, or --local to build with the local default builder.
+
+Images are tagged REPOSITORY:SITE-SHA, where REPOSITORY is the service's image name
+(or PROJECT-SERVICE), moved under --registry when set, and SHA is the checkout's short
+git commit, suffixed with -dirty when there are uncommitted changes.
+
+With --push, the context's registry logins (see sitectl registry login) are
+refreshed locally for the registries being pushed to.
+
+Without --push the image is loaded into the local Docker daemon, which only works
+for a single platform; multi-platform builds need --push.
+
+Examples:
+  sitectl build --context museum-prod --platform linux/amd64,linux/arm64 \
+    --registry ghcr.io/libraries --push
+  sitectl build drupal --local`,
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f := cmd.Flags()
+		dir, err := f.GetString("dir")
+		if err != nil {
+			return err
+		}
+		builder, err := f.GetString("builder")
+		if err != nil {
+			return err
+		}
+		local, err := f.GetBool("local")
+		if err != nil {
+			return err
+		}
+		platforms, err := f.GetStringSlice("platform")
+		if err != nil {
+			return err
+		}
+		registry, err := f.GetString("registry")
+		if err != nil {
+			return err
+		}
+		tag, err := f.GetString("tag")
+		if err != nil {
+			return err
+		}
+		push, err := f.GetBool("push")
+		if err != nil {
+			return err
+		}
+		if len(platforms) > 1 && !push {
+			return fmt.Errorf("multi-platform images cannot be loaded into the local Docker daemon: pass --push")
+		}
+
+		ctx, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+		runCtx := cmd.Context()
+		var composeArgs []string
+		switch {
+		case strings.TrimSpace(dir) != "":
+		case ctx.DockerHostType == config.ContextLocal:
+			dir = ctx.ProjectDir
+			composeArgs = ctx.DockerComposeGlobalArgsForCommand("build")
+		default:
+			dir = "."
+		}
+		if dir, err = filepath.Abs(dir); err != nil {
+			return err
+		}
+
+		if tag == "" {
+			sha, err := buildGitRevision(runCtx, dir)
+			if err != nil {
+				return err
+			}
+			tag = helpers.FirstNonEmpty(ctx.Site, ctx.Name) + "-" + sha
+			if strings.HasSuffix(sha, "-dirty") {
+				fmt.Fprintln(cmd.ErrOrStderr(), "Warning: building a checkout with uncommitted changes")
+			}
+		}
+
+		composeConfig, err := buildComposeConfig(runCtx, dir, composeArgs)
+		if err != nil {
+			return err
+		}
+		var doc composeConfigDocument
+		if err := json.Unmarshal(composeConfig, &doc); err != nil {
+			return fmt.Errorf("parse compose config: %w", err)
+		}
+		targets, err := buildTargets(doc, args, helpers.FirstNonEmpty(doc.Name, ctx.EffectiveComposeProjectName()), registry, tag)
+		if err != nil {
+			return err
+		}
+
+		if push {
+			var hosts []string
+			for _, target := range targets {
+				hosts = append(hosts, config.ImageRegistryHost(target.Image))
+			}
+			if err := loginContextRegistries(runCtx, ctx, false, hosts...); err != nil {
+				return err
+			}
+		}
+
+		plan := buildPlan{Dir: dir, Builder: builder, Platforms: platforms, Push: push, Targets: targets}
+		if plan.Builder == "" && !local && ctx.DockerHostType == config.ContextRemote {
+			plan.Builder = buildBuilderName(ctx)
+			if plan.Endpoint, err = buildBuilderEndpoint(ctx); err != nil {
+				return err
+			}
+		}
+		if err := runBuildPlan(runCtx, plan, composeConfig, cmd.OutOrStdout(), cmd.ErrOrStderr()); err != nil {
+			return err
+		}
+		for _, target := range plan.Targets {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\n", target.Service, target.Image)
+		}
+		return nil
+	},
+}
+
+// buildGitRevision returns the short commit of the checkout in dir, with a
+// -dirty suffix when it has uncommitted changes.
+func buildGitRevision(runCtx context.Context, dir string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	command := buildExecCommandContext(runCtx, "git", "-C", dir, "rev-parse", "--short=12", "HEAD") // #nosec G204 -- git args are fixed and dir is passed as a single argument.
+	command.Stdout, command.Stderr = &stdout, &stderr
+	if err := command.Run(); err != nil {
+		return "", fmt.Errorf("read git commit of %s (pass --tag to build outside a git checkout): %w: %s", dir, err, strings.TrimSpace(stderr.String()))
+	}
+	sha := strings.TrimSpace(stdout.String())
+	stdout.Reset()
+	command = buildExecCommandContext(runCtx, "git", "-C", dir, "status", "--porcelain") // #nosec G204 -- git args are fixed and dir is passed as a single argument.
+	command.Stdout = &stdout
+	if err := command.Run(); err != nil {
+		return "", fmt.Errorf("read git status of %s: %w", dir, err)
+	}
+	if strings.TrimSpace(stdout.String()) != "" {
+		sha += "-dirty"
+	}
+	return sha, nil
+}
+
+// buildComposeConfig returns the resolved compose project in dir as JSON.
+func buildComposeConfig(runCtx context.Context, dir string, composeArgs []string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	args := append(append([]string{"compose"}, composeArgs...), "config", "--format", "json")
+	command := buildExecCommandContext(runCtx, "docker", args...) // #nosec G204 -- compose args come from the sitectl context.
+	command.Dir = dir
+	command.Stdout, command.Stderr = &stdout, &stderr
+	if err := command.Run(); err != nil {
+		return nil, fmt.Errorf("docker compose config in %s: %w: %s", dir, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// buildTargets returns the image reference of each buildable service, limited
+// to services when any are given.
+func buildTargets(doc composeConfigDocument, services []string, project, registry, tag string) ([]buildTarget, error) {
+	for _, service := range services {
+		if _, ok := doc.Services[service]; !ok {
+			return nil, fmt.Errorf("unknown compose service %q", service)
+		}
+		if !serviceHasBuild(doc.Services[service]) {
+			return nil, fmt.Errorf("compose service %q has no build section", service)
+		}
+	}
+	names := make([]string, 0, len(doc.Services))
+	for name, service := range doc.Services {
+		if serviceHasBuild(service) && (len(services) == 0 || slices.Contains(services, name)) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no compose services have a build section")
+	}
+	sort.Strings(names)
+
+	targets := make([]buildTarget, 0, len(names))
+	for _, name := range names {
+		repository := imageRepository(doc.Services[name].Image)
+		if repository == "" {
+			repository = project + "-" + name
+		}
+		if registry = strings.TrimRight(strings.TrimSpace(registry), "/"); registry != "" {
+			repository = registry + "/" + repository[strings.LastIndex(repository, "/")+1:]
+		}
+		targets = append(targets, buildTarget{Service: name, Image: repository + ":" + tag})
+	}
+	return targets, nil
+}
+
+// imageRepository strips the tag and digest from an image reference.
+func imageRepository(image string) string {
+	image, _, _ = strings.Cut(strings.TrimSpace(image), "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+func buildBuilderName(ctx *config.Context) string {
+	return "sitectl-" + strings.Trim(buildBuilderNameUnsafe.ReplaceAllString(ctx.Name, "-"), "-")
+}
+
+// buildBuilderEndpoint is the ssh:// Docker endpoint of a remote context.
+// Buildx cannot go through a jump host, so contexts that need one are
+// refused rather than handed an endpoint that is unreachable.
+func buildBuilderEndpoint(ctx *config.Context) (string, error) {
+	jumps, err := ctx.SSHJumpHosts()
+	if err != nil {
+		return "", err
+	}
+	if len(jumps) > 0 {
+		return "", fmt.Errorf("context %q reaches its host through jump host %s, which the sitectl buildx builder cannot use; pass --builder with a builder that reaches the host, or --local", ctx.Name, jumps[0].Hostname)
+	}
+	host, err := ctx.ResolvedSSHEndpoint()
+	if err != nil {
+		return "", err
+	}
+	endpoint := "ssh://"
+	if host.User != "" {
+		endpoint += host.User + "@"
+	}
+	endpoint += host.Hostname
+	if host.Port != 0 && host.Port != 22 {
+		endpoint += ":" + strconv.FormatUint(uint64(host.Port), 10)
+	}
+	return endpoint, nil
+}
+
+// bakeArgs returns the docker buildx bake arguments for plan, reading the
+// resolved compose project from composeFile.
+func (plan buildPlan) bakeArgs(composeFile string) []string {
+	args := []string{"buildx", "bake", "--file", composeFile}
+	if plan.Builder != "" {
+		args = append(args, "--builder", plan.Builder)
+	}
+	if len(plan.Platforms) > 0 {
+		args = append(args, "--set", "*.platform="+strings.Join(plan.Platforms, ","))
+	}
+	for _, target := range plan.Targets {
+		args = append(args, "--set", target.Service+".tags="+target.Image)
+	}
+	if plan.Push {
+		args = append(args, "--push")
+	} else {
+		args = append(args, "--load")
+	}
+	for _, target := range plan.Targets {
+		args = append(args, target.Service)
+	}
+	return args
+}
+
+// runBuildPlan creates the SSH builder when plan needs one and runs the bake.
+func runBuildPlan(runCtx context.Context, plan buildPlan, composeConfig []byte, stdout, stderr io.Writer) error {
+	if plan.Endpoint != "" {
+		inspect := buildExecCommandContext(runCtx, "docker", "buildx", "inspect", plan.Builder) // #nosec G204 -- builder name is derived from the context name.
+		if err := inspect.Run(); err != nil {
+			fmt.Fprintf(stderr, "Creating buildx builder %s on %s\n", plan.Builder, plan.Endpoint)
+			create := buildExecCommandContext(runCtx, "docker", "buildx", "create", "--name", plan.Builder, "--driver", "docker-container", plan.Endpoint) // #nosec G204 -- builder name and endpoint come from the sitectl context.
+			create.Stdout, create.Stderr = stderr, stderr
+			if err := create.Run(); err != nil {
+				return fmt.Errorf("create buildx builder %s: %w", plan.Builder, err)
+			}
+		}
+	}
+
+	file, err := os.CreateTemp("", "sitectl-build-*.compose.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(composeConfig); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	bake := buildExecCommandContext(runCtx, "docker", plan.bakeArgs(file.Name())...) // #nosec G204 -- bake args come from the compose project and flags.
+	bake.Dir = plan.Dir
+	bake.Stdout, bake.Stderr = stdout, stderr
+	if err := bake.Run(); err != nil {
+		return fmt.Errorf("docker buildx bake: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	buildCmd.Flags().String("dir", "", "Checkout to build (default: the project directory of a local context, otherwise the current directory)")
+	buildCmd.Flags().String("builder", "", "buildx builder to use (default: sitectl-CONTEXT on the remote daemon for remote contexts)")
+	buildCmd.Flags().Bool("local", false, "Build with the local default builder even for a remote context")
+	buildCmd.Flags().StringSlice("platform", nil, "Target platforms, such as linux/amd64,linux/arm64 (default: the builder's platform)")
+	buildCmd.Flags().String("registry", "", "Registry and namespace to tag images under, such as ghcr.io/libraries")
+	buildCmd.Flags().String("tag", "", "Image tag (default: SITE-SHA from the checkout's git commit)")
+	buildCmd.Flags().Bool("push", false, "Push the images to their registry instead of loading them locally")
+	buildCmd.GroupID = "workflow"
+	buildCmd.Annotations = map[string]string{notify.Annotation: ""}
+	RootCmd.AddCommand(buildCmd)
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/libops/sitectl/pkg/config"
+)
+
+func TestBuildTargets(t *testing.T) {
+	t.Parallel()
+
+	var doc composeConfigDocument
+	if err := json.Unmarshal([]byte(`{"name":"museum","services":{
+		"drupal":{"image":"islandora/drupal:4.1@sha256:abc","build":{"context":"."}},
+		"worker":{"build":{"context":"worker"}},
+		"mariadb":{"image":"mariadb:11"}
+	}}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	targets, err := buildTargets(doc, nil, "museum", "", "museum-abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []buildTarget{
+		{Service: "drupal", Image: "islandora/drupal:museum-abc123"},
+		{Service: "worker", Image: "museum-worker:museum-abc123"},
+	}
+	if !slices.Equal(targets, want) {
+		t.Errorf("targets = %+v, want %+v", targets, want)
+	}
+
+	targets, err = buildTargets(doc, []string{"drupal"}, "museum", "ghcr.io/libraries/", "v1")
+	if err != nil || !slices.Equal(targets, []buildTarget{{Service: "drupal", Image: "ghcr.io/libraries/drupal:v1"}}) {
+		t.Errorf("registry targets = %+v, %v", targets, err)
+	}
+	for _, services := range [][]string{{"mariadb"}, {"solr"}} {
+		if _, err := buildTargets(doc, services, "museum", "", "v1"); err == nil {
+			t.Errorf("buildTargets(%q) expected error", services)
+		}
+	}
+}
+
+func TestImageRepository(t *testing.T) {
+	t.Parallel()
+
+	for image, want := range map[string]string{
+		"drupal":                          "drupal",
+		"islandora/drupal:4.1":            "islandora/drupal",
+		"registry:5000/site/drupal":       "registry:5000/site/drupal",
+		"registry:5000/site/drupal:main":  "registry:5000/site/drupal",
+		"ghcr.io/org/app@sha256:deadbeef": "ghcr.io/org/app",
+	} {
+		if got := imageRepository(image); got != want {
+			t.Errorf("imageRepository(%q) = %q, want %q", image, got, want)
+		}
+	}
+}
+
+func TestBuildBuilderEndpointRefusesJumpHost(t *testing.T) {
+	t.Parallel()
+
+	ctx := &config.Context{Name: "museum prod", SSHUser: "deploy", SSHHostname: "10.0.0.5", SSHPort: 22, SSHJumpHost: "bastion.example.edu"}
+	if _, err := buildBuilderEndpoint(ctx); err == nil || !strings.Contains(err.Error(), "bastion.example.edu") {
+		t.Fatalf("buildBuilderEndpoint() error = %v, want a jump host error", err)
+	}
+}
+
+func TestBuildPlanBakeArgs(t *testing.T) {
+	t.Parallel()
+
+	ctx := &config.Context{Name: "museum prod", SSHUser: "deploy", SSHHostname: "museum.example.edu", SSHPort: 2222}
+	endpoint, err := buildBuilderEndpoint(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := buildPlan{
+		Builder:   buildBuilderName(ctx),
+		Endpoint:  endpoint,
+		Platforms: []string{"linux/amd64", "linux/arm64"},
+		Push:      true,
+		Targets:   []buildTarget{{Service: "drupal", Image: "ghcr.io/libraries/drupal:museum-abc"}},
+	}
+	if plan.Builder != "sitectl-museum-prod" || plan.Endpoint != "ssh://deploy@museum.example.edu:2222" {
+		t.Errorf("builder = %q at %q", plan.Builder, plan.Endpoint)
+	}
+	want := []string{
+		"buildx", "bake", "--file", "compose.json", "--builder", "sitectl-museum-prod",
+		"--set", "*.platform=linux/amd64,linux/arm64",
+		"--set", "drupal.tags=ghcr.io/libraries/drupal:museum-abc",
+		"--push", "drupal",
+	}
+	if got := plan.bakeArgs("compose.json"); !slices.Equal(got, want) {
+		t.Errorf("bakeArgs() = %q, want %q", got, want)
+	}
+
+	plan = buildPlan{Targets: plan.Targets}
+	if got := plan.bakeArgs("compose.json"); !slices.Contains(got, "--load") || slices.Contains(got, "--builder") {
+		t.Errorf("local bakeArgs() = %q", got)
+	}
+}
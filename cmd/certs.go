@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	corecomponent "github.com/libops/sitectl/pkg/component"
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/healthcheck"
+	coretraefik "github.com/libops/sitectl/pkg/services/traefik"
+	"github.com/spf13/cobra"
+)
+
+func certsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "certs",
+		Short:   "Manage local TLS certificates for a context",
+		GroupID: "setup",
+	}
+	cmd.AddCommand(certsTrustCommand())
+	return cmd
+}
+
+func certsTrustCommand() *cobra.Command {
+	opts := struct {
+		domains []string
+		yolo    bool
+	}{}
+	cmd := &cobra.Command{
+		Use:   "trust",
+		Short: "Create locally-trusted HTTPS certificates with mkcert",
+		Long: `Create locally-trusted certificates for the project's domains with mkcert.
+
+sitectl installs mkcert when it is missing (after confirmation), adds the mkcert root CA to
+the host trust store with mkcert -install, and writes certs/cert.pem and certs/privkey.pem in
+the project directory, where the https-mkcert ingress mode configures Traefik to read them.
+The certificate covers the given domains (default: DOMAIN and INGRESS_HOSTNAMES from .env,
+or localhost) plus localhost, 127.0.0.1 and ::1.
+
+This is limited to local and development environments.
+
+Examples:
+  sitectl certs trust                           # Certificates for the .env domain
+  sitectl certs trust --domain mysite.local     # Certificates for https://mysite.local
+  sitectl set ingress enabled --mode https-mkcert --domain mysite.local`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := resolveCurrentContext(cmd)
+			if err != nil {
+				return err
+			}
+			domains := opts.domains
+			if len(domains) == 0 {
+				domains = certsProjectDomains(healthcheck.ProjectEnv(ctx))
+			}
+			hosts, err := coretraefik.TrustMkcertCertificates(cmd.Context(), ctx, domains, corecomponent.ApplyOptions{Yolo: opts.yolo})
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Wrote a locally-trusted certificate for %s\n", strings.Join(hosts, ", "))
+			if ctx.DockerHostType == config.ContextRemote {
+				fmt.Fprintln(out, "The mkcert root CA was trusted on the remote host; import it from `mkcert -CAROOT` on that host to trust it in your browser.")
+			}
+			fmt.Fprintln(out, "Restart Traefik to load it: sitectl compose restart traefik")
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVar(&opts.domains, "domain", nil, "Domain to include on the certificate (repeatable)")
+	cmd.Flags().BoolVar(&opts.yolo, "yolo", false, "Install mkcert without prompting when it is missing")
+	return cmd
+}
+
+// certsProjectDomains reads the ingress hostnames a project serves from its
+// .env values.
+func certsProjectDomains(env map[string]string) []string {
+	var domains []string
+	if domain := strings.TrimSpace(env["DOMAIN"]); domain != "" {
+		domains = append(domains, domain)
+	}
+	for _, hostname := range strings.Split(env["INGRESS_HOSTNAMES"], ",") {
+		if hostname = strings.TrimSpace(hostname); hostname != "" {
+			domains = append(domains, hostname)
+		}
+	}
+	return domains
+}
+
+func init() {
+	RootCmd.AddCommand(certsCommand())
+}
@@ -0,0 +1,388 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/kballard/go-shellquote"
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/helpers"
+	"github.com/spf13/cobra"
+)
+
+// CI providers supported by sitectl ci render.
+const (
+	ciProviderGitHub = "github"
+	ciProviderGitLab = "gitlab"
+)
+
+// ciSSHKeyPath is where the generated pipelines write the deploy key. It is
+// expanded by the runner's shell, so it is never shell-quoted.
+const ciSSHKeyPath = `"$HOME/.ssh/sitectl"`
+
+// ciPipeline is the data a CI template renders from.
+type ciPipeline struct {
+	Context     string
+	Environment string
+	Branch      string
+	Binaries    []string
+	Version     string
+	Build       bool
+	Registry    string
+	SetContext  string
+}
+
+var ciCmd = &cobra.Command{
+	Use:   "ci",
+	Short: "Generate CI pipelines that build and deploy a site with sitectl",
+}
+
+var ciRenderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Render a build-push-deploy pipeline for the current context",
+	Long: `Render a CI pipeline implementing the recommended sitectl rollout for a remote context.
+
+The pipeline installs sitectl (and the context's plugin) from GitHub releases, then:
+  1. runs sitectl scan on every push and merge request
+  2. builds images when compose services declare a build section
+  3. logs in to --registry and pushes the built images from the deploy branch
+  4. recreates the context from its current settings, runs sitectl preflight,
+     deploys the branch with sitectl deploy and waits for healthy services
+
+The pipeline expects two CI secrets: SITECTL_SSH_KEY with the private deploy key, and
+SITECTL_KNOWN_HOSTS with the host's known_hosts entries (ssh-keyscan output you have
+verified). Pushing to a registry also needs REGISTRY_USERNAME and REGISTRY_PASSWORD.
+
+Examples:
+  sitectl ci render --context prod > .github/workflows/deploy.yml
+  sitectl ci render --context prod --provider gitlab --output-file .gitlab-ci.yml
+  sitectl ci render --context prod --registry ghcr.io --branch release`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f := cmd.Flags()
+		provider, err := f.GetString("provider")
+		if err != nil {
+			return err
+		}
+		branch, err := f.GetString("branch")
+		if err != nil {
+			return err
+		}
+		registry, err := f.GetString("registry")
+		if err != nil {
+			return err
+		}
+		version, err := f.GetString("sitectl-version")
+		if err != nil {
+			return err
+		}
+		ctx, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+
+		build := false
+		doc, err := preflightComposeConfig(cmd.Context(), ctx)
+		if err != nil {
+			slog.Warn("could not read the compose config; the pipeline will not build images", "context", ctx.Name, "err", err)
+		} else {
+			build = ciComposeBuilds(doc)
+		}
+
+		pipeline, err := newCIPipeline(ctx, branch, registry, version, build)
+		if err != nil {
+			return err
+		}
+		return renderCIPipeline(cmd.OutOrStdout(), provider, pipeline)
+	},
+}
+
+// newCIPipeline collects the settings a pipeline needs to recreate ctx on a
+// CI runner.
+func newCIPipeline(ctx *config.Context, branch, registry, version string, build bool) (ciPipeline, error) {
+	if ctx.DockerHostType != config.ContextRemote {
+		return ciPipeline{}, fmt.Errorf("context %q is %s; ci render needs a remote context to deploy to", ctx.Name, ctx.DockerHostType)
+	}
+	branch = strings.TrimSpace(branch)
+	if branch == "" {
+		return ciPipeline{}, fmt.Errorf("--branch cannot be empty")
+	}
+	registry = strings.TrimSpace(registry)
+	if registry != "" && !build {
+		return ciPipeline{}, fmt.Errorf("no compose service in context %q builds an image, so there is nothing to push to %s", ctx.Name, registry)
+	}
+
+	setContext, err := ciSetContextCommand(ctx)
+	if err != nil {
+		return ciPipeline{}, err
+	}
+	binaries := []string{"sitectl"}
+	if plugin := strings.TrimSpace(ctx.Plugin); plugin != "" && plugin != "core" {
+		binaries = append(binaries, "sitectl-"+plugin)
+	}
+	return ciPipeline{
+		Context:     ctx.Name,
+		Environment: strings.TrimSpace(ctx.Environment),
+		Branch:      branch,
+		Binaries:    binaries,
+		Version:     helpers.FirstNonEmpty(strings.TrimSpace(version), "latest"),
+		Build:       build,
+		Registry:    registry,
+		SetContext:  setContext,
+	}, nil
+}
+
+// ciSetContextCommand returns the sitectl config set-context command that
+// recreates ctx on a runner, using the deploy key written by the pipeline.
+// Runners have no ~/.ssh/config, so ssh-config-host aliases are written out
+// as the host, port and user they resolve to.
+func ciSetContextCommand(ctx *config.Context) (string, error) {
+	endpoint, err := ctx.ResolvedSSHEndpoint()
+	if err != nil {
+		return "", err
+	}
+	args := []string{"sitectl", "config", "set-context", ctx.Name,
+		"--type", string(config.ContextRemote),
+		"--ssh-hostname", endpoint.Hostname,
+		"--ssh-port", strconv.FormatUint(uint64(endpoint.Port), 10),
+	}
+	for _, flag := range [][2]string{
+		{"--ssh-user", endpoint.User},
+		{"--project-dir", ctx.ProjectDir},
+		{"--site", ctx.Site},
+		{"--site-id", ctx.SiteID},
+		{"--plugin", ctx.Plugin},
+		{"--environment", ctx.Environment},
+		{"--project-name", ctx.ProjectName},
+		{"--compose-project-name", ctx.ComposeProjectName},
+		{"--compose-network", ctx.ComposeNetwork},
+	} {
+		if strings.TrimSpace(flag[1]) != "" {
+			args = append(args, flag[0], flag[1])
+		}
+	}
+	for _, file := range ctx.ComposeFile {
+		args = append(args, "--compose-file", file)
+	}
+	for _, file := range ctx.EnvFile {
+		args = append(args, "--env-file", file)
+	}
+	args = append(args, "--default")
+	return shellquote.Join(args...) + " --ssh-key " + ciSSHKeyPath, nil
+}
+
+// ciComposeBuilds reports whether any compose service builds its image.
+func ciComposeBuilds(doc composeConfigDocument) bool {
+	names := make([]string, 0, len(doc.Services))
+	for name := range doc.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if serviceHasBuild(doc.Services[name]) {
+			return true
+		}
+	}
+	return false
+}
+
+func renderCIPipeline(out io.Writer, provider string, pipeline ciPipeline) error {
+	var text string
+	switch provider {
+	case ciProviderGitHub:
+		text = ciGitHubTemplate
+	case ciProviderGitLab:
+		text = ciGitLabTemplate
+	default:
+		return fmt.Errorf("unsupported provider %q: use %s or %s", provider, ciProviderGitHub, ciProviderGitLab)
+	}
+	tmpl, err := template.New(provider).Funcs(template.FuncMap{
+		"quote":   shellquote.Join,
+		"install": ciInstallScript,
+	}).Parse(text)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(out, pipeline)
+}
+
+// ciInstallScript returns shell lines that download the Linux release archive
+// of each binary to /usr/local/bin. Plugins are published from their own
+// libops repositories with the same archive naming as sitectl.
+func ciInstallScript(sudo string, pipeline ciPipeline) []string {
+	lines := []string{}
+	for _, binary := range pipeline.Binaries {
+		url := fmt.Sprintf("https://github.com/libops/%s/releases/latest/download/%s_Linux_x86_64.tar.gz", binary, binary)
+		if pipeline.Version != "latest" && binary == "sitectl" {
+			url = fmt.Sprintf("https://github.com/libops/sitectl/releases/download/%s/sitectl_Linux_x86_64.tar.gz", pipeline.Version)
+		}
+		lines = append(lines, fmt.Sprintf("curl -fsSL %s | %star -xz -C /usr/local/bin %s", url, sudo, binary))
+	}
+	return lines
+}
+
+const ciGitHubTemplate = `# Generated by sitectl ci render for context {{ .Context }}.
+# Regenerate it with sitectl ci render instead of editing it by hand.
+name: sitectl deploy ({{ .Context }})
+
+on:
+  push:
+    branches: [{{ .Branch }}]
+  pull_request:
+    branches: [{{ .Branch }}]
+  workflow_dispatch:
+
+concurrency:
+  group: sitectl-deploy-{{ .Context }}
+  cancel-in-progress: false
+
+env:
+  SITECTL_NON_INTERACTIVE: "1"
+
+jobs:
+  check:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - name: Install sitectl
+        run: |
+{{- range install "sudo " . }}
+          {{ . }}
+{{- end }}
+      - name: Scan for committed secrets
+        run: sitectl scan .
+{{- if .Build }}
+      - name: Build images
+        run: docker compose build
+{{- end }}
+{{- if .Registry }}
+
+  push:
+    needs: check
+    if: github.ref == 'refs/heads/{{ .Branch }}' && github.event_name != 'pull_request'
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - name: Log in to {{ .Registry }}
+        env:
+          REGISTRY_USERNAME: ${{ "{{" }} secrets.REGISTRY_USERNAME {{ "}}" }}
+          REGISTRY_PASSWORD: ${{ "{{" }} secrets.REGISTRY_PASSWORD {{ "}}" }}
+        run: printf '%s' "$REGISTRY_PASSWORD" | docker login {{ quote .Registry }} --username "$REGISTRY_USERNAME" --password-stdin
+      - name: Build and push images
+        run: |
+          docker compose build
+          docker compose push
+{{- end }}
+
+  deploy:
+    needs: [check{{ if .Registry }}, push{{ end }}]
+    if: github.ref == 'refs/heads/{{ .Branch }}' && github.event_name != 'pull_request'
+    runs-on: ubuntu-latest
+{{- if .Environment }}
+    environment: {{ .Environment }}
+{{- end }}
+    steps:
+      - name: Install sitectl
+        run: |
+{{- range install "sudo " . }}
+          {{ . }}
+{{- end }}
+      - name: Configure SSH
+        env:
+          SITECTL_SSH_KEY: ${{ "{{" }} secrets.SITECTL_SSH_KEY {{ "}}" }}
+          SITECTL_KNOWN_HOSTS: ${{ "{{" }} secrets.SITECTL_KNOWN_HOSTS {{ "}}" }}
+        run: |
+          install -d -m 700 "$HOME/.ssh"
+          printf '%s\n' "$SITECTL_SSH_KEY" > "$HOME/.ssh/sitectl"
+          chmod 600 "$HOME/.ssh/sitectl"
+          printf '%s\n' "$SITECTL_KNOWN_HOSTS" >> "$HOME/.ssh/known_hosts"
+      - name: Configure context
+        run: {{ .SetContext }}
+      - name: Preflight
+        run: sitectl preflight --context {{ quote .Context }} --branch {{ quote .Branch }}
+      - name: Deploy
+        run: sitectl deploy --context {{ quote .Context }} --branch {{ quote .Branch }} --skip-scan --wait
+`
+
+const ciGitLabTemplate = `# Generated by sitectl ci render for context {{ .Context }}.
+# Regenerate it with sitectl ci render instead of editing it by hand.
+stages:
+  - check
+{{- if .Registry }}
+  - push
+{{- end }}
+  - deploy
+
+variables:
+  SITECTL_NON_INTERACTIVE: "1"
+
+default:
+  image: docker:27
+  before_script:
+    - apk add --no-cache bash curl git openssh-client
+{{- range install "" . }}
+    - {{ . }}
+{{- end }}
+
+check:
+  stage: check
+{{- if .Build }}
+  services:
+    - docker:27-dind
+{{- end }}
+  rules:
+    - if: $CI_PIPELINE_SOURCE == "merge_request_event"
+    - if: $CI_COMMIT_BRANCH == "{{ .Branch }}"
+  script:
+    - sitectl scan .
+{{- if .Build }}
+    - docker compose build
+{{- end }}
+{{- if .Registry }}
+
+push:
+  stage: push
+  services:
+    - docker:27-dind
+  rules:
+    - if: $CI_COMMIT_BRANCH == "{{ .Branch }}"
+  script:
+    - printf '%s' "$REGISTRY_PASSWORD" | docker login {{ quote .Registry }} --username "$REGISTRY_USERNAME" --password-stdin
+    - docker compose build
+    - docker compose push
+{{- end }}
+
+deploy:
+  stage: deploy
+  resource_group: sitectl-deploy-{{ .Context }}
+{{- if .Environment }}
+  environment:
+    name: {{ .Environment }}
+{{- end }}
+  rules:
+    - if: $CI_COMMIT_BRANCH == "{{ .Branch }}"
+  script:
+    - install -d -m 700 "$HOME/.ssh"
+    - printf '%s\n' "$SITECTL_SSH_KEY" > "$HOME/.ssh/sitectl"
+    - chmod 600 "$HOME/.ssh/sitectl"
+    - printf '%s\n' "$SITECTL_KNOWN_HOSTS" >> "$HOME/.ssh/known_hosts"
+    - {{ .SetContext }}
+    - sitectl preflight --context {{ quote .Context }} --branch {{ quote .Branch }}
+    - sitectl deploy --context {{ quote .Context }} --branch {{ quote .Branch }} --skip-scan --wait
+`
+
+func init() {
+	ciRenderCmd.Flags().String("provider", ciProviderGitHub, "CI provider to render for: github or gitlab")
+	ciRenderCmd.Flags().String("branch", "main", "Branch whose pushes deploy the context")
+	ciRenderCmd.Flags().String("registry", "", "Registry to log in to and push built images to before deploying")
+	ciRenderCmd.Flags().String("sitectl-version", "latest", "sitectl release tag the pipeline installs")
+	enableOutputFile(ciRenderCmd)
+	ciCmd.AddCommand(ciRenderCmd)
+	ciCmd.GroupID = "workflow"
+	RootCmd.AddCommand(ciCmd)
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/libops/sitectl/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+func testCIContext() *config.Context {
+	return &config.Context{
+		Name:           "prod",
+		DockerHostType: config.ContextRemote,
+		Plugin:         "isle",
+		Environment:    "production",
+		SSHHostname:    "prod.example.edu",
+		SSHUser:        "deploy",
+		SSHPort:        22,
+		ProjectDir:     "/opt/sites/my site",
+		ComposeFile:    []string{"docker-compose.yml", "docker-compose.prod.yml"},
+	}
+}
+
+func TestNewCIPipeline(t *testing.T) {
+	t.Parallel()
+
+	pipeline, err := newCIPipeline(testCIContext(), "main", "", "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(pipeline.Binaries, ",") != "sitectl,sitectl-isle" || pipeline.Version != "latest" {
+		t.Errorf("pipeline = %+v", pipeline)
+	}
+	want := `sitectl config set-context prod --type remote --ssh-hostname prod.example.edu --ssh-port 22 --ssh-user deploy --project-dir '/opt/sites/my site' --plugin isle --environment production --compose-file docker-compose.yml --compose-file docker-compose.prod.yml --default --ssh-key "$HOME/.ssh/sitectl"`
+	if pipeline.SetContext != want {
+		t.Errorf("SetContext = %s\nwant %s", pipeline.SetContext, want)
+	}
+
+	local := testCIContext()
+	local.DockerHostType = config.ContextLocal
+	if _, err := newCIPipeline(local, "main", "", "", true); err == nil {
+		t.Error("local context: expected error")
+	}
+	if _, err := newCIPipeline(testCIContext(), "main", "ghcr.io", "", false); err == nil {
+		t.Error("registry without build: expected error")
+	}
+}
+
+func TestCISetContextResolvesSSHConfigHost(t *testing.T) {
+	fakeSSHConfig(t, "user deploy\nhostname 10.0.0.5\nport 2222\nidentityfile /keys/prod\nproxyjump none\nproxycommand none")
+	ctx := testCIContext()
+	ctx.SSHHostname, ctx.SSHUser, ctx.SSHPort = "", "", 0
+	ctx.SSHConfigHost = "prod"
+
+	got, err := ciSetContextCommand(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "--ssh-hostname 10.0.0.5 --ssh-port 2222 --ssh-user deploy ") {
+		t.Errorf("SetContext = %s", got)
+	}
+}
+
+func TestCIComposeBuilds(t *testing.T) {
+	t.Parallel()
+
+	doc := composeConfigDocument{Services: map[string]composeConfigService{"mariadb": {Image: "mariadb:11"}}}
+	if ciComposeBuilds(doc) {
+		t.Error("image-only services reported as building")
+	}
+	doc.Services["drupal"] = composeConfigService{Build: json.RawMessage(`{"context":"."}`)}
+	if !ciComposeBuilds(doc) {
+		t.Error("build service not detected")
+	}
+}
+
+func TestRenderCIPipeline(t *testing.T) {
+	t.Parallel()
+
+	pipeline, err := newCIPipeline(testCIContext(), "main", "ghcr.io", "v1.2.3", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for provider, wants := range map[string][]string{
+		ciProviderGitHub: {
+			"sudo tar -xz -C /usr/local/bin sitectl",
+			"releases/download/v1.2.3/sitectl_Linux_x86_64.tar.gz",
+			"releases/latest/download/sitectl-isle_Linux_x86_64.tar.gz",
+			"needs: [check, push]",
+			"environment: production",
+			"${{ secrets.SITECTL_SSH_KEY }}",
+			"sitectl deploy --context prod --branch main --skip-scan --wait",
+		},
+		ciProviderGitLab: {
+			"| tar -xz -C /usr/local/bin sitectl",
+			"resource_group: sitectl-deploy-prod",
+			"docker compose push",
+			"sitectl preflight --context prod --branch main",
+		},
+	} {
+		var out bytes.Buffer
+		if err := renderCIPipeline(&out, provider, pipeline); err != nil {
+			t.Fatalf("%s: %v", provider, err)
+		}
+		var doc map[string]any
+		if err := yaml.Unmarshal(out.Bytes(), &doc); err != nil {
+			t.Fatalf("%s: rendered invalid YAML: %v\n%s", provider, err, out.String())
+		}
+		for _, want := range wants {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("%s: output missing %q\n%s", provider, want, out.String())
+			}
+		}
+	}
+
+	if err := renderCIPipeline(&bytes.Buffer{}, "jenkins", pipeline); err == nil {
+		t.Error("unknown provider: expected error")
+	}
+}
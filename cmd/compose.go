@@ -2,12 +2,20 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"slices"
+	"strings"
 
+	"github.com/kballard/go-shellquote"
 	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/docker"
+	"github.com/libops/sitectl/pkg/logfile"
+	"github.com/libops/sitectl/pkg/logfilter"
+	"github.com/libops/sitectl/pkg/notify"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var composeCmd = &cobra.Command{
@@ -24,12 +32,22 @@ Automatic behaviors:
   - 'compose up' automatically adds '-d --remove-orphans' if not already specified
   - Compose file paths (-f flags) are injected from context.ComposeFile setting
   - Env file paths (--env-file flags) are injected from context.EnvFile setting
+  - Variables in the context's compose-env map are exported into docker compose's environment;
+    a value of keyring:ACCOUNT or env:NAME is read from the system keyring or sitectl's environment
   - Working directory is set to context.ProjectDir
+  - 'compose up' on a production context first runs sitectl scan and asks for confirmation
+    when it finds potential secret leaks ('--skip-scan' proceeds without asking)
 
 Examples:
   sitectl compose up                    # Start containers in detached mode
   sitectl compose down                  # Stop and remove containers
   sitectl compose logs -f drupal        # Follow drupal container logs
+  sitectl compose logs -f --log-file logs/site.log --log-compress
+                                        # Also capture logs in rotated files
+  sitectl compose logs --since 30m --timestamps --grep 'error|warn' drupal
+                                        # Filter recent drupal logs, highlighting matches
+  sitectl compose logs -f --output json | jq .message
+                                        # Stream logs as one JSON object per line
   sitectl compose ps                    # List running containers
   sitectl compose exec -it drupal bash      # Open shell in drupal container
   sitectl compose --context prod up     # Start containers on prod context`,
@@ -40,6 +58,8 @@ Examples:
 		if err != nil {
 			return err
 		}
+		// --read-only was applied in PersistentPreRunE
+		filteredArgs = slices.DeleteFunc(filteredArgs, isReadOnlyFlag)
 
 		validCommands := []string{
 			"attach",
@@ -102,12 +122,25 @@ Examples:
 			}
 		}
 
+		if filteredArgs[0] == "up" {
+			skipScan := slices.Contains(filteredArgs, "--skip-scan")
+			filteredArgs = slices.DeleteFunc(filteredArgs, func(arg string) bool { return arg == "--skip-scan" })
+			if err := confirmProductionScan(cmd, &context, "compose up", skipScan); err != nil {
+				return err
+			}
+		}
+
 		// consider adding a flag to not do this
 		// but this seems like a nice default for ISLE projects
 		if filteredArgs[0] == "up" && !slices.Contains(filteredArgs, "-d") && !slices.Contains(filteredArgs, "--detach") {
 			filteredArgs = append(filteredArgs, "-d", "--remove-orphans")
 		}
 		filteredArgs = context.DockerComposeSubcommandArgs(filteredArgs)
+		if filteredArgs[0] == "logs" {
+			if remaining, logArgs := splitLogArgs(filteredArgs); len(logArgs) > 0 {
+				return runComposeLogs(cmd, &context, remaining, logArgs)
+			}
+		}
 		if shouldAutoReconcileComposeUp(filteredArgs) {
 			handled, err := maybeRunComposeReconcile(cmd, &context)
 			if err != nil {
@@ -148,6 +181,120 @@ Examples:
 	},
 }
 
+// splitLogArgs separates sitectl's --grep filter and --log-* capture flags
+// from the docker compose arguments, since flag parsing is disabled for this
+// command.
+func splitLogArgs(args []string) ([]string, []string) {
+	remaining := []string{}
+	logArgs := []string{}
+	for i := 0; i < len(args); i++ {
+		name, _, hasValue := strings.Cut(strings.TrimPrefix(args[i], "--"), "=")
+		switch {
+		case !strings.HasPrefix(args[i], "--"):
+			remaining = append(remaining, args[i])
+		case name == "log-compress" || hasValue && slices.Contains(logValueFlags, name):
+			logArgs = append(logArgs, args[i])
+		case slices.Contains(logValueFlags, name) && i+1 < len(args):
+			logArgs = append(logArgs, args[i], args[i+1])
+			i++
+		default:
+			remaining = append(remaining, args[i])
+		}
+	}
+	return remaining, logArgs
+}
+
+var logValueFlags = []string{"grep", "output", "log-file", "log-max-size", "log-rotate-every", "log-max-files"}
+
+// runComposeLogs streams compose logs through the --grep filter and into a
+// rotating log file. --since, --until, --tail and --timestamps are docker
+// compose logs flags and stay in args. With --output json the logs are read
+// from the Docker API instead and written as JSON lines.
+func runComposeLogs(cmd *cobra.Command, ctx *config.Context, args, logArgs []string) error {
+	flags := pflag.NewFlagSet("logs", pflag.ContinueOnError)
+	logFile := logfile.AddFlags(flags)
+	filter := logfilter.AddFlags(flags, "")
+	if err := flags.Parse(logArgs); err != nil {
+		return err
+	}
+	opts, err := logFile.Options()
+	if err != nil {
+		return err
+	}
+	if !opts.Enabled() && slices.ContainsFunc(logArgs, func(arg string) bool { return strings.HasPrefix(arg, "--log-") }) {
+		return fmt.Errorf("--log-file is required with --log-* flags")
+	}
+	filterOpts, err := filter.Options()
+	if err != nil {
+		return err
+	}
+	tee, closeLog, err := logfile.Tee(cmd.OutOrStdout(), opts)
+	if err != nil {
+		return err
+	}
+	if filterOpts.Output == logfilter.OutputJSON {
+		err = runComposeJSONLogs(cmd, ctx, args, filterOpts, tee)
+		if closeErr := closeLog(); err == nil {
+			err = closeErr
+		}
+		return err
+	}
+	stdout, flush := logfilter.Filter(tee, filterOpts, logfilter.Highlight(cmd.OutOrStdout()))
+	command := "docker compose " + shellquote.Join(args...)
+	err = pluginSDK.RunComposeProjectCommandContext(cmd.Context(), ctx, ctx.ProjectDir, stdout, cmd.ErrOrStderr(), command)
+	if flushErr := flush(); err == nil {
+		err = flushErr
+	}
+	if closeErr := closeLog(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// runComposeJSONLogs writes the logs selected by the docker compose logs args
+// to out as JSON lines.
+func runComposeJSONLogs(cmd *cobra.Command, ctx *config.Context, args []string, filterOpts logfilter.Options, out io.Writer) error {
+	services, follow, err := parseComposeLogsArgs(args, &filterOpts)
+	if err != nil {
+		return err
+	}
+	cli, err := docker.GetDockerCli(ctx)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+	api, err := cli.Logs()
+	if err != nil {
+		return err
+	}
+	return logfilter.WriteJSON(cmd.Context(), api, ctx.EffectiveComposeProjectName(), services, follow, filterOpts, out)
+}
+
+// parseComposeLogsArgs reads the services and docker compose logs flags from
+// args ("logs" followed by its flags and services) into opts, and reports
+// whether --follow was passed. Formatting flags such as --no-color do not
+// apply to JSON output and are ignored.
+func parseComposeLogsArgs(args []string, opts *logfilter.Options) ([]string, bool, error) {
+	flags := pflag.NewFlagSet("logs", pflag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	follow := flags.BoolP("follow", "f", false, "")
+	since := flags.String("since", opts.Since, "")
+	until := flags.String("until", opts.Until, "")
+	tail := flags.StringP("tail", "n", opts.Tail, "")
+	flags.BoolP("timestamps", "t", false, "")
+	flags.Bool("no-color", false, "")
+	flags.Bool("no-log-prefix", false, "")
+	flags.Int("index", 0, "")
+	if len(args) > 0 && args[0] == "logs" {
+		args = args[1:]
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, false, fmt.Errorf("unsupported docker compose logs arguments with --output json: %w", err)
+	}
+	opts.Since, opts.Until, opts.Tail = *since, *until, *tail
+	return flags.Args(), *follow, nil
+}
+
 func isComposeUpCommand(args []string) bool {
 	if len(args) == 0 {
 		return false
@@ -177,5 +324,6 @@ func shouldAutoReconcileComposeUp(args []string) bool {
 
 func init() {
 	composeCmd.GroupID = "ops"
+	composeCmd.Annotations = map[string]string{notify.Annotation: "build pull up"}
 	RootCmd.AddCommand(composeCmd)
 }
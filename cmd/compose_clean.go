@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/i18n"
 	"github.com/libops/sitectl/pkg/plugin"
 	"github.com/spf13/cobra"
 )
@@ -35,7 +36,6 @@ secrets, certificates, and env files declared by the plugin can be lost.`,
 }
 
 func init() {
-	composeCleanCmd.Flags().Bool("yes", false, "Skip the destructive confirmation prompt.")
 	composeCmd.AddCommand(composeCleanCmd)
 }
 
@@ -44,6 +44,11 @@ func runComposeCleanCommand(cmd *cobra.Command, yes bool) error {
 	if err != nil {
 		return err
 	}
+	if ctx != nil {
+		if err := ctx.RequireUnprotected("delete volumes and init files"); err != nil {
+			return err
+		}
+	}
 	if err := confirmComposeClean(ctx, yes); err != nil {
 		return err
 	}
@@ -60,7 +65,7 @@ func runComposeCleanCommand(cmd *cobra.Command, yes bool) error {
 	if err := composeReconcileClear(ctx, spec); err != nil {
 		return err
 	}
-	fmt.Fprintln(cmd.OutOrStdout(), "Compose project cleaned")
+	fmt.Fprintln(cmd.OutOrStdout(), i18n.T(i18n.ComposeCleaned))
 	return nil
 }
 
@@ -89,9 +94,6 @@ func composeCleanContext(cmd *cobra.Command) (*config.Context, plugin.CreateSpec
 }
 
 func confirmComposeClean(ctx *config.Context, yes bool) error {
-	if yes {
-		return nil
-	}
 	contextName := ""
 	projectDir := ""
 	if ctx != nil {
@@ -101,12 +103,15 @@ func confirmComposeClean(ctx *config.Context, yes bool) error {
 	if contextName == "" {
 		contextName = "this context"
 	}
+	if config.AutoConfirmTyped(fmt.Sprintf("delete compose volumes and init files of %q", contextName), yes) {
+		return nil
+	}
 	token := "delete " + contextName
 	input, err := composeCleanInput(
-		fmt.Sprintf("This will permanently delete local Docker Compose volumes and plugin init files for %q.", contextName),
-		fmt.Sprintf("Project directory: %s", projectDir),
-		"Database contents, uploaded files stored in named volumes, generated secrets, certificates, and declared env files can be lost.",
-		fmt.Sprintf("Type %q to continue: ", token),
+		i18n.T(i18n.ComposeCleanWarning, contextName),
+		i18n.T(i18n.ComposeCleanProjectDir, projectDir),
+		i18n.T(i18n.ComposeCleanDataLossNotice),
+		i18n.T(i18n.TypeToContinuePrompt, token),
 	)
 	if err != nil {
 		return err
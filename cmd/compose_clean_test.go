@@ -31,3 +31,16 @@ func TestConfirmComposeCleanRequiresDeleteToken(t *testing.T) {
 		t.Fatalf("confirmComposeClean() error = %v", err)
 	}
 }
+
+func TestConfirmComposeCleanHonorsGlobalYes(t *testing.T) {
+	oldInput := composeCleanInput
+	t.Cleanup(func() { composeCleanInput = oldInput })
+	composeCleanInput = func(question ...string) (string, error) {
+		t.Fatal("unexpected prompt with global --yes")
+		return "", nil
+	}
+	t.Setenv(config.AssumeYesEnv, "1")
+	if err := confirmComposeClean(&config.Context{Name: "wp", ProjectDir: "/tmp/wp"}, false); err != nil {
+		t.Fatalf("confirmComposeClean() error = %v", err)
+	}
+}
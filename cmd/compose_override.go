@@ -0,0 +1,330 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/helpers"
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v3"
+)
+
+const composeOverrideHeader = "# Local development overrides generated by sitectl compose override init.\n# Review and edit freely; rerun with --force to regenerate.\n"
+
+// composeOverrideWellKnownPorts are the container ports published for
+// services whose base definition neither exposes nor publishes a port.
+var composeOverrideWellKnownPorts = map[string]int{
+	"mariadb":  3306,
+	"mysql":    3306,
+	"postgres": 5432,
+	"solr":     8983,
+	"redis":    6379,
+	"memcache": 11211,
+	"mailpit":  8025,
+}
+
+// composeOverridePHPHints mark services that get Xdebug settings by default.
+var composeOverridePHPHints = []string{"php", "drupal", "wordpress", "islandora"}
+
+var composeOverrideCmd = &cobra.Command{
+	Use:   "override",
+	Short: "Manage the local development Compose override file",
+}
+
+var composeOverrideInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Generate a local development Compose override from the base compose file",
+	Long: `Generate a Compose override for local development from the base compose file.
+
+The override:
+  - publishes each service's exposed (or well-known, such as 3306 for mariadb) ports
+    on 127.0.0.1, moving ports below 1024 up by 8000 and skipping ports in use by
+    another service
+  - enables Xdebug (XDEBUG_MODE=debug, client host host.docker.internal) for PHP
+    services; choose them with --xdebug, or pass --xdebug none
+  - bind mounts source code into services given with --mount SERVICE=HOST:CONTAINER,
+    and the Drupal rootfs of the context into the drupal service when one is set
+
+When the context has an environment the file is written as docker-compose.ENV.yml
+and linked as docker-compose.override.yml, like other tracked overrides; otherwise it
+is written as docker-compose.override.yml. The override is added to the context's
+compose-file list.
+
+Examples:
+  sitectl compose override init
+  sitectl compose override init --mount drupal=./codebase:/var/www/drupal --xdebug drupal
+  sitectl compose override init --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f := cmd.Flags()
+		force, err := f.GetBool("force")
+		if err != nil {
+			return err
+		}
+		dryRun, err := f.GetBool("dry-run")
+		if err != nil {
+			return err
+		}
+		xdebug, err := f.GetStringSlice("xdebug")
+		if err != nil {
+			return err
+		}
+		mountFlags, err := f.GetStringArray("mount")
+		if err != nil {
+			return err
+		}
+
+		ctx, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+		if ctx.DockerHostType != config.ContextLocal {
+			return fmt.Errorf("compose override init is for local development; context %q is %s", ctx.Name, ctx.DockerHostType)
+		}
+		baseFiles := composeOverrideBaseFiles(ctx)
+		if len(baseFiles) == 0 {
+			return fmt.Errorf("no compose file found in %s", ctx.ProjectDir)
+		}
+		base, err := readComposeOverrideSource(ctx.ProjectDir, baseFiles)
+		if err != nil {
+			return err
+		}
+		mounts, err := parseComposeOverrideMounts(mountFlags)
+		if err != nil {
+			return err
+		}
+		if ctx.DrupalRootfs != "" {
+			if _, ok := base.Services["drupal"]; ok && len(mounts["drupal"]) == 0 {
+				mounts["drupal"] = []string{"./" + strings.TrimPrefix(ctx.EffectiveDrupalRootfs(), "./") + ":" + ctx.EffectiveDrupalContainerRoot()}
+			}
+		}
+		override, err := generateComposeOverride(base, xdebug, mounts)
+		if err != nil {
+			return err
+		}
+		data, err := yaml.Marshal(override)
+		if err != nil {
+			return err
+		}
+		data = append([]byte(composeOverrideHeader), data...)
+		if dryRun {
+			_, err := cmd.OutOrStdout().Write(data)
+			return err
+		}
+
+		path := helpers.FirstNonEmpty(ctx.TrackedComposeOverridePath(), ctx.RuntimeComposeOverridePath())
+		if _, err := os.Lstat(path); err == nil && !force {
+			return fmt.Errorf("%s already exists; pass --force to replace it", path)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil { // #nosec G306 -- compose files are project source, not secrets.
+			return err
+		}
+		if err := ctx.EnsureTrackedComposeOverrideSymlink(); err != nil {
+			return err
+		}
+		if err := updateStoredContext(ctx.Name, func(c *config.Context) {
+			c.ComposeFile = registerComposeOverride(c.ComposeFile, baseFiles)
+		}); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s\n", path)
+		return nil
+	},
+}
+
+// composeOverrideSource is the part of a base compose file the override is
+// generated from.
+type composeOverrideSource struct {
+	Services map[string]composeOverrideSourceService `yaml:"services"`
+}
+
+type composeOverrideSourceService struct {
+	Image  string    `yaml:"image"`
+	Build  yaml.Node `yaml:"build"`
+	Expose []string  `yaml:"expose"`
+	Ports  []any     `yaml:"ports"`
+}
+
+type composeOverrideFile struct {
+	Services map[string]composeOverrideService `yaml:"services"`
+}
+
+type composeOverrideService struct {
+	Ports       []string          `yaml:"ports,omitempty"`
+	Environment map[string]string `yaml:"environment,omitempty"`
+	ExtraHosts  []string          `yaml:"extra_hosts,omitempty"`
+	Volumes     []string          `yaml:"volumes,omitempty"`
+}
+
+// composeOverrideBaseFiles returns the context's compose files other than
+// overrides, relative to the project directory when they are inside it.
+func composeOverrideBaseFiles(ctx *config.Context) []string {
+	candidates := ctx.ComposeFile
+	if len(candidates) == 0 {
+		candidates = []string{"docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml"}
+	}
+	var files []string
+	for _, file := range candidates {
+		name := filepath.Base(file)
+		if strings.Contains(name, ".override.") || name == ctx.TrackedComposeOverrideName() {
+			continue
+		}
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(ctx.ProjectDir, file)
+		}
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+func readComposeOverrideSource(projectDir string, files []string) (composeOverrideSource, error) {
+	merged := composeOverrideSource{Services: map[string]composeOverrideSourceService{}}
+	for _, file := range files {
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(projectDir, file)
+		}
+		data, err := os.ReadFile(path) // #nosec G304 -- path is one of the context's compose files.
+		if err != nil {
+			return composeOverrideSource{}, err
+		}
+		var source composeOverrideSource
+		if err := yaml.Unmarshal(data, &source); err != nil {
+			return composeOverrideSource{}, fmt.Errorf("parse %s: %w", file, err)
+		}
+		for name, service := range source.Services {
+			merged.Services[name] = service
+		}
+	}
+	return merged, nil
+}
+
+func parseComposeOverrideMounts(values []string) (map[string][]string, error) {
+	mounts := map[string][]string{}
+	for _, value := range values {
+		service, mount, ok := strings.Cut(value, "=")
+		host, container, hasContainer := strings.Cut(mount, ":")
+		if !ok || strings.TrimSpace(service) == "" || !hasContainer || host == "" || !strings.HasPrefix(container, "/") {
+			return nil, fmt.Errorf("invalid --mount %q: expected SERVICE=HOST_PATH:/container/path", value)
+		}
+		mounts[service] = append(mounts[service], mount)
+	}
+	return mounts, nil
+}
+
+// generateComposeOverride builds the local development override for base.
+// xdebug lists the services to enable Xdebug for; empty selects PHP services
+// by name and image, and "none" disables it.
+func generateComposeOverride(base composeOverrideSource, xdebug []string, mounts map[string][]string) (composeOverrideFile, error) {
+	names := make([]string, 0, len(base.Services))
+	for name := range base.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, service := range append(slices.Clone(xdebug), slices.Collect(maps.Keys(mounts))...) {
+		if _, ok := base.Services[service]; !ok && service != "none" {
+			return composeOverrideFile{}, fmt.Errorf("unknown compose service %q", service)
+		}
+	}
+
+	override := composeOverrideFile{Services: map[string]composeOverrideService{}}
+	usedPorts := map[int]bool{}
+	for _, name := range names {
+		source := base.Services[name]
+		var service composeOverrideService
+		if len(source.Ports) == 0 {
+			for _, port := range composeOverrideContainerPorts(name, source) {
+				host := port
+				if host < 1024 {
+					host += 8000
+				}
+				for usedPorts[host] {
+					host++
+				}
+				usedPorts[host] = true
+				service.Ports = append(service.Ports, fmt.Sprintf("127.0.0.1:%d:%d", host, port))
+			}
+		}
+		if composeOverrideWantsXdebug(name, source, xdebug) {
+			service.Environment = map[string]string{
+				"XDEBUG_MODE":   "debug",
+				"XDEBUG_CONFIG": "client_host=host.docker.internal",
+			}
+			service.ExtraHosts = []string{"host.docker.internal:host-gateway"}
+		}
+		service.Volumes = mounts[name]
+		if len(service.Ports) > 0 || len(service.Environment) > 0 || len(service.Volumes) > 0 {
+			override.Services[name] = service
+		}
+	}
+	if len(override.Services) == 0 {
+		return composeOverrideFile{}, fmt.Errorf("nothing to override: no ports to publish, PHP services or --mount flags")
+	}
+	return override, nil
+}
+
+// composeOverrideContainerPorts returns the service's exposed container ports,
+// or its well-known port.
+func composeOverrideContainerPorts(name string, service composeOverrideSourceService) []int {
+	var ports []int
+	for _, expose := range service.Expose {
+		port, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(expose), "/tcp"), "/udp"))
+		if err == nil && port > 0 {
+			ports = append(ports, port)
+		}
+	}
+	if len(ports) > 0 {
+		return ports
+	}
+	image := imageRepository(service.Image)
+	image = image[strings.LastIndex(image, "/")+1:]
+	for _, key := range []string{name, image} {
+		if port, ok := composeOverrideWellKnownPorts[key]; ok {
+			return []int{port}
+		}
+	}
+	return nil
+}
+
+func composeOverrideWantsXdebug(name string, service composeOverrideSourceService, xdebug []string) bool {
+	if len(xdebug) > 0 {
+		return slices.Contains(xdebug, name)
+	}
+	for _, hint := range composeOverridePHPHints {
+		if strings.Contains(name, hint) || strings.Contains(service.Image, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// registerComposeOverride adds the runtime override file to a context's
+// compose-file list, listing the base files first when the list was empty.
+func registerComposeOverride(files, baseFiles []string) []string {
+	if slices.Contains(files, config.RuntimeComposeOverrideName) {
+		return files
+	}
+	if len(files) == 0 {
+		files = slices.Clone(baseFiles)
+	}
+	return append(files, config.RuntimeComposeOverrideName)
+}
+
+func init() {
+	composeOverrideInitCmd.Flags().Bool("force", false, "Replace an existing override file")
+	composeOverrideInitCmd.Flags().Bool("dry-run", false, "Print the generated override instead of writing it")
+	composeOverrideInitCmd.Flags().StringSlice("xdebug", nil, `Services to enable Xdebug for (default: PHP services), or "none"`)
+	composeOverrideInitCmd.Flags().StringArray("mount", nil, "Bind mount source code as SERVICE=HOST_PATH:/container/path; may be passed more than once")
+	composeOverrideCmd.AddCommand(composeOverrideInitCmd)
+	composeCmd.AddCommand(composeOverrideCmd)
+}
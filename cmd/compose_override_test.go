@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/libops/sitectl/pkg/config"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func TestGenerateComposeOverride(t *testing.T) {
+	t.Parallel()
+
+	var base composeOverrideSource
+	if err := yaml.Unmarshal([]byte(`services:
+  drupal:
+    image: islandora/drupal:4
+    expose: ["80"]
+  traefik:
+    image: traefik:3
+    ports: ["80:80"]
+  mariadb:
+    image: mariadb:11
+  solr:
+    image: islandora/solr:4
+  nginx:
+    image: nginx:1
+    expose: ["80/tcp"]
+`), &base); err != nil {
+		t.Fatal(err)
+	}
+
+	override, err := generateComposeOverride(base, nil, map[string][]string{"drupal": {"./codebase:/var/www/drupal"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	drupal := override.Services["drupal"]
+	if !slices.Equal(drupal.Ports, []string{"127.0.0.1:8080:80"}) {
+		t.Errorf("drupal ports = %v", drupal.Ports)
+	}
+	if drupal.Environment["XDEBUG_MODE"] != "debug" || !slices.Equal(drupal.ExtraHosts, []string{"host.docker.internal:host-gateway"}) {
+		t.Errorf("drupal xdebug = %v %v", drupal.Environment, drupal.ExtraHosts)
+	}
+	if !slices.Equal(drupal.Volumes, []string{"./codebase:/var/www/drupal"}) {
+		t.Errorf("drupal volumes = %v", drupal.Volumes)
+	}
+	if got := override.Services["nginx"].Ports; !slices.Equal(got, []string{"127.0.0.1:8081:80"}) {
+		t.Errorf("nginx ports = %v, want the next free host port", got)
+	}
+	if got := override.Services["mariadb"].Ports; !slices.Equal(got, []string{"127.0.0.1:3306:3306"}) {
+		t.Errorf("mariadb ports = %v", got)
+	}
+	if got := override.Services["solr"].Ports; !slices.Equal(got, []string{"127.0.0.1:8983:8983"}) {
+		t.Errorf("solr ports = %v", got)
+	}
+	if _, ok := override.Services["traefik"]; ok {
+		t.Error("traefik already publishes ports and should not be overridden")
+	}
+
+	override, err = generateComposeOverride(base, []string{"none"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env := override.Services["drupal"].Environment; len(env) != 0 {
+		t.Errorf("--xdebug none environment = %v", env)
+	}
+
+	if _, err := generateComposeOverride(base, []string{"php"}, nil); err == nil {
+		t.Error("expected an error for an unknown --xdebug service")
+	}
+	if _, err := generateComposeOverride(base, nil, map[string][]string{"php": {"./src:/app"}}); err == nil {
+		t.Error("expected an error for an unknown --mount service")
+	}
+}
+
+func TestParseComposeOverrideMounts(t *testing.T) {
+	t.Parallel()
+
+	mounts, err := parseComposeOverrideMounts([]string{"drupal=./codebase:/var/www/drupal", "drupal=./modules:/var/www/drupal/web/modules/custom"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mounts["drupal"]) != 2 {
+		t.Errorf("mounts = %v", mounts)
+	}
+	for _, value := range []string{"./codebase:/var/www/drupal", "drupal=./codebase", "drupal=./codebase:relative", "=./a:/a"} {
+		if _, err := parseComposeOverrideMounts([]string{value}); err == nil {
+			t.Errorf("parseComposeOverrideMounts(%q) succeeded", value)
+		}
+	}
+}
+
+func TestComposeOverrideBaseFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"docker-compose.yml", "docker-compose.override.yml", "docker-compose.dev.yml"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("services: {}\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := &config.Context{ProjectDir: dir}
+	if got := composeOverrideBaseFiles(ctx); !slices.Equal(got, []string{"docker-compose.yml"}) {
+		t.Errorf("default base files = %v", got)
+	}
+	ctx.Environment = "dev"
+	ctx.ComposeFile = []string{"docker-compose.yml", "docker-compose.dev.yml", "docker-compose.override.yml"}
+	if got := composeOverrideBaseFiles(ctx); !slices.Equal(got, []string{"docker-compose.yml"}) {
+		t.Errorf("base files = %v, want the tracked and runtime overrides skipped", got)
+	}
+}
+
+func TestRegisterComposeOverride(t *testing.T) {
+	t.Parallel()
+
+	if got := registerComposeOverride(nil, []string{"docker-compose.yml"}); !slices.Equal(got, []string{"docker-compose.yml", config.RuntimeComposeOverrideName}) {
+		t.Errorf("empty list = %v", got)
+	}
+	files := []string{"docker-compose.yml", config.RuntimeComposeOverrideName}
+	if got := registerComposeOverride(files, nil); !slices.Equal(got, files) {
+		t.Errorf("registered twice: %v", got)
+	}
+}
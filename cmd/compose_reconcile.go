@@ -87,7 +87,9 @@ type composeConfigService struct {
 }
 
 type composeConfigSecret struct {
-	File string `json:"file"`
+	File        string `json:"file"`
+	Environment string `json:"environment"`
+	External    bool   `json:"external"`
 }
 
 type composeConfigVolume struct {
@@ -535,8 +537,13 @@ func readComposeConfigDocument(ctx *config.Context) (composeConfigDocument, erro
 	args = append(args, ctx.DockerComposeGlobalArgs()...)
 	args = append(args, "config", "--format", "json")
 
+	composeEnv, err := ctx.ComposeEnvironment()
+	if err != nil {
+		return composeConfigDocument{}, err
+	}
 	command := exec.Command("docker", args...) // #nosec G204 -- fixed docker compose command with context-owned compose/env file arguments.
 	command.Dir = ctx.ProjectDir
+	command.Env = config.AppendEnvOverrides(os.Environ(), composeEnv)
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
 	command.Stdout = &stdout
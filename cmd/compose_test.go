@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/libops/sitectl/pkg/logfilter"
+)
+
+func TestSplitLogArgs(t *testing.T) {
+	t.Parallel()
+
+	remaining, logArgs := splitLogArgs([]string{"logs", "-f", "--log-file", "site.log", "--tail=50", "--log-max-size=10", "--log-compress", "--grep", "error|warn", "--output=json", "drupal"})
+	if want := []string{"logs", "-f", "--tail=50", "drupal"}; !reflect.DeepEqual(remaining, want) {
+		t.Errorf("remaining = %q, want %q", remaining, want)
+	}
+	if want := []string{"--log-file", "site.log", "--log-max-size=10", "--log-compress", "--grep", "error|warn", "--output=json"}; !reflect.DeepEqual(logArgs, want) {
+		t.Errorf("log args = %q, want %q", logArgs, want)
+	}
+
+	remaining, logArgs = splitLogArgs([]string{"logs", "--timestamps", "mariadb"})
+	if len(logArgs) != 0 || len(remaining) != 3 {
+		t.Errorf("plain logs split = %q, %q", remaining, logArgs)
+	}
+}
+
+func TestParseComposeLogsArgs(t *testing.T) {
+	t.Parallel()
+
+	opts := logfilter.Options{Tail: "all", Output: logfilter.OutputJSON}
+	services, follow, err := parseComposeLogsArgs([]string{"logs", "-f", "--since=30m", "-n", "50", "--no-color", "drupal", "mariadb"}, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"drupal", "mariadb"}; !reflect.DeepEqual(services, want) {
+		t.Errorf("services = %q, want %q", services, want)
+	}
+	if !follow || opts.Since != "30m" || opts.Tail != "50" {
+		t.Errorf("follow = %v, opts = %+v", follow, opts)
+	}
+
+	if _, _, err := parseComposeLogsArgs([]string{"logs", "--bogus"}, &opts); err == nil {
+		t.Error("expected an unknown flag to fail")
+	}
+}
@@ -30,7 +30,18 @@ A sitectl context is a docker compose site running somewhere. "Somewhere" meanin
 Remote contexts require SSH access to the remote server from where sitectl is being ran from.
 When creating a context the remote server DNS name, SSH port, SSH username, and the path to your SSH private key will need to be set in the context configuration.
 
-You can have a default context which will be used when running sitectl commands, unless the context is overridden with the --context flag.`,
+You can have a default context which will be used when running sitectl commands, unless the context is overridden with the --context flag.
+
+A defaults: section in the config file replaces the built-in defaults of common flags:
+
+  defaults:
+    format: json    # --format of commands that support the format
+    color: never    # auto, always or never
+    confirm: yes    # prompt, or yes to auto-confirm like --yes
+    read-only: true # refuse commands that change sites, like --read-only
+
+Flags passed on the command line still take precedence. confirm: yes does not answer
+prompts that ask you to type a name, such as db push; pass --yes for those.`,
 }
 
 var viewConfigCmd = &cobra.Command{
@@ -86,6 +97,9 @@ var getContextsCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if cfg.Contexts, err = selectContextsByLabel(cmd, cfg.Contexts); err != nil {
+			return err
+		}
 		if len(cfg.Contexts) == 0 {
 			fmt.Fprintln(cmd.OutOrStdout(), "No contexts available")
 			return nil
@@ -103,6 +117,9 @@ var getSitesCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if cfg.Contexts, err = selectContextsByLabel(cmd, cfg.Contexts); err != nil {
+			return err
+		}
 		if len(cfg.Contexts) == 0 {
 			fmt.Fprintln(cmd.OutOrStdout(), "No sites available")
 			return nil
@@ -160,6 +177,9 @@ var getEnvironmentsCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if cfg.Contexts, err = selectContextsByLabel(cmd, cfg.Contexts); err != nil {
+			return err
+		}
 		if len(cfg.Contexts) == 0 {
 			fmt.Fprintln(cmd.OutOrStdout(), "No environments available")
 			return nil
@@ -201,7 +221,7 @@ var getEnvironmentsCmd = &cobra.Command{
 		for _, ctx := range contexts {
 			host := "-"
 			if ctx.DockerHostType == config.ContextRemote {
-				host = helpers.FirstNonEmpty(ctx.SSHHostname, "-")
+				host = helpers.FirstNonEmpty(ctx.SSHHostname, ctx.SSHConfigHost, "-")
 			}
 			name := ctx.Name
 			if ctx.Name == cfg.CurrentContext {
@@ -222,9 +242,10 @@ var getEnvironmentsCmd = &cobra.Command{
 }
 
 var (
-	configValidateAll    bool
-	configValidateSite   string
-	configValidateFormat string
+	configValidateAll      bool
+	configValidateSite     string
+	configValidateSelector string
+	configValidateFormat   string
 )
 
 var validateConfigCmd = &cobra.Command{
@@ -288,6 +309,25 @@ var setContextCmd = &cobra.Command{
 			return err
 		}
 		cc.Name = args[0]
+		labels, err := f.GetStringArray("label")
+		if err != nil {
+			return err
+		}
+		changes, err := config.ParseLabelChanges(labels)
+		if err != nil {
+			return err
+		}
+		cc.ApplyLabelChanges(changes)
+		if cc.SiteID != "" {
+			if err := validateSiteID(cc.SiteID); err != nil {
+				return err
+			}
+		}
+		if cc.APIURL != "" {
+			if err := config.ValidateAPIURL(cc.APIURL); err != nil {
+				return err
+			}
+		}
 
 		defaultContext, err := f.GetBool("default")
 		if err != nil {
@@ -362,6 +402,9 @@ var deleteContextCmd = &cobra.Command{
 		var newContexts []config.Context
 		for _, ctx := range cfg.Contexts {
 			if ctx.Name == name {
+				if err := ctx.RequireUnprotected("delete the context"); err != nil {
+					return err
+				}
 				found = true
 				continue
 			}
@@ -379,31 +422,204 @@ var deleteContextCmd = &cobra.Command{
 	},
 }
 
+var protectContextCmd = &cobra.Command{
+	Use:   "protect-context [context-name]",
+	Short: "Refuse destructive operations on a context until it is unprotected",
+	Long: `Mark a context as protected. Destructive commands (database imports, sync, pull and push
+into it, files pull, snapshot restore, cache flush, compose clean and delete-context) refuse to
+run against a protected context, even with --yes or --yolo.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setContextProtection(cmd, args[0], true)
+	},
+}
+
+var unprotectContextCmd = &cobra.Command{
+	Use:   "unprotect-context [context-name]",
+	Short: "Allow destructive operations on a protected context again",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setContextProtection(cmd, args[0], false)
+	},
+}
+
+func setContextProtection(cmd *cobra.Command, name string, protected bool) error {
+	ctx, err := config.GetContext(name)
+	if err != nil {
+		return err
+	}
+	state := "unprotected"
+	if protected {
+		state = "protected"
+	}
+	if ctx.Protected == protected {
+		fmt.Fprintf(cmd.OutOrStdout(), "Context %s is already %s\n", name, state)
+		return nil
+	}
+	ctx.Protected = protected
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	for i := range cfg.Contexts {
+		if cfg.Contexts[i].Name == name {
+			cfg.Contexts[i] = ctx
+		}
+	}
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Context %s is now %s\n", name, state)
+	return nil
+}
+
 func writeContextTable(out io.Writer, cfg *config.Config) {
 	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "CURRENT\tCONTEXT\tSITE\tPLUGIN\tENVIRONMENT\tTYPE\tPROJECT")
+	fmt.Fprintln(w, "CURRENT\tCONTEXT\tSITE\tLINKED\tPLUGIN\tENVIRONMENT\tTYPE\tPROJECT\tLABELS")
 	for _, ctx := range cfg.Contexts {
 		activeMark := ""
 		if ctx.Name == cfg.CurrentContext {
 			activeMark = "*"
 		}
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
 			activeMark,
 			ctx.Name,
 			helpers.FirstNonEmpty(ctx.Site, "-"),
+			helpers.FirstNonEmpty(ctx.SiteID, "-"),
 			helpers.FirstNonEmpty(ctx.Plugin, "-"),
 			helpers.FirstNonEmpty(ctx.Environment, "-"),
 			helpers.FirstNonEmpty(string(ctx.DockerHostType), "-"),
 			helpers.FirstNonEmpty(ctx.ProjectName, "-"),
+			helpers.FirstNonEmpty(config.FormatLabels(ctx.Labels), "-"),
 		)
 	}
 	_ = w.Flush()
 }
 
+var labelContextCmd = &cobra.Command{
+	Use:   "label [context-name...] key=value|key-...",
+	Short: "Add, change, or remove labels on one or more contexts",
+	Long: `Add, change, or remove labels on contexts. key=value sets a label and key- removes it.
+Select contexts by name or with --selector.
+
+Labels tag contexts with ownership or purpose, such as owner=web-team or tier=public, and
+are matched by --selector on config get-contexts, get-sites, get-environments and validate.
+
+Examples:
+  sitectl config label museum-prod museum-stage owner=web-team
+  sitectl config label --selector site=museum tier=public
+  sitectl config label museum-stage owner-`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var names, labelArgs []string
+		for _, arg := range args {
+			if strings.Contains(arg, "=") || strings.HasSuffix(arg, "-") {
+				labelArgs = append(labelArgs, arg)
+			} else {
+				names = append(names, arg)
+			}
+		}
+		if len(labelArgs) == 0 {
+			return fmt.Errorf("no labels given: use key=value to set or key- to remove")
+		}
+		changes, err := config.ParseLabelChanges(labelArgs)
+		if err != nil {
+			return err
+		}
+		selector, err := cmd.Flags().GetString("selector")
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 && strings.TrimSpace(selector) == "" {
+			return fmt.Errorf("name one or more contexts or pass --selector")
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		targets := map[string]bool{}
+		for _, name := range names {
+			targets[name] = false
+		}
+		matched, err := selectContextsByLabel(cmd, cfg.Contexts)
+		if err != nil {
+			return err
+		}
+		selected := map[string]bool{}
+		if strings.TrimSpace(selector) != "" {
+			if len(matched) == 0 && len(names) == 0 {
+				return fmt.Errorf("no contexts match selector %q", selector)
+			}
+			for _, ctx := range matched {
+				selected[ctx.Name] = true
+			}
+		}
+
+		updated := 0
+		for i := range cfg.Contexts {
+			name := cfg.Contexts[i].Name
+			_, named := targets[name]
+			if !named && !selected[name] {
+				continue
+			}
+			targets[name] = true
+			if cfg.Contexts[i].ApplyLabelChanges(changes) {
+				updated++
+				fmt.Fprintf(cmd.OutOrStdout(), "Labeled context %s: %s\n", name, helpers.FirstNonEmpty(config.FormatLabels(cfg.Contexts[i].Labels), "-"))
+			}
+		}
+		for _, name := range names {
+			if !targets[name] {
+				return fmt.Errorf("context %q not found", name)
+			}
+		}
+		if updated == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No labels changed")
+			return nil
+		}
+		return config.Save(cfg)
+	},
+}
+
+// addContextSelectorFlag adds --selector to a command that lists or acts on
+// several contexts.
+func addContextSelectorFlag(cmd *cobra.Command, target *string) {
+	help := "Only include contexts whose labels match, e.g. owner=web-team,tier!=internal"
+	if target != nil {
+		cmd.Flags().StringVarP(target, "selector", "l", "", help)
+		return
+	}
+	cmd.Flags().StringP("selector", "l", "", help)
+}
+
+// selectContextsByLabel filters contexts by the command's --selector flag.
+func selectContextsByLabel(cmd *cobra.Command, contexts []config.Context) ([]config.Context, error) {
+	raw, err := cmd.Flags().GetString("selector")
+	if err != nil {
+		return nil, err
+	}
+	selector, err := config.ParseLabelSelector(raw)
+	if err != nil {
+		return nil, err
+	}
+	return selector.FilterContexts(contexts), nil
+}
+
 func resolveValidationContexts(cmd *cobra.Command, cfg *config.Config, args []string) ([]config.Context, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("config is nil")
 	}
+	if strings.TrimSpace(configValidateSelector) != "" {
+		selected, err := selectContextsByLabel(cmd, cfg.Contexts)
+		if err != nil {
+			return nil, err
+		}
+		if len(selected) == 0 {
+			return nil, fmt.Errorf("no contexts match selector %q", configValidateSelector)
+		}
+		return filterValidationContexts(selected), nil
+	}
 	if configValidateAll {
 		return filterValidationContexts(cfg.Contexts), nil
 	}
@@ -475,10 +691,18 @@ func init() {
 	setFlags := setContextCmd.Flags()
 	config.SetCommandFlags(setFlags)
 	setFlags.Bool("default", false, "set to default context")
+	setFlags.StringArray("label", nil, "Set a label with key=value or remove one with key- (repeatable)")
 
 	validateConfigCmd.Flags().BoolVar(&configValidateAll, "all", false, "Validate all configured contexts")
 	validateConfigCmd.Flags().StringVar(&configValidateSite, "site", "", "Validate all contexts for a specific site")
+	addContextSelectorFlag(validateConfigCmd, &configValidateSelector)
+	for _, cmd := range []*cobra.Command{getContextsCmd, getSitesCmd, getEnvironmentsCmd, labelContextCmd} {
+		addContextSelectorFlag(cmd, nil)
+	}
 	corecomponent.AddReportFlags(validateConfigCmd, nil, &configValidateFormat)
+	declareFormats(validateConfigCmd, corecomponent.ReportFormatSection, corecomponent.ReportFormatTable, "json", "yaml")
+
+	enableOutputFile(viewConfigCmd, getContextsCmd, getSitesCmd, getEnvironmentsCmd)
 
 	configCmd.AddCommand(viewConfigCmd)
 	configCmd.AddCommand(currentContextCmd)
@@ -489,6 +713,9 @@ func init() {
 	configCmd.AddCommand(setContextCmd)
 	configCmd.AddCommand(useContextCmd)
 	configCmd.AddCommand(deleteContextCmd)
+	configCmd.AddCommand(labelContextCmd)
+	configCmd.AddCommand(protectContextCmd)
+	configCmd.AddCommand(unprotectContextCmd)
 	configCmd.GroupID = "setup"
 	RootCmd.AddCommand(configCmd)
 }
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func TestLabelContextCommand(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	for _, ctx := range []config.Context{
+		{Name: "museum-prod", Site: "museum", Environment: "prod", DockerHostType: config.ContextLocal, ProjectDir: tempHome},
+		{Name: "museum-stage", Site: "museum", Environment: "stage", DockerHostType: config.ContextLocal, ProjectDir: tempHome},
+		{Name: "library-prod", Site: "library", Environment: "prod", DockerHostType: config.ContextLocal, ProjectDir: tempHome},
+	} {
+		if err := config.SaveContext(&ctx, false); err != nil {
+			t.Fatalf("SaveContext() error = %v", err)
+		}
+	}
+
+	run := func(selector string, args ...string) (string, error) {
+		t.Helper()
+		if err := labelContextCmd.Flags().Set("selector", selector); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = labelContextCmd.Flags().Set("selector", "") })
+		var out bytes.Buffer
+		labelContextCmd.SetOut(&out)
+		err := labelContextCmd.RunE(labelContextCmd, args)
+		return out.String(), err
+	}
+
+	if _, err := run("", "museum-prod", "museum-stage", "owner=web-team"); err != nil {
+		t.Fatalf("label by name error = %v", err)
+	}
+	if _, err := run("owner=web-team", "tier=public"); err != nil {
+		t.Fatalf("label by selector error = %v", err)
+	}
+	if _, err := run("", "museum-stage", "owner-"); err != nil {
+		t.Fatalf("remove label error = %v", err)
+	}
+
+	want := map[string]string{
+		"museum-prod":  "owner=web-team,tier=public",
+		"museum-stage": "tier=public",
+		"library-prod": "",
+	}
+	for name, labels := range want {
+		ctx, err := config.GetContext(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := config.FormatLabels(ctx.Labels); got != labels {
+			t.Errorf("%s labels = %q, want %q", name, got, labels)
+		}
+	}
+
+	out, err := run("", "museum-prod", "owner=web-team")
+	if err != nil || !strings.Contains(out, "No labels changed") {
+		t.Fatalf("no-op label = %q, %v", out, err)
+	}
+	if _, err := run("", "missing", "owner=x"); err == nil || !strings.Contains(err.Error(), `context "missing" not found`) {
+		t.Fatalf("missing context error = %v", err)
+	}
+	if _, err := run("owner=nobody", "tier=x"); err == nil {
+		t.Fatal("expected error when the selector matches no contexts")
+	}
+	if _, err := run("", "museum-prod"); err == nil {
+		t.Fatal("expected error without label arguments")
+	}
+}
+
+func TestProtectContextBlocksDelete(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	for _, ctx := range []config.Context{
+		{Name: "current", DockerHostType: config.ContextLocal, ProjectDir: tempHome},
+		{Name: "prod", DockerHostType: config.ContextLocal, ProjectDir: tempHome},
+	} {
+		if err := config.SaveContext(&ctx, ctx.Name == "current"); err != nil {
+			t.Fatalf("SaveContext() error = %v", err)
+		}
+	}
+	var out bytes.Buffer
+	for _, cmd := range []*cobra.Command{protectContextCmd, unprotectContextCmd, deleteContextCmd} {
+		cmd.SetOut(&out)
+	}
+
+	if err := protectContextCmd.RunE(protectContextCmd, []string{"prod"}); err != nil {
+		t.Fatalf("protect-context error = %v", err)
+	}
+	err := deleteContextCmd.RunE(deleteContextCmd, []string{"prod"})
+	if !errors.Is(err, config.ErrContextProtected) {
+		t.Fatalf("delete-context on protected context = %v, want ErrContextProtected", err)
+	}
+	if _, err := config.GetContext("prod"); err != nil {
+		t.Fatalf("protected context was removed: %v", err)
+	}
+
+	if err := unprotectContextCmd.RunE(unprotectContextCmd, []string{"prod"}); err != nil {
+		t.Fatalf("unprotect-context error = %v", err)
+	}
+	if err := deleteContextCmd.RunE(deleteContextCmd, []string{"prod"}); err != nil {
+		t.Fatalf("delete-context after unprotect = %v", err)
+	}
+	if !strings.Contains(out.String(), "Context prod is now protected") || !strings.Contains(out.String(), "Context prod is now unprotected") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
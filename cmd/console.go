@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/helpers"
+	"github.com/spf13/cobra"
+)
+
+// consoleURLEnv overrides the libops web console base URL, for example to
+// open a staging console.
+const consoleURLEnv = "SITECTL_CONSOLE_URL"
+
+const defaultConsoleURL = "https://console.libops.io"
+
+var consoleCmd = &cobra.Command{
+	Use:   "console",
+	Short: "Open the libops web console for an org, project or site",
+	Long: `Open the libops web console at the page for an organization, project or site.
+
+Without flags, console opens the site the active context is linked to (see sitectl link).
+--site opens a site by ID, and --org with an optional --project opens an organization or
+one of its projects.
+
+The console base URL defaults to ` + defaultConsoleURL + ` and can be changed with
+--console-url or the ` + consoleURLEnv + ` environment variable.
+
+Examples:
+  sitectl console                           # The linked site of the active context
+  sitectl console --context museum-prod
+  sitectl console --site museum
+  sitectl console --org libraries --project museum
+  sitectl console --no-open                 # Print the URL instead of opening a browser`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f := cmd.Flags()
+		base, err := f.GetString("console-url")
+		if err != nil {
+			return err
+		}
+		org, err := f.GetString("org")
+		if err != nil {
+			return err
+		}
+		project, err := f.GetString("project")
+		if err != nil {
+			return err
+		}
+		site, err := f.GetString("site")
+		if err != nil {
+			return err
+		}
+		noOpen, err := f.GetBool("no-open")
+		if err != nil {
+			return err
+		}
+
+		if strings.TrimSpace(org) == "" && strings.TrimSpace(project) == "" && strings.TrimSpace(site) == "" {
+			ctx, err := resolveCurrentContext(cmd)
+			if err != nil {
+				return err
+			}
+			if ctx.SiteID == "" {
+				return fmt.Errorf("context %q is not linked to a site: pass --site or --org, or run sitectl link SITE_ID", ctx.Name)
+			}
+			site = ctx.SiteID
+		}
+		target, err := consoleURL(helpers.FirstNonEmpty(base, os.Getenv(consoleURLEnv), defaultConsoleURL), org, project, site)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(cmd.OutOrStdout(), target)
+		if noOpen {
+			return nil
+		}
+		if err := helpers.OpenURL(target); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "unable to open a browser: %v\n", err)
+		}
+		return nil
+	},
+}
+
+// consoleURL builds the console page URL for a site, or for an organization
+// and optionally one of its projects.
+func consoleURL(base, org, project, site string) (string, error) {
+	base = strings.TrimRight(strings.TrimSpace(base), "/")
+	if err := config.ValidateAPIURL(base); err != nil {
+		return "", fmt.Errorf("invalid console URL %q: expected an http:// or https:// URL", base)
+	}
+	org, project, site = strings.TrimSpace(org), strings.TrimSpace(project), strings.TrimSpace(site)
+
+	var segments []string
+	switch {
+	case site != "" && (org != "" || project != ""):
+		return "", fmt.Errorf("--site cannot be combined with --org or --project")
+	case site != "":
+		segments = []string{"sites", site}
+	case org == "":
+		return "", fmt.Errorf("--project needs --org")
+	default:
+		segments = []string{"orgs", org}
+		if project != "" {
+			segments = append(segments, "projects", project)
+		}
+	}
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return base + "/" + strings.Join(segments, "/"), nil
+}
+
+func init() {
+	consoleCmd.Flags().String("org", "", "Organization to open")
+	consoleCmd.Flags().String("project", "", "Project within --org to open")
+	consoleCmd.Flags().String("site", "", "Site ID to open (default: the site linked to the active context)")
+	consoleCmd.Flags().String("console-url", "", "libops web console base URL (default: "+consoleURLEnv+" or "+defaultConsoleURL+")")
+	consoleCmd.Flags().Bool("no-open", false, "Print the console URL without opening a browser")
+	consoleCmd.GroupID = "workflow"
+	RootCmd.AddCommand(consoleCmd)
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import "testing"
+
+func TestConsoleURL(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		base, org, project, site string
+		want                     string
+	}{
+		{base: "https://console.example.com/", site: "museum", want: "https://console.example.com/sites/museum"},
+		{base: "https://console.example.com", org: "libraries", want: "https://console.example.com/orgs/libraries"},
+		{base: "https://console.example.com", org: "libraries", project: "special collections", want: "https://console.example.com/orgs/libraries/projects/special%20collections"},
+	} {
+		got, err := consoleURL(tc.base, tc.org, tc.project, tc.site)
+		if err != nil || got != tc.want {
+			t.Errorf("consoleURL(%+v) = %q, %v, want %q", tc, got, err, tc.want)
+		}
+	}
+
+	for _, tc := range []struct{ base, org, project, site string }{
+		{base: "console.example.com", site: "museum"},
+		{base: "https://console.example.com", org: "libraries", site: "museum"},
+		{base: "https://console.example.com", project: "museum"},
+	} {
+		if got, err := consoleURL(tc.base, tc.org, tc.project, tc.site); err == nil {
+			t.Errorf("consoleURL(%+v) = %q, expected error", tc, got)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/notify"
 	"github.com/libops/sitectl/pkg/plugin"
 	"github.com/spf13/cobra"
 )
@@ -78,5 +79,6 @@ Examples:
 
 func init() {
 	convergeCmd.GroupID = "workflow"
+	convergeCmd.Annotations = map[string]string{notify.Annotation: ""}
 	RootCmd.AddCommand(convergeCmd)
 }
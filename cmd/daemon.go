@@ -0,0 +1,445 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// daemonStartTimeout bounds how long daemon start waits for the control socket.
+const daemonStartTimeout = 10 * time.Second
+
+// daemonRequest is one request on the daemon control socket.
+type daemonRequest struct {
+	Op      string   `json:"op"`
+	Context string   `json:"context,omitempty"`
+	Specs   []string `json:"specs,omitempty"`
+}
+
+// daemonResponse answers a daemonRequest.
+type daemonResponse struct {
+	Error     string          `json:"error,omitempty"`
+	PID       int             `json:"pid"`
+	StartedAt time.Time       `json:"started_at"`
+	Forwards  []daemonForward `json:"forwards"`
+}
+
+// daemonForward is a group of port forwards the daemon keeps open.
+type daemonForward struct {
+	ID        int       `json:"id"`
+	Context   string    `json:"context"`
+	Specs     []string  `json:"specs"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Keep port forwards running in the background",
+	Long: `Run a background sitectl process that holds port forwards open, so a terminal does not
+have to stay pinned for tunnels.
+
+The daemon listens on a control socket in ~/.sitectl/run and logs to ~/.sitectl/run/daemon.log.
+Its tunnels are bound to 127.0.0.1 like sitectl port-forward and also appear in
+sitectl port-forward list.
+
+Examples:
+  sitectl daemon start --preset dev --context stage
+  sitectl daemon start 8983:solr:8983 --context prod
+  sitectl daemon status
+  sitectl daemon stop`,
+}
+
+var daemonStartCmd = &cobra.Command{
+	Use:   "start [LOCAL-PORT:SERVICE:REMOTE-PORT...]",
+	Args:  cobra.ArbitraryArgs,
+	Short: "Start the daemon and hand it port forwards",
+	Long: `Start the daemon if it is not running. Specs given as arguments or through --preset are
+forwarded from the current context by the daemon; running start again adds more.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		presets, err := cmd.Flags().GetStringArray("preset")
+		if err != nil {
+			return err
+		}
+		var specs []portForwardSpec
+		var contextName string
+		if len(presets) > 0 || len(args) > 0 {
+			c, err := resolveCurrentContext(cmd)
+			if err != nil {
+				return err
+			}
+			if specs, err = portForwardSpecs(c, presets, args); err != nil {
+				return err
+			}
+			contextName = c.Name
+		}
+
+		status, err := ensureDaemon()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "sitectl daemon running (pid %d)\n", status.PID)
+		if len(specs) == 0 {
+			return nil
+		}
+		values := make([]string, 0, len(specs))
+		for _, spec := range specs {
+			values = append(values, spec.String())
+		}
+		if _, err := daemonCall(daemonRequest{Op: "forward", Context: contextName, Specs: values}); err != nil {
+			return err
+		}
+		for _, spec := range specs {
+			fmt.Fprintf(cmd.OutOrStdout(), "Forwarding 127.0.0.1:%d -> %s:%d on %s in the background\n", spec.localPort, spec.service, spec.remotePort, contextName)
+		}
+		return nil
+	},
+}
+
+var daemonStopCmd = &cobra.Command{
+	Use:   "stop",
+	Args:  cobra.NoArgs,
+	Short: "Stop the daemon and close its port forwards",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := daemonCall(daemonRequest{Op: "stop"}); err != nil {
+			if errors.Is(err, errDaemonNotRunning) {
+				fmt.Fprintln(cmd.OutOrStdout(), "sitectl daemon is not running")
+				return nil
+			}
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "Stopped sitectl daemon")
+		return nil
+	},
+}
+
+var daemonStatusCmd = &cobra.Command{
+	Use:   "status",
+	Args:  cobra.NoArgs,
+	Short: "Show whether the daemon is running and its port forwards",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		if format != "table" && format != "json" {
+			return fmt.Errorf("unsupported format %q: use table or json", format)
+		}
+		status, err := daemonCall(daemonRequest{Op: "status"})
+		running := err == nil
+		if err != nil && !errors.Is(err, errDaemonNotRunning) {
+			return err
+		}
+		if format == "json" {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(struct {
+				Running bool `json:"running"`
+				daemonResponse
+			}{running, status})
+		}
+		if !running {
+			fmt.Fprintln(cmd.OutOrStdout(), "sitectl daemon is not running")
+			return nil
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "sitectl daemon running (pid %d) since %s\n", status.PID, status.StartedAt.Local().Format(time.DateTime))
+		if len(status.Forwards) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No port forwards")
+			return nil
+		}
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tCONTEXT\tFORWARDS\tSTARTED")
+		for _, forward := range status.Forwards {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", forward.ID, forward.Context, strings.Join(forward.Specs, ", "), forward.StartedAt.Local().Format(time.DateTime))
+		}
+		return w.Flush()
+	},
+}
+
+var daemonRunCmd = &cobra.Command{
+	Use:    "run",
+	Args:   cobra.NoArgs,
+	Hidden: true,
+	Short:  "Run the daemon in the foreground",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := daemonRunDir()
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return err
+		}
+		socket := filepath.Join(dir, "daemon.sock")
+		if _, err := daemonCall(daemonRequest{Op: "status"}); err == nil {
+			return fmt.Errorf("sitectl daemon is already running")
+		}
+		// a socket left by a daemon that did not exit cleanly refuses connections
+		_ = os.Remove(socket)
+		listener, err := net.Listen("unix", socket)
+		if err != nil {
+			return fmt.Errorf("listen on %s: %w", socket, err)
+		}
+		pidFile := filepath.Join(dir, "daemon.pid")
+		if err := os.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o600); err != nil {
+			_ = listener.Close()
+			return err
+		}
+		defer os.Remove(pidFile)
+		defer os.Remove(socket)
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGHUP, syscall.SIGTERM)
+		defer stop()
+		return newSitectlDaemon(cmd.OutOrStdout()).serve(ctx, listener)
+	},
+}
+
+var errDaemonNotRunning = errors.New("sitectl daemon is not running")
+
+// sitectlDaemon serves the control socket and owns the background forwards.
+type sitectlDaemon struct {
+	log       io.Writer
+	startedAt time.Time
+
+	mu       sync.Mutex
+	nextID   int
+	forwards map[int]daemonForward
+	wg       sync.WaitGroup
+	// forwardCtx is canceled when the daemon stops.
+	forwardCtx context.Context
+	shutdown   context.CancelFunc
+}
+
+func newSitectlDaemon(log io.Writer) *sitectlDaemon {
+	return &sitectlDaemon{log: &syncWriter{w: log}, startedAt: time.Now().UTC(), forwards: map[int]daemonForward{}}
+}
+
+// serve answers requests on listener until ctx is done or a stop request
+// arrives, then closes every forward.
+func (d *sitectlDaemon) serve(ctx context.Context, listener net.Listener) error {
+	d.forwardCtx, d.shutdown = context.WithCancel(ctx)
+	defer d.wg.Wait()
+	defer d.shutdown()
+	stopListening := context.AfterFunc(d.forwardCtx, func() { _ = listener.Close() })
+	defer stopListening()
+
+	fmt.Fprintf(d.log, "sitectl daemon started (pid %d)\n", os.Getpid())
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if d.forwardCtx.Err() != nil || isClosedNetworkError(err) {
+				fmt.Fprintln(d.log, "sitectl daemon stopping")
+				return nil
+			}
+			return err
+		}
+		d.handle(conn)
+	}
+}
+
+func (d *sitectlDaemon) handle(conn net.Conn) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(time.Minute))
+	var request daemonRequest
+	var response daemonResponse
+	if err := json.NewDecoder(conn).Decode(&request); err != nil {
+		response.Error = fmt.Sprintf("invalid request: %v", err)
+	} else if err := d.apply(request); err != nil {
+		response.Error = err.Error()
+	}
+	response.PID = os.Getpid()
+	response.StartedAt = d.startedAt
+	response.Forwards = d.list()
+	_ = json.NewEncoder(conn).Encode(response)
+	if request.Op == "stop" {
+		d.shutdown()
+	}
+}
+
+func (d *sitectlDaemon) apply(request daemonRequest) error {
+	switch request.Op {
+	case "status", "stop":
+		return nil
+	case "forward":
+		return d.forward(request.Context, request.Specs)
+	default:
+		return fmt.Errorf("unknown daemon request %q", request.Op)
+	}
+}
+
+// forward starts a group of port forwards and waits until they are accepting
+// connections or have failed.
+func (d *sitectlDaemon) forward(contextName string, values []string) error {
+	c, err := config.GetContext(contextName)
+	if err != nil {
+		return err
+	}
+	specs, err := portForwardSpecs(&c, nil, values)
+	if err != nil {
+		return err
+	}
+	for _, existing := range d.list() {
+		for _, value := range existing.Specs {
+			existingSpec, err := parsePortForwardSpec(value)
+			if err != nil {
+				continue
+			}
+			for _, spec := range specs {
+				if spec.localPort == existingSpec.localPort {
+					return fmt.Errorf("local port %d is already forwarded by the daemon", spec.localPort)
+				}
+			}
+		}
+	}
+
+	d.mu.Lock()
+	d.nextID++
+	id := d.nextID
+	d.mu.Unlock()
+	started := make(chan error, 1)
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		err := servePortForwards(d.forwardCtx, d.log, d.log, &c, specs, func() error {
+			d.mu.Lock()
+			d.forwards[id] = daemonForward{ID: id, Context: c.Name, Specs: values, StartedAt: time.Now().UTC()}
+			d.mu.Unlock()
+			started <- nil
+			return nil
+		})
+		d.mu.Lock()
+		_, wasStarted := d.forwards[id]
+		delete(d.forwards, id)
+		d.mu.Unlock()
+		if err != nil {
+			fmt.Fprintf(d.log, "port forwards %s on %s: %v\n", strings.Join(values, ", "), c.Name, err)
+		}
+		if !wasStarted {
+			if err == nil {
+				err = errors.New("port forwards stopped before they were ready")
+			}
+			started <- err
+		}
+	}()
+	return <-started
+}
+
+func (d *sitectlDaemon) list() []daemonForward {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	forwards := make([]daemonForward, 0, len(d.forwards))
+	for id := 1; id <= d.nextID; id++ {
+		if forward, ok := d.forwards[id]; ok {
+			forwards = append(forwards, forward)
+		}
+	}
+	return forwards
+}
+
+// syncWriter serializes writes from concurrent forwards to the daemon log.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+// daemonCall sends one request to the running daemon.
+func daemonCall(request daemonRequest) (daemonResponse, error) {
+	dir, err := daemonRunDir()
+	if err != nil {
+		return daemonResponse{}, err
+	}
+	conn, err := net.DialTimeout("unix", filepath.Join(dir, "daemon.sock"), 2*time.Second)
+	if err != nil {
+		return daemonResponse{}, errDaemonNotRunning
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(2 * time.Minute))
+	if err := json.NewEncoder(conn).Encode(request); err != nil {
+		return daemonResponse{}, err
+	}
+	var response daemonResponse
+	if err := json.NewDecoder(conn).Decode(&response); err != nil {
+		return daemonResponse{}, fmt.Errorf("read daemon response: %w", err)
+	}
+	if response.Error != "" {
+		return response, errors.New(response.Error)
+	}
+	return response, nil
+}
+
+// ensureDaemon starts the daemon in the background unless it is running.
+func ensureDaemon() (daemonResponse, error) {
+	if status, err := daemonCall(daemonRequest{Op: "status"}); err == nil {
+		return status, nil
+	}
+	dir, err := daemonRunDir()
+	if err != nil {
+		return daemonResponse{}, err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return daemonResponse{}, err
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return daemonResponse{}, err
+	}
+	logPath := filepath.Join(dir, "daemon.log")
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) // #nosec G304 -- log path is generated under sitectl config state.
+	if err != nil {
+		return daemonResponse{}, err
+	}
+	defer logFile.Close()
+	process := exec.Command(executable, "daemon", "run") // #nosec G204 -- re-runs the current sitectl executable.
+	process.Stdout, process.Stderr = logFile, logFile
+	process.SysProcAttr = daemonSysProcAttr()
+	if err := process.Start(); err != nil {
+		return daemonResponse{}, fmt.Errorf("start sitectl daemon: %w", err)
+	}
+	_ = process.Process.Release()
+
+	deadline := time.Now().Add(daemonStartTimeout)
+	for time.Now().Before(deadline) {
+		if status, err := daemonCall(daemonRequest{Op: "status"}); err == nil {
+			return status, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return daemonResponse{}, fmt.Errorf("sitectl daemon did not start; see %s", logPath)
+}
+
+func daemonRunDir() (string, error) {
+	configPath, err := config.ConfigFilePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "run"), nil
+}
+
+func init() {
+	daemonStartCmd.Flags().StringArray("preset", nil, "Forward the specs of a preset from the context's port-forwards (repeatable)")
+	daemonStatusCmd.Flags().String("format", "table", "Output format: table or json")
+	declareFormats(daemonStatusCmd, "table", "json")
+	daemonCmd.AddCommand(daemonStartCmd, daemonStopCmd, daemonStatusCmd, daemonRunCmd)
+	daemonCmd.GroupID = "workflow"
+	RootCmd.AddCommand(daemonCmd)
+}
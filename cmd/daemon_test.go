@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func startTestDaemon(t *testing.T) <-chan error {
+	t.Helper()
+	// keep the socket path short; Unix socket paths are limited to ~100 bytes
+	home, err := os.MkdirTemp("", "sd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(home) })
+	t.Setenv("HOME", home)
+	dir, err := daemonRunDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("unix", filepath.Join(dir, "daemon.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() { done <- newSitectlDaemon(io.Discard).serve(ctx, listener) }()
+	return done
+}
+
+func TestDaemonStatusAndStop(t *testing.T) {
+	done := startTestDaemon(t)
+
+	status, err := daemonCall(daemonRequest{Op: "status"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.PID != os.Getpid() || len(status.Forwards) != 0 {
+		t.Fatalf("status = %+v", status)
+	}
+
+	if _, err := daemonCall(daemonRequest{Op: "reload"}); err == nil || !strings.Contains(err.Error(), `unknown daemon request "reload"`) {
+		t.Errorf("unknown op error = %v", err)
+	}
+	if _, err := daemonCall(daemonRequest{Op: "forward", Context: "missing", Specs: []string{"8983:solr:8983"}}); err == nil {
+		t.Error("expected an error forwarding from an unknown context")
+	}
+
+	if _, err := daemonCall(daemonRequest{Op: "stop"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("serve() = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("daemon did not stop")
+	}
+	if _, err := daemonCall(daemonRequest{Op: "status"}); !errors.Is(err, errDaemonNotRunning) {
+		t.Errorf("status after stop = %v, want errDaemonNotRunning", err)
+	}
+}
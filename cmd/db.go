@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/healthcheck"
+	"github.com/libops/sitectl/pkg/i18n"
+	corejob "github.com/libops/sitectl/pkg/job"
+	"github.com/libops/sitectl/pkg/notify"
+	"github.com/spf13/cobra"
+)
+
+type dbPullOptions struct {
+	mariaDBSyncOptions
+	sanitize bool
+}
+
+type dbPushOptions struct {
+	mariaDBSyncOptions
+	confirm          string
+	allowProduction  bool
+	skipTargetBackup bool
+}
+
+func dbCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "db",
+		Short:   "Move site databases between contexts",
+		GroupID: "workflow",
+	}
+	cmd.AddCommand(
+		dbPullCommand(),
+		dbPushCommand(),
+	)
+	return cmd
+}
+
+func dbPullCommand() *cobra.Command {
+	opts := dbPullOptions{
+		mariaDBSyncOptions: mariaDBSyncOptions{
+			service:   defaultMariaDBService,
+			backupDir: "/tmp/sitectl-mariadb-jobs/db-backup",
+		},
+		sanitize: true,
+	}
+	cmd := &cobra.Command{
+		Use:         "pull",
+		Short:       "Import a database from another context into the active context",
+		Annotations: map[string]string{notify.Annotation: ""},
+		Long: `Back up the database of a source context, copy the dump to the target context (default:
+the active context), import it, and sanitize it. When --source is omitted and the target is
+linked to a site (see sitectl link), the site's production context is the source.
+
+This is mariadb sync with developer defaults: backups from today are reused unless --fresh is
+passed, and Drupal contexts run drush sql:sanitize after the import so user emails and
+passwords from the source never stay in the target. Use --sanitize=false to keep them.
+
+Examples:
+  sitectl db pull                                # Pull the linked site's production context
+  sitectl db pull --source prod                  # Pull prod into the active context
+  sitectl db pull --source prod --fresh --yolo   # Take a new backup, no prompt
+  sitectl db pull --source prod --target stage   # Pull prod into stage`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(opts.target) == "" {
+				ctx, err := resolveCurrentContext(cmd)
+				if err != nil {
+					return err
+				}
+				opts.target = ctx.Name
+			}
+			if strings.TrimSpace(opts.source) == "" {
+				source, err := resolveLinkedSource(opts.target)
+				if err != nil {
+					return err
+				}
+				opts.source = source
+			}
+			return runDBPull(cmd, opts)
+		},
+	}
+	cmd.Flags().StringVar(&opts.source, "source", "", "Source sitectl context (default: the linked site's production context)")
+	cmd.Flags().StringVar(&opts.target, "target", "", "Target sitectl context (default: the active context)")
+	cmd.Flags().StringVar(&opts.service, "service", opts.service, "MariaDB compose service name")
+	cmd.Flags().StringVar(&opts.database, "database", "", "Database to pull instead of all databases")
+	cmd.Flags().StringVar(&opts.backupDir, "backup-dir", opts.backupDir, "Directory on the source host used to cache backup artifacts")
+	cmd.Flags().BoolVar(&opts.fresh, "fresh", false, "Always take a fresh backup instead of reusing one from today")
+	cmd.Flags().BoolVar(&opts.sanitize, "sanitize", opts.sanitize, "Sanitize user data after importing (Drupal contexts)")
+	cmd.Flags().BoolVar(&opts.yolo, "yolo", false, "Skip the confirmation prompt before importing")
+	return cmd
+}
+
+func runDBPull(cmd *cobra.Command, opts dbPullOptions) error {
+	if err := runMariaDBSync(cmd, opts.mariaDBSyncOptions); err != nil {
+		return err
+	}
+	if !opts.sanitize {
+		return nil
+	}
+	targetCtx, err := config.GetContext(opts.target)
+	if err != nil {
+		return fmt.Errorf("load target context %q: %w", opts.target, err)
+	}
+	if !isDrupalContext(&targetCtx) {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Skipping sanitize: no sanitizer is known for plugin %q\n", targetCtx.Plugin)
+		return nil
+	}
+	uri := healthcheck.PublicURLFromEnv(&targetCtx, "", "")
+	if err := runInServiceContainer(cmd.Context(), &targetCtx, defaultIngressAppService(&targetCtx), targetCtx.EffectiveDrupalContainerRoot(), drushCommandArgs([]string{"sql:sanitize", "--yes"}, uri)); err != nil {
+		return fmt.Errorf("sanitize database in %q: %w", targetCtx.Name, err)
+	}
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), i18n.T(i18n.DatabaseSanitized, targetCtx.Name))
+	return nil
+}
+
+func dbPushCommand() *cobra.Command {
+	opts := dbPushOptions{
+		mariaDBSyncOptions: mariaDBSyncOptions{
+			service:   defaultMariaDBService,
+			backupDir: "/tmp/sitectl-mariadb-jobs/db-backup",
+			fresh:     true,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:         "push",
+		Short:       "Replace the database of another context with the active context's database",
+		Annotations: map[string]string{notify.Annotation: ""},
+		Long: `Back up the database of the source context (default: the active context) and import it
+into the target context, replacing the target database.
+
+Because this overwrites a shared environment, push is guarded:
+  - you must type the target context name to confirm (or pass it with --confirm or the
+    global --yes in scripts)
+  - the target database is backed up to --backup-dir on the target host before the import
+  - contexts whose environment is prod, production or live are refused unless
+    --i-know-what-i-am-doing is passed
+
+Examples:
+  sitectl db push --target stage                     # Push the active context to stage
+  sitectl db push --target stage --confirm stage     # Non-interactive push`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(opts.source) == "" {
+				ctx, err := resolveCurrentContext(cmd)
+				if err != nil {
+					return err
+				}
+				opts.source = ctx.Name
+			}
+			return runDBPush(cmd, opts)
+		},
+	}
+	cmd.Flags().StringVar(&opts.source, "source", "", "Source sitectl context (default: the active context)")
+	cmd.Flags().StringVar(&opts.target, "target", "", "Target sitectl context")
+	cmd.Flags().StringVar(&opts.service, "service", opts.service, "MariaDB compose service name")
+	cmd.Flags().StringVar(&opts.database, "database", "", "Database to push instead of all databases")
+	cmd.Flags().StringVar(&opts.backupDir, "backup-dir", opts.backupDir, "Directory used for backup artifacts on the source and target hosts")
+	cmd.Flags().StringVar(&opts.confirm, "confirm", "", "Target context name, to confirm the push without a prompt")
+	cmd.Flags().BoolVar(&opts.skipTargetBackup, "skip-target-backup", false, "Do not back up the target database before importing")
+	cmd.Flags().BoolVar(&opts.allowProduction, "i-know-what-i-am-doing", false, "Allow pushing into a production context")
+	markRequired(cmd, "target")
+	return cmd
+}
+
+func runDBPush(cmd *cobra.Command, opts dbPushOptions) error {
+	sourceCtx, targetCtx, err := corejob.ResolveContextPair(opts.source, opts.target)
+	if err != nil {
+		return err
+	}
+	if err := targetCtx.RequireUnprotected("replace the database"); err != nil {
+		return err
+	}
+	if targetCtx.IsProduction() && !opts.allowProduction {
+		return fmt.Errorf("refusing to push into context %q: its environment is %q; pass --i-know-what-i-am-doing to override", targetCtx.Name, targetCtx.Environment)
+	}
+	if err := validateMariaDBDatabaseName(strings.TrimSpace(opts.database)); err != nil {
+		return err
+	}
+	if err := confirmDBPush(sourceCtx.Name, targetCtx, opts.confirm); err != nil {
+		return err
+	}
+
+	if !opts.skipTargetBackup {
+		backupPath := dbPushBackupPath(opts.backupDir, opts.database, time.Now().UTC())
+		if err := corejob.EnsureDirOnContext(targetCtx, filepath.Dir(backupPath)); err != nil {
+			return fmt.Errorf("prepare target backup directory: %w", err)
+		}
+		if err := runMariaDBBackup(cmd, targetCtx, mariaDBBackupOptions{
+			service:  opts.service,
+			output:   backupPath,
+			database: opts.database,
+			compress: true,
+		}); err != nil {
+			return fmt.Errorf("back up %q before push: %w", targetCtx.Name, err)
+		}
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), i18n.T(i18n.DatabaseBackedUp, targetCtx.Name, backupPath))
+	}
+
+	sync := opts.mariaDBSyncOptions
+	sync.yolo = true
+	return runMariaDBSync(cmd, sync)
+}
+
+// confirmDBPush requires the target context name to be typed back, either at
+// the prompt or through --confirm.
+func confirmDBPush(source string, target *config.Context, confirmed string) error {
+	if strings.TrimSpace(confirmed) == "" && config.AutoConfirmTyped(fmt.Sprintf("replace the database of context %q with %q", target.Name, source), false) {
+		return nil
+	}
+	if strings.TrimSpace(confirmed) == "" {
+		input, err := config.GetInput(
+			i18n.T(i18n.DatabasePushWarning, target.Name, source),
+			i18n.T(i18n.TypeToContinuePrompt, target.Name),
+		)
+		if err != nil {
+			return err
+		}
+		confirmed = input
+	}
+	if strings.TrimSpace(confirmed) != target.Name {
+		return fmt.Errorf("database push cancelled: confirmation %q does not match target context %q", strings.TrimSpace(confirmed), target.Name)
+	}
+	return nil
+}
+
+func dbPushBackupPath(backupDir, database string, now time.Time) string {
+	return corejob.DatedArtifactPath(backupDir, "pre-push-"+now.Format("150405")+"-"+mariaDBArtifactName(database), now)
+}
+
+func init() {
+	RootCmd.AddCommand(dbCommand())
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func TestDBPullCommandDefaults(t *testing.T) {
+	cmd := dbPullCommand()
+	tests := map[string]string{
+		"service":    defaultMariaDBService,
+		"sanitize":   "true",
+		"backup-dir": "/tmp/sitectl-mariadb-jobs/db-backup",
+		"target":     "",
+	}
+	for name, want := range tests {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil {
+			t.Fatalf("missing --%s flag", name)
+		}
+		if flag.DefValue != want {
+			t.Fatalf("--%s default = %q, want %q", name, flag.DefValue, want)
+		}
+	}
+	source := cmd.Flags().Lookup("source")
+	if source == nil || source.Annotations[cobra.BashCompOneRequiredFlag] != nil {
+		t.Fatal("expected --source to be optional so linked contexts can default it")
+	}
+}
+
+func TestConfirmDBPush(t *testing.T) {
+	target := &config.Context{Name: "stage"}
+	if err := confirmDBPush("local", target, " stage "); err != nil {
+		t.Fatalf("confirmDBPush() error = %v", err)
+	}
+	if err := confirmDBPush("local", target, "prod"); err == nil {
+		t.Fatal("expected mismatched confirmation to cancel the push")
+	}
+
+	// defaults.confirm: yes still asks for the target name
+	t.Setenv(config.AssumeYesEnv, config.AssumeYesFromConfig)
+	t.Setenv(config.NonInteractiveEnv, "1")
+	if err := confirmDBPush("local", target, ""); !errors.Is(err, config.ErrNonInteractive) {
+		t.Fatalf("confirmDBPush() with defaults.confirm error = %v, want a prompt", err)
+	}
+	t.Setenv(config.AssumeYesEnv, "1")
+	if err := confirmDBPush("local", target, ""); err != nil {
+		t.Fatalf("confirmDBPush() with --yes error = %v", err)
+	}
+}
+
+func TestDBPushBackupPath(t *testing.T) {
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	got := dbPushBackupPath("/backups", "drupal", now)
+	want := "/backups/2026/03/04/pre-push-050607-mariadb-drupal.sql.gz"
+	if got != want {
+		t.Fatalf("dbPushBackupPath() = %q, want %q", got, want)
+	}
+}
@@ -1,23 +1,31 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/docker"
+	"github.com/libops/sitectl/pkg/notify"
 	"github.com/libops/sitectl/pkg/plugin"
 	"github.com/spf13/cobra"
 )
 
 var (
-	deployBranch  string
-	deployRef     string
-	deployNoPull  bool
-	deploySkipGit bool
+	deployBranch         string
+	deployRef            string
+	deployNoPull         bool
+	deploySkipGit        bool
+	deploySkipScan       bool
+	deployWait           bool
+	deployTimeout        time.Duration
+	deployProgressFormat string
 )
 
 var deployCmd = &cobra.Command{
@@ -34,6 +42,13 @@ The deploy sequence runs:
   5. The plugin's remaining application-aware rollout commands when declared;
      otherwise docker compose up -d --remove-orphans
   6. Plugin post-up hooks (if the context plugin registers a deploy runner)
+  7. With --wait, wait until every service is healthy (or running, when it
+     has no healthcheck)
+
+Each step is reported on stderr as it starts and finishes, with its duration.
+--progress json emits the same steps as JSON lines (started, progress,
+succeeded, failed) for CI systems and dashboards; --progress none turns the
+report off.
 
 The --branch flag fetches and checks out a named remote branch before a
 fast-forward merge. The --ref flag fetches an exact remote ref (including a
@@ -46,7 +61,12 @@ Examples:
   sitectl deploy --branch main           # Switch to main and deploy
   sitectl deploy --ref refs/pull/123/head # Deploy an exact pull-request ref
   sitectl deploy --skip-git              # Restart services without pulling git changes
-  sitectl deploy --context prod          # Deploy on a specific context`,
+  sitectl deploy --context prod          # Deploy on a specific context
+  sitectl deploy --wait --wait-timeout 10m
+                                         # Deploy and wait for healthy services
+
+Before deploying to a production context, sitectl runs sitectl scan and asks for
+confirmation when it finds committed secrets or exposed key files (see --skip-scan).`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		contextName, err := resolveContextName(cmd)
 		if err != nil {
@@ -57,25 +77,40 @@ Examples:
 			return err
 		}
 
+		if err := confirmProductionScan(cmd, &ctx, "deploy", deploySkipScan); err != nil {
+			return err
+		}
+
+		progress, err := newDeployProgress(cmd.ErrOrStderr(), deployProgressFormat, contextName)
+		if err != nil {
+			return err
+		}
+
 		pluginName := strings.TrimSpace(ctx.Plugin)
 		hasDeployHooks, err := pluginHasDeployHooks(cmd, contextName, pluginName)
 		if err != nil {
 			return err
 		}
 		return runDeployCycle(cmd, contextName, ctx, pluginName, hasDeployHooks, deployCycleOptions{
-			Branch:  deployBranch,
-			Ref:     deployRef,
-			NoPull:  deployNoPull,
-			SkipGit: deploySkipGit,
+			Branch:      deployBranch,
+			Ref:         deployRef,
+			NoPull:      deployNoPull,
+			SkipGit:     deploySkipGit,
+			Wait:        deployWait,
+			WaitTimeout: deployTimeout,
+			Progress:    progress,
 		})
 	},
 }
 
 type deployCycleOptions struct {
-	Branch  string
-	Ref     string
-	NoPull  bool
-	SkipGit bool
+	Branch      string
+	Ref         string
+	NoPull      bool
+	SkipGit     bool
+	Wait        bool
+	WaitTimeout time.Duration
+	Progress    *deployProgress
 }
 
 var (
@@ -84,20 +119,24 @@ var (
 	deployRunContextCompose = runContextCompose
 	deployRunHook           = invokeDeployHook
 	deployResolveRollout    = pluginComposeRollout
+	deployWaitHealthy       = runDeployWait
+	deployLoginRegistries   = loginContextRegistries
 )
 
 func runDeployCycle(cmd *cobra.Command, contextName string, ctx config.Context, pluginName string, hasDeployHooks bool, opts deployCycleOptions) error {
+	progress := opts.Progress
+
 	// Update the checkout while the healthy site is still online. A fetch,
 	// checkout, or pull failure therefore cannot turn an update failure into an
 	// outage.
 	if !opts.SkipGit {
 		slog.Debug("running git update", "context", contextName, "branch", strings.TrimSpace(opts.Branch), "ref", strings.TrimSpace(opts.Ref))
-		var err error
-		if strings.TrimSpace(opts.Ref) != "" {
-			err = deployRunGitRefUpdate(cmd, ctx, opts.Ref)
-		} else {
-			err = deployRunGitUpdate(cmd, ctx, opts.Branch)
-		}
+		err := progress.run("git", "Update the git checkout", func() error {
+			if strings.TrimSpace(opts.Ref) != "" {
+				return deployRunGitRefUpdate(cmd, ctx, opts.Ref)
+			}
+			return deployRunGitUpdate(cmd, ctx, opts.Branch)
+		})
 		if err != nil {
 			return fmt.Errorf("git update failed: %w", err)
 		}
@@ -112,6 +151,16 @@ func runDeployCycle(cmd *cobra.Command, contextName string, ctx config.Context,
 	}
 	preparationCommands, rolloutCommands := splitLeadingComposePreparationCommands(rolloutCommands)
 
+	// Refresh the daemon's registry logins from the keyring so private images
+	// pull even after a token rotation.
+	if !opts.NoPull && len(ctx.Registries) > 0 {
+		if err := progress.run("registry", "Log in to registries", func() error {
+			return deployLoginRegistries(cmd.Context(), &ctx, true)
+		}); err != nil {
+			return fmt.Errorf("registry login failed: %w", err)
+		}
+	}
+
 	// Pull and build while the healthy site is still online. Registry,
 	// connectivity, missing-image, and build failures must not turn an update
 	// failure into an outage. The rollout runner still honors --no-pull while
@@ -119,57 +168,103 @@ func runDeployCycle(cmd *cobra.Command, contextName string, ctx config.Context,
 	if hasRollout {
 		if len(preparationCommands) > 0 {
 			slog.Debug("running plugin compose preparation", "context", contextName, "plugin", pluginName)
-			if err := deployRunComposeRollout(cmd, &ctx, preparationCommands, opts.NoPull); err != nil {
+			if err := progress.run("prepare", "Pull and build images", func() error {
+				return deployRunComposeRollout(cmd, &ctx, preparationCommands, opts.NoPull)
+			}); err != nil {
 				return fmt.Errorf("compose preparation failed: %w", err)
 			}
 		}
 	} else if !opts.NoPull {
 		slog.Debug("running compose pull preflight", "context", contextName)
-		if err := deployRunContextCompose(cmd, ctx, []string{"pull"}); err != nil {
+		if err := progress.run("pull", "Pull images", func() error {
+			return deployRunContextCompose(cmd, ctx, []string{"pull"})
+		}); err != nil {
 			return fmt.Errorf("compose pull preflight failed: %w", err)
 		}
 	}
 
 	if hasDeployHooks {
 		slog.Debug("running pre-down hooks", "context", contextName, "plugin", pluginName)
-		if err := deployRunHook(cmd, contextName, pluginName, "pre-down"); err != nil {
+		if err := progress.run("pre-down", "Run pre-down hooks", func() error {
+			return deployRunHook(cmd, contextName, pluginName, "pre-down")
+		}); err != nil {
 			return fmt.Errorf("pre-down hook failed: %w", err)
 		}
 	}
 
 	slog.Debug("running compose down", "context", contextName)
-	if err := deployRunContextCompose(cmd, ctx, []string{"down", "--remove-orphans"}); err != nil {
+	if err := progress.run("down", "Stop the running services", func() error {
+		return deployRunContextCompose(cmd, ctx, []string{"down", "--remove-orphans"})
+	}); err != nil {
 		return fmt.Errorf("compose down failed: %w", err)
 	}
 
 	if hasRollout {
 		slog.Debug("running plugin compose rollout", "context", contextName, "plugin", pluginName)
-		if err := deployRunComposeRollout(cmd, &ctx, rolloutCommands, opts.NoPull); err != nil {
+		if err := progress.run("rollout", "Roll out the services", func() error {
+			return deployRunComposeRollout(cmd, &ctx, rolloutCommands, opts.NoPull)
+		}); err != nil {
 			return fmt.Errorf("compose rollout failed: %w", err)
 		}
 	} else {
 		slog.Debug("running compose up", "context", contextName)
-		if err := deployRunContextCompose(cmd, ctx, []string{"up", "-d", "--remove-orphans"}); err != nil {
+		if err := progress.run("up", "Start the services", func() error {
+			return deployRunContextCompose(cmd, ctx, []string{"up", "-d", "--remove-orphans"})
+		}); err != nil {
 			return fmt.Errorf("compose up failed: %w", err)
 		}
 	}
 
 	if hasDeployHooks {
 		slog.Debug("running post-up hooks", "context", contextName, "plugin", pluginName)
-		if err := deployRunHook(cmd, contextName, pluginName, "post-up"); err != nil {
+		if err := progress.run("post-up", "Run post-up hooks", func() error {
+			return deployRunHook(cmd, contextName, pluginName, "post-up")
+		}); err != nil {
 			return fmt.Errorf("post-up hook failed: %w", err)
 		}
 	}
+
+	if opts.Wait {
+		slog.Debug("waiting for healthy services", "context", contextName, "timeout", opts.WaitTimeout)
+		if err := progress.run("health", "Wait for services to become healthy", func() error {
+			return deployWaitHealthy(cmd, ctx, opts.WaitTimeout, progress.detail)
+		}); err != nil {
+			return fmt.Errorf("health check failed: %w", err)
+		}
+	}
 	return nil
 }
 
+// runDeployWait blocks until every service of the compose project reports
+// healthy (or running, without a healthcheck), or timeout elapses.
+func runDeployWait(cmd *cobra.Command, ctx config.Context, timeout time.Duration, report func(string)) error {
+	cli, err := docker.GetDockerCli(&ctx)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	runCtx := cmd.Context()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(runCtx, timeout)
+		defer cancel()
+	}
+	return waitForServices(runCtx, cli.CLI, &ctx, waitOptions{healthy: true, interval: 2 * time.Second}, report)
+}
+
 func init() {
 	deployCmd.Flags().StringVar(&deployBranch, "branch", "", "Git branch to check out during the deploy (default: current branch)")
 	deployCmd.Flags().StringVar(&deployRef, "ref", "", "Exact remote Git ref or advertised commit to fetch and deploy detached")
 	deployCmd.MarkFlagsMutuallyExclusive("branch", "ref")
 	deployCmd.Flags().BoolVar(&deployNoPull, "no-pull", false, "Skip explicit docker compose pull steps (build --pull is unaffected)")
 	deployCmd.Flags().BoolVar(&deploySkipGit, "skip-git", false, "Skip the git fetch/checkout step")
+	deployCmd.Flags().BoolVar(&deployWait, "wait", false, "Wait for every service to become healthy after the rollout")
+	deployCmd.Flags().DurationVar(&deployTimeout, "wait-timeout", 5*time.Minute, "Give up waiting for healthy services after this long; 0 waits forever")
+	deployCmd.Flags().StringVar(&deployProgressFormat, "progress", deployProgressPlain, "Step progress on stderr: plain, json or none")
+	deployCmd.Flags().BoolVar(&deploySkipScan, "skip-scan", false, "Deploy to a production context even when sitectl scan finds potential secret leaks")
 	deployCmd.GroupID = "workflow"
+	deployCmd.Annotations = map[string]string{notify.Annotation: ""}
 	RootCmd.AddCommand(deployCmd)
 }
 
@@ -361,6 +361,8 @@ func stubDeployCycle(t *testing.T) func() {
 	oldHook := deployRunHook
 	oldResolve := deployResolveRollout
 	oldRollout := deployRunComposeRollout
+	oldWait := deployWaitHealthy
+	oldLogin := deployLoginRegistries
 	return func() {
 		deployRunGitUpdate = oldGit
 		deployRunGitRefUpdate = oldGitRef
@@ -368,5 +370,7 @@ func stubDeployCycle(t *testing.T) func() {
 		deployRunHook = oldHook
 		deployResolveRollout = oldResolve
 		deployRunComposeRollout = oldRollout
+		deployWaitHealthy = oldWait
+		deployLoginRegistries = oldLogin
 	}
 }
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Progress formats for sitectl deploy --progress.
+const (
+	deployProgressPlain = "plain"
+	deployProgressJSON  = "json"
+	deployProgressNone  = "none"
+)
+
+// Deploy progress event statuses.
+const (
+	deployStepStarted   = "started"
+	deployStepProgress  = "progress"
+	deployStepSucceeded = "succeeded"
+	deployStepFailed    = "failed"
+)
+
+// deployEvent is one structured progress event of a deploy, emitted as a JSON
+// line with --progress json.
+type deployEvent struct {
+	Time           time.Time `json:"time"`
+	Context        string    `json:"context"`
+	Step           string    `json:"step"`
+	Title          string    `json:"title"`
+	Status         string    `json:"status"`
+	Detail         string    `json:"detail,omitempty"`
+	ElapsedSeconds float64   `json:"elapsed_seconds,omitempty"`
+}
+
+// deployProgress reports deploy steps as they start and finish. A nil
+// deployProgress runs steps silently.
+type deployProgress struct {
+	out     io.Writer
+	format  string
+	context string
+	now     func() time.Time
+
+	mu         sync.Mutex
+	step       string
+	title      string
+	started    time.Time
+	lastDetail string
+}
+
+func newDeployProgress(out io.Writer, format, contextName string) (*deployProgress, error) {
+	switch format {
+	case deployProgressNone:
+		return nil, nil
+	case deployProgressPlain, deployProgressJSON:
+		return &deployProgress{out: out, format: format, context: contextName, now: time.Now}, nil
+	default:
+		return nil, fmt.Errorf("unsupported progress format %q: use %s, %s or %s", format, deployProgressPlain, deployProgressJSON, deployProgressNone)
+	}
+}
+
+// run reports step as started, runs fn, and reports whether it succeeded or
+// failed along with how long it took.
+func (p *deployProgress) run(step, title string, fn func() error) error {
+	if p == nil {
+		return fn()
+	}
+	p.mu.Lock()
+	p.step, p.title, p.started, p.lastDetail = step, title, p.now(), ""
+	p.emit(deployEvent{Status: deployStepStarted})
+	p.mu.Unlock()
+
+	err := fn()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	event := deployEvent{Status: deployStepSucceeded, ElapsedSeconds: p.now().Sub(p.started).Seconds()}
+	if err != nil {
+		event.Status, event.Detail = deployStepFailed, err.Error()
+	}
+	p.emit(event)
+	p.step = ""
+	return err
+}
+
+// detail reports an update from the running step, such as which services a
+// health check is still waiting on. Repeated identical details are dropped.
+func (p *deployProgress) detail(message string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.step == "" || message == p.lastDetail {
+		return
+	}
+	p.lastDetail = message
+	p.emit(deployEvent{Status: deployStepProgress, Detail: message})
+}
+
+// emit writes event for the current step. The caller holds p.mu.
+func (p *deployProgress) emit(event deployEvent) {
+	event.Time, event.Context, event.Step, event.Title = p.now().UTC(), p.context, p.step, p.title
+	if p.format == deployProgressJSON {
+		data, err := json.Marshal(event)
+		if err == nil {
+			_, _ = fmt.Fprintf(p.out, "%s\n", data)
+		}
+		return
+	}
+	elapsed := time.Duration(event.ElapsedSeconds * float64(time.Second)).Round(100 * time.Millisecond)
+	switch event.Status {
+	case deployStepStarted:
+		_, _ = fmt.Fprintf(p.out, "==> %s\n", event.Title)
+	case deployStepProgress:
+		_, _ = fmt.Fprintf(p.out, "    %s\n", event.Detail)
+	case deployStepSucceeded:
+		_, _ = fmt.Fprintf(p.out, "ok  %s (%s)\n", event.Title, elapsed)
+	case deployStepFailed:
+		_, _ = fmt.Fprintf(p.out, "ERR %s (%s): %s\n", event.Title, elapsed, event.Detail)
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// fixedDeployProgress returns a progress reporter whose clock advances one
+// second per reading.
+func fixedDeployProgress(t *testing.T, format string) (*deployProgress, *bytes.Buffer) {
+	t.Helper()
+	var out bytes.Buffer
+	progress, err := newDeployProgress(&out, format, "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	progress.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+	return progress, &out
+}
+
+func TestRunDeployCycleReportsProgressAndWaits(t *testing.T) {
+	restore := stubDeployCycle(t)
+	defer restore()
+
+	deployRunGitUpdate = func(*cobra.Command, config.Context, string) error { return nil }
+	deployResolveRollout = func(string) ([]string, bool, error) { return nil, false, nil }
+	deployRunContextCompose = func(*cobra.Command, config.Context, []string) error { return nil }
+	var gotTimeout time.Duration
+	deployWaitHealthy = func(_ *cobra.Command, _ config.Context, timeout time.Duration, report func(string)) error {
+		gotTimeout = timeout
+		report("Waiting for drupal health=starting")
+		report("Waiting for drupal health=starting")
+		return nil
+	}
+
+	progress, out := fixedDeployProgress(t, deployProgressJSON)
+	err := runDeployCycle(&cobra.Command{}, "prod", config.Context{}, "core", false, deployCycleOptions{Wait: true, WaitTimeout: time.Minute, Progress: progress})
+	if err != nil {
+		t.Fatalf("runDeployCycle() error = %v", err)
+	}
+	if gotTimeout != time.Minute {
+		t.Fatalf("wait timeout = %s", gotTimeout)
+	}
+
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var event deployEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("invalid event %q: %v", line, err)
+		}
+		if event.Context != "prod" {
+			t.Errorf("event context = %q", event.Context)
+		}
+		got = append(got, event.Step+":"+event.Status)
+	}
+	want := "git:started git:succeeded pull:started pull:succeeded down:started down:succeeded up:started up:succeeded health:started health:progress health:succeeded"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("events = %s\nwant     %s", strings.Join(got, " "), want)
+	}
+}
+
+func TestDeployProgressPlainReportsFailure(t *testing.T) {
+	t.Parallel()
+
+	progress, out := fixedDeployProgress(t, deployProgressPlain)
+	if err := progress.run("down", "Stop the running services", func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	err := progress.run("up", "Start the services", func() error { return errors.New("port is already allocated") })
+	if err == nil {
+		t.Fatal("expected the step error to be returned")
+	}
+	want := "==> Stop the running services\nok  Stop the running services (2s)\n==> Start the services\nERR Start the services (2s): port is already allocated\n"
+	if out.String() != want {
+		t.Fatalf("output = %q, want %q", out.String(), want)
+	}
+
+	if _, err := newDeployProgress(out, "xml", "prod"); err == nil {
+		t.Error("unknown format: expected error")
+	}
+	var none *deployProgress
+	if err := none.run("git", "Update", func() error { return nil }); err != nil {
+		t.Errorf("nil progress run() error = %v", err)
+	}
+}
@@ -60,7 +60,7 @@ func runDeployComposeRollout(cmd *cobra.Command, ctx *config.Context, commands [
 			}
 			command.Env = config.AppendEnvOverrides(os.Environ(), envValues)
 		}
-		if _, err := ctx.RunCommandContext(cmd.Context(), command); err != nil {
+		if _, err := ctx.RunComposeScriptContext(cmd.Context(), command); err != nil {
 			return fmt.Errorf("run %s: %w", commandText, err)
 		}
 	}
@@ -135,6 +135,28 @@ func TestRunDeployComposeRolloutPropagatesCommandFailure(t *testing.T) {
 	}
 }
 
+func TestRunDeployComposeRolloutExportsComposeEnv(t *testing.T) {
+	t.Parallel()
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	cmd.SetOut(io.Discard)
+	ctx := &config.Context{
+		DockerHostType: config.ContextLocal,
+		ProjectDir:     t.TempDir(),
+		ComposeEnv:     map[string]string{"BUILD_TAG": "v1.2.3"},
+	}
+	if err := runDeployComposeRollout(cmd, ctx, []string{`printf '%s' "$BUILD_TAG" > build-tag.txt`}, false); err != nil {
+		t.Fatalf("runDeployComposeRollout() error = %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(ctx.ProjectDir, "build-tag.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "v1.2.3" {
+		t.Fatalf("rollout saw BUILD_TAG=%q, want v1.2.3", got)
+	}
+}
+
 func TestRunDeployComposeRolloutHonorsContextComposeFiles(t *testing.T) {
 	logPath := filepath.Join(t.TempDir(), "docker-args.txt")
 	projectDir := t.TempDir()
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/muesli/mango"
+	mcobra "github.com/muesli/mango-cobra"
+	"github.com/muesli/roff"
+	"github.com/spf13/cobra"
+)
+
+const (
+	docsFormatMan      = "man"
+	docsFormatMarkdown = "markdown"
+)
+
+type docsOptions struct {
+	dir     string
+	formats []string
+	section uint
+}
+
+func docsCommand() *cobra.Command {
+	opts := docsOptions{
+		formats: []string{docsFormatMan, docsFormatMarkdown},
+		section: 1,
+	}
+	cmd := &cobra.Command{
+		Use:    "docs",
+		Short:  "Generate man pages and markdown reference for every command",
+		Hidden: true,
+		Long: `Generate one man page and one markdown file per command from the command tree, including
+commands registered by installed plugins, so packaged man pages and the website reference
+always match the flags of this build.
+
+Man pages are named sitectl-<command>.<section> and markdown files sitectl_<command>.md,
+following the cobra doc generator conventions.
+
+Examples:
+  sitectl docs --dir dist/man --format man
+  sitectl docs --dir website/docs/cli --format markdown`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			written, err := generateDocs(cmd.Root(), opts)
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Wrote %d files to %s\n", written, opts.dir)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&opts.dir, "dir", "", "Directory to write the generated files into")
+	cmd.Flags().StringSliceVar(&opts.formats, "format", opts.formats, "Formats to generate: man, markdown")
+	cmd.Flags().UintVar(&opts.section, "section", opts.section, "Man page section")
+	markRequired(cmd, "dir")
+	return cmd
+}
+
+// generateDocs writes the requested formats for root and every available
+// descendant and returns the number of files written.
+func generateDocs(root *cobra.Command, opts docsOptions) (int, error) {
+	var man, markdown bool
+	for _, format := range opts.formats {
+		switch strings.ToLower(strings.TrimSpace(format)) {
+		case docsFormatMan:
+			man = true
+		case docsFormatMarkdown, "md":
+			markdown = true
+		default:
+			return 0, fmt.Errorf("invalid argument %q for \"--format\": must be man or markdown", format)
+		}
+	}
+	if err := os.MkdirAll(opts.dir, 0o755); err != nil {
+		return 0, fmt.Errorf("create %s: %w", opts.dir, err)
+	}
+
+	written := 0
+	for _, c := range docsCommands(root) {
+		if man {
+			page, err := manPageForCommand(c, opts.section)
+			if err != nil {
+				return written, fmt.Errorf("generate man page for %q: %w", c.CommandPath(), err)
+			}
+			name := fmt.Sprintf("%s.%d", manPageName(c), opts.section)
+			if err := os.WriteFile(filepath.Join(opts.dir, name), []byte(page), 0o644); err != nil {
+				return written, err
+			}
+			written++
+		}
+		if markdown {
+			if err := os.WriteFile(filepath.Join(opts.dir, markdownDocName(c)), []byte(markdownForCommand(c)), 0o644); err != nil {
+				return written, err
+			}
+			written++
+		}
+	}
+	return written, nil
+}
+
+// docsCommands returns root and its available descendants in help order.
+// Hidden, deprecated and help commands are skipped.
+func docsCommands(root *cobra.Command) []*cobra.Command {
+	commands := []*cobra.Command{root}
+	for _, c := range root.Commands() {
+		if !c.IsAvailableCommand() || c.IsAdditionalHelpTopicCommand() {
+			continue
+		}
+		commands = append(commands, docsCommands(c)...)
+	}
+	return commands
+}
+
+func manPageForCommand(c *cobra.Command, section uint) (string, error) {
+	name := manPageName(c)
+	page := mango.NewManPage(section, name, c.Short).
+		WithLongDescription(c.Long)
+	if err := mcobra.AddCommand(page, c); err != nil {
+		return "", err
+	}
+	// mcobra names the page after the leaf command; use the full path so
+	// "sitectl-db-pull" and "sitectl-files-pull" stay distinct.
+	page.Root.Name = name
+	return page.Build(roff.NewDocument()), nil
+}
+
+func manPageName(c *cobra.Command) string {
+	return strings.ReplaceAll(c.CommandPath(), " ", "-")
+}
+
+func markdownDocName(c *cobra.Command) string {
+	return strings.ReplaceAll(c.CommandPath(), " ", "_") + ".md"
+}
+
+func markdownForCommand(c *cobra.Command) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "## %s\n\n%s\n\n", c.CommandPath(), c.Short)
+	if long := strings.TrimSpace(c.Long); long != "" {
+		fmt.Fprintf(&buf, "### Synopsis\n\n%s\n\n", long)
+	}
+	if c.Runnable() {
+		fmt.Fprintf(&buf, "```\n%s\n```\n\n", c.UseLine())
+	}
+	if example := strings.TrimSpace(c.Example); example != "" {
+		fmt.Fprintf(&buf, "### Examples\n\n```\n%s\n```\n\n", c.Example)
+	}
+	if flags := c.NonInheritedFlags(); flags.HasAvailableFlags() {
+		fmt.Fprintf(&buf, "### Options\n\n```\n%s```\n\n", flags.FlagUsages())
+	}
+	if flags := c.InheritedFlags(); flags.HasAvailableFlags() {
+		fmt.Fprintf(&buf, "### Options inherited from parent commands\n\n```\n%s```\n\n", flags.FlagUsages())
+	}
+
+	var related []*cobra.Command
+	if c.HasParent() {
+		related = append(related, c.Parent())
+	}
+	for _, child := range c.Commands() {
+		if child.IsAvailableCommand() && !child.IsAdditionalHelpTopicCommand() {
+			related = append(related, child)
+		}
+	}
+	if len(related) > 0 {
+		buf.WriteString("### See also\n\n")
+		for _, r := range related {
+			fmt.Fprintf(&buf, "* [%s](%s) - %s\n", r.CommandPath(), markdownDocName(r), r.Short)
+		}
+	}
+	return buf.String()
+}
+
+func init() {
+	RootCmd.AddCommand(docsCommand())
+}
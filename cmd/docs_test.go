@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func docsTestTree() *cobra.Command {
+	root := &cobra.Command{Use: "sitectl", Short: "Root"}
+	root.PersistentFlags().String("context", "", "The sitectl context to use")
+	db := &cobra.Command{Use: "db", Short: "Databases"}
+	pull := &cobra.Command{Use: "pull", Short: "Pull a database", RunE: func(*cobra.Command, []string) error { return nil }}
+	pull.Flags().Bool("fresh", false, "Take a fresh backup")
+	db.AddCommand(pull)
+	plugin := &cobra.Command{Use: "isle", Short: "ISLE plugin", DisableFlagParsing: true, RunE: func(*cobra.Command, []string) error { return nil }}
+	hidden := &cobra.Command{Use: "secret", Hidden: true, RunE: func(*cobra.Command, []string) error { return nil }}
+	root.AddCommand(db, plugin, hidden)
+	return root
+}
+
+func TestGenerateDocs(t *testing.T) {
+	dir := t.TempDir()
+	written, err := generateDocs(docsTestTree(), docsOptions{dir: dir, formats: []string{"man", "markdown"}, section: 1})
+	if err != nil {
+		t.Fatalf("generateDocs() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	want := []string{
+		"sitectl-db-pull.1", "sitectl-db.1", "sitectl-isle.1", "sitectl.1",
+		"sitectl.md", "sitectl_db.md", "sitectl_db_pull.md", "sitectl_isle.md",
+	}
+	sort.Strings(want)
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("generated files = %v, want %v", names, want)
+	}
+	if written != len(want) {
+		t.Fatalf("written = %d, want %d", written, len(want))
+	}
+
+	page, err := os.ReadFile(filepath.Join(dir, "sitectl-db-pull.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(page), ".TH SITECTL-DB-PULL 1") || !strings.Contains(string(page), "fresh") {
+		t.Fatalf("unexpected man page:\n%s", page)
+	}
+
+	markdown, err := os.ReadFile(filepath.Join(dir, "sitectl_db_pull.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fragment := range []string{
+		"## sitectl db pull",
+		"--fresh",
+		"### Options inherited from parent commands",
+		"* [sitectl db](sitectl_db.md) - Databases",
+	} {
+		if !strings.Contains(string(markdown), fragment) {
+			t.Errorf("markdown missing %q:\n%s", fragment, markdown)
+		}
+	}
+}
+
+func TestGenerateDocsRejectsUnknownFormat(t *testing.T) {
+	_, err := generateDocs(docsTestTree(), docsOptions{dir: t.TempDir(), formats: []string{"pdf"}, section: 1})
+	if err == nil || classifyError(err) != errorClassUsage {
+		t.Fatalf("generateDocs() error = %v, want usage error", err)
+	}
+}
@@ -0,0 +1,282 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/httplog"
+	"github.com/spf13/cobra"
+)
+
+const domainCheckTimeout = 10 * time.Second
+
+type domainCheckStatus string
+
+const (
+	domainCheckOK   domainCheckStatus = "ok"
+	domainCheckWarn domainCheckStatus = "warn"
+	domainCheckFail domainCheckStatus = "fail"
+)
+
+// domainFinding is one line of a domain check diagnosis.
+type domainFinding struct {
+	Check  string
+	Status domainCheckStatus
+	Detail string
+}
+
+// domainChecker holds the network operations a domain check needs so tests
+// can substitute them.
+type domainChecker struct {
+	lookupIP    func(ctx context.Context, host string) ([]net.IP, error)
+	lookupCNAME func(ctx context.Context, host string) (string, error)
+	dialTLS     func(ctx context.Context, domain string) (*tls.ConnectionState, error)
+	httpStatus  func(ctx context.Context, url string) (int, string, error)
+	now         func() time.Time
+}
+
+func newDomainChecker() domainChecker {
+	return domainChecker{
+		lookupIP: func(ctx context.Context, host string) ([]net.IP, error) {
+			return net.DefaultResolver.LookupIP(ctx, "ip", host)
+		},
+		lookupCNAME: net.DefaultResolver.LookupCNAME,
+		dialTLS: func(ctx context.Context, domain string) (*tls.ConnectionState, error) {
+			dialer := &tls.Dialer{Config: &tls.Config{ServerName: domain, MinVersion: tls.VersionTLS12}}
+			conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(domain, "443"))
+			if err != nil {
+				return nil, err
+			}
+			defer conn.Close()
+			state := conn.(*tls.Conn).ConnectionState()
+			return &state, nil
+		},
+		httpStatus: func(ctx context.Context, url string) (int, string, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if err != nil {
+				return 0, "", err
+			}
+			resp, err := httplog.WrapClient(http.DefaultClient).Do(req)
+			if err != nil {
+				return 0, "", err
+			}
+			defer resp.Body.Close()
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+			return resp.StatusCode, resp.Request.URL.String(), nil
+		},
+		now: time.Now,
+	}
+}
+
+var domainCmd = &cobra.Command{
+	Use:     "domain",
+	Short:   "Diagnose the domains that serve a context",
+	GroupID: "troubleshoot",
+}
+
+var domainCheckCmd = &cobra.Command{
+	Use:   "check DOMAIN",
+	Args:  cobra.ExactArgs(1),
+	Short: "Check DNS, TLS and HTTP reachability for a domain",
+	Long: `Check whether DOMAIN points at the active context and serves the site correctly.
+
+The check resolves the domain (CNAME and A/AAAA records) and compares the addresses with
+the expected targets: the SSH host of a remote context, loopback for a local context, or the
+addresses and hostnames passed with --expect. It then verifies the TLS certificate chain,
+hostname and expiry on port 443 and reports the HTTP status of https://DOMAIN/ (falling back
+to http:// when TLS is unavailable).
+
+sitectl exits non-zero when any check fails.
+
+Examples:
+  sitectl domain check example.edu --context prod
+  sitectl domain check www.example.edu --expect 203.0.113.10`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		expect, err := cmd.Flags().GetStringSlice("expect")
+		if err != nil {
+			return err
+		}
+		domain := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(args[0])), ".")
+		if domain == "" {
+			return fmt.Errorf("domain cannot be empty")
+		}
+
+		checker := newDomainChecker()
+		runCtx, cancel := context.WithTimeout(cmd.Context(), 3*domainCheckTimeout)
+		defer cancel()
+
+		if len(expect) == 0 {
+			ctx, err := resolveCurrentContext(cmd)
+			if err != nil {
+				return err
+			}
+			expect = domainExpectedTargets(ctx)
+		}
+		expectedIPs := checker.resolveTargets(runCtx, expect)
+
+		findings := checker.check(runCtx, domain, expect, expectedIPs)
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CHECK\tSTATUS\tDETAIL")
+		failures := 0
+		for _, finding := range findings {
+			if finding.Status == domainCheckFail {
+				failures++
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", finding.Check, finding.Status, finding.Detail)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		if failures > 0 {
+			return fmt.Errorf("domain check for %s found %d problem(s)", domain, failures)
+		}
+		return nil
+	},
+}
+
+// domainExpectedTargets returns what a domain for ctx should resolve to.
+func domainExpectedTargets(ctx *config.Context) []string {
+	if ctx.DockerHostType == config.ContextRemote {
+		if host := strings.TrimSpace(contextSSHHost(ctx)); host != "" {
+			return []string{host}
+		}
+	}
+	return []string{"127.0.0.1", "::1"}
+}
+
+// resolveTargets turns expected hostnames and IPs into a set of addresses.
+func (c domainChecker) resolveTargets(ctx context.Context, targets []string) []net.IP {
+	var ips []net.IP
+	for _, target := range targets {
+		target = strings.TrimSpace(target)
+		if ip := net.ParseIP(target); ip != nil {
+			ips = append(ips, ip)
+			continue
+		}
+		resolved, err := c.lookupIP(ctx, target)
+		if err == nil {
+			ips = append(ips, resolved...)
+		}
+	}
+	return ips
+}
+
+func (c domainChecker) check(ctx context.Context, domain string, expect []string, expectedIPs []net.IP) []domainFinding {
+	var findings []domainFinding
+
+	cname, err := c.lookupCNAME(ctx, domain)
+	cname = strings.TrimSuffix(cname, ".")
+	if err == nil && cname != "" && !strings.EqualFold(cname, domain) {
+		status := domainCheckOK
+		if !slices.ContainsFunc(expect, func(target string) bool { return strings.EqualFold(strings.TrimSuffix(target, "."), cname) }) {
+			status = domainCheckWarn
+		}
+		findings = append(findings, domainFinding{Check: "cname", Status: status, Detail: domain + " -> " + cname})
+	}
+
+	ips, err := c.lookupIP(ctx, domain)
+	if err != nil {
+		findings = append(findings, domainFinding{Check: "dns", Status: domainCheckFail, Detail: fmt.Sprintf("%s does not resolve: %v", domain, err)})
+		return findings
+	}
+	findings = append(findings, diagnoseDomainAddresses(domain, ips, expectedIPs))
+
+	tlsOK := false
+	state, err := c.dialTLS(ctx, domain)
+	if err != nil {
+		findings = append(findings, domainFinding{Check: "tls", Status: domainCheckFail, Detail: describeTLSError(err)})
+	} else {
+		finding := diagnoseCertificate(state, c.now())
+		tlsOK = finding.Status != domainCheckFail
+		findings = append(findings, finding)
+	}
+
+	url := "https://" + domain + "/"
+	if !tlsOK {
+		url = "http://" + domain + "/"
+	}
+	status, finalURL, err := c.httpStatus(ctx, url)
+	switch {
+	case err != nil:
+		findings = append(findings, domainFinding{Check: "http", Status: domainCheckFail, Detail: fmt.Sprintf("GET %s: %v", url, err)})
+	case status >= 500:
+		findings = append(findings, domainFinding{Check: "http", Status: domainCheckFail, Detail: fmt.Sprintf("GET %s returned %d", finalURL, status)})
+	case status >= 400:
+		findings = append(findings, domainFinding{Check: "http", Status: domainCheckWarn, Detail: fmt.Sprintf("GET %s returned %d", finalURL, status)})
+	default:
+		findings = append(findings, domainFinding{Check: "http", Status: domainCheckOK, Detail: fmt.Sprintf("GET %s returned %d", finalURL, status)})
+	}
+	return findings
+}
+
+func diagnoseDomainAddresses(domain string, ips, expected []net.IP) domainFinding {
+	addresses := make([]string, 0, len(ips))
+	matched := 0
+	for _, ip := range ips {
+		addresses = append(addresses, ip.String())
+		if slices.ContainsFunc(expected, ip.Equal) {
+			matched++
+		}
+	}
+	detail := domain + " -> " + strings.Join(addresses, ", ")
+	switch {
+	case len(expected) == 0:
+		return domainFinding{Check: "dns", Status: domainCheckWarn, Detail: detail + " (no expected target could be resolved)"}
+	case matched == 0:
+		return domainFinding{Check: "dns", Status: domainCheckFail, Detail: detail + " (does not point at the expected host)"}
+	case matched < len(ips):
+		return domainFinding{Check: "dns", Status: domainCheckWarn, Detail: detail + " (some records point elsewhere)"}
+	default:
+		return domainFinding{Check: "dns", Status: domainCheckOK, Detail: detail}
+	}
+}
+
+func diagnoseCertificate(state *tls.ConnectionState, now time.Time) domainFinding {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return domainFinding{Check: "tls", Status: domainCheckFail, Detail: "no certificate presented"}
+	}
+	leaf := state.PeerCertificates[0]
+	daysLeft := int(leaf.NotAfter.Sub(now).Hours() / 24)
+	detail := fmt.Sprintf("issued by %s, expires %s (%d days)", leaf.Issuer.CommonName, leaf.NotAfter.Format("2006-01-02"), daysLeft)
+	switch {
+	case daysLeft < 0:
+		return domainFinding{Check: "tls", Status: domainCheckFail, Detail: detail}
+	case daysLeft < 14:
+		return domainFinding{Check: "tls", Status: domainCheckWarn, Detail: detail}
+	default:
+		return domainFinding{Check: "tls", Status: domainCheckOK, Detail: detail}
+	}
+}
+
+func describeTLSError(err error) string {
+	var hostnameErr x509.HostnameError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var invalidErr x509.CertificateInvalidError
+	switch {
+	case errors.As(err, &hostnameErr):
+		return "certificate does not cover this domain: " + hostnameErr.Error()
+	case errors.As(err, &unknownAuthorityErr):
+		return "certificate chain is not trusted (self-signed, staging or missing intermediate): " + err.Error()
+	case errors.As(err, &invalidErr):
+		return "certificate is invalid: " + err.Error()
+	default:
+		return "TLS handshake failed: " + err.Error()
+	}
+}
+
+func init() {
+	domainCheckCmd.Flags().StringSlice("expect", nil, "Expected IP addresses or hostnames the domain should point at (defaults to the context host)")
+	domainCmd.AddCommand(domainCheckCmd)
+	RootCmd.AddCommand(domainCmd)
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestDomainCheckDiagnosesMisconfiguredDNS(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	checker := domainChecker{
+		lookupIP: func(_ context.Context, host string) ([]net.IP, error) {
+			switch host {
+			case "example.edu":
+				return []net.IP{net.ParseIP("198.51.100.7")}, nil
+			case "prod.example.org":
+				return []net.IP{net.ParseIP("203.0.113.10")}, nil
+			}
+			return nil, errors.New("no such host")
+		},
+		lookupCNAME: func(_ context.Context, host string) (string, error) {
+			return host + ".", nil
+		},
+		dialTLS: func(context.Context, string) (*tls.ConnectionState, error) {
+			return &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{
+				Issuer:   pkix.Name{CommonName: "R11"},
+				NotAfter: now.Add(5 * 24 * time.Hour),
+			}}}, nil
+		},
+		httpStatus: func(_ context.Context, url string) (int, string, error) {
+			return 502, url, nil
+		},
+		now: func() time.Time { return now },
+	}
+
+	expect := []string{"prod.example.org"}
+	findings := checker.check(context.Background(), "example.edu", expect, checker.resolveTargets(context.Background(), expect))
+	want := map[string]domainCheckStatus{
+		"dns":  domainCheckFail,
+		"tls":  domainCheckWarn,
+		"http": domainCheckFail,
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("findings = %+v, want %d entries", findings, len(want))
+	}
+	for _, finding := range findings {
+		if want[finding.Check] != finding.Status {
+			t.Errorf("%s status = %s, want %s (%s)", finding.Check, finding.Status, want[finding.Check], finding.Detail)
+		}
+	}
+}
+
+func TestDomainCheckStopsWhenDomainDoesNotResolve(t *testing.T) {
+	t.Parallel()
+
+	checker := domainChecker{
+		lookupIP: func(context.Context, string) ([]net.IP, error) {
+			return nil, errors.New("no such host")
+		},
+		lookupCNAME: func(context.Context, string) (string, error) {
+			return "", errors.New("no such host")
+		},
+	}
+	findings := checker.check(context.Background(), "missing.example.edu", nil, nil)
+	if len(findings) != 1 || findings[0].Check != "dns" || findings[0].Status != domainCheckFail {
+		t.Fatalf("findings = %+v, want a single dns failure", findings)
+	}
+}
+
+func TestDiagnoseDomainAddresses(t *testing.T) {
+	t.Parallel()
+
+	expected := []net.IP{net.ParseIP("203.0.113.10")}
+	tests := []struct {
+		ips  []string
+		want domainCheckStatus
+	}{
+		{ips: []string{"203.0.113.10"}, want: domainCheckOK},
+		{ips: []string{"203.0.113.10", "198.51.100.7"}, want: domainCheckWarn},
+		{ips: []string{"198.51.100.7"}, want: domainCheckFail},
+	}
+	for _, tc := range tests {
+		var ips []net.IP
+		for _, ip := range tc.ips {
+			ips = append(ips, net.ParseIP(ip))
+		}
+		if got := diagnoseDomainAddresses("example.edu", ips, expected); got.Status != tc.want {
+			t.Errorf("diagnoseDomainAddresses(%v) = %s, want %s", tc.ips, got.Status, tc.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/healthcheck"
+	"github.com/libops/sitectl/pkg/helpers"
+	"github.com/spf13/cobra"
+)
+
+// commonDrushCommands seeds shell completion. It is deliberately short: drush
+// itself owns the full command list and `sitectl drush list` shows it.
+var commonDrushCommands = []string{
+	"cache:rebuild\tRebuild all caches",
+	"config:export\tExport Drupal configuration to a directory",
+	"config:import\tImport config from the config directory",
+	"config:get\tDisplay a config value",
+	"config:set\tSet a config value",
+	"cron\tRun all cron hooks",
+	"deploy\tRun updatedb, config:import and deploy hooks",
+	"list\tList available drush commands",
+	"php:cli\tOpen an interactive PHP shell",
+	"php:eval\tEvaluate arbitrary PHP code",
+	"pm:install\tEnable one or more modules",
+	"pm:list\tList modules and themes",
+	"pm:uninstall\tUninstall one or more modules",
+	"queue:run\tRun a specific queue by name",
+	"sql:cli\tOpen a SQL command-line interface",
+	"sql:dump\tExport the Drupal database",
+	"sql:query\tExecute a query against a database",
+	"status\tShow an overview of the Drupal environment",
+	"updatedb\tApply pending database updates",
+	"user:login\tGenerate a one-time login link",
+	"user:password\tSet the password for a user",
+	"watchdog:show\tShow watchdog messages",
+}
+
+var drushCmd = &cobra.Command{
+	Use:                "drush [DRUSH ARGS...]",
+	DisableFlagParsing: true,
+	Args:               cobra.ArbitraryArgs,
+	Short:              "Run drush inside the Drupal container of a context",
+	Long: `Run drush inside the Drupal container of the active context.
+
+sitectl finds the container of --service (default: the context's app service, drupal for
+drupal and isle contexts) through its Compose labels, runs drush from the context's
+Drupal container root, and passes --uri from SITE_URL (or the ingress domain in .env) so
+generated links such as user:login point at the site. Pass --uri yourself to override it.
+All other arguments are passed to drush unchanged.
+
+Examples:
+  sitectl drush status                  # Show the Drupal environment
+  sitectl drush --service cron cr       # Rebuild caches from the cron service
+  sitectl drush cr                      # Rebuild caches
+  sitectl drush uli                     # One-time login link for the site URI
+  sitectl drush sql:cli                 # Open an interactive SQL shell
+  sitectl drush --context prod updb -y  # Apply database updates on prod`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		return commonDrushCommands, cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filteredArgs, contextName, err := getContextFromArgs(cmd, args)
+		if err != nil {
+			return err
+		}
+		ctx, err := config.GetContext(contextName)
+		if err != nil {
+			return err
+		}
+
+		service, filteredArgs := takeFlag(filteredArgs, "--service")
+		service = helpers.FirstNonEmpty(service, defaultIngressAppService(&ctx))
+		uri := healthcheck.PublicURLFromEnv(&ctx, "", "")
+		return runInServiceContainer(cmd.Context(), &ctx, service, ctx.EffectiveDrupalContainerRoot(), drushCommandArgs(filteredArgs, uri))
+	},
+}
+
+// drushCommandArgs prepends --uri unless the caller already chose one.
+func drushCommandArgs(args []string, uri string) []string {
+	command := []string{"drush"}
+	if strings.TrimSpace(uri) != "" && !hasAnyFlag(args, "--uri", "-l") {
+		command = append(command, "--uri="+uri)
+	}
+	return append(command, args...)
+}
+
+func init() {
+	drushCmd.GroupID = "workflow"
+	RootCmd.AddCommand(drushCmd)
+}
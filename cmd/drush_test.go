@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDrushCommandArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+		uri  string
+		want []string
+	}{
+		{name: "adds site uri", args: []string{"uli"}, uri: "https://example.localhost/", want: []string{"drush", "--uri=https://example.localhost/", "uli"}},
+		{name: "keeps explicit uri", args: []string{"--uri=https://other.test", "uli"}, uri: "https://example.localhost/", want: []string{"drush", "--uri=https://other.test", "uli"}},
+		{name: "keeps explicit short uri", args: []string{"-l", "https://other.test", "uli"}, uri: "https://example.localhost/", want: []string{"drush", "-l", "https://other.test", "uli"}},
+		{name: "ignores uri after separator", args: []string{"php:eval", "--", "--uri"}, uri: "http://localhost/", want: []string{"drush", "--uri=http://localhost/", "php:eval", "--", "--uri"}},
+		{name: "no uri available", args: []string{"status"}, want: []string{"drush", "status"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := drushCommandArgs(tc.args, tc.uri); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("drushCommandArgs() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDockerExecArgs(t *testing.T) {
+	t.Parallel()
+
+	got := dockerExecArgs("site-drupal-1", "/var/www/drupal", true, []string{"drush", "status"})
+	want := []string{"exec", "-i", "-t", "-w", "/var/www/drupal", "site-drupal-1", "drush", "status"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("dockerExecArgs() = %v, want %v", got, want)
+	}
+
+	got = dockerExecArgs("site-drupal-1", "", false, []string{"drush", "status"})
+	want = []string{"exec", "-i", "site-drupal-1", "drush", "status"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("dockerExecArgs() without tty = %v, want %v", got, want)
+	}
+}
+
+func TestDrushCompletionOffersCommonCommands(t *testing.T) {
+	t.Parallel()
+
+	completions, _ := drushCmd.ValidArgsFunction(drushCmd, nil, "")
+	if len(completions) == 0 {
+		t.Fatal("expected drush command completions")
+	}
+	if more, _ := drushCmd.ValidArgsFunction(drushCmd, []string{"status"}, ""); len(more) != 0 {
+		t.Fatalf("expected no completions after the drush command, got %v", more)
+	}
+}
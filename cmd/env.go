@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	dockerimage "github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/docker"
+	"github.com/libops/sitectl/pkg/httplog"
+	"github.com/spf13/cobra"
+)
+
+// Sources of a service environment entry.
+const (
+	envSourceImage   = "image"
+	envSourceCompose = "compose"
+	envSourceSecret  = "secret"
+)
+
+const (
+	envRedacted     = "REDACTED"
+	envSecretsMount = "/run/secrets"
+)
+
+// serviceEnvAPI is the part of the Docker client used to resolve a service
+// container's environment.
+type serviceEnvAPI interface {
+	docker.DockerAPI
+	ImageInspect(ctx context.Context, imageID string, inspectOpts ...client.ImageInspectOption) (dockerimage.InspectResponse, error)
+}
+
+// serviceEnvEntry is one variable or mounted secret of a service container.
+type serviceEnvEntry struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Source   string `json:"source"`
+	Redacted bool   `json:"redacted,omitempty"`
+}
+
+var envCmd = &cobra.Command{
+	Use:   "env SERVICE",
+	Short: "Show the environment a service container runs with",
+	Long: `Show the effective environment of a running compose service container.
+
+Variables are read from the container through the Docker API, so they reflect the compose
+environment, env_file entries and interpolation exactly as the container received them.
+Each variable is marked "image" when it is the image default, or "compose" when the compose
+project set or overrode it. Secrets mounted under /run/secrets are listed as "secret".
+
+Secret values and variables whose names look like credentials are redacted unless --reveal
+is passed.
+
+Examples:
+  sitectl env drupal
+  sitectl env mariadb --reveal
+  sitectl env drupal --context prod --format json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		if format != "table" && format != "json" {
+			return fmt.Errorf("unsupported format %q: use table or json", format)
+		}
+		reveal, err := cmd.Flags().GetBool("reveal")
+		if err != nil {
+			return err
+		}
+		ctx, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+		cli, err := docker.GetDockerCli(ctx)
+		if err != nil {
+			return err
+		}
+		defer cli.Close()
+		api, ok := cli.CLI.(serviceEnvAPI)
+		if !ok {
+			return fmt.Errorf("docker client does not support image inspection")
+		}
+		container, err := cli.GetContainerNameContext(cmd.Context(), ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("find %s container: %w", args[0], err)
+		}
+		if container == "" {
+			return fmt.Errorf("no running container found for service %q in context %q", args[0], ctx.Name)
+		}
+
+		entries, err := resolveServiceEnv(cmd.Context(), api, ctx, strings.TrimPrefix(container, "/"), reveal)
+		if err != nil {
+			return err
+		}
+		if format == "json" {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(entries)
+		}
+		return writeServiceEnvTable(cmd.OutOrStdout(), entries)
+	},
+}
+
+// resolveServiceEnv lists the environment variables and mounted secrets of
+// container, sorted by name. Secret files are only read when reveal is set.
+func resolveServiceEnv(runCtx context.Context, api serviceEnvAPI, ctx *config.Context, container string, reveal bool) ([]serviceEnvEntry, error) {
+	inspect, err := api.ContainerInspect(runCtx, container)
+	if err != nil {
+		return nil, fmt.Errorf("inspect container %s: %w", container, err)
+	}
+	imageEnv := map[string]string{}
+	if image, err := api.ImageInspect(runCtx, inspect.Image); err == nil && image.Config != nil {
+		imageEnv = parseEnvList(image.Config.Env)
+	}
+
+	entries := []serviceEnvEntry{}
+	if inspect.Config != nil {
+		for name, value := range parseEnvList(inspect.Config.Env) {
+			entry := serviceEnvEntry{Name: name, Value: value, Source: envSourceCompose}
+			if imageValue, ok := imageEnv[name]; ok && imageValue == value {
+				entry.Source = envSourceImage
+			}
+			if !reveal && httplog.SensitiveName(name) {
+				entry.Value, entry.Redacted = envRedacted, true
+			}
+			entries = append(entries, entry)
+		}
+	}
+	for _, mount := range inspect.Mounts {
+		if path.Dir(mount.Destination) != envSecretsMount {
+			continue
+		}
+		name := path.Base(mount.Destination)
+		entry := serviceEnvEntry{Name: name, Value: envRedacted, Source: envSourceSecret, Redacted: true}
+		if reveal {
+			value, err := docker.GetSecret(runCtx, api, ctx, container, name)
+			if err != nil {
+				return nil, err
+			}
+			entry.Value, entry.Redacted = value, false
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Name != entries[j].Name {
+			return entries[i].Name < entries[j].Name
+		}
+		return entries[i].Source < entries[j].Source
+	})
+	return entries, nil
+}
+
+// parseEnvList turns Docker's NAME=value list into a map. Entries without
+// "=" are kept with an empty value.
+func parseEnvList(env []string) map[string]string {
+	values := make(map[string]string, len(env))
+	for _, entry := range env {
+		name, value, _ := strings.Cut(entry, "=")
+		values[name] = value
+	}
+	return values
+}
+
+func writeServiceEnvTable(out io.Writer, entries []serviceEnvEntry) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSOURCE\tVALUE")
+	for _, entry := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", entry.Name, entry.Source, entry.Value)
+	}
+	return tw.Flush()
+}
+
+func init() {
+	envCmd.Flags().String("format", "table", "Output format: table or json")
+	declareFormats(envCmd, "table", "json")
+	envCmd.Flags().Bool("reveal", false, "Print secret values and credential-like variables instead of redacting them")
+	envCmd.GroupID = "troubleshoot"
+	RootCmd.AddCommand(envCmd)
+}
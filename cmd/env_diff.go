@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/joho/godotenv"
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// Statuses of an env diff entry.
+const (
+	envDiffMissingTo   = "missing-in-to"
+	envDiffMissingFrom = "missing-in-from"
+	envDiffChanged     = "differs"
+)
+
+// envSourceComposeDefault marks a variable that is only set by a compose file
+// interpolation default such as ${PHP_MEMORY_LIMIT:-256M}.
+const envSourceComposeDefault = "compose default"
+
+// composeInterpolation matches $$ escapes and ${NAME...} references in compose
+// files. Group 1 is the name, group 2 the operator and group 3 the default.
+var composeInterpolation = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(:?[-?+])?([^}]*)\}`)
+
+// contextEnvValue is the value of a variable in a context and where it came
+// from: an env file path or envSourceComposeDefault.
+type contextEnvValue struct {
+	Value  string
+	Source string
+}
+
+// envDiffEntry is one variable that differs between two contexts.
+type envDiffEntry struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	From       string `json:"from,omitempty"`
+	FromSource string `json:"from_source,omitempty"`
+	To         string `json:"to,omitempty"`
+	ToSource   string `json:"to_source,omitempty"`
+	Redacted   bool   `json:"redacted,omitempty"`
+}
+
+var envDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare the env files of two contexts",
+	Long: `Compare the environment of two contexts' compose projects.
+
+sitectl reads each context's env files (the context's env-file list, or .env) and the
+variables its compose files interpolate, using the ${NAME:-default} default when no env
+file sets one. Variables missing from either side and variables with different values are
+listed. Values are redacted unless --reveal is passed.
+
+--from defaults to the active context. With --exit-code the command fails when any
+difference is found, for use in CI.
+
+Examples:
+  sitectl env diff --from local --to prod
+  sitectl env diff --to museum-stage --reveal
+  sitectl env diff --from museum-stage --to museum-prod --format json --exit-code`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f := cmd.Flags()
+		fromName, err := f.GetString("from")
+		if err != nil {
+			return err
+		}
+		toName, err := f.GetString("to")
+		if err != nil {
+			return err
+		}
+		format, err := f.GetString("format")
+		if err != nil {
+			return err
+		}
+		if format != "table" && format != "json" {
+			return fmt.Errorf("unsupported format %q: use table or json", format)
+		}
+		reveal, err := f.GetBool("reveal")
+		if err != nil {
+			return err
+		}
+		exitCode, err := f.GetBool("exit-code")
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(toName) == "" {
+			return fmt.Errorf("--to is required")
+		}
+
+		from, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(fromName) != "" {
+			fromCtx, err := config.GetContext(fromName)
+			if err != nil {
+				return err
+			}
+			from = &fromCtx
+		}
+		toCtx, err := config.GetContext(toName)
+		if err != nil {
+			return err
+		}
+		to := &toCtx
+		if from.Name == to.Name {
+			return fmt.Errorf("--from and --to are both context %q", to.Name)
+		}
+
+		fromEnv, err := loadContextEnv(cmd.Context(), from)
+		if err != nil {
+			return fmt.Errorf("context %s: %w", from.Name, err)
+		}
+		toEnv, err := loadContextEnv(cmd.Context(), to)
+		if err != nil {
+			return fmt.Errorf("context %s: %w", to.Name, err)
+		}
+		entries := diffContextEnv(fromEnv, toEnv, reveal)
+
+		if format == "json" {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(entries); err != nil {
+				return err
+			}
+		} else if len(entries) == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "No differences between %s and %s\n", from.Name, to.Name)
+		} else if err := writeEnvDiffTable(cmd.OutOrStdout(), from.Name, to.Name, entries); err != nil {
+			return err
+		}
+		if exitCode && len(entries) > 0 {
+			return fmt.Errorf("%d environment differences between %s and %s", len(entries), from.Name, to.Name)
+		}
+		return nil
+	},
+}
+
+// loadContextEnv reads the env files of ctx and the interpolation defaults of
+// its compose files. Later env files override earlier ones, as with compose
+// --env-file, and missing files are skipped.
+func loadContextEnv(runCtx context.Context, ctx *config.Context) (map[string]contextEnvValue, error) {
+	accessor, err := ctx.NewFileAccessor()
+	if err != nil {
+		return nil, err
+	}
+	defer accessor.Close()
+	read := func(file string) ([]byte, bool, error) {
+		path := ctx.ResolveProjectPath(file)
+		exists, err := accessor.FileExists(path)
+		if err != nil || !exists {
+			return nil, false, err
+		}
+		data, err := accessor.ReadFileContext(runCtx, path)
+		if err != nil {
+			return nil, false, fmt.Errorf("read %s: %w", path, err)
+		}
+		return data, true, nil
+	}
+
+	env := map[string]contextEnvValue{}
+	envFiles := ctx.EnvFile
+	if len(envFiles) == 0 {
+		envFiles = []string{".env"}
+	}
+	for _, file := range envFiles {
+		data, ok, err := read(file)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		values, err := godotenv.UnmarshalBytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", file, err)
+		}
+		for name, value := range values {
+			env[name] = contextEnvValue{Value: value, Source: file}
+		}
+	}
+
+	composeFiles := ctx.ComposeFile
+	if len(composeFiles) == 0 {
+		composeFiles = []string{"docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml", config.RuntimeComposeOverrideName}
+	}
+	for _, file := range composeFiles {
+		data, ok, err := read(file)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		for name, value := range composeInterpolationDefaults(string(data)) {
+			if _, set := env[name]; !set {
+				env[name] = contextEnvValue{Value: value, Source: envSourceComposeDefault}
+			}
+		}
+	}
+	return env, nil
+}
+
+// composeInterpolationDefaults returns the variables a compose file
+// interpolates with a default value (${NAME:-value} or ${NAME-value}). The
+// first default for a name wins.
+func composeInterpolationDefaults(compose string) map[string]string {
+	defaults := map[string]string{}
+	for _, match := range composeInterpolation.FindAllStringSubmatch(compose, -1) {
+		name, operator := match[1], match[2]
+		if name == "" || (operator != "-" && operator != ":-") {
+			continue
+		}
+		if _, ok := defaults[name]; !ok {
+			defaults[name] = match[3]
+		}
+	}
+	return defaults
+}
+
+// diffContextEnv lists the variables that are missing from one side or set
+// to different values, sorted by name. Values are redacted unless reveal.
+func diffContextEnv(from, to map[string]contextEnvValue, reveal bool) []envDiffEntry {
+	names := map[string]bool{}
+	for name := range from {
+		names[name] = true
+	}
+	for name := range to {
+		names[name] = true
+	}
+
+	entries := []envDiffEntry{}
+	for name := range names {
+		fromValue, inFrom := from[name]
+		toValue, inTo := to[name]
+		entry := envDiffEntry{Name: name, FromSource: fromValue.Source, ToSource: toValue.Source}
+		switch {
+		case !inTo:
+			entry.Status = envDiffMissingTo
+		case !inFrom:
+			entry.Status = envDiffMissingFrom
+		case fromValue.Value != toValue.Value:
+			entry.Status = envDiffChanged
+		default:
+			continue
+		}
+		if inFrom {
+			entry.From = fromValue.Value
+		}
+		if inTo {
+			entry.To = toValue.Value
+		}
+		if !reveal {
+			entry.Redacted = true
+			if inFrom {
+				entry.From = envRedacted
+			}
+			if inTo {
+				entry.To = envRedacted
+			}
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+func writeEnvDiffTable(out io.Writer, fromName, toName string, entries []envDiffEntry) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "NAME\tSTATUS\t%s\t%s\n", strings.ToUpper(fromName), strings.ToUpper(toName))
+	for _, entry := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", entry.Name, entry.Status, envDiffCell(entry.From, entry.FromSource), envDiffCell(entry.To, entry.ToSource))
+	}
+	return tw.Flush()
+}
+
+func envDiffCell(value, source string) string {
+	if source == "" {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%s)", value, source)
+}
+
+func init() {
+	envDiffCmd.Flags().String("from", "", "Context to compare from (default: the active context)")
+	envDiffCmd.Flags().String("to", "", "Context to compare to")
+	envDiffCmd.Flags().String("format", "table", "Output format: table or json")
+	declareFormats(envDiffCmd, "table", "json")
+	envDiffCmd.Flags().Bool("reveal", false, "Print values instead of redacting them")
+	envDiffCmd.Flags().Bool("exit-code", false, "Exit with an error when the contexts differ")
+	envCmd.AddCommand(envDiffCmd)
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/libops/sitectl/pkg/config"
+)
+
+func TestComposeInterpolationDefaults(t *testing.T) {
+	t.Parallel()
+
+	got := composeInterpolationDefaults(`services:
+  drupal:
+    environment:
+      PHP_MEMORY_LIMIT: ${PHP_MEMORY_LIMIT:-256M}
+      DRUPAL_DEFAULT_SITE_URL: ${DOMAIN-museum.test}
+      DB_NAME: ${DB_NAME:?set DB_NAME}
+      ESCAPED: $${NOT_A_VARIABLE:-x}
+      PLAIN: $DOMAIN
+      AGAIN: ${PHP_MEMORY_LIMIT:-1G}
+`)
+	want := map[string]string{"PHP_MEMORY_LIMIT": "256M", "DOMAIN": "museum.test"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("composeInterpolationDefaults() = %v, want %v", got, want)
+	}
+}
+
+func TestLoadContextEnv(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		".env":               "DOMAIN=museum.test\nDB_PASSWORD=\"hunter2\"\n",
+		".env.local":         "DOMAIN=localhost\n",
+		"docker-compose.yml": "services:\n  drupal:\n    environment:\n      DOMAIN: ${DOMAIN:-example.com}\n      PHP_MEMORY_LIMIT: ${PHP_MEMORY_LIMIT:-256M}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := &config.Context{DockerHostType: config.ContextLocal, ProjectDir: dir, EnvFile: []string{".env", ".env.local", ".env.missing"}}
+
+	got, err := loadContextEnv(context.Background(), ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]contextEnvValue{
+		"DOMAIN":           {Value: "localhost", Source: ".env.local"},
+		"DB_PASSWORD":      {Value: "hunter2", Source: ".env"},
+		"PHP_MEMORY_LIMIT": {Value: "256M", Source: envSourceComposeDefault},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loadContextEnv() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDiffContextEnv(t *testing.T) {
+	t.Parallel()
+
+	from := map[string]contextEnvValue{
+		"DOMAIN":       {Value: "localhost", Source: ".env"},
+		"DB_PASSWORD":  {Value: "password", Source: ".env"},
+		"XDEBUG_MODE":  {Value: "debug", Source: ".env"},
+		"TZ":           {Value: "UTC", Source: ".env"},
+		"SOLR_VERSION": {Value: "9", Source: envSourceComposeDefault},
+	}
+	to := map[string]contextEnvValue{
+		"DOMAIN":       {Value: "museum.example.edu", Source: ".env"},
+		"DB_PASSWORD":  {Value: "password", Source: ".env"},
+		"TZ":           {Value: "UTC", Source: ".env"},
+		"SMTP_HOST":    {Value: "smtp.example.edu", Source: ".env"},
+		"SOLR_VERSION": {Value: "9", Source: ".env"},
+	}
+
+	got := diffContextEnv(from, to, false)
+	want := []envDiffEntry{
+		{Name: "DOMAIN", Status: envDiffChanged, From: envRedacted, FromSource: ".env", To: envRedacted, ToSource: ".env", Redacted: true},
+		{Name: "SMTP_HOST", Status: envDiffMissingFrom, To: envRedacted, ToSource: ".env", Redacted: true},
+		{Name: "XDEBUG_MODE", Status: envDiffMissingTo, From: envRedacted, FromSource: ".env", Redacted: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("diffContextEnv() = %+v, want %+v", got, want)
+	}
+
+	got = diffContextEnv(from, to, true)
+	if got[0].From != "localhost" || got[0].To != "museum.example.edu" || got[0].Redacted {
+		t.Errorf("revealed entry = %+v", got[0])
+	}
+	if got := diffContextEnv(from, from, false); len(got) != 0 {
+		t.Errorf("identical envs differ: %+v", got)
+	}
+}
@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/helpers"
+	"github.com/pkg/sftp"
+	"github.com/spf13/cobra"
+)
+
+// envSyncState records each context env file as sitectl last pulled or pushed
+// it, so env push can tell when the copy on the context changed since.
+type envSyncState struct {
+	Files map[string]envSyncRecord `json:"files"`
+}
+
+type envSyncRecord struct {
+	SHA256   string    `json:"sha256"`
+	SyncedAt time.Time `json:"synced_at"`
+}
+
+type envPushOptions struct {
+	as    string
+	force bool
+	sudo  bool
+}
+
+var envPullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Download the context's env file",
+	Long: `Download the env file of the active context's compose project.
+
+The file is the context's first env-file (default .env), or --as. It is written with mode
+0600 to --output, .env.CONTEXT by default, and sitectl remembers its checksum so
+sitectl env push can detect later changes on the context.
+
+Examples:
+  sitectl env pull --context prod
+  sitectl env pull --context prod --output .env.prod --force`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		as, err := cmd.Flags().GetString("as")
+		if err != nil {
+			return err
+		}
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil {
+			return err
+		}
+		ctx, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+		output = helpers.FirstNonEmpty(strings.TrimSpace(output), ".env."+ctx.Name)
+		if _, err := os.Stat(output); err == nil && !force {
+			return fmt.Errorf("%s already exists; pass --force to replace it", output)
+		}
+
+		remotePath := envSyncPath(ctx, as)
+		accessor, err := ctx.NewFileAccessor()
+		if err != nil {
+			return err
+		}
+		defer accessor.Close()
+		data, err := accessor.ReadFileContext(cmd.Context(), remotePath)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", remotePath, err)
+		}
+		if err := config.WriteFileAtomic(output, bytes.NewReader(data)); err != nil {
+			return err
+		}
+		if err := recordEnvSync(ctx, remotePath, data); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Pulled %s from %s to %s\n", remotePath, ctx.Name, output)
+		return nil
+	},
+}
+
+var envPushCmd = &cobra.Command{
+	Use:   "push FILE",
+	Short: "Upload an env file to the context",
+	Long: `Upload a local env file to the active context's compose project directory.
+
+The file replaces the context's first env-file (default .env), or --as. It is uploaded over
+SFTP for remote contexts and renamed into place with mode 0600. The previous file is kept
+next to it as NAME.TIMESTAMP.bak, and the new file gets the previous file's owner, or the
+project directory's owner for a new file; pass --sudo when the SSH user cannot chown.
+
+sitectl refuses to replace a file that changed on the context since it was last pulled or
+pushed with sitectl env pull or push; review it with sitectl env pull, or pass --force.
+
+Examples:
+  sitectl env push .env.prod --context prod
+  sitectl env push .env.prod --context prod --sudo
+  sitectl env push .env.solr --as solr.env --context prod --force`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var opts envPushOptions
+		var err error
+		if opts.as, err = cmd.Flags().GetString("as"); err != nil {
+			return err
+		}
+		if opts.force, err = cmd.Flags().GetBool("force"); err != nil {
+			return err
+		}
+		if opts.sudo, err = cmd.Flags().GetBool("sudo"); err != nil {
+			return err
+		}
+		ctx, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+		remotePath, backup, err := pushContextEnvFile(cmd.Context(), ctx, args[0], opts)
+		if err != nil {
+			return err
+		}
+		if backup != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "Backed up the previous file to %s\n", backup)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Pushed %s to %s on %s\n", args[0], remotePath, ctx.Name)
+		return nil
+	},
+}
+
+// pushContextEnvFile uploads local over the context's env file and returns
+// the remote path and the backup of the previous file, if there was one.
+func pushContextEnvFile(runCtx context.Context, ctx *config.Context, local string, opts envPushOptions) (string, string, error) {
+	data, err := os.ReadFile(local) // #nosec G304 -- the user names the env file to push.
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := godotenv.UnmarshalBytes(data); err != nil {
+		return "", "", fmt.Errorf("parse %s: %w", local, err)
+	}
+
+	remotePath := envSyncPath(ctx, opts.as)
+	accessor, err := ctx.NewFileAccessor()
+	if err != nil {
+		return "", "", err
+	}
+	defer accessor.Close()
+
+	backup := ""
+	owner, err := accessor.Stat(remotePath)
+	switch {
+	case err == nil:
+		current, err := accessor.ReadFileContext(runCtx, remotePath)
+		if err != nil {
+			return "", "", fmt.Errorf("read %s: %w", remotePath, err)
+		}
+		if !opts.force {
+			if err := checkEnvSyncDrift(ctx, remotePath, current); err != nil {
+				return "", "", err
+			}
+		}
+		if backup, err = backupEnvFile(accessor, remotePath, time.Now()); err != nil {
+			return "", "", fmt.Errorf("back up %s: %w", remotePath, err)
+		}
+	case errors.Is(err, fs.ErrNotExist):
+		if owner, err = accessor.Stat(filepath.Dir(remotePath)); err != nil {
+			return "", "", fmt.Errorf("stat project directory: %w", err)
+		}
+	default:
+		return "", "", fmt.Errorf("stat %s: %w", remotePath, err)
+	}
+
+	if err := accessor.UploadFile(local, remotePath); err != nil {
+		return "", "", fmt.Errorf("upload %s: %w", remotePath, err)
+	}
+	if err := chownEnvFile(runCtx, ctx, accessor, remotePath, owner, opts.sudo); err != nil {
+		return "", "", err
+	}
+	if err := recordEnvSync(ctx, remotePath, data); err != nil {
+		return "", "", err
+	}
+	return remotePath, backup, nil
+}
+
+// backupEnvFile hard links file to FILE.TIMESTAMP.bak, adding a counter when
+// a backup from the same second exists.
+func backupEnvFile(accessor *config.FileAccessor, file string, now time.Time) (string, error) {
+	base := fmt.Sprintf("%s.%s", file, now.UTC().Format("20060102T150405Z"))
+	backup := base + ".bak"
+	for i := 1; ; i++ {
+		exists, err := accessor.FileExists(backup)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			break
+		}
+		backup = fmt.Sprintf("%s-%d.bak", base, i)
+	}
+	return backup, accessor.Link(file, backup)
+}
+
+// chownEnvFile gives a file uploaded over SFTP, which the SSH user owns, the
+// owner of like. Local files keep the current user as owner.
+func chownEnvFile(runCtx context.Context, ctx *config.Context, accessor *config.FileAccessor, file string, like fs.FileInfo, sudo bool) error {
+	want, ok := like.Sys().(*sftp.FileStat)
+	if !ok || ctx.DockerHostType != config.ContextRemote {
+		return nil
+	}
+	info, err := accessor.Stat(file)
+	if err != nil {
+		return err
+	}
+	if got, ok := info.Sys().(*sftp.FileStat); ok && got.UID == want.UID && got.GID == want.GID {
+		return nil
+	}
+	owner := fmt.Sprintf("%d:%d", want.UID, want.GID)
+	args := []string{"chown", owner, file}
+	if sudo {
+		args = append([]string{"sudo", "-n"}, args...)
+	}
+	if _, err := ctx.RunQuietCommandContext(runCtx, exec.Command(args[0], args[1:]...)); err != nil { // #nosec G204 -- chown of the pushed env file.
+		hint := "; pass --sudo if the SSH user cannot change file owners"
+		if sudo {
+			hint = ""
+		}
+		return fmt.Errorf("set owner of %s to %s: %w%s", file, owner, err, hint)
+	}
+	return nil
+}
+
+// envSyncPath returns the env file on the context that pull and push use:
+// as, or the context's first env file, or .env.
+func envSyncPath(ctx *config.Context, as string) string {
+	first := ""
+	if len(ctx.EnvFile) > 0 {
+		first = ctx.EnvFile[0]
+	}
+	return ctx.ResolveProjectPath(helpers.FirstNonEmpty(strings.TrimSpace(as), strings.TrimSpace(first), ".env"))
+}
+
+// checkEnvSyncDrift fails when current no longer matches the file as it was
+// last pulled or pushed, or when it never was.
+func checkEnvSyncDrift(ctx *config.Context, remotePath string, current []byte) error {
+	state, err := loadEnvSyncState()
+	if err != nil {
+		return err
+	}
+	record, ok := state.Files[envSyncKey(ctx, remotePath)]
+	switch {
+	case !ok:
+		return fmt.Errorf("%s on %s has not been pulled with sitectl env pull; pull and review it first, or pass --force", remotePath, ctx.Name)
+	case record.SHA256 != envSyncChecksum(current):
+		return fmt.Errorf("%s on %s changed since it was last pulled at %s; pull and review it first, or pass --force", remotePath, ctx.Name, record.SyncedAt.Local().Format(time.RFC3339))
+	}
+	return nil
+}
+
+func recordEnvSync(ctx *config.Context, remotePath string, data []byte) error {
+	state, err := loadEnvSyncState()
+	if err != nil {
+		return err
+	}
+	state.Files[envSyncKey(ctx, remotePath)] = envSyncRecord{SHA256: envSyncChecksum(data), SyncedAt: time.Now().UTC()}
+	return saveEnvSyncState(state)
+}
+
+func envSyncKey(ctx *config.Context, remotePath string) string {
+	return ctx.Name + ":" + remotePath
+}
+
+func envSyncChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func loadEnvSyncState() (envSyncState, error) {
+	path, err := envSyncStatePath()
+	if err != nil {
+		return envSyncState{}, err
+	}
+	state := envSyncState{Files: map[string]envSyncRecord{}}
+	data, err := os.ReadFile(path) // #nosec G304 -- state path is generated under sitectl config state.
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return envSyncState{}, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return envSyncState{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if state.Files == nil {
+		state.Files = map[string]envSyncRecord{}
+	}
+	return state, nil
+}
+
+func saveEnvSyncState(state envSyncState) error {
+	path, err := envSyncStatePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	return os.WriteFile(path, data, 0o600)
+}
+
+func envSyncStatePath() (string, error) {
+	configPath, err := config.ConfigFilePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "env-sync.json"), nil
+}
+
+func init() {
+	envPullCmd.Flags().String("as", "", "Env file on the context, relative to the project directory (default: the context's first env-file, or .env)")
+	envPullCmd.Flags().StringP("output", "o", "", "Local file to write (default: .env.CONTEXT)")
+	envPullCmd.Flags().Bool("force", false, "Replace an existing local file")
+	envPushCmd.Flags().String("as", "", "Env file on the context, relative to the project directory (default: the context's first env-file, or .env)")
+	envPushCmd.Flags().Bool("force", false, "Replace the file even if it changed on the context since the last pull")
+	envPushCmd.Flags().Bool("sudo", false, "Use sudo to give the uploaded file its owner")
+	envCmd.AddCommand(envPullCmd)
+	envCmd.AddCommand(envPushCmd)
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/libops/sitectl/pkg/config"
+)
+
+func TestPushContextEnvFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	projectDir := t.TempDir()
+	ctx := &config.Context{Name: "museum-prod", DockerHostType: config.ContextLocal, ProjectDir: projectDir}
+	remote := filepath.Join(projectDir, ".env")
+	local := filepath.Join(t.TempDir(), ".env.prod")
+	if err := os.WriteFile(local, []byte("DOMAIN=museum.example.edu\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new file is created without a prior pull.
+	remotePath, backup, err := pushContextEnvFile(context.Background(), ctx, local, envPushOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remotePath != remote || backup != "" {
+		t.Errorf("push = %q, %q", remotePath, backup)
+	}
+	info, err := os.Stat(remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// The pushed file is recorded, so a second push replaces it and keeps a backup.
+	if err := os.WriteFile(local, []byte("DOMAIN=museum.example.org\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, backup, err = pushContextEnvFile(context.Background(), ctx, local, envPushOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(backup); err != nil || string(data) != "DOMAIN=museum.example.edu\n" {
+		t.Errorf("backup %s = %q, %v", backup, data, err)
+	}
+	if data, _ := os.ReadFile(remote); string(data) != "DOMAIN=museum.example.org\n" {
+		t.Errorf("remote = %q", data)
+	}
+
+	// An edit on the context since the last push is refused unless forced.
+	if err := os.WriteFile(remote, []byte("DOMAIN=edited.example.org\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := pushContextEnvFile(context.Background(), ctx, local, envPushOptions{}); err == nil || !strings.Contains(err.Error(), "changed since") {
+		t.Fatalf("push over a changed file = %v", err)
+	}
+	if _, _, err := pushContextEnvFile(context.Background(), ctx, local, envPushOptions{force: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A file that was never pulled is refused as well.
+	if err := os.WriteFile(filepath.Join(projectDir, "solr.env"), []byte("SOLR_HEAP=2g\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := pushContextEnvFile(context.Background(), ctx, local, envPushOptions{as: "solr.env"}); err == nil || !strings.Contains(err.Error(), "has not been pulled") {
+		t.Errorf("push over an unpulled file = %v", err)
+	}
+}
+
+func TestPushContextEnvFileRejectsInvalidEnv(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	local := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(local, []byte("DOMAIN='unterminated\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ctx := &config.Context{Name: "museum-prod", DockerHostType: config.ContextLocal, ProjectDir: t.TempDir()}
+	if _, _, err := pushContextEnvFile(context.Background(), ctx, local, envPushOptions{}); err == nil {
+		t.Error("expected a parse error")
+	}
+}
+
+func TestEnvSyncPath(t *testing.T) {
+	t.Parallel()
+
+	ctx := &config.Context{ProjectDir: "/srv/museum"}
+	if got := envSyncPath(ctx, ""); got != "/srv/museum/.env" {
+		t.Errorf("default = %q", got)
+	}
+	ctx.EnvFile = []string{"env/prod.env", ".env"}
+	if got := envSyncPath(ctx, ""); got != "/srv/museum/env/prod.env" {
+		t.Errorf("env-file = %q", got)
+	}
+	if got := envSyncPath(ctx, "solr.env"); got != "/srv/museum/solr.env" {
+		t.Errorf("--as = %q", got)
+	}
+}
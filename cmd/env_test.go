@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	dockerimage "github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+	"github.com/libops/sitectl/pkg/config"
+	dockerspec "github.com/moby/docker-image-spec/specs-go/v1"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type fakeServiceEnvAPI struct {
+	inspect dockercontainer.InspectResponse
+	image   dockerimage.InspectResponse
+}
+
+func (f fakeServiceEnvAPI) ContainerInspect(context.Context, string) (dockercontainer.InspectResponse, error) {
+	return f.inspect, nil
+}
+
+func (f fakeServiceEnvAPI) ContainerList(context.Context, dockercontainer.ListOptions) ([]dockercontainer.Summary, error) {
+	return nil, nil
+}
+
+func (f fakeServiceEnvAPI) ImageInspect(context.Context, string, ...client.ImageInspectOption) (dockerimage.InspectResponse, error) {
+	return f.image, nil
+}
+
+func TestResolveServiceEnv(t *testing.T) {
+	t.Parallel()
+
+	projectDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(projectDir, "secrets"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, "secrets", "DB_ROOT_PASSWORD"), []byte("hunter2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	api := fakeServiceEnvAPI{
+		inspect: dockercontainer.InspectResponse{
+			ContainerJSONBase: &dockercontainer.ContainerJSONBase{Image: "sha256:abc"},
+			Config: &dockercontainer.Config{Env: []string{
+				"PATH=/usr/local/bin:/usr/bin",
+				"PHP_MEMORY_LIMIT=512M",
+				"DRUPAL_DEFAULT_DB_PASSWORD=secret",
+				"DOMAIN=museum.test",
+			}},
+			Mounts: []dockercontainer.MountPoint{
+				{Destination: "/run/secrets/DB_ROOT_PASSWORD"},
+				{Destination: "/var/www/drupal/web/sites/default/files"},
+			},
+		},
+		image: dockerimage.InspectResponse{Config: &dockerspec.DockerOCIImageConfig{ImageConfig: ocispec.ImageConfig{Env: []string{
+			"PATH=/usr/local/bin:/usr/bin",
+			"PHP_MEMORY_LIMIT=256M",
+		}}}},
+	}
+	ctx := &config.Context{DockerHostType: config.ContextLocal, ProjectDir: projectDir}
+
+	got, err := resolveServiceEnv(context.Background(), api, ctx, "museum-drupal-1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []serviceEnvEntry{
+		{Name: "DB_ROOT_PASSWORD", Value: envRedacted, Source: envSourceSecret, Redacted: true},
+		{Name: "DOMAIN", Value: "museum.test", Source: envSourceCompose},
+		{Name: "DRUPAL_DEFAULT_DB_PASSWORD", Value: envRedacted, Source: envSourceCompose, Redacted: true},
+		{Name: "PATH", Value: "/usr/local/bin:/usr/bin", Source: envSourceImage},
+		{Name: "PHP_MEMORY_LIMIT", Value: "512M", Source: envSourceCompose},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("resolveServiceEnv() = %+v, want %+v", got, want)
+	}
+
+	got, err = resolveServiceEnv(context.Background(), api, ctx, "museum-drupal-1", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].Value != "hunter2" || got[0].Redacted || got[2].Value != "secret" {
+		t.Errorf("revealed entries = %+v", got)
+	}
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+
+	"charm.land/fang/v2"
+	"github.com/libops/sitectl/pkg/config"
+)
+
+// Exit statuses for failure classes scripts commonly branch on. Commands that
+// run a user command (run, drush, wp, ...) exit with that command's status
+// instead.
+const (
+	exitGeneral    = 1
+	exitUsage      = 2
+	exitAuth       = 3
+	exitNotFound   = 4
+	exitPermission = 5
+	exitNetwork    = 6
+	exitTimeout    = 7
+	exitCancelled  = 130
+)
+
+// errorClass names a failure class in machine-readable error output.
+type errorClass string
+
+const (
+	errorClassGeneral    errorClass = "error"
+	errorClassUsage      errorClass = "usage"
+	errorClassAuth       errorClass = "auth"
+	errorClassNotFound   errorClass = "not_found"
+	errorClassPermission errorClass = "permission"
+	errorClassNetwork    errorClass = "network"
+	errorClassTimeout    errorClass = "timeout"
+	errorClassCancelled  errorClass = "cancelled"
+	errorClassCommand    errorClass = "command_failed"
+)
+
+var errorClassExitStatus = map[errorClass]int{
+	errorClassGeneral:    exitGeneral,
+	errorClassUsage:      exitUsage,
+	errorClassAuth:       exitAuth,
+	errorClassNotFound:   exitNotFound,
+	errorClassPermission: exitPermission,
+	errorClassNetwork:    exitNetwork,
+	errorClassTimeout:    exitTimeout,
+	errorClassCancelled:  exitCancelled,
+}
+
+// classifyError maps err to a failure class. Checks run from most to least
+// specific because, for example, an SSH auth failure is also a network error.
+func classifyError(err error) errorClass {
+	var statusErr *exitStatusError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &statusErr):
+		return errorClassCommand
+	case errors.Is(err, context.Canceled):
+		return errorClassCancelled
+	case errors.Is(err, context.DeadlineExceeded):
+		return errorClassTimeout
+	case isUsageError(err), errors.Is(err, config.ErrNonInteractive):
+		return errorClassUsage
+	case isAuthError(err):
+		return errorClassAuth
+	case errors.Is(err, config.ErrContextNotFound), errors.Is(err, os.ErrNotExist):
+		return errorClassNotFound
+	case errors.Is(err, config.ErrContextProtected), errors.Is(err, config.ErrReadOnly), errors.Is(err, os.ErrPermission), strings.Contains(strings.ToLower(err.Error()), "permission denied"):
+		return errorClassPermission
+	case isNetworkError(err):
+		return errorClassNetwork
+	}
+	return errorClassGeneral
+}
+
+func isUsageError(err error) bool {
+	message := err.Error()
+	for _, prefix := range []string{
+		"flag needs an argument:",
+		"unknown flag:",
+		"unknown shorthand flag:",
+		"unknown command",
+		"invalid argument",
+		"required flag(s)",
+		"accepts ",
+		"requires at least ",
+		"requires at most ",
+		"if any flags in the group",
+	} {
+		if strings.HasPrefix(message, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isAuthError(err error) bool {
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "unable to authenticate") ||
+		strings.Contains(message, "no supported methods remain") ||
+		strings.Contains(message, "knownhosts: key mismatch") ||
+		strings.Contains(message, "error reading ssh key") ||
+		strings.Contains(message, "error parsing ssh key")
+}
+
+func isNetworkError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EHOSTUNREACH) ||
+		errors.Is(err, syscall.ENETUNREACH)
+}
+
+// exitStatusForError returns the process exit status for err: the status of
+// a wrapped user command, otherwise the status of its failure class.
+func exitStatusForError(err error) int {
+	var statusErr *exitStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.status
+	}
+	if status, ok := errorClassExitStatus[classifyError(err)]; ok {
+		return status
+	}
+	return exitGeneral
+}
+
+// errorReport is the --error-format json payload written to stderr.
+type errorReport struct {
+	Error      string     `json:"error"`
+	Class      errorClass `json:"class"`
+	ExitStatus int        `json:"exit_status"`
+}
+
+func newErrorReport(err error) errorReport {
+	return errorReport{
+		Error:      err.Error(),
+		Class:      classifyError(err),
+		ExitStatus: exitStatusForError(err),
+	}
+}
+
+// handleCommandError prints err in the format selected by --error-format.
+func handleCommandError(w io.Writer, styles fang.Styles, err error) {
+	format, _ := RootCmd.PersistentFlags().GetString("error-format")
+	if !strings.EqualFold(strings.TrimSpace(format), "json") {
+		fang.DefaultErrorHandler(w, styles, err)
+		return
+	}
+	data, marshalErr := json.Marshal(newErrorReport(err))
+	if marshalErr != nil {
+		_, _ = fmt.Fprintln(w, err.Error())
+		return
+	}
+	_, _ = fmt.Fprintln(w, string(data))
+}
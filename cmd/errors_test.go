@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+
+	"charm.land/fang/v2"
+	"github.com/libops/sitectl/pkg/config"
+)
+
+func TestClassifyError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantClass  errorClass
+		wantStatus int
+	}{
+		{name: "general", err: errors.New("boom"), wantClass: errorClassGeneral, wantStatus: exitGeneral},
+		{name: "usage", err: errors.New(`unknown flag: --nope`), wantClass: errorClassUsage, wantStatus: exitUsage},
+		{name: "required flag", err: errors.New(`required flag(s) "source" not set`), wantClass: errorClassUsage, wantStatus: exitUsage},
+		{name: "ssh auth", err: fmt.Errorf("error establishing SSH connection: %w", errors.New("ssh: handshake failed: ssh: unable to authenticate, attempted methods [none publickey]")), wantClass: errorClassAuth, wantStatus: exitAuth},
+		{name: "missing context", err: fmt.Errorf("%w: prod", config.ErrContextNotFound), wantClass: errorClassNotFound, wantStatus: exitNotFound},
+		{name: "missing file", err: fmt.Errorf("read: %w", os.ErrNotExist), wantClass: errorClassNotFound, wantStatus: exitNotFound},
+		{name: "protected context", err: (config.Context{Name: "prod", Protected: true}).RequireUnprotected("flush the cache"), wantClass: errorClassPermission, wantStatus: exitPermission},
+		{name: "permission", err: fmt.Errorf("dial unix /var/run/docker.sock: connect: permission denied"), wantClass: errorClassPermission, wantStatus: exitPermission},
+		{name: "network", err: fmt.Errorf("dial: %w", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}), wantClass: errorClassNetwork, wantStatus: exitNetwork},
+		{name: "timeout", err: fmt.Errorf("wait: %w", context.DeadlineExceeded), wantClass: errorClassTimeout, wantStatus: exitTimeout},
+		{name: "cancelled", err: context.Canceled, wantClass: errorClassCancelled, wantStatus: exitCancelled},
+		{name: "command status", err: &exitStatusError{status: 9, err: errors.New("exit 9")}, wantClass: errorClassCommand, wantStatus: 9},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := classifyError(tc.err); got != tc.wantClass {
+				t.Fatalf("classifyError() = %q, want %q", got, tc.wantClass)
+			}
+			if got := exitStatusForError(tc.err); got != tc.wantStatus {
+				t.Fatalf("exitStatusForError() = %d, want %d", got, tc.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandleCommandErrorJSON(t *testing.T) {
+	if err := RootCmd.PersistentFlags().Set("error-format", "json"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = RootCmd.PersistentFlags().Set("error-format", "text")
+	})
+
+	var buf bytes.Buffer
+	handleCommandError(&buf, fang.Styles{}, fmt.Errorf("%w: prod", config.ErrContextNotFound))
+	var report errorReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("error output is not JSON: %q: %v", buf.String(), err)
+	}
+	want := errorReport{Error: "context not found: prod", Class: errorClassNotFound, ExitStatus: exitNotFound}
+	if report != want {
+		t.Fatalf("error report = %+v, want %+v", report, want)
+	}
+}
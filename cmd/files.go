@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/docker"
+	"github.com/libops/sitectl/pkg/i18n"
+	corejob "github.com/libops/sitectl/pkg/job"
+	"github.com/spf13/cobra"
+)
+
+const defaultFilesWarnSizeMiB = 1024
+
+type filesPullOptions struct {
+	source   string
+	target   string
+	service  string
+	path     string
+	include  []string
+	exclude  []string
+	warnSize int
+	yolo     bool
+}
+
+func filesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "files",
+		Short:   "Move user-uploaded site files between contexts",
+		GroupID: "workflow",
+	}
+	cmd.AddCommand(filesPullCommand())
+	return cmd
+}
+
+func filesPullCommand() *cobra.Command {
+	opts := filesPullOptions{warnSize: defaultFilesWarnSizeMiB}
+	cmd := &cobra.Command{
+		Use:   "pull",
+		Short: "Copy the uploaded files directory from one context into another",
+		Long: `Copy the user-uploaded files directory of a site from a source context into the
+target context (default: the active context).
+
+Files are streamed as a tar archive from the source container to the target container
+through the Docker API, over SSH for remote contexts, so nothing is staged on disk.
+Existing files in the target are overwritten; files that only exist in the target are kept.
+
+The directory defaults to sites/default/files under the Drupal root for drupal and isle
+contexts and wp-content/uploads under the WordPress root for wp contexts. Use --include
+and --exclude glob patterns (matched against the path inside the files directory or the
+file name) to limit the copy.
+
+Examples:
+  sitectl files pull --source prod                          # Pull prod files into the active context
+  sitectl files pull --source prod --exclude styles         # Skip generated image styles
+  sitectl files pull --source prod --include '*.pdf' --yolo # Only PDFs, no prompt`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(opts.target) == "" {
+				ctx, err := resolveCurrentContext(cmd)
+				if err != nil {
+					return err
+				}
+				opts.target = ctx.Name
+			}
+			return runFilesPull(cmd, opts)
+		},
+	}
+	cmd.Flags().StringVar(&opts.source, "source", "", "Source sitectl context")
+	cmd.Flags().StringVar(&opts.target, "target", "", "Target sitectl context (default: the active context)")
+	cmd.Flags().StringVar(&opts.service, "service", "", "Compose service that holds the files (default: the context's app service)")
+	cmd.Flags().StringVar(&opts.path, "path", "", "Files directory inside the service container (default: based on the context plugin)")
+	cmd.Flags().StringSliceVar(&opts.include, "include", nil, "Only copy files matching this glob (repeatable)")
+	cmd.Flags().StringSliceVar(&opts.exclude, "exclude", nil, "Skip files matching this glob (repeatable)")
+	cmd.Flags().IntVar(&opts.warnSize, "warn-size", opts.warnSize, "Warn before copying more than this many MiB")
+	cmd.Flags().BoolVar(&opts.yolo, "yolo", false, "Skip the confirmation prompt before copying")
+	markRequired(cmd, "source")
+	return cmd
+}
+
+func runFilesPull(cmd *cobra.Command, opts filesPullOptions) error {
+	sourceCtx, targetCtx, err := corejob.ResolveContextPair(opts.source, opts.target)
+	if err != nil {
+		return err
+	}
+	if err := targetCtx.RequireUnprotected("overwrite uploaded files"); err != nil {
+		return err
+	}
+	sourceService := filesServiceForContext(sourceCtx, opts.service)
+	targetService := filesServiceForContext(targetCtx, opts.service)
+	sourcePath, err := filesPathForContext(sourceCtx, opts.path)
+	if err != nil {
+		return err
+	}
+	targetPath, err := filesPathForContext(targetCtx, opts.path)
+	if err != nil {
+		return err
+	}
+
+	sourceCli, err := docker.GetDockerCli(sourceCtx)
+	if err != nil {
+		return err
+	}
+	defer sourceCli.Close()
+	sourceContainer, err := resolveComposeServiceContainer(cmd.Context(), sourceCtx, sourceService)
+	if err != nil {
+		return err
+	}
+
+	sizeKiB, err := filesDirectorySizeKiB(cmd, sourceCli, sourceContainer, sourcePath)
+	if err != nil {
+		return err
+	}
+	ok, err := confirmFilesPull(sourceCtx.Name, targetCtx.Name, targetPath, sizeKiB, opts.warnSize, opts.yolo)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("files pull cancelled")
+	}
+
+	targetCli, err := docker.GetDockerCli(targetCtx)
+	if err != nil {
+		return err
+	}
+	defer targetCli.Close()
+	targetContainer, err := resolveComposeServiceContainer(cmd.Context(), targetCtx, targetService)
+	if err != nil {
+		return err
+	}
+
+	archive, err := sourceCli.CopyFromContainer(cmd.Context(), sourceContainer, sourcePath)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	reader, writer := io.Pipe()
+	statsCh := make(chan docker.TarCopyStats, 1)
+	go func() {
+		stats, err := docker.FilterTar(archive, writer, docker.TarFilter{
+			Include:  opts.include,
+			Exclude:  opts.exclude,
+			RootName: path.Base(targetPath),
+		})
+		statsCh <- stats
+		_ = writer.CloseWithError(err)
+	}()
+	if err := targetCli.CopyToContainer(cmd.Context(), targetContainer, path.Dir(targetPath), reader); err != nil {
+		_ = reader.CloseWithError(err)
+		return fmt.Errorf("copy files into %q: %w", targetCtx.Name, err)
+	}
+	stats := <-statsCh
+
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), i18n.T(i18n.FilesCopied,
+		stats.Files, formatKiB(stats.Bytes/1024), sourceCtx.Name, sourcePath, targetCtx.Name, targetPath))
+	return nil
+}
+
+// filesServiceForContext returns the service holding the uploaded files,
+// honoring an explicit override. Without one each side of a pull uses its own
+// app service, so contexts of different plugins still find their containers.
+func filesServiceForContext(ctx *config.Context, override string) string {
+	if service := strings.TrimSpace(override); service != "" {
+		return service
+	}
+	return defaultIngressAppService(ctx)
+}
+
+// filesPathForContext returns the uploaded files directory inside the app
+// container, honoring an explicit override.
+func filesPathForContext(ctx *config.Context, override string) (string, error) {
+	if p := strings.TrimSpace(override); p != "" {
+		if !path.IsAbs(p) {
+			return "", fmt.Errorf("--path must be an absolute container path, got %q", p)
+		}
+		return path.Clean(p), nil
+	}
+	switch {
+	case isDrupalContext(ctx):
+		return path.Join(ctx.EffectiveDrupalContainerRoot(), "web/sites/default/files"), nil
+	case strings.TrimSpace(ctx.Plugin) == "wp":
+		return path.Join(ctx.EffectiveWPContainerRoot(), "wp-content/uploads"), nil
+	}
+	return "", fmt.Errorf("context %q does not have a known files directory; pass --path", ctx.Name)
+}
+
+func filesDirectorySizeKiB(cmd *cobra.Command, cli *docker.DockerClient, containerName, dir string) (int64, error) {
+	output, err := docker.ExecCapture(cmd.Context(), cli, containerName, "", []string{"du", "-sk", dir})
+	if err != nil {
+		return 0, fmt.Errorf("measure %s: %w", dir, err)
+	}
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("measure %s: empty du output", dir)
+	}
+	size, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("measure %s: parse du output %q: %w", dir, fields[0], err)
+	}
+	return size, nil
+}
+
+func confirmFilesPull(source, target, targetPath string, sizeKiB int64, warnSizeMiB int, yolo bool) (bool, error) {
+	if config.AutoConfirm(fmt.Sprintf("copy files from context %q into context %q", source, target), yolo) {
+		return true, nil
+	}
+	prompt := []string{
+		i18n.T(i18n.FilesCopyWarning, formatKiB(sizeKiB), source, targetPath, target),
+		i18n.T(i18n.FilesOverwriteWarning),
+	}
+	if warnSizeMiB > 0 && sizeKiB > int64(warnSizeMiB)*1024 {
+		prompt = append(prompt, i18n.T(i18n.FilesSizeWarning, warnSizeMiB))
+	}
+	prompt = append(prompt, i18n.T(i18n.ContinuePrompt))
+	input, err := config.GetInput(prompt...)
+	if err != nil {
+		return false, err
+	}
+	return i18n.IsYes(input), nil
+}
+
+func formatKiB(kib int64) string {
+	switch {
+	case kib >= 1024*1024:
+		return fmt.Sprintf("%.1f GiB", float64(kib)/(1024*1024))
+	case kib >= 1024:
+		return fmt.Sprintf("%.1f MiB", float64(kib)/1024)
+	default:
+		return fmt.Sprintf("%d KiB", kib)
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(filesCommand())
+}
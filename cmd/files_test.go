@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/libops/sitectl/pkg/config"
+)
+
+func TestFilesPathForContext(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      *config.Context
+		override string
+		want     string
+		wantErr  bool
+	}{
+		{name: "drupal default", ctx: &config.Context{Name: "local", Plugin: "drupal"}, want: "/var/www/drupal/web/sites/default/files"},
+		{name: "isle custom root", ctx: &config.Context{Name: "local", Plugin: "isle", DrupalContainerRoot: "/srv/app"}, want: "/srv/app/web/sites/default/files"},
+		{name: "wordpress", ctx: &config.Context{Name: "local", Plugin: "wp"}, want: "/var/www/html/wp-content/uploads"},
+		{name: "wordpress root", ctx: &config.Context{Name: "local", Plugin: "wp", WPContainerRoot: "/srv/wp"}, want: "/srv/wp/wp-content/uploads"},
+		{name: "override", ctx: &config.Context{Name: "local", Plugin: "wp"}, override: "/data/files/", want: "/data/files"},
+		{name: "relative override", ctx: &config.Context{Name: "local"}, override: "files", wantErr: true},
+		{name: "unknown plugin", ctx: &config.Context{Name: "local", Plugin: "ojs"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filesPathForContext(tt.ctx, tt.override)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("filesPathForContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("filesPathForContext() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilesServiceForContext(t *testing.T) {
+	drupal := &config.Context{Name: "prod", Plugin: "drupal"}
+	wp := &config.Context{Name: "local", Plugin: "wp"}
+	if got := filesServiceForContext(drupal, ""); got != "drupal" {
+		t.Errorf("source service = %q, want drupal", got)
+	}
+	if got := filesServiceForContext(wp, ""); got != "wp" {
+		t.Errorf("target service = %q, want wp", got)
+	}
+	if got := filesServiceForContext(drupal, " app "); got != "app" {
+		t.Errorf("override = %q, want app", got)
+	}
+}
+
+func TestFormatKiB(t *testing.T) {
+	tests := map[int64]string{
+		12:              "12 KiB",
+		2048:            "2.0 MiB",
+		3 * 1024 * 1024: "3.0 GiB",
+	}
+	for input, want := range tests {
+		if got := formatKiB(input); got != want {
+			t.Fatalf("formatKiB(%d) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/format"
+	"github.com/libops/sitectl/pkg/helpers"
+	"github.com/spf13/cobra"
+)
+
+var hostCmd = &cobra.Command{
+	Use:   "host",
+	Short: "Inspect and maintain the Docker host of the active context",
+	Long: `Inspect and maintain the machine that runs the Docker daemon of the active context.
+
+Commands run on the context host over SSH for remote contexts and on this machine for local
+contexts.`,
+	GroupID: "ops",
+}
+
+// hostRunScript runs a shell script on the context host and returns its
+// stdout. It is a variable so tests can stand in for the host.
+var hostRunScript = func(runCtx context.Context, ctx *config.Context, script string) (string, error) {
+	return ctx.RunQuietCommandContext(runCtx, exec.Command("sh", "-c", script))
+}
+
+// contextSSHEndpoint returns the SSH endpoint sitectl connects to for ctx,
+// resolving ssh-config-host aliases. When the alias cannot be resolved it
+// falls back to the context fields so messages still name a host.
+func contextSSHEndpoint(ctx *config.Context) config.SSHEndpoint {
+	endpoint, err := ctx.ResolvedSSHEndpoint()
+	if err != nil {
+		slog.Debug("resolve ssh endpoint", "context", ctx.Name, "err", err)
+		return config.SSHEndpoint{
+			User:     ctx.SSHUser,
+			Hostname: helpers.FirstNonEmpty(ctx.SSHHostname, ctx.SSHConfigHost),
+			Port:     ctx.SSHPort,
+			KeyPath:  ctx.SSHKeyPath,
+		}
+	}
+	return endpoint
+}
+
+// contextSSHHost is the host name of ctx for messages and logs.
+func contextSSHHost(ctx *config.Context) string {
+	return contextSSHEndpoint(ctx).Hostname
+}
+
+// hostDiskUsageScript prints the df table, then the Docker root directory and
+// the docker system df rows after marker lines. Docker failures, such as an
+// SSH user outside the docker group, leave those sections empty instead of
+// hiding the filesystem report.
+const hostDiskUsageScript = `echo @@df
+df -Pk 2>/dev/null
+echo @@docker-root
+docker info --format '{{.DockerRootDir}}' 2>/dev/null
+echo @@docker-df
+docker system df --format '{{.Type}}|{{.TotalCount}}|{{.Active}}|{{.Size}}|{{.Reclaimable}}' 2>/dev/null
+true`
+
+// pseudoFilesystems are df sources that never hold project or Docker data.
+var pseudoFilesystems = []string{"tmpfs", "devtmpfs", "udev", "shm", "overlay", "none", "efivarfs", "map ", "devfs"}
+
+type hostDiskUsage struct {
+	Filesystems []hostFilesystem  `json:"filesystems"`
+	DockerRoot  string            `json:"docker_root,omitempty"`
+	Docker      []hostDockerUsage `json:"docker,omitempty"`
+}
+
+type hostFilesystem struct {
+	Filesystem  string   `json:"filesystem"`
+	MountedOn   string   `json:"mounted_on"`
+	SizeKB      int64    `json:"size_kb"`
+	UsedKB      int64    `json:"used_kb"`
+	AvailableKB int64    `json:"available_kb"`
+	UsePercent  int      `json:"use_percent"`
+	Holds       []string `json:"holds,omitempty"`
+	Warning     bool     `json:"warning,omitempty"`
+}
+
+type hostDockerUsage struct {
+	Type        string `json:"type"`
+	Total       string `json:"total"`
+	Active      string `json:"active"`
+	Size        string `json:"size"`
+	Reclaimable string `json:"reclaimable"`
+}
+
+var hostDfCmd = &cobra.Command{
+	Use:   "df",
+	Short: "Report disk usage of the context host and its Docker data",
+	Long: `Combine df and docker system df from the context host into one report.
+
+The filesystems holding the project directory and the Docker root directory are marked, and
+any filesystem at or above the --warn percentage is flagged so an impending disk-full
+incident is visible before it takes the site down. Pseudo filesystems such as tmpfs are
+hidden unless --all is passed.
+
+Examples:
+  sitectl host df
+  sitectl host df --context prod --warn 80
+  sitectl host df --format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		outputFormat, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		warn, err := cmd.Flags().GetInt("warn")
+		if err != nil {
+			return err
+		}
+		all, err := cmd.Flags().GetBool("all")
+		if err != nil {
+			return err
+		}
+		formatter, err := format.NewFormatter(outputFormat)
+		if err != nil {
+			return err
+		}
+		formatter.SetOutput(cmd.OutOrStdout())
+		ctx, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+
+		output, err := hostRunScript(cmd.Context(), ctx, hostDiskUsageScript)
+		if err != nil {
+			return fmt.Errorf("read disk usage of context %q: %w", ctx.Name, err)
+		}
+		usage := parseHostDiskUsage(output, ctx.ProjectDir, warn, all)
+		if len(usage.Filesystems) == 0 {
+			return fmt.Errorf("df on context %q returned no filesystems", ctx.Name)
+		}
+
+		parsed, err := format.ParseFormat(outputFormat)
+		if err != nil {
+			return err
+		}
+		if parsed.Type != "table" || parsed.Template != "" {
+			return formatter.Print(usage, nil, nil)
+		}
+		if err := formatter.Print(usage, []string{"FILESYSTEM", "SIZE", "USED", "AVAIL", "USE%", "MOUNTED ON", "HOLDS"}, hostFilesystemRows(usage.Filesystems)); err != nil {
+			return err
+		}
+		if len(usage.Docker) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "\nDocker disk usage unavailable; is the SSH user allowed to run docker?")
+			return nil
+		}
+		fmt.Fprintln(cmd.OutOrStdout())
+		return formatter.Print(usage, []string{"DOCKER", "TOTAL", "ACTIVE", "SIZE", "RECLAIMABLE"}, hostDockerRows(usage.Docker))
+	},
+}
+
+// parseHostDiskUsage parses the output of hostDiskUsageScript. Filesystems
+// holding projectDir or the Docker root are annotated, and those at or above
+// warn percent are flagged.
+func parseHostDiskUsage(output, projectDir string, warn int, all bool) hostDiskUsage {
+	sections := splitHostSections(output)
+	usage := hostDiskUsage{Filesystems: []hostFilesystem{}}
+	for _, line := range sections["df"] {
+		if fs, ok := parseDfLine(line); ok && (all || !isPseudoFilesystem(fs.Filesystem)) {
+			usage.Filesystems = append(usage.Filesystems, fs)
+		}
+	}
+	if root := sections["docker-root"]; len(root) > 0 {
+		usage.DockerRoot = root[0]
+	}
+	for _, line := range sections["docker-df"] {
+		fields := strings.Split(line, "|")
+		if len(fields) == 5 {
+			usage.Docker = append(usage.Docker, hostDockerUsage{Type: fields[0], Total: fields[1], Active: fields[2], Size: fields[3], Reclaimable: fields[4]})
+		}
+	}
+
+	markFilesystem := func(dir, label string) {
+		if strings.TrimSpace(dir) == "" {
+			return
+		}
+		if index := containingFilesystem(usage.Filesystems, dir); index >= 0 {
+			usage.Filesystems[index].Holds = append(usage.Filesystems[index].Holds, label)
+		}
+	}
+	markFilesystem(projectDir, "project dir")
+	markFilesystem(usage.DockerRoot, "docker root")
+	for i := range usage.Filesystems {
+		usage.Filesystems[i].Warning = warn > 0 && usage.Filesystems[i].UsePercent >= warn
+	}
+	return usage
+}
+
+// splitHostSections splits the output of a host script into the non-empty
+// lines following each "@@name" marker line, keyed by name.
+func splitHostSections(output string) map[string][]string {
+	sections := map[string][]string{}
+	section := ""
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "@@"):
+			section = strings.TrimPrefix(line, "@@")
+		default:
+			sections[section] = append(sections[section], line)
+		}
+	}
+	return sections
+}
+
+// parseDfLine parses one POSIX `df -Pk` row. The header and malformed rows
+// are rejected. Mount points may contain spaces.
+func parseDfLine(line string) (hostFilesystem, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 6 {
+		return hostFilesystem{}, false
+	}
+	size, errSize := strconv.ParseInt(fields[1], 10, 64)
+	used, errUsed := strconv.ParseInt(fields[2], 10, 64)
+	available, errAvailable := strconv.ParseInt(fields[3], 10, 64)
+	percent, errPercent := strconv.Atoi(strings.TrimSuffix(fields[4], "%"))
+	if errSize != nil || errUsed != nil || errAvailable != nil || errPercent != nil {
+		return hostFilesystem{}, false
+	}
+	return hostFilesystem{
+		Filesystem:  fields[0],
+		SizeKB:      size,
+		UsedKB:      used,
+		AvailableKB: available,
+		UsePercent:  percent,
+		MountedOn:   strings.Join(fields[5:], " "),
+	}, true
+}
+
+func isPseudoFilesystem(name string) bool {
+	for _, pseudo := range pseudoFilesystems {
+		if strings.HasPrefix(name, pseudo) {
+			return true
+		}
+	}
+	return false
+}
+
+// containingFilesystem returns the index of the filesystem with the longest
+// mount point that contains dir, or -1.
+func containingFilesystem(filesystems []hostFilesystem, dir string) int {
+	dir = path.Clean(dir)
+	best, bestLength := -1, -1
+	for i, fs := range filesystems {
+		mount := path.Clean(fs.MountedOn)
+		if dir != mount && mount != "/" && !strings.HasPrefix(dir, mount+"/") {
+			continue
+		}
+		if len(mount) > bestLength {
+			best, bestLength = i, len(mount)
+		}
+	}
+	return best
+}
+
+func hostFilesystemRows(filesystems []hostFilesystem) [][]string {
+	rows := make([][]string, 0, len(filesystems))
+	for _, fs := range filesystems {
+		percent := strconv.Itoa(fs.UsePercent) + "%"
+		if fs.Warning {
+			percent += " !"
+		}
+		rows = append(rows, []string{
+			fs.Filesystem,
+			humanBytes(fs.SizeKB * 1024),
+			humanBytes(fs.UsedKB * 1024),
+			humanBytes(fs.AvailableKB * 1024),
+			percent,
+			fs.MountedOn,
+			strings.Join(fs.Holds, ", "),
+		})
+	}
+	return rows
+}
+
+func hostDockerRows(usage []hostDockerUsage) [][]string {
+	rows := make([][]string, 0, len(usage))
+	for _, item := range usage {
+		rows = append(rows, []string{item.Type, item.Total, item.Active, item.Size, item.Reclaimable})
+	}
+	return rows
+}
+
+func init() {
+	hostDfCmd.Flags().String("format", "table", "Output format: table, json, or a Go template")
+	declareFormats(hostDfCmd, "table", "json")
+	hostDfCmd.Flags().Int("warn", 85, "Flag filesystems at or above this use percentage; 0 disables")
+	hostDfCmd.Flags().Bool("all", false, "Include pseudo filesystems such as tmpfs and overlay")
+	hostCmd.AddCommand(hostDfCmd)
+	RootCmd.AddCommand(hostCmd)
+}
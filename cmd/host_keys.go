@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+)
+
+const hostReadAuthorizedKeysScript = `cat ~/.ssh/authorized_keys 2>/dev/null; true`
+
+var hostAddKeyCmd = &cobra.Command{
+	Use:   "add-key",
+	Short: "Authorize a public key for the SSH user of the remote context host",
+	Long: `Append a public key to ~/.ssh/authorized_keys of the remote context's SSH user, so CI or a
+teammate can reach the host without a manual SSH session. Keys that are already authorized are
+skipped, whatever their options or comment.
+
+Examples:
+  sitectl host add-key --context prod --pubkey ./ci.pub`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keys, err := readPublicKeyFile(cmd)
+		if err != nil {
+			return err
+		}
+		ctx, authorized, err := hostAuthorizedKeys(cmd, "add-key")
+		if err != nil {
+			return err
+		}
+
+		var added []string
+		for _, key := range keys {
+			if containsAuthorizedKey(authorized, key.key) {
+				fmt.Fprintf(cmd.OutOrStdout(), "Already authorized: %s\n", key.label())
+				continue
+			}
+			added = append(added, key.line)
+			authorized = append(authorized, key.line)
+			fmt.Fprintf(cmd.OutOrStdout(), "Authorized: %s\n", key.label())
+		}
+		if len(added) == 0 {
+			return nil
+		}
+		endpoint := contextSSHEndpoint(ctx)
+		slog.Info("authorizing ssh keys", "context", ctx.Name, "host", endpoint.Hostname, "user", endpoint.User, "count", len(added))
+		return writeAuthorizedKeys(cmd, ctx, authorized)
+	},
+}
+
+var hostRemoveKeyCmd = &cobra.Command{
+	Use:   "remove-key",
+	Short: "Revoke a public key from the SSH user of the remote context host",
+	Long: `Remove a public key from ~/.ssh/authorized_keys of the remote context's SSH user. The key is
+matched by its key material, so options and comments do not need to match. The key sitectl
+itself connects with is never removed.
+
+Examples:
+  sitectl host remove-key --context prod --pubkey ./ci.pub
+  sitectl host remove-key --context prod --fingerprint SHA256:2Jx...`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fingerprint, err := cmd.Flags().GetString("fingerprint")
+		if err != nil {
+			return err
+		}
+		pubkey, err := cmd.Flags().GetString("pubkey")
+		if err != nil {
+			return err
+		}
+		if (fingerprint == "") == (pubkey == "") {
+			return fmt.Errorf("pass exactly one of --pubkey or --fingerprint")
+		}
+		fingerprints := []string{fingerprint}
+		if pubkey != "" {
+			keys, err := readPublicKeyFile(cmd)
+			if err != nil {
+				return err
+			}
+			fingerprints = fingerprints[:0]
+			for _, key := range keys {
+				fingerprints = append(fingerprints, ssh.FingerprintSHA256(key.key))
+			}
+		}
+		ctx, authorized, err := hostAuthorizedKeys(cmd, "remove-key")
+		if err != nil {
+			return err
+		}
+		endpoint, err := ctx.ResolvedSSHEndpoint()
+		if err != nil {
+			return err
+		}
+		if own, ok := contextPublicKey(endpoint.KeyPath); ok {
+			for _, fingerprint := range fingerprints {
+				if fingerprint == ssh.FingerprintSHA256(own) {
+					return fmt.Errorf("refusing to remove %s: sitectl connects to %s with it", fingerprint, endpoint.Hostname)
+				}
+			}
+		}
+
+		kept, removed := removeAuthorizedKeys(authorized, fingerprints)
+		if len(removed) == 0 {
+			return fmt.Errorf("no authorized key on %s matches %s", endpoint.Hostname, strings.Join(fingerprints, ", "))
+		}
+		slog.Info("revoking ssh keys", "context", ctx.Name, "host", endpoint.Hostname, "user", endpoint.User, "count", len(removed))
+		if err := writeAuthorizedKeys(cmd, ctx, kept); err != nil {
+			return err
+		}
+		for _, line := range removed {
+			key, comment, _, _, _ := ssh.ParseAuthorizedKey([]byte(line))
+			fmt.Fprintf(cmd.OutOrStdout(), "Revoked: %s\n", authorizedKey{key: key, comment: comment}.label())
+		}
+		return nil
+	},
+}
+
+type authorizedKey struct {
+	key     ssh.PublicKey
+	comment string
+	line    string
+}
+
+func (k authorizedKey) label() string {
+	label := k.key.Type() + " " + ssh.FingerprintSHA256(k.key)
+	if k.comment != "" {
+		label += " (" + k.comment + ")"
+	}
+	return label
+}
+
+// readPublicKeyFile parses every key in the --pubkey file.
+func readPublicKeyFile(cmd *cobra.Command) ([]authorizedKey, error) {
+	path, err := cmd.Flags().GetString("pubkey")
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read public key: %w", err)
+	}
+	keys, err := parseAuthorizedKeys(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key %s: %w", path, err)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s contains no public key", path)
+	}
+	return keys, nil
+}
+
+func parseAuthorizedKeys(data []byte) ([]authorizedKey, error) {
+	var keys []authorizedKey
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		key, comment, _, _, err := ssh.ParseAuthorizedKey(line)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, authorizedKey{key: key, comment: comment, line: string(line)})
+	}
+	return keys, nil
+}
+
+// hostAuthorizedKeys resolves the remote context and returns the lines of its
+// SSH user's authorized_keys file.
+func hostAuthorizedKeys(cmd *cobra.Command, action string) (*config.Context, []string, error) {
+	ctx, err := resolveCurrentContext(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ctx.DockerHostType != config.ContextRemote {
+		return nil, nil, fmt.Errorf("host %s requires a remote context; %q is %s", action, ctx.Name, ctx.DockerHostType)
+	}
+	output, err := hostRunScript(cmd.Context(), ctx, hostReadAuthorizedKeysScript)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read authorized keys on %s: %w", contextSSHHost(ctx), err)
+	}
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return ctx, lines, nil
+}
+
+func containsAuthorizedKey(lines []string, key ssh.PublicKey) bool {
+	_, removed := removeAuthorizedKeys(lines, []string{ssh.FingerprintSHA256(key)})
+	return len(removed) > 0
+}
+
+// removeAuthorizedKeys splits authorized_keys lines into those to keep and
+// those whose key matches one of fingerprints. Comments and lines that do
+// not parse are always kept.
+func removeAuthorizedKeys(lines, fingerprints []string) (kept, removed []string) {
+	for _, line := range lines {
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+		if err == nil && containsString(fingerprints, ssh.FingerprintSHA256(key)) {
+			removed = append(removed, line)
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return kept, removed
+}
+
+// writeAuthorizedKeys replaces authorized_keys through a temporary file so an
+// interrupted write never leaves a truncated file behind.
+func writeAuthorizedKeys(cmd *cobra.Command, ctx *config.Context, lines []string) error {
+	content := strings.Join(lines, "\n") + "\n"
+	script := `set -e
+umask 077
+mkdir -p ~/.ssh
+printf '%s' ` + shellquote.Join(content) + ` > ~/.ssh/authorized_keys.sitectl
+mv ~/.ssh/authorized_keys.sitectl ~/.ssh/authorized_keys`
+	if _, err := hostRunScript(cmd.Context(), ctx, script); err != nil {
+		return fmt.Errorf("write authorized keys on %s: %w", contextSSHHost(ctx), err)
+	}
+	return nil
+}
+
+// contextPublicKey returns the public key of the context's SSH private key
+// at keyPath, read from the key itself or, for passphrase-protected keys,
+// from the .pub file next to it.
+func contextPublicKey(keyPath string) (ssh.PublicKey, bool) {
+	if keyPath == "" {
+		return nil, false
+	}
+	if data, err := os.ReadFile(keyPath); err == nil {
+		if signer, err := ssh.ParsePrivateKey(data); err == nil {
+			return signer.PublicKey(), true
+		}
+	}
+	data, err := os.ReadFile(keyPath + ".pub")
+	if err != nil {
+		return nil, false
+	}
+	key, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil, false
+	}
+	return key, true
+}
+
+func init() {
+	hostAddKeyCmd.Flags().String("pubkey", "", "Path to the public key file to authorize")
+	markRequired(hostAddKeyCmd, "pubkey")
+	hostRemoveKeyCmd.Flags().String("pubkey", "", "Path to the public key file to revoke")
+	hostRemoveKeyCmd.Flags().String("fingerprint", "", "SHA256 fingerprint of the key to revoke, as printed by ssh-keygen -l")
+	hostCmd.AddCommand(hostAddKeyCmd, hostRemoveKeyCmd)
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/libops/sitectl/pkg/config"
+	"golang.org/x/crypto/ssh"
+)
+
+func testAuthorizedKey(t *testing.T, comment string) (ssh.PublicKey, string) {
+	t.Helper()
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key))) + " " + comment
+}
+
+func TestHostAddAndRemoveKey(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	ownKey, ownLine := testAuthorizedKey(t, "sitectl")
+	_, teammateLine := testAuthorizedKey(t, "teammate")
+	ciKey, ciLine := testAuthorizedKey(t, "ci")
+	keyPath := filepath.Join(tempHome, "id_ed25519")
+	if err := os.WriteFile(keyPath+".pub", []byte(ownLine+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ciPath := filepath.Join(tempHome, "ci.pub")
+	if err := os.WriteFile(ciPath, []byte(ciLine+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveContext(&config.Context{Name: "prod", DockerHostType: config.ContextRemote, SSHHostname: "prod.example.org", SSHUser: "deploy", SSHKeyPath: keyPath}, true); err != nil {
+		t.Fatalf("SaveContext() error = %v", err)
+	}
+	previous := hostRunScript
+	t.Cleanup(func() { hostRunScript = previous })
+
+	authorized := "# managed by ops\n" + ownLine + "\n" + `no-pty ` + teammateLine + "\n"
+	writes := 0
+	hostRunScript = func(_ context.Context, _ *config.Context, script string) (string, error) {
+		if script == hostReadAuthorizedKeysScript {
+			return authorized, nil
+		}
+		writes++
+		start := strings.Index(script, "printf '%s' ") + len("printf '%s' ")
+		end := strings.Index(script, " > ~/.ssh/authorized_keys.sitectl")
+		quoted := script[start:end]
+		authorized = strings.ReplaceAll(strings.Trim(quoted, "'"), `'\''`, "'")
+		return "", nil
+	}
+	run := func(t *testing.T, flags map[string]string, remove bool) (string, error) {
+		t.Helper()
+		command := hostAddKeyCmd
+		if remove {
+			command = hostRemoveKeyCmd
+		}
+		for _, name := range []string{"pubkey", "fingerprint"} {
+			if flag := command.Flags().Lookup(name); flag != nil {
+				_ = flag.Value.Set(flags[name])
+			}
+		}
+		var out bytes.Buffer
+		command.SetOut(&out)
+		command.SetContext(context.Background())
+		err := command.RunE(command, nil)
+		return out.String(), err
+	}
+
+	if _, err := run(t, map[string]string{"pubkey": ciPath}, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run(t, map[string]string{"pubkey": ciPath}, false); err != nil {
+		t.Fatal(err)
+	}
+	if writes != 1 || strings.Count(authorized, ciLine) != 1 || !strings.HasPrefix(authorized, "# managed by ops\n") {
+		t.Fatalf("after add writes = %d, authorized_keys:\n%s", writes, authorized)
+	}
+
+	if _, err := run(t, map[string]string{"fingerprint": ssh.FingerprintSHA256(ownKey)}, true); err == nil || !strings.Contains(err.Error(), "refusing") {
+		t.Fatalf("removing the context key error = %v", err)
+	}
+	out, err := run(t, map[string]string{"fingerprint": ssh.FingerprintSHA256(ciKey)}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(authorized, ciLine) || !strings.Contains(authorized, "no-pty "+teammateLine) || !strings.Contains(out, "Revoked: ssh-ed25519") {
+		t.Fatalf("after remove output %q, authorized_keys:\n%s", out, authorized)
+	}
+	if _, err := run(t, map[string]string{"pubkey": ciPath}, true); err == nil || !strings.Contains(err.Error(), "no authorized key") {
+		t.Fatalf("removing an absent key error = %v", err)
+	}
+}
+
+// fakeSSHConfig puts an ssh client on PATH whose -G output is dump, so
+// ssh-config-host aliases resolve without a real ~/.ssh/config.
+func fakeSSHConfig(t *testing.T, dump string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake ssh client is a shell script")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\ncat <<'SSH_CONFIG'\n" + dump + "\nSSH_CONFIG\n"
+	if err := os.WriteFile(filepath.Join(dir, "ssh"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestHostRemoveKeyRefusesAliasContextKey(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	ownKey, ownLine := testAuthorizedKey(t, "sitectl")
+	keyPath := filepath.Join(tempHome, "prod_ed25519")
+	if err := os.WriteFile(keyPath+".pub", []byte(ownLine+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fakeSSHConfig(t, "user deploy\nhostname 10.0.0.5\nport 22\nidentityfile "+keyPath+"\nproxyjump none\nproxycommand none")
+	if err := config.SaveContext(&config.Context{Name: "prod", DockerHostType: config.ContextRemote, SSHConfigHost: "prod"}, true); err != nil {
+		t.Fatalf("SaveContext() error = %v", err)
+	}
+	previous := hostRunScript
+	t.Cleanup(func() { hostRunScript = previous })
+	writes := 0
+	hostRunScript = func(_ context.Context, _ *config.Context, script string) (string, error) {
+		if script == hostReadAuthorizedKeysScript {
+			return ownLine + "\n", nil
+		}
+		writes++
+		return "", nil
+	}
+
+	command := hostRemoveKeyCmd
+	_ = command.Flags().Lookup("pubkey").Value.Set("")
+	_ = command.Flags().Lookup("fingerprint").Value.Set(ssh.FingerprintSHA256(ownKey))
+	command.SetContext(context.Background())
+	err := command.RunE(command, nil)
+	if err == nil || !strings.Contains(err.Error(), "refusing") || !strings.Contains(err.Error(), "10.0.0.5") {
+		t.Fatalf("removing the alias context key error = %v", err)
+	}
+	if writes != 0 {
+		t.Errorf("authorized_keys written %d times", writes)
+	}
+}
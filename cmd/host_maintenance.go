@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+// hostStreamScript runs a shell script on the context host and streams its
+// output. It is a variable so tests can stand in for the host.
+var hostStreamScript = func(runCtx context.Context, ctx *config.Context, script string) error {
+	_, err := ctx.RunNoTTYCommandContext(runCtx, exec.Command("sh", "-c", script))
+	return err
+}
+
+var (
+	hostMaintenanceInput = config.GetInput
+	hostWaitInterval     = 5 * time.Second
+)
+
+// hostAccessScript reports the SSH user's uid and whether sudo works without
+// a password, which is required because maintenance runs without a TTY.
+const hostAccessScript = `echo @@uid
+id -u
+echo @@sudo
+sudo -n true 2>/dev/null && echo ok
+true`
+
+// hostReadyScript reports the kernel boot id and the Docker server version.
+// A new boot id proves a reboot happened; a version proves Docker is back.
+const hostReadyScript = `echo @@boot-id
+cat /proc/sys/kernel/random/boot_id 2>/dev/null
+echo @@docker
+docker info --format '{{.ServerVersion}}' 2>/dev/null
+true`
+
+// hostRebootScript schedules the reboot in the background so the SSH session
+// that requested it can close cleanly before the host goes down.
+const hostRebootScript = `nohup $SUDO sh -c 'sleep 2; systemctl reboot || reboot' >/dev/null 2>&1 &`
+
+// hostDockerPackages are the Docker packages update-docker upgrades when they
+// are installed, covering Docker's own repositories and distribution builds.
+var hostDockerPackages = []string{
+	"docker-ce", "docker-ce-cli", "containerd.io", "docker-buildx-plugin", "docker-compose-plugin",
+	"docker.io", "docker-compose-v2", "moby-engine", "docker",
+}
+
+var hostRebootCmd = &cobra.Command{
+	Use:   "reboot",
+	Short: "Reboot the remote context host and wait for SSH and Docker to return",
+	Long: `Reboot the host of a remote context, then wait until it has booted again and its Docker
+daemon answers. The SSH user must be root or have passwordless sudo.
+
+Examples:
+  sitectl host reboot --context prod
+  sitectl host reboot --context prod --yolo --timeout 15m`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := hostMaintenanceFlags(cmd)
+		if err != nil {
+			return err
+		}
+		ctx, sudo, err := hostMaintenanceContext(cmd, "reboot")
+		if err != nil {
+			return err
+		}
+		host := contextSSHHost(ctx)
+		if err := confirmHostMaintenance(ctx, "reboot", i18n.HostRebootWarning, opts.yolo); err != nil {
+			return err
+		}
+		before, err := hostRunScript(cmd.Context(), ctx, hostReadyScript)
+		if err != nil {
+			return fmt.Errorf("read boot id of %s: %w", host, err)
+		}
+		bootID := hostSectionValue(before, "boot-id")
+
+		slog.Info("rebooting host", "context", ctx.Name, "host", host)
+		if _, err := hostRunScript(cmd.Context(), ctx, "SUDO="+sudo+"\n"+hostRebootScript); err != nil {
+			return fmt.Errorf("reboot %s: %w", host, err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Rebooting %s; waiting up to %s for it to return\n", host, opts.timeout)
+		version, err := waitForHostReady(cmd.Context(), ctx, bootID, opts.timeout)
+		if err != nil {
+			return err
+		}
+		slog.Info("host returned after reboot", "context", ctx.Name, "host", host, "docker", version)
+		fmt.Fprintf(cmd.OutOrStdout(), "%s is back with Docker %s\n", host, version)
+		return nil
+	},
+}
+
+var hostUpdateDockerCmd = &cobra.Command{
+	Use:   "update-docker",
+	Short: "Upgrade the Docker packages on the remote context host",
+	Long: `Upgrade the installed Docker engine, CLI, containerd and compose packages of a remote context
+host with its package manager (apt, dnf or yum), then wait for the Docker daemon to answer
+again. Running containers restart when the daemon restarts. The SSH user must be root or
+have passwordless sudo.
+
+Examples:
+  sitectl host update-docker --context prod
+  sitectl host update-docker --context prod --yolo`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := hostMaintenanceFlags(cmd)
+		if err != nil {
+			return err
+		}
+		ctx, sudo, err := hostMaintenanceContext(cmd, "update-docker")
+		if err != nil {
+			return err
+		}
+		host := contextSSHHost(ctx)
+		if err := confirmHostMaintenance(ctx, "upgrade docker", i18n.HostUpdateDockerWarning, opts.yolo); err != nil {
+			return err
+		}
+		before, err := hostRunScript(cmd.Context(), ctx, hostReadyScript)
+		if err != nil {
+			return fmt.Errorf("read docker version on %s: %w", host, err)
+		}
+		previous := hostSectionValue(before, "docker")
+
+		slog.Info("upgrading docker", "context", ctx.Name, "host", host, "from", previous)
+		if err := hostStreamScript(cmd.Context(), ctx, hostUpdateDockerScript(sudo)); err != nil {
+			return fmt.Errorf("upgrade docker on %s: %w", host, err)
+		}
+		version, err := waitForHostReady(cmd.Context(), ctx, "", opts.timeout)
+		if err != nil {
+			return err
+		}
+		slog.Info("docker upgraded", "context", ctx.Name, "host", host, "from", previous, "to", version)
+		if previous == version {
+			fmt.Fprintf(cmd.OutOrStdout(), "Docker on %s is already at the latest packaged version %s\n", host, version)
+			return nil
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Docker on %s upgraded from %s to %s\n", host, stringValueOrUnknown(previous), version)
+		return nil
+	},
+}
+
+type hostMaintenanceOptions struct {
+	yolo    bool
+	timeout time.Duration
+}
+
+func hostMaintenanceFlags(cmd *cobra.Command) (hostMaintenanceOptions, error) {
+	var opts hostMaintenanceOptions
+	var err error
+	if opts.yolo, err = cmd.Flags().GetBool("yolo"); err != nil {
+		return opts, err
+	}
+	if opts.timeout, err = cmd.Flags().GetDuration("timeout"); err != nil {
+		return opts, err
+	}
+	if opts.timeout <= 0 {
+		return opts, fmt.Errorf("invalid argument %q for \"--timeout\": must be greater than zero", opts.timeout)
+	}
+	return opts, nil
+}
+
+// hostMaintenanceContext resolves the remote context for a maintenance
+// command and returns the prefix that runs a command as root on its host.
+func hostMaintenanceContext(cmd *cobra.Command, action string) (*config.Context, string, error) {
+	ctx, err := resolveCurrentContext(cmd)
+	if err != nil {
+		return nil, "", err
+	}
+	if ctx.DockerHostType != config.ContextRemote {
+		return nil, "", fmt.Errorf("host %s requires a remote context; %q is %s", action, ctx.Name, ctx.DockerHostType)
+	}
+	sudo, err := hostRootPrefix(cmd.Context(), ctx, "host "+action)
+	if err != nil {
+		return nil, "", err
+	}
+	return ctx, sudo, nil
+}
+
+// hostRootPrefix returns the prefix that runs a command as root on the
+// context host: empty for root, "sudo -n" for passwordless sudo.
+func hostRootPrefix(runCtx context.Context, ctx *config.Context, action string) (string, error) {
+	endpoint := contextSSHEndpoint(ctx)
+	output, err := hostRunScript(runCtx, ctx, hostAccessScript)
+	if err != nil {
+		return "", fmt.Errorf("check access to %s: %w", endpoint.Hostname, err)
+	}
+	if hostSectionValue(output, "uid") == "0" {
+		return "", nil
+	}
+	if hostSectionValue(output, "sudo") == "ok" {
+		return "sudo -n", nil
+	}
+	return "", fmt.Errorf("%s needs root: %s on %s must be root or have passwordless sudo", action, endpoint.User, endpoint.Hostname)
+}
+
+func confirmHostMaintenance(ctx *config.Context, action string, warning i18n.Message, yolo bool) error {
+	host := contextSSHHost(ctx)
+	if config.AutoConfirm(fmt.Sprintf("%s on %s (context %q)", action, host, ctx.Name), yolo) {
+		return nil
+	}
+	input, err := hostMaintenanceInput(i18n.T(warning, host, ctx.Name), i18n.T(i18n.ContinuePrompt))
+	if err != nil {
+		return err
+	}
+	if !i18n.IsYes(input) {
+		return fmt.Errorf("host %s cancelled", action)
+	}
+	return nil
+}
+
+// waitForHostReady polls the host until Docker answers and, when bootID is
+// set, the host reports a different boot id. Connection failures while the
+// host is down are expected and retried.
+func waitForHostReady(runCtx context.Context, ctx *config.Context, bootID string, timeout time.Duration) (string, error) {
+	host := contextSSHHost(ctx)
+	waitCtx, cancel := context.WithTimeout(runCtx, timeout)
+	defer cancel()
+	for {
+		output, err := hostRunScript(waitCtx, ctx, hostReadyScript)
+		if err == nil {
+			version := hostSectionValue(output, "docker")
+			booted := bootID == "" || hostSectionValue(output, "boot-id") != bootID
+			if booted && version != "" {
+				return version, nil
+			}
+		} else {
+			slog.Debug("host not ready", "host", host, "err", err)
+		}
+
+		timer := time.NewTimer(hostWaitInterval)
+		select {
+		case <-waitCtx.Done():
+			timer.Stop()
+			return "", fmt.Errorf("%s did not return with a running Docker daemon: %w", host, waitCtx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// hostUpdateDockerScript upgrades whichever Docker packages are installed,
+// using the host's package manager.
+func hostUpdateDockerScript(sudo string) string {
+	packages := strings.Join(hostDockerPackages, " ")
+	return `set -e
+SUDO="` + sudo + `"
+pkgs=
+if command -v apt-get >/dev/null 2>&1; then
+  for p in ` + packages + `; do dpkg -s "$p" >/dev/null 2>&1 && pkgs="$pkgs $p"; done
+  [ -n "$pkgs" ] || { echo "no Docker packages are installed through apt" >&2; exit 1; }
+  $SUDO env DEBIAN_FRONTEND=noninteractive apt-get update
+  $SUDO env DEBIAN_FRONTEND=noninteractive apt-get install -y --only-upgrade $pkgs
+elif command -v dnf >/dev/null 2>&1 || command -v yum >/dev/null 2>&1; then
+  installer=$(command -v dnf || command -v yum)
+  for p in ` + packages + `; do rpm -q "$p" >/dev/null 2>&1 && pkgs="$pkgs $p"; done
+  [ -n "$pkgs" ] || { echo "no Docker packages are installed through $installer" >&2; exit 1; }
+  $SUDO "$installer" upgrade -y $pkgs
+else
+  echo "no supported package manager (apt-get, dnf or yum) found" >&2
+  exit 1
+fi`
+}
+
+// hostSectionValue returns the first line of a marker section in the output
+// of a host script.
+func hostSectionValue(output, section string) string {
+	if lines := splitHostSections(output)[section]; len(lines) > 0 {
+		return lines[0]
+	}
+	return ""
+}
+
+func stringValueOrUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
+
+func init() {
+	for _, command := range []*cobra.Command{hostRebootCmd, hostUpdateDockerCmd} {
+		command.Flags().Bool("yolo", false, "Skip the confirmation prompt")
+		command.Flags().Duration("timeout", 10*time.Minute, "How long to wait for the host and Docker to return")
+		hostCmd.AddCommand(command)
+	}
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libops/sitectl/pkg/config"
+)
+
+func TestHostReboot(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	if err := config.SaveContext(&config.Context{Name: "prod", DockerHostType: config.ContextRemote, SSHHostname: "prod.example.org", SSHUser: "deploy", ProjectDir: "/srv/museum"}, true); err != nil {
+		t.Fatalf("SaveContext() error = %v", err)
+	}
+	previousRun, previousInterval := hostRunScript, hostWaitInterval
+	t.Cleanup(func() { hostRunScript, hostWaitInterval = previousRun, previousInterval })
+	hostWaitInterval = time.Millisecond
+
+	var rebootScript string
+	probes := 0
+	hostRunScript = func(_ context.Context, _ *config.Context, script string) (string, error) {
+		switch {
+		case script == hostAccessScript:
+			return "@@uid\n1000\n@@sudo\nok\n", nil
+		case script == hostReadyScript:
+			probes++
+			switch {
+			case rebootScript == "":
+				return "@@boot-id\nold\n@@docker\n28.5.1\n", nil
+			case probes == 2:
+				return "", errors.New("ssh: connection refused")
+			case probes == 3:
+				return "@@boot-id\nnew\n@@docker\n", nil
+			default:
+				return "@@boot-id\nnew\n@@docker\n28.5.1\n", nil
+			}
+		default:
+			rebootScript = script
+			return "", nil
+		}
+	}
+
+	var out bytes.Buffer
+	hostRebootCmd.SetOut(&out)
+	hostRebootCmd.SetContext(context.Background())
+	if err := hostRebootCmd.Flags().Set("yolo", "true"); err != nil {
+		t.Fatal(err)
+	}
+	if err := hostRebootCmd.RunE(hostRebootCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(rebootScript, "SUDO=sudo -n\n") || !strings.Contains(rebootScript, "systemctl reboot") {
+		t.Errorf("reboot script = %q", rebootScript)
+	}
+	if probes != 4 {
+		t.Errorf("ready probes = %d, want 4", probes)
+	}
+	if !strings.Contains(out.String(), "prod.example.org is back with Docker 28.5.1") {
+		t.Errorf("output = %q", out.String())
+	}
+}
+
+func TestHostMaintenanceContext(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	previous := hostRunScript
+	t.Cleanup(func() { hostRunScript = previous })
+
+	tests := []struct {
+		name     string
+		context  config.Context
+		access   string
+		wantSudo string
+		wantErr  string
+	}{
+		{name: "local", context: config.Context{Name: "dev", DockerHostType: config.ContextLocal}, wantErr: "requires a remote context"},
+		{name: "root", context: config.Context{Name: "prod", DockerHostType: config.ContextRemote, SSHHostname: "prod"}, access: "@@uid\n0\n@@sudo\n"},
+		{name: "sudo", context: config.Context{Name: "prod", DockerHostType: config.ContextRemote, SSHHostname: "prod"}, access: "@@uid\n1000\n@@sudo\nok\n", wantSudo: "sudo -n"},
+		{name: "no sudo", context: config.Context{Name: "prod", DockerHostType: config.ContextRemote, SSHHostname: "prod"}, access: "@@uid\n1000\n@@sudo\n", wantErr: "passwordless sudo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := config.SaveContext(&tt.context, true); err != nil {
+				t.Fatalf("SaveContext() error = %v", err)
+			}
+			hostRunScript = func(context.Context, *config.Context, string) (string, error) {
+				return tt.access, nil
+			}
+			hostUpdateDockerCmd.SetContext(context.Background())
+			_, sudo, err := hostMaintenanceContext(hostUpdateDockerCmd, "update-docker")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sudo != tt.wantSudo {
+				t.Errorf("sudo = %q, want %q", sudo, tt.wantSudo)
+			}
+		})
+	}
+}
+
+func TestWaitForHostReadyTimeout(t *testing.T) {
+	previousRun, previousInterval := hostRunScript, hostWaitInterval
+	t.Cleanup(func() { hostRunScript, hostWaitInterval = previousRun, previousInterval })
+	hostWaitInterval = time.Millisecond
+	hostRunScript = func(context.Context, *config.Context, string) (string, error) {
+		return "@@boot-id\nold\n@@docker\n28.5.1\n", nil
+	}
+
+	_, err := waitForHostReady(context.Background(), &config.Context{SSHHostname: "prod"}, "old", 20*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error = %v, want deadline exceeded", err)
+	}
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/libops/sitectl/pkg/format"
+	"github.com/spf13/cobra"
+)
+
+// hostStatusScript collects triage facts from a Linux host after marker
+// lines. Every probe tolerates failure so a missing tool only blanks its own
+// section.
+const hostStatusScript = `echo @@uptime
+cat /proc/uptime 2>/dev/null
+echo @@loadavg
+cat /proc/loadavg 2>/dev/null
+echo @@cpus
+nproc 2>/dev/null
+echo @@meminfo
+grep -E '^(MemTotal|MemAvailable|SwapTotal|SwapFree):' /proc/meminfo 2>/dev/null
+echo @@security-updates
+if command -v apt-get >/dev/null 2>&1; then
+  apt-get -s -o Debug::NoLocking=1 upgrade 2>/dev/null | grep '^Inst' | grep -ci security
+elif command -v dnf >/dev/null 2>&1; then
+  dnf -q updateinfo list --security 2>/dev/null | wc -l
+elif command -v yum >/dev/null 2>&1; then
+  yum -q updateinfo list security 2>/dev/null | wc -l
+fi
+echo @@reboot-required
+test -f /var/run/reboot-required && echo yes
+echo @@docker-service
+systemctl is-active docker 2>/dev/null
+echo @@docker
+docker info --format '{{.ServerVersion}}|{{.ContainersRunning}}|{{.Containers}}' 2>/dev/null
+true`
+
+type hostStatus struct {
+	Uptime            string  `json:"uptime,omitempty"`
+	UptimeSeconds     float64 `json:"uptime_seconds,omitempty"`
+	Load1             float64 `json:"load1"`
+	Load5             float64 `json:"load5"`
+	Load15            float64 `json:"load15"`
+	CPUs              int     `json:"cpus,omitempty"`
+	MemoryTotalKB     int64   `json:"memory_total_kb,omitempty"`
+	MemoryAvailableKB int64   `json:"memory_available_kb,omitempty"`
+	SwapTotalKB       int64   `json:"swap_total_kb,omitempty"`
+	SwapFreeKB        int64   `json:"swap_free_kb,omitempty"`
+	SecurityUpdates   *int    `json:"security_updates,omitempty"`
+	RebootRequired    bool    `json:"reboot_required,omitempty"`
+	DockerService     string  `json:"docker_service,omitempty"`
+	DockerReachable   bool    `json:"docker_reachable"`
+	DockerVersion     string  `json:"docker_version,omitempty"`
+	ContainersRunning int     `json:"containers_running"`
+	ContainersTotal   int     `json:"containers_total"`
+
+	hasLoad bool
+}
+
+var hostStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show load, memory, uptime, pending security updates and Docker state of the context host",
+	Long: `Collect a quick triage view of the context host: uptime, load average against the CPU count,
+memory and swap use, pending OS security updates (apt, dnf or yum), whether a reboot is
+required, and whether the Docker daemon is running and answering.
+
+Values that cannot be read on the host, for example on a non-Linux local machine, are shown
+as "-".
+
+Examples:
+  sitectl host status
+  sitectl host status --context prod --format json
+  sitectl host status --format '{{.DockerVersion}}'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		outputFormat, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		formatter, err := format.NewFormatter(outputFormat)
+		if err != nil {
+			return err
+		}
+		formatter.SetOutput(cmd.OutOrStdout())
+		ctx, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+
+		output, err := hostRunScript(cmd.Context(), ctx, hostStatusScript)
+		if err != nil {
+			return fmt.Errorf("read host status of context %q: %w", ctx.Name, err)
+		}
+		status := parseHostStatus(output)
+		return formatter.Print(status, []string{"CHECK", "VALUE"}, hostStatusRows(status))
+	},
+}
+
+// parseHostStatus parses the output of hostStatusScript.
+func parseHostStatus(output string) hostStatus {
+	sections := splitHostSections(output)
+	first := func(name string) string {
+		if lines := sections[name]; len(lines) > 0 {
+			return lines[0]
+		}
+		return ""
+	}
+
+	var status hostStatus
+	if fields := strings.Fields(first("uptime")); len(fields) > 0 {
+		if seconds, err := strconv.ParseFloat(fields[0], 64); err == nil {
+			status.UptimeSeconds = seconds
+			status.Uptime = formatUptime(time.Duration(seconds) * time.Second)
+		}
+	}
+	if fields := strings.Fields(first("loadavg")); len(fields) >= 3 {
+		status.Load1, _ = strconv.ParseFloat(fields[0], 64)
+		status.Load5, _ = strconv.ParseFloat(fields[1], 64)
+		status.Load15, _ = strconv.ParseFloat(fields[2], 64)
+		status.hasLoad = true
+	}
+	status.CPUs, _ = strconv.Atoi(first("cpus"))
+	for _, line := range sections["meminfo"] {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		value, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch strings.TrimSuffix(fields[0], ":") {
+		case "MemTotal":
+			status.MemoryTotalKB = value
+		case "MemAvailable":
+			status.MemoryAvailableKB = value
+		case "SwapTotal":
+			status.SwapTotalKB = value
+		case "SwapFree":
+			status.SwapFreeKB = value
+		}
+	}
+	if updates, err := strconv.Atoi(first("security-updates")); err == nil {
+		status.SecurityUpdates = &updates
+	}
+	status.RebootRequired = first("reboot-required") == "yes"
+	status.DockerService = first("docker-service")
+	if fields := strings.Split(first("docker"), "|"); len(fields) == 3 {
+		status.DockerReachable = true
+		status.DockerVersion = fields[0]
+		status.ContainersRunning, _ = strconv.Atoi(fields[1])
+		status.ContainersTotal, _ = strconv.Atoi(fields[2])
+	}
+	return status
+}
+
+func formatUptime(uptime time.Duration) string {
+	days := int(uptime.Hours()) / 24
+	hours := int(uptime.Hours()) % 24
+	minutes := int(uptime.Minutes()) % 60
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+func hostStatusRows(status hostStatus) [][]string {
+	value := func(ok bool, text string) string {
+		if !ok {
+			return "-"
+		}
+		return text
+	}
+	load := fmt.Sprintf("%.2f %.2f %.2f", status.Load1, status.Load5, status.Load15)
+	if status.CPUs > 0 {
+		load += fmt.Sprintf(" (%d CPUs)", status.CPUs)
+		if status.Load5 > float64(status.CPUs) {
+			load += " !"
+		}
+	}
+	memory := ""
+	if status.MemoryTotalKB > 0 {
+		used := status.MemoryTotalKB - status.MemoryAvailableKB
+		memory = fmt.Sprintf("%s / %s (%d%%)", humanBytes(used*1024), humanBytes(status.MemoryTotalKB*1024), used*100/status.MemoryTotalKB)
+	}
+	swap := ""
+	if status.SwapTotalKB > 0 {
+		swap = fmt.Sprintf("%s / %s", humanBytes((status.SwapTotalKB-status.SwapFreeKB)*1024), humanBytes(status.SwapTotalKB*1024))
+	}
+	updates := ""
+	if status.SecurityUpdates != nil {
+		updates = strconv.Itoa(*status.SecurityUpdates)
+	}
+	reboot := "no"
+	if status.RebootRequired {
+		reboot = "yes"
+	}
+	docker := "not reachable"
+	if status.DockerReachable {
+		docker = fmt.Sprintf("%s, %d/%d containers running", status.DockerVersion, status.ContainersRunning, status.ContainersTotal)
+	}
+	return [][]string{
+		{"Uptime", value(status.Uptime != "", status.Uptime)},
+		{"Load average", value(status.hasLoad, load)},
+		{"Memory used", value(memory != "", memory)},
+		{"Swap used", value(swap != "", swap)},
+		{"Security updates", value(updates != "", updates)},
+		{"Reboot required", reboot},
+		{"Docker service", value(status.DockerService != "", status.DockerService)},
+		{"Docker", docker},
+	}
+}
+
+func init() {
+	hostStatusCmd.Flags().String("format", "table", "Output format: table, json, or a Go template")
+	declareFormats(hostStatusCmd, "table", "json")
+	hostCmd.AddCommand(hostStatusCmd)
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseHostStatus(t *testing.T) {
+	t.Parallel()
+
+	output := `@@uptime
+1036800.52 4011212.10
+@@loadavg
+3.10 4.50 2.00 2/411 12345
+@@cpus
+4
+@@meminfo
+MemTotal:        8000000 kB
+MemAvailable:    2000000 kB
+SwapTotal:       1048576 kB
+SwapFree:        1048576 kB
+@@security-updates
+7
+@@reboot-required
+yes
+@@docker-service
+active
+@@docker
+28.5.2|9|11
+`
+	status := parseHostStatus(output)
+	if status.Uptime != "12d 0h 0m" || status.CPUs != 4 || status.Load5 != 4.5 {
+		t.Fatalf("status = %+v", status)
+	}
+	if status.SecurityUpdates == nil || *status.SecurityUpdates != 7 || !status.RebootRequired {
+		t.Fatalf("updates = %v, reboot = %v", status.SecurityUpdates, status.RebootRequired)
+	}
+	rows := map[string]string{}
+	for _, row := range hostStatusRows(status) {
+		rows[row[0]] = row[1]
+	}
+	for check, want := range map[string]string{
+		"Load average":     "3.10 4.50 2.00 (4 CPUs) !",
+		"Memory used":      "5.7GiB / 7.6GiB (75%)",
+		"Swap used":        "0B / 1.0GiB",
+		"Security updates": "7",
+		"Docker service":   "active",
+		"Docker":           "28.5.2, 9/11 containers running",
+	} {
+		if rows[check] != want {
+			t.Errorf("%s = %q, want %q", check, rows[check], want)
+		}
+	}
+
+	empty := parseHostStatus("@@uptime\n@@loadavg\n@@security-updates\n@@docker\n")
+	for _, row := range hostStatusRows(empty) {
+		if row[0] == "Reboot required" {
+			continue
+		}
+		if row[1] != "-" && !strings.Contains(row[1], "not reachable") {
+			t.Errorf("%s = %q on a host without data, want -", row[0], row[1])
+		}
+	}
+}
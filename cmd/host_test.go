@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/libops/sitectl/pkg/config"
+)
+
+const hostDiskUsageOutput = `@@df
+Filesystem     1024-blocks      Used Available Capacity Mounted on
+udev               4017040         0   4017040       0% /dev
+tmpfs               806532      1140    805392       1% /run
+/dev/sda1         50620216  46010004   4593828      91% /
+/dev/sdb1        103081248  20971520  76850016      22% /srv
+/dev/sdc1         10255636    102400   9612564       1% /mnt/backup disk
+@@docker-root
+/var/lib/docker
+@@docker-df
+Images|12|5|4.2GB|2.1GB (50%)
+Containers|6|5|12MB|0B (0%)
+Local Volumes|4|3|8.5GB|1GB (11%)
+Build Cache|0|0|0B|0B
+`
+
+func TestParseHostDiskUsage(t *testing.T) {
+	t.Parallel()
+
+	usage := parseHostDiskUsage(hostDiskUsageOutput, "/srv/museum", 85, false)
+	if len(usage.Filesystems) != 3 {
+		t.Fatalf("filesystems = %+v, want 3 real filesystems", usage.Filesystems)
+	}
+	root, srv, backup := usage.Filesystems[0], usage.Filesystems[1], usage.Filesystems[2]
+	if root.MountedOn != "/" || !root.Warning || strings.Join(root.Holds, ",") != "docker root" {
+		t.Errorf("root = %+v, want warning holding docker root", root)
+	}
+	if srv.Warning || strings.Join(srv.Holds, ",") != "project dir" {
+		t.Errorf("srv = %+v, want project dir without warning", srv)
+	}
+	if backup.MountedOn != "/mnt/backup disk" || len(backup.Holds) != 0 {
+		t.Errorf("backup = %+v", backup)
+	}
+	if usage.DockerRoot != "/var/lib/docker" || len(usage.Docker) != 4 || usage.Docker[2].Type != "Local Volumes" {
+		t.Errorf("docker usage = %q %+v", usage.DockerRoot, usage.Docker)
+	}
+
+	all := parseHostDiskUsage(hostDiskUsageOutput, "", 0, true)
+	if len(all.Filesystems) != 5 || all.Filesystems[2].Warning {
+		t.Errorf("--all --warn 0 filesystems = %+v", all.Filesystems)
+	}
+}
+
+func TestHostDfCommand(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	if err := config.SaveContext(&config.Context{Name: "museum", DockerHostType: config.ContextLocal, ProjectDir: "/srv/museum"}, true); err != nil {
+		t.Fatalf("SaveContext() error = %v", err)
+	}
+	previous := hostRunScript
+	t.Cleanup(func() { hostRunScript = previous })
+
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{
+			name:   "with docker",
+			output: hostDiskUsageOutput,
+			want:   []string{"91% !", "docker root", "project dir", "Local Volumes", "8.5GB"},
+		},
+		{
+			name:   "docker unavailable",
+			output: strings.Split(hostDiskUsageOutput, "@@docker-root")[0] + "@@docker-root\n@@docker-df\n",
+			want:   []string{"project dir", "Docker disk usage unavailable"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hostRunScript = func(_ context.Context, ctx *config.Context, script string) (string, error) {
+				if ctx.Name != "museum" || script != hostDiskUsageScript {
+					t.Fatalf("ran %q on %q", script, ctx.Name)
+				}
+				return tt.output, nil
+			}
+			var out bytes.Buffer
+			hostDfCmd.SetOut(&out)
+			hostDfCmd.SetContext(context.Background())
+			if err := hostDfCmd.RunE(hostDfCmd, nil); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("host df output missing %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
@@ -10,8 +10,9 @@ import (
 )
 
 var imageCmd = &cobra.Command{
-	Use:   "image",
-	Short: "Manage Compose image overrides for a site",
+	Use:     "image",
+	Aliases: []string{"images"},
+	Short:   "Manage Compose image overrides, check image freshness and copy images to a site",
 }
 
 var imageSetCmd = &cobra.Command{
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	dockerimage "github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/client"
+	"github.com/libops/sitectl/pkg/docker"
+	"github.com/spf13/cobra"
+)
+
+// Image freshness states reported by image outdated.
+const (
+	imageStatusCurrent     = "current"
+	imageStatusOutdated    = "outdated"
+	imageStatusNotRecreate = "pulled, not recreated"
+	imageStatusLocal       = "local build"
+	imageStatusUnknown     = "unknown"
+)
+
+// imageFreshnessAPI is the part of the Docker client used to compare the
+// images of running containers with their registries.
+type imageFreshnessAPI interface {
+	ImageInspect(ctx context.Context, imageID string, inspectOpts ...client.ImageInspectOption) (dockerimage.InspectResponse, error)
+	DistributionInspect(ctx context.Context, imageRef, encodedRegistryAuth string) (registry.DistributionInspect, error)
+	ImagePull(ctx context.Context, refStr string, options dockerimage.PullOptions) (io.ReadCloser, error)
+}
+
+// imageFreshness is the registry comparison for one Compose service.
+type imageFreshness struct {
+	Service        string `json:"service"`
+	Image          string `json:"image"`
+	Status         string `json:"status"`
+	LocalDigest    string `json:"local_digest,omitempty"`
+	RegistryDigest string `json:"registry_digest,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+var imageOutdatedCmd = &cobra.Command{
+	Use:   "outdated",
+	Short: "Report services running images that are stale compared to their registry",
+	Long: `Compare the image of every running container in the active context's compose project with
+the digest its registry currently serves for the same tag.
+
+A service is "outdated" when the registry has a newer image, and "pulled, not recreated"
+when the newer image is already on the Docker host but the container still runs the old one.
+Images built locally have no registry digest and are reported as "local build".
+
+With --pull, outdated images are pulled onto the Docker host. Containers are not
+recreated; run sitectl compose up -d afterwards.
+
+Examples:
+  sitectl image outdated
+  sitectl image outdated --pull
+  sitectl image outdated --context prod --format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		if format != "table" && format != "json" {
+			return fmt.Errorf("unsupported format %q: use table or json", format)
+		}
+		pull, err := cmd.Flags().GetBool("pull")
+		if err != nil {
+			return err
+		}
+		ctx, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+		cli, err := docker.GetDockerCli(ctx)
+		if err != nil {
+			return err
+		}
+		defer cli.Close()
+		api, ok := cli.CLI.(imageFreshnessAPI)
+		if !ok {
+			return fmt.Errorf("docker client does not support registry inspection")
+		}
+
+		filterArgs := filters.NewArgs()
+		filterArgs.Add("label", "com.docker.compose.project="+ctx.EffectiveComposeProjectName())
+		containers, err := cli.CLI.ContainerList(cmd.Context(), dockercontainer.ListOptions{Filters: filterArgs})
+		if err != nil {
+			return fmt.Errorf("list compose containers: %w", err)
+		}
+
+		results := checkImageFreshness(cmd.Context(), api, containers)
+		if pull {
+			if err := pullOutdatedImages(cmd.Context(), api, results, cmd.ErrOrStderr()); err != nil {
+				return err
+			}
+		}
+		if format == "json" {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(results)
+		}
+		if len(results) == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "No running services found for context %q\n", ctx.Name)
+			return nil
+		}
+		return writeImageFreshnessTable(cmd.OutOrStdout(), results)
+	},
+}
+
+// checkImageFreshness compares each running service's image with its
+// registry, sorted by service. Registry failures are reported per service so
+// one private or unreachable registry does not hide the rest of the report.
+func checkImageFreshness(ctx context.Context, api imageFreshnessAPI, containers []dockercontainer.Summary) []imageFreshness {
+	results := []imageFreshness{}
+	seen := map[string]bool{}
+	for _, container := range containers {
+		service := container.Labels["com.docker.compose.service"]
+		if service == "" || seen[service] {
+			continue
+		}
+		seen[service] = true
+		results = append(results, checkContainerImage(ctx, api, service, container))
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Service < results[j].Service })
+	return results
+}
+
+func checkContainerImage(ctx context.Context, api imageFreshnessAPI, service string, container dockercontainer.Summary) imageFreshness {
+	result := imageFreshness{Service: service, Image: container.Image, Status: imageStatusUnknown}
+	running, err := api.ImageInspect(ctx, container.ImageID)
+	if err != nil {
+		result.Error = fmt.Sprintf("inspect image: %v", err)
+		return result
+	}
+	if len(running.RepoDigests) == 0 || strings.HasPrefix(container.Image, "sha256:") {
+		result.Status = imageStatusLocal
+		return result
+	}
+	result.LocalDigest = repoDigest(running.RepoDigests[0])
+
+	distribution, err := api.DistributionInspect(ctx, container.Image, "")
+	if err != nil {
+		result.Error = fmt.Sprintf("inspect registry: %v", err)
+		return result
+	}
+	result.RegistryDigest = distribution.Descriptor.Digest.String()
+	for _, digest := range running.RepoDigests {
+		if repoDigest(digest) == result.RegistryDigest {
+			result.LocalDigest = result.RegistryDigest
+			result.Status = imageStatusCurrent
+			return result
+		}
+	}
+
+	result.Status = imageStatusOutdated
+	if tagged, err := api.ImageInspect(ctx, container.Image); err == nil && tagged.ID != container.ImageID {
+		for _, digest := range tagged.RepoDigests {
+			if repoDigest(digest) == result.RegistryDigest {
+				result.Status = imageStatusNotRecreate
+			}
+		}
+	}
+	return result
+}
+
+// repoDigest returns the digest part of a repo digest such as
+// "mariadb@sha256:abc".
+func repoDigest(value string) string {
+	if index := strings.LastIndex(value, "@"); index >= 0 {
+		return value[index+1:]
+	}
+	return value
+}
+
+func pullOutdatedImages(ctx context.Context, api imageFreshnessAPI, results []imageFreshness, progress io.Writer) error {
+	pulled := map[string]bool{}
+	for _, result := range results {
+		if result.Status != imageStatusOutdated || pulled[result.Image] {
+			continue
+		}
+		fmt.Fprintf(progress, "Pulling %s\n", result.Image)
+		reader, err := api.ImagePull(ctx, result.Image, dockerimage.PullOptions{})
+		if err != nil {
+			return fmt.Errorf("pull %s: %w", result.Image, err)
+		}
+		_, err = io.Copy(io.Discard, reader)
+		_ = reader.Close()
+		if err != nil {
+			return fmt.Errorf("pull %s: %w", result.Image, err)
+		}
+		pulled[result.Image] = true
+	}
+	for i, result := range results {
+		if result.Status == imageStatusOutdated && pulled[result.Image] {
+			results[i].Status = imageStatusNotRecreate
+		}
+	}
+	if len(pulled) > 0 {
+		fmt.Fprintln(progress, "Run sitectl compose up -d to recreate containers with the pulled images.")
+	}
+	return nil
+}
+
+func writeImageFreshnessTable(out io.Writer, results []imageFreshness) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tIMAGE\tSTATUS")
+	for _, result := range results {
+		status := result.Status
+		if result.Error != "" {
+			status += ": " + result.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.Service, result.Image, status)
+	}
+	return w.Flush()
+}
+
+func init() {
+	imageOutdatedCmd.Flags().Bool("pull", false, "Pull outdated images onto the Docker host")
+	imageOutdatedCmd.Flags().String("format", "table", "Output format: table or json")
+	declareFormats(imageOutdatedCmd, "table", "json")
+	imageCmd.AddCommand(imageOutdatedCmd)
+}
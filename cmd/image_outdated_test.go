@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	dockerimage "github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/client"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type fakeImageFreshnessAPI struct {
+	images   map[string]dockerimage.InspectResponse
+	registry map[string]ocispec.Descriptor
+	pulled   []string
+}
+
+func (f *fakeImageFreshnessAPI) ImageInspect(_ context.Context, imageID string, _ ...client.ImageInspectOption) (dockerimage.InspectResponse, error) {
+	image, ok := f.images[imageID]
+	if !ok {
+		return dockerimage.InspectResponse{}, errors.New("no such image")
+	}
+	return image, nil
+}
+
+func (f *fakeImageFreshnessAPI) DistributionInspect(_ context.Context, imageRef, _ string) (registry.DistributionInspect, error) {
+	descriptor, ok := f.registry[imageRef]
+	if !ok {
+		return registry.DistributionInspect{}, errors.New("unauthorized")
+	}
+	return registry.DistributionInspect{Descriptor: descriptor}, nil
+}
+
+func (f *fakeImageFreshnessAPI) ImagePull(_ context.Context, ref string, _ dockerimage.PullOptions) (io.ReadCloser, error) {
+	f.pulled = append(f.pulled, ref)
+	return io.NopCloser(strings.NewReader("{}")), nil
+}
+
+func TestCheckImageFreshness(t *testing.T) {
+	t.Parallel()
+
+	api := &fakeImageFreshnessAPI{
+		images: map[string]dockerimage.InspectResponse{
+			"sha256:db-old":     {ID: "sha256:db-old", RepoDigests: []string{"mariadb@sha256:aaa"}},
+			"sha256:solr":       {ID: "sha256:solr", RepoDigests: []string{"solr@sha256:bbb"}},
+			"sha256:drupal":     {ID: "sha256:drupal"},
+			"sha256:redis-old":  {ID: "sha256:redis-old", RepoDigests: []string{"redis@sha256:ccc"}},
+			"redis:7":           {ID: "sha256:redis-new", RepoDigests: []string{"redis@sha256:ddd"}},
+			"sha256:private":    {ID: "sha256:private", RepoDigests: []string{"registry.example.edu/app@sha256:eee"}},
+			"mariadb:11":        {ID: "sha256:db-old", RepoDigests: []string{"mariadb@sha256:aaa"}},
+			"solr:9":            {ID: "sha256:solr", RepoDigests: []string{"solr@sha256:bbb"}},
+			"registry/app:main": {ID: "sha256:private"},
+		},
+		registry: map[string]ocispec.Descriptor{
+			"mariadb:11": {Digest: "sha256:fff"},
+			"solr:9":     {Digest: "sha256:bbb"},
+			"redis:7":    {Digest: "sha256:ddd"},
+		},
+	}
+	containers := []dockercontainer.Summary{
+		{Image: "solr:9", ImageID: "sha256:solr", Labels: map[string]string{"com.docker.compose.service": "solr"}},
+		{Image: "mariadb:11", ImageID: "sha256:db-old", Labels: map[string]string{"com.docker.compose.service": "mariadb"}},
+		{Image: "site-drupal", ImageID: "sha256:drupal", Labels: map[string]string{"com.docker.compose.service": "drupal"}},
+		{Image: "redis:7", ImageID: "sha256:redis-old", Labels: map[string]string{"com.docker.compose.service": "redis"}},
+		{Image: "registry/app:main", ImageID: "sha256:private", Labels: map[string]string{"com.docker.compose.service": "app"}},
+	}
+
+	results := checkImageFreshness(context.Background(), api, containers)
+	want := map[string]string{
+		"app":     imageStatusUnknown,
+		"drupal":  imageStatusLocal,
+		"mariadb": imageStatusOutdated,
+		"redis":   imageStatusNotRecreate,
+		"solr":    imageStatusCurrent,
+	}
+	if len(results) != len(want) {
+		t.Fatalf("results = %+v", results)
+	}
+	for _, result := range results {
+		if result.Status != want[result.Service] {
+			t.Errorf("%s status = %q, want %q", result.Service, result.Status, want[result.Service])
+		}
+	}
+	if results[0].Service != "app" || !strings.Contains(results[0].Error, "unauthorized") {
+		t.Errorf("app result = %+v, want sorted first with registry error", results[0])
+	}
+
+	var progress bytes.Buffer
+	if err := pullOutdatedImages(context.Background(), api, results, &progress); err != nil {
+		t.Fatal(err)
+	}
+	if len(api.pulled) != 1 || api.pulled[0] != "mariadb:11" {
+		t.Fatalf("pulled = %v, want only mariadb:11", api.pulled)
+	}
+	for _, result := range results {
+		if result.Service == "mariadb" && result.Status != imageStatusNotRecreate {
+			t.Fatalf("mariadb status after pull = %q", result.Status)
+		}
+	}
+	if !strings.Contains(progress.String(), "compose up -d") {
+		t.Fatalf("progress = %q", progress.String())
+	}
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/docker"
+	"github.com/libops/sitectl/pkg/plugin"
+	"github.com/spf13/cobra"
+)
+
+var imagePushToContextCmd = &cobra.Command{
+	Use:   "push-to-context IMAGE...",
+	Short: "Copy local images to the context's Docker host over SSH",
+	Long: `Copy images from the local Docker daemon to the active context's Docker host.
+
+The images are streamed from docker save into docker load over the context's SSH
+connection, without a registry or a temporary archive, for hosts that cannot reach a
+registry. The archive is gzipped in transit unless --compress=false.
+
+--from copies from another context's Docker host instead of the local daemon.
+
+Examples:
+  sitectl build --context museum-prod --local
+  sitectl image push-to-context museum-drupal:museum-3f2a9c1d4e5b --context museum-prod
+  sitectl image push-to-context islandora/solr:4 --from museum-stage --context museum-prod`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from, err := cmd.Flags().GetString("from")
+		if err != nil {
+			return err
+		}
+		compress, err := cmd.Flags().GetBool("compress")
+		if err != nil {
+			return err
+		}
+		target, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+
+		var source *config.Context
+		switch {
+		case strings.TrimSpace(from) != "":
+			fromCtx, err := config.GetContext(from)
+			if err != nil {
+				return err
+			}
+			source = &fromCtx
+		case target.DockerHostType == config.ContextLocal:
+			return fmt.Errorf("context %q already uses the local Docker daemon; pass --from to copy from another context", target.Name)
+		default:
+			source = &config.Context{Name: "local", DockerHostType: config.ContextLocal, DockerSocket: config.GetDefaultLocalDockerSocket("/var/run/docker.sock")}
+		}
+		if source.Name == target.Name {
+			return fmt.Errorf("source and target are both context %q", target.Name)
+		}
+
+		sourceCli, err := docker.GetDockerCli(source)
+		if err != nil {
+			return err
+		}
+		defer sourceCli.Close()
+		targetCli, err := docker.GetDockerCli(target)
+		if err != nil {
+			return err
+		}
+		defer targetCli.Close()
+		saver, ok := sourceCli.CLI.(docker.ImageSaveAPI)
+		if !ok {
+			return fmt.Errorf("docker client for %s cannot save images", source.Name)
+		}
+		loader, ok := targetCli.CLI.(docker.ImageLoadAPI)
+		if !ok {
+			return fmt.Errorf("docker client for %s cannot load images", target.Name)
+		}
+
+		title := fmt.Sprintf("Copying %s to %s", strings.Join(args, ", "), target.Name)
+		progress := plugin.NewProgressLine(cmd.ErrOrStderr(), title, "")
+		loaded, err := docker.TransferImages(cmd.Context(), saver, loader, args, docker.ImageTransferOptions{
+			Compress: compress,
+			Progress: func(transferred int64) { progress.Report(title, formatKiB(transferred/1024)) },
+		})
+		progress.Close()
+		if err != nil {
+			return err
+		}
+		for _, image := range loaded {
+			fmt.Fprintf(cmd.OutOrStdout(), "Loaded %s on %s\n", image, target.Name)
+		}
+		return nil
+	},
+}
+
+func init() {
+	imagePushToContextCmd.Flags().String("from", "", "Copy from this context's Docker host instead of the local daemon")
+	imagePushToContextCmd.Flags().Bool("compress", true, "Gzip the image archive in transit")
+	imageCmd.AddCommand(imagePushToContextCmd)
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/docker"
+	"github.com/libops/sitectl/pkg/helpers"
+	"github.com/libops/sitectl/pkg/plugin"
+	"github.com/spf13/cobra"
+)
+
+const infoProbeTimeout = 15 * time.Second
+
+type infoOptions struct {
+	format  string
+	offline bool
+}
+
+type infoReport struct {
+	Sitectl infoSitectl `json:"sitectl"`
+	Context infoContext `json:"context"`
+	Docker  *infoDocker `json:"docker,omitempty"`
+	Plugin  *infoPlugin `json:"plugin,omitempty"`
+}
+
+type infoSitectl struct {
+	Version    string `json:"version"`
+	ConfigFile string `json:"config_file"`
+}
+
+type infoContext struct {
+	Name           string            `json:"name"`
+	Current        bool              `json:"current"`
+	Site           string            `json:"site,omitempty"`
+	Plugin         string            `json:"plugin,omitempty"`
+	Environment    string            `json:"environment,omitempty"`
+	Type           string            `json:"type"`
+	Host           string            `json:"host,omitempty"`
+	ProjectDir     string            `json:"project_dir,omitempty"`
+	ComposeProject string            `json:"compose_project,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	Protected      bool              `json:"protected,omitempty"`
+}
+
+type infoDocker struct {
+	Reachable      bool   `json:"reachable"`
+	ConnectLatency string `json:"connect_latency,omitempty"`
+	APILatency     string `json:"api_latency,omitempty"`
+	Version        string `json:"version,omitempty"`
+	APIVersion     string `json:"api_version,omitempty"`
+	OS             string `json:"os,omitempty"`
+	Arch           string `json:"arch,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+type infoPlugin struct {
+	Name      string `json:"name"`
+	Installed bool   `json:"installed"`
+	Version   string `json:"version,omitempty"`
+	Path      string `json:"path,omitempty"`
+}
+
+// dockerVersionAPI is the part of the Docker client used to report the
+// daemon version.
+type dockerVersionAPI interface {
+	ServerVersion(ctx context.Context) (dockertypes.Version, error)
+}
+
+var infoConnectDocker = func(ctx *config.Context) (*docker.DockerClient, error) {
+	return docker.GetDockerCli(ctx)
+}
+
+var infoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Summarize the active context, its Docker connectivity, and the sitectl install",
+	Long: `Print the sitectl version and config file, the active context's settings, whether its Docker
+daemon is reachable (with connection and API latency over SSH for remote contexts), the daemon
+version, and the plugin that owns the context.
+
+This is the first thing to attach to a support request.
+
+Examples:
+  sitectl info
+  sitectl info --context prod --format json
+  sitectl info --offline        # Skip connecting to Docker`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+		report := buildInfoReport(cmd.Context(), ctx, infoOpts.offline)
+		switch strings.ToLower(strings.TrimSpace(infoOpts.format)) {
+		case "", "table":
+			return writeInfoTable(cmd.OutOrStdout(), report)
+		case "json":
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(report)
+		default:
+			return fmt.Errorf("invalid argument %q for \"--format\": must be table or json", infoOpts.format)
+		}
+	},
+}
+
+var infoOpts infoOptions
+
+func buildInfoReport(runCtx context.Context, ctx *config.Context, offline bool) infoReport {
+	report := infoReport{
+		Sitectl: infoSitectl{Version: helpers.FirstNonEmpty(RootCmd.Version, "dev")},
+		Context: infoContext{
+			Name:           ctx.Name,
+			Site:           ctx.Site,
+			Plugin:         helpers.FirstNonEmpty(ctx.Plugin, "core"),
+			Environment:    ctx.Environment,
+			Type:           string(ctx.DockerHostType),
+			ProjectDir:     ctx.ProjectDir,
+			ComposeProject: ctx.EffectiveComposeProjectName(),
+			Labels:         ctx.Labels,
+			Protected:      ctx.Protected,
+		},
+	}
+	if path, err := config.ConfigFilePath(); err == nil {
+		report.Sitectl.ConfigFile = path
+	}
+	if current, err := config.Current(); err == nil {
+		report.Context.Current = current == ctx.Name
+	}
+	if ctx.DockerHostType == config.ContextRemote {
+		endpoint := contextSSHEndpoint(ctx)
+		report.Context.Host = fmt.Sprintf("%s@%s:%d", endpoint.User, endpoint.Hostname, endpoint.Port)
+		if jump := strings.TrimSpace(ctx.SSHJumpHost); jump != "" {
+			report.Context.Host += " via " + jump
+		}
+		if alias := strings.TrimSpace(ctx.SSHConfigHost); alias != "" {
+			report.Context.Host += " (" + alias + " in ~/.ssh/config)"
+		}
+	}
+	if pluginName := strings.TrimSpace(ctx.Plugin); pluginName != "" && pluginName != "core" {
+		installed, ok := plugin.FindInstalled(pluginName)
+		report.Plugin = &infoPlugin{Name: pluginName, Installed: ok, Version: installed.Version, Path: installed.Path}
+	}
+	if !offline {
+		probe := probeInfoDocker(runCtx, ctx)
+		report.Docker = &probe
+	}
+	return report
+}
+
+func probeInfoDocker(runCtx context.Context, ctx *config.Context) infoDocker {
+	var result infoDocker
+	started := time.Now()
+	cli, err := infoConnectDocker(ctx)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer cli.Close()
+	result.ConnectLatency = time.Since(started).Round(time.Millisecond).String()
+
+	versionAPI, ok := cli.CLI.(dockerVersionAPI)
+	if !ok {
+		result.Error = "docker client does not report a version"
+		return result
+	}
+	probeCtx, cancel := context.WithTimeout(runCtx, infoProbeTimeout)
+	defer cancel()
+	started = time.Now()
+	version, err := versionAPI.ServerVersion(probeCtx)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Reachable = true
+	result.APILatency = time.Since(started).Round(time.Millisecond).String()
+	result.Version = version.Version
+	result.APIVersion = version.APIVersion
+	result.OS = version.Os
+	result.Arch = version.Arch
+	return result
+}
+
+func writeInfoTable(out io.Writer, report infoReport) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	row := func(label, value string) {
+		fmt.Fprintf(w, "%s\t%s\n", label, helpers.FirstNonEmpty(value, "-"))
+	}
+	row("Sitectl version", report.Sitectl.Version)
+	row("Config file", report.Sitectl.ConfigFile)
+	name := report.Context.Name
+	if report.Context.Current {
+		name += " (current)"
+	}
+	row("Context", name)
+	row("Site", report.Context.Site)
+	row("Plugin", report.Context.Plugin)
+	row("Environment", report.Context.Environment)
+	row("Type", report.Context.Type)
+	if report.Context.Host != "" {
+		row("SSH host", report.Context.Host)
+	}
+	row("Project dir", report.Context.ProjectDir)
+	row("Compose project", report.Context.ComposeProject)
+	row("Labels", config.FormatLabels(report.Context.Labels))
+	if report.Context.Protected {
+		row("Protected", "yes")
+	}
+	switch {
+	case report.Docker == nil:
+		row("Docker", "not checked")
+	case report.Docker.Error != "":
+		row("Docker", "unreachable: "+report.Docker.Error)
+	case report.Docker.Reachable:
+		row("Docker", fmt.Sprintf("reachable (connect %s, API %s)", report.Docker.ConnectLatency, report.Docker.APILatency))
+		row("Docker version", fmt.Sprintf("%s (API %s, %s/%s)", report.Docker.Version, report.Docker.APIVersion, report.Docker.OS, report.Docker.Arch))
+	}
+	if report.Plugin != nil {
+		status := "not installed"
+		if report.Plugin.Installed {
+			status = strings.TrimSpace(helpers.FirstNonEmpty(report.Plugin.Version, "unknown version") + " " + report.Plugin.Path)
+		}
+		row("Plugin "+report.Plugin.Name, status)
+	}
+	return w.Flush()
+}
+
+func init() {
+	infoCmd.Flags().StringVar(&infoOpts.format, "format", "table", "Output format: table or json")
+	declareFormats(infoCmd, "table", "json")
+	infoCmd.Flags().BoolVar(&infoOpts.offline, "offline", false, "Do not connect to the context's Docker daemon")
+	infoCmd.GroupID = "troubleshoot"
+	enableOutputFile(infoCmd)
+	RootCmd.AddCommand(infoCmd)
+}
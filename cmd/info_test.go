@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/docker"
+)
+
+type fakeInfoDockerAPI struct {
+	fakeServiceDetectAPI
+	version dockertypes.Version
+	err     error
+}
+
+func (f fakeInfoDockerAPI) ServerVersion(context.Context) (dockertypes.Version, error) {
+	return f.version, f.err
+}
+
+func TestBuildInfoReport(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := &config.Context{
+		Name:           "museum-prod",
+		Site:           "museum",
+		Environment:    "prod",
+		DockerHostType: config.ContextRemote,
+		SSHUser:        "deploy",
+		SSHHostname:    "museum.example.edu",
+		SSHPort:        22,
+		ProjectDir:     "/srv/museum",
+		Labels:         map[string]string{"owner": "web-team"},
+		Protected:      true,
+	}
+
+	tests := []struct {
+		name    string
+		api     fakeInfoDockerAPI
+		connect error
+		want    []string
+	}{
+		{
+			name: "reachable",
+			api:  fakeInfoDockerAPI{version: dockertypes.Version{Version: "28.5.2", APIVersion: "1.51", Os: "linux", Arch: "amd64"}},
+			want: []string{"reachable (connect", "28.5.2 (API 1.51, linux/amd64)"},
+		},
+		{
+			name:    "connect failure",
+			connect: errors.New("error establishing SSH connection: dial tcp: timeout"),
+			want:    []string{"unreachable: error establishing SSH connection"},
+		},
+		{
+			name: "api failure",
+			api:  fakeInfoDockerAPI{err: errors.New("Cannot connect to the Docker daemon")},
+			want: []string{"unreachable: Cannot connect to the Docker daemon"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := infoConnectDocker
+			t.Cleanup(func() { infoConnectDocker = previous })
+			infoConnectDocker = func(*config.Context) (*docker.DockerClient, error) {
+				if tt.connect != nil {
+					return nil, tt.connect
+				}
+				return &docker.DockerClient{CLI: tt.api}, nil
+			}
+
+			report := buildInfoReport(context.Background(), ctx, false)
+			var out bytes.Buffer
+			if err := writeInfoTable(&out, report); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range append([]string{
+				"museum-prod",
+				"deploy@museum.example.edu:22",
+				"owner=web-team",
+				"Protected",
+			}, tt.want...) {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("info output missing %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestBuildInfoReportOffline(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	previous := infoConnectDocker
+	t.Cleanup(func() { infoConnectDocker = previous })
+	infoConnectDocker = func(*config.Context) (*docker.DockerClient, error) {
+		t.Fatal("offline info connected to Docker")
+		return nil, nil
+	}
+
+	report := buildInfoReport(context.Background(), &config.Context{Name: "local", DockerHostType: config.ContextLocal}, true)
+	if report.Docker != nil {
+		t.Fatalf("offline report has docker details: %#v", report.Docker)
+	}
+	var out bytes.Buffer
+	if err := writeInfoTable(&out, report); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "not checked") {
+		t.Fatalf("offline output = %s", out.String())
+	}
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var siteIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+var linkCmd = &cobra.Command{
+	Use:   "link [SITE_ID]",
+	Short: "Link a context to a libops site",
+	Long: `Associate a local or remote context with a libops site ID. The ID is stored on the context
+and shown in config get-contexts.
+
+Contexts linked to the same site are treated as environments of one site: sitectl db pull
+without --source pulls from the site's production context.
+
+Without SITE_ID, link prints the site the context is linked to.
+
+Examples:
+  sitectl link museum                    # Link the active context
+  sitectl link museum --context museum-prod
+  sitectl link                           # Show the link of the active context
+  sitectl link --unlink                  # Remove the link`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		unlink, err := cmd.Flags().GetBool("unlink")
+		if err != nil {
+			return err
+		}
+		if unlink && len(args) > 0 {
+			return fmt.Errorf("--unlink does not take a site ID")
+		}
+		ctx, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case unlink:
+			if ctx.SiteID == "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "Context %s is not linked\n", ctx.Name)
+				return nil
+			}
+			if err := setContextSiteID(ctx.Name, ""); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Context %s is no longer linked to site %s\n", ctx.Name, ctx.SiteID)
+		case len(args) == 0:
+			if ctx.SiteID == "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "Context %s is not linked; run sitectl link SITE_ID\n", ctx.Name)
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Context %s is linked to site %s\n", ctx.Name, ctx.SiteID)
+		default:
+			siteID := strings.TrimSpace(args[0])
+			if err := validateSiteID(siteID); err != nil {
+				return err
+			}
+			if ctx.SiteID == siteID {
+				fmt.Fprintf(cmd.OutOrStdout(), "Context %s is already linked to site %s\n", ctx.Name, siteID)
+				return nil
+			}
+			if err := setContextSiteID(ctx.Name, siteID); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Context %s is now linked to site %s\n", ctx.Name, siteID)
+		}
+		return nil
+	},
+}
+
+func validateSiteID(siteID string) error {
+	if !siteIDPattern.MatchString(siteID) {
+		return fmt.Errorf("invalid site ID %q: use letters, digits, '.', '_' or '-'", siteID)
+	}
+	return nil
+}
+
+func setContextSiteID(name, siteID string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	found := false
+	for i := range cfg.Contexts {
+		if cfg.Contexts[i].Name == name {
+			cfg.Contexts[i].SiteID = siteID
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", config.ErrContextNotFound, name)
+	}
+	return config.Save(cfg)
+}
+
+// resolveLinkedSource returns the context db pull reads from when --source is
+// omitted: the production context linked to the same site as target.
+func resolveLinkedSource(target string) (string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+	targetCtx, err := config.GetContext(target)
+	if err != nil {
+		return "", fmt.Errorf("load target context %q: %w", target, err)
+	}
+	return linkedProductionContext(cfg.Contexts, targetCtx)
+}
+
+// linkedProductionContext returns the production context linked to the same
+// site as target. It fails when target is not linked or when the site has no
+// production context, or more than one.
+func linkedProductionContext(contexts []config.Context, target config.Context) (string, error) {
+	if target.SiteID == "" {
+		return "", fmt.Errorf("context %q is not linked to a site: pass --source or run sitectl link SITE_ID", target.Name)
+	}
+	candidates := []string{}
+	for _, ctx := range contexts {
+		if ctx.Name != target.Name && ctx.SiteID == target.SiteID && ctx.IsProduction() {
+			candidates = append(candidates, ctx.Name)
+		}
+	}
+	sort.Strings(candidates)
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("site %s has no production context besides %q: pass --source", target.SiteID, target.Name)
+	case 1:
+		return candidates[0], nil
+	default:
+		return "", fmt.Errorf("site %s has several production contexts (%s): pass --source", target.SiteID, strings.Join(candidates, ", "))
+	}
+}
+
+func init() {
+	linkCmd.Flags().Bool("unlink", false, "Remove the site link from the context")
+	linkCmd.GroupID = "setup"
+	RootCmd.AddCommand(linkCmd)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/libops/sitectl/pkg/config"
+)
+
+func TestLinkedProductionContext(t *testing.T) {
+	t.Parallel()
+
+	contexts := []config.Context{
+		{Name: "museum-local", SiteID: "museum", Environment: "local"},
+		{Name: "museum-stage", SiteID: "museum", Environment: "stage"},
+		{Name: "museum-prod", SiteID: "museum", Environment: "prod"},
+		{Name: "library-prod", SiteID: "library", Environment: "prod"},
+		{Name: "library-live", SiteID: "library", Environment: "live"},
+	}
+	if got, err := linkedProductionContext(contexts, contexts[0]); err != nil || got != "museum-prod" {
+		t.Fatalf("linkedProductionContext(museum-local) = %q, %v", got, err)
+	}
+	for _, target := range []config.Context{
+		{Name: "unlinked"},
+		{Name: "archive-local", SiteID: "archive"},
+		{Name: "library-local", SiteID: "library"},
+		contexts[2],
+	} {
+		if got, err := linkedProductionContext(contexts, target); err == nil {
+			t.Errorf("linkedProductionContext(%s) = %q, expected error", target.Name, got)
+		}
+	}
+}
+
+func TestSetContextSiteIDShowsInContextTable(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	for _, ctx := range []config.Context{
+		{Name: "museum-prod", Site: "museum", Environment: "prod", DockerHostType: config.ContextLocal, ProjectDir: tempHome},
+		{Name: "museum-local", Site: "museum", Environment: "local", DockerHostType: config.ContextLocal, ProjectDir: tempHome},
+	} {
+		if err := config.SaveContext(&ctx, false); err != nil {
+			t.Fatalf("SaveContext() error = %v", err)
+		}
+	}
+	if err := setContextSiteID("museum-prod", "site-42"); err != nil {
+		t.Fatal(err)
+	}
+	if err := setContextSiteID("missing", "site-42"); err == nil {
+		t.Fatal("setContextSiteID(missing) expected error")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	writeContextTable(&out, cfg)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if !strings.Contains(lines[0], "LINKED") {
+		t.Fatalf("header = %q", lines[0])
+	}
+	for _, line := range lines[1:] {
+		fields := strings.Fields(strings.TrimPrefix(line, "*"))
+		want := "-"
+		if fields[0] == "museum-prod" {
+			want = "site-42"
+		}
+		if fields[2] != want {
+			t.Errorf("row %q: linked = %q, want %q", line, fields[2], want)
+		}
+	}
+
+	for _, bad := range []string{"", "-x", "has space", "a/b"} {
+		if validateSiteID(bad) == nil {
+			t.Errorf("validateSiteID(%q) expected error", bad)
+		}
+	}
+}
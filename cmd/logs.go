@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/libops/sitectl/pkg/docker"
+	"github.com/libops/sitectl/pkg/logfile"
+	"github.com/libops/sitectl/pkg/logfilter"
+	"github.com/spf13/cobra"
+)
+
+// logPrefixColors cycles through the ANSI colors docker compose uses for
+// service prefixes.
+var logPrefixColors = []string{"36", "33", "32", "35", "34", "96", "93", "92", "95", "94"}
+
+var logsCmd = &cobra.Command{
+	Use:   "logs [SERVICE...]",
+	Short: "Stream the logs of the context's compose services",
+	Long: `Stream the logs of the context's compose services, or only the named services,
+reading every container concurrently from the Docker API. Local and remote contexts
+behave the same; nothing is run through docker compose on the host.
+
+Each line is prefixed with its container (drupal-1 | ...), colored per container when
+writing to a terminal. --since, --until and --tail are applied by Docker, --grep filters
+and highlights messages, and --json writes one
+{service, container, timestamp, stream, message} object per line instead.
+
+Examples:
+  sitectl logs
+  sitectl logs drupal traefik -f --since 10m
+  sitectl logs --grep 'error|warn' --tail all
+  sitectl logs --context prod --json --since 1h > prod.jsonl`,
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runLogs(cmd, args)
+	},
+}
+
+var (
+	logsFile   *logfile.Flags
+	logsFilter *logfilter.Flags
+)
+
+func runLogs(cmd *cobra.Command, services []string) error {
+	follow, err := cmd.Flags().GetBool("follow")
+	if err != nil {
+		return err
+	}
+	jsonOutput, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return err
+	}
+	noColor, err := cmd.Flags().GetBool("no-color")
+	if err != nil {
+		return err
+	}
+	fileOpts, err := logsFile.Options()
+	if err != nil {
+		return err
+	}
+	filterOpts, err := logsFilter.Options()
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		filterOpts.Output = logfilter.OutputJSON
+	}
+
+	ctx, err := resolveCurrentContext(cmd)
+	if err != nil {
+		return err
+	}
+	cli, err := docker.GetDockerCli(ctx)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+	api, err := cli.Logs()
+	if err != nil {
+		return err
+	}
+
+	out, closeLog, err := logfile.Tee(cmd.OutOrStdout(), fileOpts)
+	if err != nil {
+		return err
+	}
+	project := ctx.EffectiveComposeProjectName()
+	if filterOpts.Output == logfilter.OutputJSON {
+		err = logfilter.WriteJSON(cmd.Context(), api, project, services, follow, filterOpts, out)
+	} else {
+		// keep escape codes out of the log file
+		color := !noColor && !fileOpts.Enabled() && logfilter.Highlight(cmd.OutOrStdout())
+		printer := newLogPrinter(out, project, filterOpts, color)
+		err = docker.StreamComposeLogs(cmd.Context(), api, project, docker.LogsOptions{
+			Services: services,
+			Since:    filterOpts.Since,
+			Until:    filterOpts.Until,
+			Tail:     filterOpts.Tail,
+			Follow:   follow,
+		}, printer.print)
+	}
+	if closeErr := closeLog(); err == nil {
+		err = closeErr
+	}
+	if cmd.Context().Err() != nil {
+		// interrupted while following
+		return nil
+	}
+	return err
+}
+
+// logPrinter writes log entries as "prefix | message" lines, like docker
+// compose logs. Containers get a color in the order they are first seen, and
+// prefixes are padded to the widest seen so far.
+type logPrinter struct {
+	out     io.Writer
+	project string
+	filter  logfilter.Options
+	color   bool
+
+	colors map[string]string
+	width  int
+}
+
+func newLogPrinter(out io.Writer, project string, filter logfilter.Options, color bool) *logPrinter {
+	return &logPrinter{out: out, project: project, filter: filter, color: color, colors: map[string]string{}}
+}
+
+// print writes entry; docker.StreamComposeLogs never calls it concurrently.
+func (p *logPrinter) print(entry docker.LogEntry) error {
+	if !p.filter.Matches(entry.Message) {
+		return nil
+	}
+	prefix := logPrefix(p.project, entry)
+	p.width = max(p.width, len(prefix))
+	padded := prefix + strings.Repeat(" ", p.width-len(prefix)) + "  |"
+	message := entry.Message
+	if p.color {
+		code, ok := p.colors[prefix]
+		if !ok {
+			code = logPrefixColors[len(p.colors)%len(logPrefixColors)]
+			p.colors[prefix] = code
+		}
+		padded = "\x1b[" + code + "m" + padded + "\x1b[0m"
+		message = p.filter.HighlightMatches(message)
+	}
+	if p.filter.Timestamps && !entry.Timestamp.IsZero() {
+		message = entry.Timestamp.Format(time.RFC3339Nano) + " " + message
+	}
+	_, err := fmt.Fprintf(p.out, "%s %s\n", padded, message)
+	return err
+}
+
+// logPrefix names the container the way docker compose does, service-N,
+// falling back to the container name for custom container_name values.
+func logPrefix(project string, entry docker.LogEntry) string {
+	if name, ok := strings.CutPrefix(entry.Container, project+"-"); ok && strings.HasPrefix(name, entry.Service+"-") {
+		return name
+	}
+	return entry.Container
+}
+
+func init() {
+	logsFile = logfile.AddFlags(logsCmd.Flags())
+	logsFilter = logfilter.AddFlags(logsCmd.Flags(), "100")
+	logsCmd.Flags().BoolP("follow", "f", false, "Keep streaming new log lines until interrupted")
+	logsCmd.Flags().Bool("json", false, "Shorthand for --output json")
+	logsCmd.Flags().Bool("no-color", false, "Do not color container prefixes and --grep matches")
+	logsCmd.GroupID = "ops"
+	RootCmd.AddCommand(logsCmd)
+}
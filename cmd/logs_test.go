@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/libops/sitectl/pkg/docker"
+	"github.com/libops/sitectl/pkg/logfilter"
+)
+
+func TestLogPrinter(t *testing.T) {
+	var out bytes.Buffer
+	printer := newLogPrinter(&out, "site", logfilter.Options{Grep: regexp.MustCompile("GET|Warning")}, false)
+	entries := []docker.LogEntry{
+		{Service: "db", Container: "site-db-1", Message: "GET /health"},
+		{Service: "drupal", Container: "site-drupal-1", Message: "PHP Warning: x"},
+		{Service: "drupal", Container: "site-drupal-1", Message: "ignored"},
+		{Service: "solr", Container: "custom-solr", Message: "GET /solr"},
+	}
+	for _, entry := range entries {
+		if err := printer.print(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "db-1  | GET /health\n" +
+		"drupal-1  | PHP Warning: x\n" +
+		"custom-solr  | GET /solr\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestLogPrinterColorsAndTimestamps(t *testing.T) {
+	var out bytes.Buffer
+	printer := newLogPrinter(&out, "site", logfilter.Options{Timestamps: true}, true)
+	at := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	for _, entry := range []docker.LogEntry{
+		{Service: "drupal", Container: "site-drupal-1", Timestamp: at, Message: "one"},
+		{Service: "db", Container: "site-db-1", Timestamp: at, Message: "two"},
+		{Service: "drupal", Container: "site-drupal-1", Timestamp: at, Message: "three"},
+	} {
+		if err := printer.print(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "\x1b[36mdrupal-1  |\x1b[0m 2026-10-16T09:00:00Z one\n" +
+		"\x1b[33mdb-1      |\x1b[0m 2026-10-16T09:00:00Z two\n" +
+		"\x1b[36mdrupal-1  |\x1b[0m 2026-10-16T09:00:00Z three\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
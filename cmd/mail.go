@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/docker"
+	"github.com/libops/sitectl/pkg/helpers"
+	"github.com/spf13/cobra"
+)
+
+// mailUIPort is the web UI port used by both Mailpit and MailHog.
+const mailUIPort = 8025
+
+var mailCmd = &cobra.Command{
+	Use:   "mail",
+	Short: "Open the Mailpit or MailHog web UI for a context",
+	Long: `Open the web UI of the mail catcher (Mailpit or MailHog) running in the active context.
+
+sitectl looks for a running mailpit or mailhog service (by service name or image) in the
+context's Compose project. When a local context publishes the UI port, that URL is opened
+directly. Otherwise sitectl forwards a local port to the service, over SSH for remote
+contexts, and keeps the tunnel open until you press Ctrl+C.
+
+Examples:
+  sitectl mail                         # Open test emails for the current context
+  sitectl mail --context stage         # Tunnel to the stage mail catcher
+  sitectl mail --local-port 18025      # Use a different local port for the tunnel
+  sitectl mail --no-open               # Print the URL instead of opening a browser`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+		localPort, err := cmd.Flags().GetInt("local-port")
+		if err != nil {
+			return err
+		}
+		noOpen, err := cmd.Flags().GetBool("no-open")
+		if err != nil {
+			return err
+		}
+
+		cli, err := docker.GetDockerCli(ctx)
+		if err != nil {
+			return err
+		}
+		service, err := detectComposeService(cmd.Context(), cli.CLI, ctx, "mailpit", "mailhog")
+		_ = cli.Close()
+		if err != nil {
+			return err
+		}
+
+		return openServiceUI(cmd, ctx, service, mailUIPort, localPort, "/", noOpen)
+	},
+}
+
+// openServiceUI opens a service's web UI. Local contexts with a published port
+// are opened directly; everything else goes through a port forward that stays
+// up until the command is interrupted.
+func openServiceUI(cmd *cobra.Command, ctx *config.Context, service detectedService, uiPort, localPort int, path string, noOpen bool) error {
+	open := func(url string) error {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s UI: %s\n", service.Service, url)
+		if noOpen {
+			return nil
+		}
+		if err := helpers.OpenURL(url); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "unable to open a browser: %v\n", err)
+		}
+		return nil
+	}
+
+	if ctx.DockerHostType == config.ContextLocal {
+		if hostPort, ok := service.publishedHostPort(uiPort); ok {
+			return open(fmt.Sprintf("http://127.0.0.1:%d%s", hostPort, path))
+		}
+	}
+	if localPort == 0 {
+		localPort = uiPort
+	}
+	spec := portForwardSpec{localPort: localPort, service: service.Service, remotePort: uiPort}
+	return runPortForwards(cmd, ctx, []portForwardSpec{spec}, func() error {
+		if err := open(fmt.Sprintf("http://127.0.0.1:%d%s", localPort, path)); err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "Press Ctrl+C to close the tunnel.")
+		return nil
+	})
+}
+
+func init() {
+	mailCmd.GroupID = "workflow"
+	mailCmd.Flags().Int("local-port", mailUIPort, "Local port for the tunnel when the UI is not published on this machine")
+	mailCmd.Flags().Bool("no-open", false, "Print the UI URL without opening a browser")
+	RootCmd.AddCommand(mailCmd)
+}
@@ -13,7 +13,9 @@ import (
 
 	"github.com/libops/sitectl/pkg/config"
 	"github.com/libops/sitectl/pkg/docker"
+	"github.com/libops/sitectl/pkg/i18n"
 	corejob "github.com/libops/sitectl/pkg/job"
+	"github.com/libops/sitectl/pkg/notify"
 	"github.com/spf13/cobra"
 )
 
@@ -260,8 +262,9 @@ func mariaDBSyncCommand() *cobra.Command {
 		backupDir: "/tmp/sitectl-mariadb-jobs/db-backup",
 	}
 	cmd := &cobra.Command{
-		Use:   "sync",
-		Short: "Sync a MariaDB database artifact between contexts",
+		Use:         "sync",
+		Short:       "Sync a MariaDB database artifact between contexts",
+		Annotations: map[string]string{notify.Annotation: ""},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runMariaDBSync(cmd, opts)
 		},
@@ -316,6 +319,9 @@ func runMariaDBImport(cmd *cobra.Command, ctx *config.Context, opts mariaDBImpor
 	if err := validateMariaDBDatabaseName(database); err != nil {
 		return err
 	}
+	if err := ctx.RequireUnprotected("replace the database"); err != nil {
+		return err
+	}
 	databaseLabel := "MariaDB"
 	if database != "" {
 		databaseLabel = "MariaDB " + database
@@ -403,6 +409,9 @@ func runMariaDBSync(cmd *cobra.Command, opts mariaDBSyncOptions) error {
 	if err != nil {
 		return err
 	}
+	if err := targetCtx.RequireUnprotected("replace the database"); err != nil {
+		return err
+	}
 	workDir, cleanupWorkDir, err := corejob.MakeTempWorkDir("sitectl-mariadb-sync-*")
 	if err != nil {
 		return fmt.Errorf("create temp dir: %w", err)
@@ -444,7 +453,7 @@ func runMariaDBSync(cmd *cobra.Command, opts mariaDBSyncOptions) error {
 	}); err != nil {
 		return fmt.Errorf("import database into %q: %w", targetCtx.Name, err)
 	}
-	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "MariaDB database synced from %s to %s\n", sourceCtx.Name, targetCtx.Name)
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), i18n.T(i18n.DatabaseSynced, sourceCtx.Name, targetCtx.Name))
 	return nil
 }
 
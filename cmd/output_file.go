@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+const outputFileFlag = "output-file"
+
+// enableOutputFile adds --output-file to commands that print a listing or
+// report. When the flag is set, everything the command writes to
+// cmd.OutOrStdout() is buffered and written to the file atomically after the
+// command succeeds; a failed command leaves any existing file untouched.
+func enableOutputFile(cmds ...*cobra.Command) {
+	for _, c := range cmds {
+		c.Flags().String(outputFileFlag, "", "Write the output to this file instead of stdout (written atomically)")
+		runE := c.RunE
+		c.RunE = func(cmd *cobra.Command, args []string) error {
+			path, err := cmd.Flags().GetString(outputFileFlag)
+			if err != nil {
+				return err
+			}
+			if strings.TrimSpace(path) == "" {
+				return runE(cmd, args)
+			}
+			stdout := cmd.OutOrStdout()
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			defer cmd.SetOut(stdout)
+			if err := runE(cmd, args); err != nil {
+				return err
+			}
+			size := buf.Len()
+			if err := config.WriteFileAtomic(path, &buf); err != nil {
+				return fmt.Errorf("write %s: %w", path, err)
+			}
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Wrote %d bytes to %s\n", size, path)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestEnableOutputFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		runErr   error
+		existing string
+		want     string
+	}{
+		{name: "writes output", want: "NAME\nlocal\n"},
+		{name: "replaces existing file", existing: "stale", want: "NAME\nlocal\n"},
+		{name: "failure keeps existing file", runErr: errors.New("boom"), existing: "previous", want: "previous"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "report.txt")
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cmd := &cobra.Command{
+				Use:           "list",
+				SilenceUsage:  true,
+				SilenceErrors: true,
+				RunE: func(cmd *cobra.Command, args []string) error {
+					fmt.Fprint(cmd.OutOrStdout(), "NAME\nlocal\n")
+					return tt.runErr
+				},
+			}
+			enableOutputFile(cmd)
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs([]string{"--output-file", path})
+			err := cmd.Execute()
+			if !errors.Is(err, tt.runErr) {
+				t.Fatalf("Execute() error = %v, want %v", err, tt.runErr)
+			}
+			if stdout.Len() != 0 {
+				t.Fatalf("stdout = %q, want nothing", stdout.String())
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Fatalf("file = %q, want %q", data, tt.want)
+			}
+		})
+	}
+}
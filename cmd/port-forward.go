@@ -21,7 +21,7 @@ import (
 
 var portForwardCmd = &cobra.Command{
 	Use:   "port-forward [LOCAL-PORT:SERVICE:REMOTE-PORT...]",
-	Args:  cobra.MinimumNArgs(1),
+	Args:  cobra.ArbitraryArgs,
 	Short: "Forward one or more local ports to a service",
 	Long: `
 Access a Docker Compose service without publishing its port on the host.
@@ -47,114 +47,195 @@ http://localhost:8080/dashboard to see the traefik dashboard (assuming it's enab
 http://localhost:8161/admin/queues.jsp to see ActiveMQ queues
 
 Be sure to run Ctrl+c in your terminal when you are done to close the connection.
+
+Specs used together can be saved as a named preset in the context's port-forwards
+map and started with --preset, alongside any specs given as arguments:
+
+  port-forwards:
+    dev: [8983:solr:8983, 8025:mailpit:8025]
+
+sitectl port-forward --preset dev --context stage
+
+sitectl port-forward list shows the tunnels every running sitectl process has open.
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		c, err := resolveCurrentContext(cmd)
 		if err != nil {
 			return err
 		}
-		cli, err := docker.GetDockerCli(c)
+		presets, err := cmd.Flags().GetStringArray("preset")
 		if err != nil {
 			return err
 		}
-		defer cli.Close()
-
-		listeners := make([]net.Listener, 0, len(args))
-		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGHUP, syscall.SIGTERM)
-		var wg sync.WaitGroup
-		defer func() {
-			stop()
-			for _, listener := range listeners {
-				_ = listener.Close()
-			}
-			_ = cli.Close()
-			wg.Wait()
-		}()
+		specs, err := portForwardSpecs(c, presets, args)
+		if err != nil {
+			return err
+		}
+		return runPortForwards(cmd, c, specs, nil)
+	},
+}
 
-		for _, arg := range args {
-			spec, err := parsePortForwardSpec(arg)
-			if err != nil {
-				return err
-			}
+// portForwardSpecs parses the specs of the named presets followed by args.
+func portForwardSpecs(c *config.Context, presets, args []string) ([]portForwardSpec, error) {
+	values := []string{}
+	for _, name := range presets {
+		preset, ok := c.PortForwards[name]
+		if !ok {
+			return nil, fmt.Errorf("context %q has no port-forward preset %q", c.Name, name)
+		}
+		values = append(values, preset...)
+	}
+	values = append(values, args...)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no ports to forward: pass LOCAL-PORT:SERVICE:REMOTE-PORT specs or --preset")
+	}
+	specs := make([]portForwardSpec, 0, len(values))
+	localPorts := map[int]bool{}
+	for _, value := range values {
+		spec, err := parsePortForwardSpec(value)
+		if err != nil {
+			return nil, err
+		}
+		if localPorts[spec.localPort] {
+			return nil, fmt.Errorf("local port %d is forwarded more than once", spec.localPort)
+		}
+		localPorts[spec.localPort] = true
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
 
-			addr := portForwardListenAddress(spec.localPort)
-			listener, err := net.Listen("tcp", addr)
-			if err != nil {
-				return fmt.Errorf("local port %d appears to be in use: %v", spec.localPort, err)
-			}
-			listeners = append(listeners, listener)
+// runPortForwards listens on 127.0.0.1 for every spec and forwards accepted
+// connections until the command is interrupted. ready, when set, runs once
+// every listener is accepting connections.
+func runPortForwards(cmd *cobra.Command, c *config.Context, specs []portForwardSpec, ready func() error) error {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGHUP, syscall.SIGTERM)
+	defer stop()
+	return servePortForwards(ctx, cmd.OutOrStdout(), cmd.ErrOrStderr(), c, specs, ready)
+}
+
+// servePortForwards is runPortForwards until ctx is done, writing progress to
+// out and connection errors to errw.
+func servePortForwards(parent context.Context, out, errw io.Writer, c *config.Context, specs []portForwardSpec, ready func() error) error {
+	cli, err := docker.GetDockerCli(c)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	listeners := make([]net.Listener, 0, len(specs))
+	active := make([]activePortForward, 0, len(specs))
+	ctx, stop := context.WithCancel(parent)
+	var wg sync.WaitGroup
+	defer func() {
+		stop()
+		for _, listener := range listeners {
+			_ = listener.Close()
+		}
+		_ = cli.Close()
+		wg.Wait()
+	}()
+
+	for _, spec := range specs {
+		addr := portForwardListenAddress(spec.localPort)
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("local port %d appears to be in use: %v", spec.localPort, err)
+		}
+		listeners = append(listeners, listener)
 
-			containerName, err := cli.GetContainerNameContext(ctx, c, spec.service)
+		containerName, err := cli.GetContainerNameContext(ctx, c, spec.service)
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(containerName) == "" {
+			return fmt.Errorf("service %q does not have a running container", spec.service)
+		}
+
+		var target string
+		var transport string
+		var forwardConnection func(net.Conn)
+		if useContainerExecPortForward(runtime.GOOS, c) {
+			target = fmt.Sprintf("%s:%d", spec.service, spec.remotePort)
+			transport = "Docker exec"
+			forwardConnection = func(localConn net.Conn) {
+				forwardContainerExec(ctx, cli, localConn, containerName, spec.remotePort, errw)
+			}
+		} else {
+			serviceIP, err := cli.GetServiceIp(ctx, c, containerName)
 			if err != nil {
 				return err
 			}
-			if strings.TrimSpace(containerName) == "" {
-				return fmt.Errorf("service %q does not have a running container", spec.service)
+			if strings.TrimSpace(serviceIP) == "" {
+				return fmt.Errorf("service %q does not have an address on the Compose network", spec.service)
 			}
-
-			var target string
-			var transport string
-			var forwardConnection func(net.Conn)
-			if useContainerExecPortForward(runtime.GOOS, c) {
-				target = fmt.Sprintf("%s:%d", spec.service, spec.remotePort)
-				transport = "Docker exec"
-				forwardConnection = func(localConn net.Conn) {
-					forwardContainerExec(ctx, cli, localConn, containerName, spec.remotePort, cmd.ErrOrStderr())
-				}
-			} else {
-				serviceIP, err := cli.GetServiceIp(ctx, c, containerName)
-				if err != nil {
-					return err
-				}
-				if strings.TrimSpace(serviceIP) == "" {
-					return fmt.Errorf("service %q does not have an address on the Compose network", spec.service)
-				}
-				target = net.JoinHostPort(serviceIP, strconv.Itoa(spec.remotePort))
-				transport = "the local Docker network"
-				if cli.SshCli != nil {
-					transport = "SSH"
-				}
-				forwardConnection = func(localConn net.Conn) {
-					forward(ctx, cli.SshCli, localConn, target, cmd.ErrOrStderr())
-				}
+			target = net.JoinHostPort(serviceIP, strconv.Itoa(spec.remotePort))
+			transport = "the local Docker network"
+			if cli.SshCli != nil {
+				transport = "SSH"
 			}
+			forwardConnection = func(localConn net.Conn) {
+				forward(ctx, cli.SshCli, localConn, target, errw)
+			}
+		}
 
-			wg.Add(1)
-			go func(listener net.Listener, localPort int, remoteTarget, via string, forwardConn func(net.Conn)) {
-				defer wg.Done()
-				fmt.Fprintf(cmd.OutOrStdout(), "Forwarding 127.0.0.1:%d -> %s via %s\n", localPort, remoteTarget, via)
-				for {
-					localConn, err := listener.Accept()
-					if err != nil {
-						if ctx.Err() != nil || isClosedNetworkError(err) {
-							return
-						}
-						fmt.Fprintf(cmd.ErrOrStderr(), "error accepting connection on port %d: %v\n", localPort, err)
-						stop()
+		active = append(active, activePortForward{
+			Context:    c.Name,
+			LocalPort:  spec.localPort,
+			Service:    spec.service,
+			RemotePort: spec.remotePort,
+			Target:     target,
+			Via:        transport,
+		})
+
+		wg.Add(1)
+		go func(listener net.Listener, localPort int, remoteTarget, via string, forwardConn func(net.Conn)) {
+			defer wg.Done()
+			fmt.Fprintf(out, "Forwarding 127.0.0.1:%d -> %s via %s\n", localPort, remoteTarget, via)
+			for {
+				localConn, err := listener.Accept()
+				if err != nil {
+					if ctx.Err() != nil || isClosedNetworkError(err) {
 						return
 					}
-					wg.Add(1)
-					go func() {
-						defer wg.Done()
-						forwardConn(localConn)
-					}()
+					fmt.Fprintf(errw, "error accepting connection on port %d: %v\n", localPort, err)
+					stop()
+					return
 				}
-			}(listener, spec.localPort, target, transport, forwardConnection)
-		}
-
-		<-ctx.Done()
-		fmt.Fprintln(cmd.OutOrStdout(), "Shutting down port forwards...")
-		for _, listener := range listeners {
-			if err := listener.Close(); err != nil {
-				fmt.Fprintf(cmd.ErrOrStderr(), "error closing listener: %v\n", err)
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					forwardConn(localConn)
+				}()
 			}
+		}(listener, spec.localPort, target, transport, forwardConnection)
+	}
+
+	unregister, err := registerPortForwards(active)
+	if err != nil {
+		fmt.Fprintf(errw, "sitectl: port-forward list will not show these tunnels: %v\n", err)
+	} else {
+		defer unregister()
+	}
+
+	if ready != nil {
+		if err := ready(); err != nil {
+			return err
 		}
-		if err := cli.Close(); err != nil {
-			fmt.Fprintf(cmd.ErrOrStderr(), "error closing docker connection: %v\n", err)
+	}
+
+	<-ctx.Done()
+	fmt.Fprintln(out, "Shutting down port forwards...")
+	for _, listener := range listeners {
+		if err := listener.Close(); err != nil {
+			fmt.Fprintf(errw, "error closing listener: %v\n", err)
 		}
-		wg.Wait()
-		return nil
-	},
+	}
+	if err := cli.Close(); err != nil {
+		fmt.Fprintf(errw, "error closing docker connection: %v\n", err)
+	}
+	wg.Wait()
+	return nil
 }
 
 type portForwardSpec struct {
@@ -163,6 +244,11 @@ type portForwardSpec struct {
 	remotePort int
 }
 
+// String formats the spec as LOCAL-PORT:SERVICE:REMOTE-PORT.
+func (s portForwardSpec) String() string {
+	return fmt.Sprintf("%d:%s:%d", s.localPort, s.service, s.remotePort)
+}
+
 func parsePortForwardSpec(value string) (portForwardSpec, error) {
 	parts := strings.Split(value, ":")
 	if len(parts) != 3 {
@@ -287,6 +373,7 @@ func isClosedNetworkError(err error) bool {
 }
 
 func init() {
+	portForwardCmd.Flags().StringArray("preset", nil, "Forward the specs of a preset from the context's port-forwards (repeatable)")
 	portForwardCmd.GroupID = "troubleshoot"
 	RootCmd.AddCommand(portForwardCmd)
 }
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"io"
 	"net"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -176,3 +178,73 @@ func TestForwardContainerExecReportsNonzeroExit(t *testing.T) {
 		t.Fatalf("forward error = %q, want nonzero status and BusyBox nc requirement", got)
 	}
 }
+
+func TestPortForwardSpecsCombinesPresetsAndArgs(t *testing.T) {
+	t.Parallel()
+	ctx := &config.Context{Name: "stage", PortForwards: map[string][]string{
+		"dev": {"8983:solr:8983", "8025:mailpit:8025"},
+	}}
+	specs, err := portForwardSpecs(ctx, []string{"dev"}, []string{"8080:traefik:8080"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []portForwardSpec{
+		{localPort: 8983, service: "solr", remotePort: 8983},
+		{localPort: 8025, service: "mailpit", remotePort: 8025},
+		{localPort: 8080, service: "traefik", remotePort: 8080},
+	}
+	if !reflect.DeepEqual(specs, want) {
+		t.Fatalf("portForwardSpecs() = %#v, want %#v", specs, want)
+	}
+
+	for _, test := range []struct {
+		presets, args []string
+		wantErr       string
+	}{
+		{presets: []string{"prod"}, wantErr: `no port-forward preset "prod"`},
+		{args: []string{"8983:solr:8080"}, presets: []string{"dev"}, wantErr: "local port 8983 is forwarded more than once"},
+		{wantErr: "no ports to forward"},
+	} {
+		if _, err := portForwardSpecs(ctx, test.presets, test.args); err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("portForwardSpecs(%v, %v) error = %v, want %q", test.presets, test.args, err, test.wantErr)
+		}
+	}
+}
+
+func TestListActivePortForwardsDropsExitedProcesses(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	originalAlive := processAlive
+	t.Cleanup(func() { processAlive = originalAlive })
+	processAlive = func(pid int) bool { return pid != 999999 }
+
+	unregister, err := registerPortForwards([]activePortForward{
+		{Context: "stage", LocalPort: 8983, Service: "solr", RemotePort: 8983, Target: "172.18.0.4:8983", Via: "SSH"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := portForwardStateDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := filepath.Join(dir, "999999-1.json")
+	if err := os.WriteFile(stale, []byte(`[{"pid":999999,"local_port":8025}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	forwards, err := listActivePortForwards()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(forwards) != 1 || forwards[0].LocalPort != 8983 || forwards[0].PID != os.Getpid() || forwards[0].Context != "stage" {
+		t.Fatalf("listActivePortForwards() = %+v", forwards)
+	}
+	if _, err := os.Stat(stale); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("state of exited process was kept: %v", err)
+	}
+
+	unregister()
+	if forwards, err := listActivePortForwards(); err != nil || len(forwards) != 0 {
+		t.Fatalf("after unregister: %+v, %v", forwards, err)
+	}
+}
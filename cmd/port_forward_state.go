@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// activePortForward is one tunnel held open by a running sitectl process.
+type activePortForward struct {
+	PID        int       `json:"pid"`
+	Context    string    `json:"context"`
+	LocalPort  int       `json:"local_port"`
+	Service    string    `json:"service"`
+	RemotePort int       `json:"remote_port"`
+	Target     string    `json:"target"`
+	Via        string    `json:"via"`
+	StartedAt  time.Time `json:"started_at"`
+}
+
+// processAlive reports whether a process with pid is running; tests replace it.
+var processAlive = isProcessAlive
+
+// portForwardStateSeq numbers the state files of one process, since the
+// daemon serves several groups of forwards.
+var portForwardStateSeq atomic.Int64
+
+var portForwardListCmd = &cobra.Command{
+	Use:   "list",
+	Args:  cobra.NoArgs,
+	Short: "List the port forwards of running sitectl processes",
+	Long: `List the tunnels held open by every running sitectl port-forward (and sitectl mail),
+across all contexts. Each process records its tunnels in a state file under ~/.sitectl
+while it runs; files left behind by processes that have exited are cleaned up.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		if format != "table" && format != "json" {
+			return fmt.Errorf("unsupported format %q: use table or json", format)
+		}
+		forwards, err := listActivePortForwards()
+		if err != nil {
+			return err
+		}
+		if format == "json" {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(forwards)
+		}
+		if len(forwards) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No active port forwards")
+			return nil
+		}
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "LOCAL\tCONTEXT\tSERVICE\tTARGET\tVIA\tPID\tSTARTED")
+		for _, forward := range forwards {
+			fmt.Fprintf(w, "127.0.0.1:%d\t%s\t%s:%d\t%s\t%s\t%d\t%s\n",
+				forward.LocalPort,
+				forward.Context,
+				forward.Service,
+				forward.RemotePort,
+				forward.Target,
+				forward.Via,
+				forward.PID,
+				forward.StartedAt.Local().Format(time.DateTime),
+			)
+		}
+		return w.Flush()
+	},
+}
+
+// registerPortForwards records this process's tunnels so port-forward list
+// can show them. The returned function removes the record.
+func registerPortForwards(forwards []activePortForward) (func(), error) {
+	dir, err := portForwardStateDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	pid := os.Getpid()
+	now := time.Now().UTC()
+	records := make([]activePortForward, 0, len(forwards))
+	for _, forward := range forwards {
+		forward.PID = pid
+		forward.StartedAt = now
+		records = append(records, forward)
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%d-%d.json", pid, portForwardStateSeq.Add(1)))
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return nil, err
+	}
+	return func() { _ = os.Remove(path) }, nil
+}
+
+// listActivePortForwards reads the tunnels of all running processes, ordered
+// by local port, and removes the state of processes that have exited.
+func listActivePortForwards() ([]activePortForward, error) {
+	dir, err := portForwardStateDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []activePortForward{}, nil
+		}
+		return nil, err
+	}
+	forwards := []activePortForward{}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		name, _, _ = strings.Cut(name, "-")
+		pid, err := strconv.Atoi(name)
+		if !ok || err != nil || entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if !processAlive(pid) {
+			_ = os.Remove(path)
+			continue
+		}
+		data, err := os.ReadFile(path) // #nosec G304 -- state path is generated under sitectl config state.
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		var records []activePortForward
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		forwards = append(forwards, records...)
+	}
+	sort.Slice(forwards, func(i, j int) bool {
+		return forwards[i].LocalPort < forwards[j].LocalPort
+	})
+	return forwards, nil
+}
+
+func portForwardStateDir() (string, error) {
+	configPath, err := config.ConfigFilePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "port-forwards"), nil
+}
+
+func init() {
+	portForwardListCmd.Flags().String("format", "table", "Output format: table or json")
+	declareFormats(portForwardListCmd, "table", "json")
+	portForwardCmd.AddCommand(portForwardListCmd)
+}
@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+	corecomponent "github.com/libops/sitectl/pkg/component"
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/docker"
+	"github.com/libops/sitectl/pkg/healthcheck"
+	"github.com/libops/sitectl/pkg/helpers"
+	sitevalidate "github.com/libops/sitectl/pkg/validate"
+	"github.com/spf13/cobra"
+)
+
+const preflightDialTimeout = 5 * time.Second
+
+// preflightCommitRef matches abbreviated and full commit IDs, which git
+// ls-remote cannot look up.
+var preflightCommitRef = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+
+type preflightOptions struct {
+	format string
+	branch string
+	ref    string
+}
+
+var (
+	preflightComposeConfig = runPreflightComposeConfig
+	preflightGitStatus     = runPreflightGitStatus
+	preflightDial          = func(address string) error {
+		conn, err := net.DialTimeout("tcp", address, preflightDialTimeout)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+)
+
+var preflightCmd = &cobra.Command{
+	Use:   "preflight",
+	Short: "Run pre-rollout checks for the active context and report pass or fail",
+	Long: `Run the checks a rollout depends on before deploying, and report them together.
+
+Checks:
+  - context configuration and Docker access (as in sitectl validate)
+  - docker compose config renders the project without errors
+  - every image that is not built locally is present on the Docker host or resolvable
+    in its registry
+  - every compose secret file exists on the context host
+  - the checkout has no local changes and the ref to deploy exists on its git remote
+    (--ref or --branch, otherwise the current branch's upstream)
+  - for remote contexts, the public site address accepts connections, so health
+    probes are not blocked by a firewall
+
+Exits non-zero when any check fails, so it can gate a CI rollout job.
+
+Examples:
+  sitectl preflight
+  sitectl preflight --context prod --branch main
+  sitectl preflight --context prod --ref refs/pull/123/head --format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := preflightOptions{}
+		var err error
+		if opts.format, err = cmd.Flags().GetString("format"); err != nil {
+			return err
+		}
+		if opts.branch, err = cmd.Flags().GetString("branch"); err != nil {
+			return err
+		}
+		if opts.ref, err = cmd.Flags().GetString("ref"); err != nil {
+			return err
+		}
+		ctx, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+
+		results, err := sitevalidate.Run(ctx, sitevalidate.CoreValidators(cfg)...)
+		if err != nil {
+			return err
+		}
+		results = append(results, runPreflightChecks(cmd.Context(), ctx, opts)...)
+
+		sitevalidate.SortResults(results)
+		report := sitevalidate.NewReport(ctx, results)
+		if err := sitevalidate.WriteReports(cmd.OutOrStdout(), []sitevalidate.Report{report}, opts.format); err != nil {
+			return err
+		}
+		if !report.Valid {
+			return fmt.Errorf("preflight failed")
+		}
+		return nil
+	},
+}
+
+// runPreflightChecks runs the rollout checks that go beyond sitectl validate.
+func runPreflightChecks(runCtx context.Context, ctx *config.Context, opts preflightOptions) []sitevalidate.Result {
+	results := []sitevalidate.Result{}
+	doc, err := preflightComposeConfig(runCtx, ctx)
+	if err != nil {
+		results = append(results, sitevalidate.Result{
+			Name:    "compose config",
+			Status:  sitevalidate.StatusFailed,
+			Detail:  err.Error(),
+			FixHint: "run sitectl compose config to see the full error",
+		})
+	} else {
+		results = append(results, sitevalidate.Result{Name: "compose config", Status: sitevalidate.StatusOK, Detail: fmt.Sprintf("%d service(s)", len(doc.Services))})
+		results = append(results, preflightImageResults(runCtx, ctx, doc)...)
+		results = append(results, preflightSecretResults(ctx, doc)...)
+	}
+	results = append(results, preflightGitResults(runCtx, ctx, opts)...)
+	if ctx.DockerHostType == config.ContextRemote {
+		results = append(results, preflightEndpointResult(ctx))
+	}
+	return results
+}
+
+// runPreflightComposeConfig renders the compose project on the context host,
+// which unlike readComposeConfigDocument also works for remote contexts.
+func runPreflightComposeConfig(runCtx context.Context, ctx *config.Context) (composeConfigDocument, error) {
+	var stdout, stderr bytes.Buffer
+	if err := pluginSDK.RunComposeProjectCommandContext(runCtx, ctx, ctx.ProjectDir, &stdout, &stderr, "docker compose config --format json"); err != nil {
+		return composeConfigDocument{}, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	var doc composeConfigDocument
+	if err := json.Unmarshal(stdout.Bytes(), &doc); err != nil {
+		return composeConfigDocument{}, fmt.Errorf("parse compose config: %w", err)
+	}
+	return doc, nil
+}
+
+// preflightImageResults checks each pulled image once. Services with a build
+// section are skipped because the rollout builds them.
+func preflightImageResults(runCtx context.Context, ctx *config.Context, doc composeConfigDocument) []sitevalidate.Result {
+	images := map[string]bool{}
+	for _, service := range doc.Services {
+		if service.Image != "" && !serviceHasBuild(service) {
+			images[service.Image] = true
+		}
+	}
+	if len(images) == 0 {
+		return nil
+	}
+	cli, err := docker.GetDockerCli(ctx)
+	if err != nil {
+		return []sitevalidate.Result{{Name: "images", Status: sitevalidate.StatusFailed, Detail: err.Error()}}
+	}
+	defer cli.Close()
+	api, ok := cli.CLI.(imageFreshnessAPI)
+	if !ok {
+		return []sitevalidate.Result{{Name: "images", Status: sitevalidate.StatusFailed, Detail: "docker client does not support registry inspection"}}
+	}
+	names := make([]string, 0, len(images))
+	for image := range images {
+		names = append(names, image)
+	}
+	sort.Strings(names)
+	results := make([]sitevalidate.Result, 0, len(names))
+	for _, image := range names {
+		results = append(results, checkPreflightImage(runCtx, api, image))
+	}
+	return results
+}
+
+func checkPreflightImage(runCtx context.Context, api imageFreshnessAPI, image string) sitevalidate.Result {
+	result := sitevalidate.Result{Name: "image " + image, Status: sitevalidate.StatusOK}
+	if _, err := api.ImageInspect(runCtx, image); err == nil {
+		result.Detail = "present on the Docker host"
+		return result
+	}
+	if _, err := api.DistributionInspect(runCtx, image, ""); err != nil {
+		result.Status = sitevalidate.StatusFailed
+		result.Detail = fmt.Sprintf("not on the Docker host and not resolvable in its registry: %v", err)
+		result.FixHint = "check the image name and tag, and that the Docker host is logged in to the registry"
+		return result
+	}
+	result.Detail = "resolvable in its registry"
+	return result
+}
+
+func preflightSecretResults(ctx *config.Context, doc composeConfigDocument) []sitevalidate.Result {
+	names := make([]string, 0, len(doc.Secrets))
+	for name := range doc.Secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	results := []sitevalidate.Result{}
+	for _, name := range names {
+		secret := doc.Secrets[name]
+		result := sitevalidate.Result{Name: "secret " + name, Status: sitevalidate.StatusOK}
+		switch {
+		case secret.External:
+			result.Status = sitevalidate.StatusWarning
+			result.Detail = "external secret; not checked"
+		case secret.Environment != "":
+			result.Detail = "read from environment variable " + secret.Environment
+		case secret.File != "":
+			exists, err := ctx.FileExists(secret.File)
+			switch {
+			case err != nil:
+				result.Status = sitevalidate.StatusFailed
+				result.Detail = fmt.Sprintf("check %s: %v", secret.File, err)
+			case !exists:
+				result.Status = sitevalidate.StatusFailed
+				result.Detail = secret.File + " does not exist"
+				result.FixHint = "create the secret file on the context host before deploying"
+			default:
+				result.Detail = secret.File
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// preflightGitScript reports the checkout state and whether the ref to deploy
+// exists, one "key value" line per fact, using the same remote selection as
+// the deploy git sync.
+func preflightGitScript(branch, ref string) string {
+	return fmt.Sprintf(
+		"set -uo pipefail; git_branch=%s; git_ref=%s; "+
+			"if ! git rev-parse --is-inside-work-tree >/dev/null 2>&1; then echo 'checkout not-git'; exit 0; fi; "+
+			"if [ -n \"$(git status --porcelain)\" ]; then echo 'checkout dirty'; else echo 'checkout clean'; fi; "+
+			"current_branch=$(git rev-parse --abbrev-ref HEAD); "+
+			"remote=$(git config --get \"branch.${current_branch}.remote\" 2>/dev/null || true); "+
+			"if [ -z \"$remote\" ] || [ \"$remote\" = . ]; then if git remote get-url origin >/dev/null 2>&1; then remote=origin; else remote=$(git remote | head -n 1); fi; fi; "+
+			"if [ -n \"$git_ref\" ]; then if git ls-remote --exit-code \"$remote\" \"$git_ref\" >/dev/null 2>&1; then echo \"ref found $remote\"; else echo \"ref missing $remote\"; fi; exit 0; fi; "+
+			"if [ -n \"$git_branch\" ]; then if git ls-remote --exit-code --heads \"$remote\" \"$git_branch\" >/dev/null 2>&1; then echo \"ref found $remote\"; else echo \"ref missing $remote\"; fi; exit 0; fi; "+
+			"upstream=$(git rev-parse --abbrev-ref --symbolic-full-name '@{u}' 2>/dev/null || true); "+
+			"if [ -z \"$upstream\" ]; then echo 'upstream none'; exit 0; fi; "+
+			"if git ls-remote --exit-code --heads \"${upstream%%%%/*}\" \"${upstream#*/}\" >/dev/null 2>&1; then echo \"upstream found $upstream\"; else echo \"upstream missing $upstream\"; fi",
+		shellquote.Join(branch), shellquote.Join(ref),
+	)
+}
+
+func runPreflightGitStatus(runCtx context.Context, ctx *config.Context, branch, ref string) (string, error) {
+	gitCmd := exec.Command("bash", "-lc", preflightGitScript(branch, ref)) // #nosec G204 -- script text is fixed and branch/ref are shell-quoted.
+	gitCmd.Dir = ctx.ProjectDir
+	return ctx.RunQuietCommandContext(runCtx, gitCmd)
+}
+
+func preflightGitResults(runCtx context.Context, ctx *config.Context, opts preflightOptions) []sitevalidate.Result {
+	ref := strings.TrimSpace(opts.ref)
+	branch := strings.TrimSpace(opts.branch)
+	target := helpers.FirstNonEmpty(ref, branch)
+	if ref != "" && preflightCommitRef.MatchString(ref) {
+		return []sitevalidate.Result{{Name: "git ref", Status: sitevalidate.StatusWarning, Detail: "commit " + ref + " can only be verified when deploy fetches it"}}
+	}
+	output, err := preflightGitStatus(runCtx, ctx, branch, ref)
+	if err != nil {
+		return []sitevalidate.Result{{Name: "git checkout", Status: sitevalidate.StatusFailed, Detail: err.Error()}}
+	}
+	results := []sitevalidate.Result{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		value := strings.Join(fields[2:], " ")
+		switch fields[0] + " " + fields[1] {
+		case "checkout not-git":
+			results = append(results, sitevalidate.Result{Name: "git checkout", Status: sitevalidate.StatusWarning, Detail: "project is not a git checkout; deploy skips the git update"})
+		case "checkout dirty":
+			results = append(results, sitevalidate.Result{Name: "git checkout", Status: sitevalidate.StatusFailed, Detail: "checkout has local changes", FixHint: "commit, stash or discard them; deploy refuses to sync a dirty checkout"})
+		case "checkout clean":
+			results = append(results, sitevalidate.Result{Name: "git checkout", Status: sitevalidate.StatusOK, Detail: "no local changes"})
+		case "ref found":
+			results = append(results, sitevalidate.Result{Name: "git ref", Status: sitevalidate.StatusOK, Detail: fmt.Sprintf("%s exists on %s", target, value)})
+		case "ref missing":
+			results = append(results, sitevalidate.Result{Name: "git ref", Status: sitevalidate.StatusFailed, Detail: fmt.Sprintf("%s not found on remote %s", target, value), FixHint: "push the ref or fix --ref/--branch"})
+		case "upstream found":
+			results = append(results, sitevalidate.Result{Name: "git ref", Status: sitevalidate.StatusOK, Detail: "upstream " + value + " exists"})
+		case "upstream missing":
+			results = append(results, sitevalidate.Result{Name: "git ref", Status: sitevalidate.StatusFailed, Detail: "upstream " + value + " not found on its remote", FixHint: "push the branch or pass --branch"})
+		case "upstream none":
+			results = append(results, sitevalidate.Result{Name: "git ref", Status: sitevalidate.StatusWarning, Detail: "current branch has no upstream; deploy skips the git update unless --branch or --ref is passed"})
+		}
+	}
+	return results
+}
+
+// preflightEndpointResult checks that the public site address accepts TCP
+// connections from here. It does not require the site to be healthy, only
+// that nothing in between drops external probes.
+func preflightEndpointResult(ctx *config.Context) sitevalidate.Result {
+	result := sitevalidate.Result{Name: "public endpoint", Status: sitevalidate.StatusOK}
+	target, err := url.Parse(healthcheck.PublicURLFromEnv(ctx, "https", ""))
+	if err != nil || target.Hostname() == "" || target.Hostname() == "localhost" {
+		result.Status = sitevalidate.StatusWarning
+		result.Detail = "no public URL configured (SITE_URL or DOMAIN in .env)"
+		return result
+	}
+	port := target.Port()
+	if port == "" {
+		port = "443"
+		if target.Scheme == "http" {
+			port = "80"
+		}
+	}
+	address := net.JoinHostPort(target.Hostname(), port)
+	if err := preflightDial(address); err != nil {
+		result.Status = sitevalidate.StatusFailed
+		result.Detail = fmt.Sprintf("cannot connect to %s: %v", address, err)
+		result.FixHint = "allow inbound connections on this port so health checks can reach the site"
+		return result
+	}
+	result.Detail = address + " accepts connections"
+	return result
+}
+
+func init() {
+	preflightCmd.Flags().String("format", corecomponent.ReportFormatSection, "Report output format: section, table, json, or yaml")
+	declareFormats(preflightCmd, corecomponent.ReportFormatSection, corecomponent.ReportFormatTable, "json", "yaml")
+	preflightCmd.Flags().String("branch", "", "Git branch the rollout will deploy")
+	preflightCmd.Flags().String("ref", "", "Exact Git ref the rollout will deploy")
+	preflightCmd.MarkFlagsMutuallyExclusive("branch", "ref")
+	preflightCmd.GroupID = "workflow"
+	RootCmd.AddCommand(preflightCmd)
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	dockerimage "github.com/docker/docker/api/types/image"
+	"github.com/libops/sitectl/pkg/config"
+	sitevalidate "github.com/libops/sitectl/pkg/validate"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func preflightStatuses(results []sitevalidate.Result) map[string]string {
+	statuses := map[string]string{}
+	for _, result := range results {
+		statuses[result.Name] = result.Status
+	}
+	return statuses
+}
+
+func TestCheckPreflightImage(t *testing.T) {
+	t.Parallel()
+
+	api := &fakeImageFreshnessAPI{
+		images:   map[string]dockerimage.InspectResponse{"mariadb:11": {}},
+		registry: map[string]ocispec.Descriptor{"solr:9": {}},
+	}
+	for image, want := range map[string]string{
+		"mariadb:11":        sitevalidate.StatusOK,
+		"solr:9":            sitevalidate.StatusOK,
+		"registry/app:typo": sitevalidate.StatusFailed,
+	} {
+		if got := checkPreflightImage(context.Background(), api, image); got.Status != want {
+			t.Errorf("checkPreflightImage(%q) = %+v, want %s", image, got, want)
+		}
+	}
+}
+
+func TestPreflightSecretResults(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	present := filepath.Join(dir, "secrets", "DB_ROOT_PASSWORD")
+	if err := os.MkdirAll(filepath.Dir(present), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(present, []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ctx := &config.Context{DockerHostType: config.ContextLocal, ProjectDir: dir}
+	doc := composeConfigDocument{Secrets: map[string]composeConfigSecret{
+		"DB_ROOT_PASSWORD": {File: present},
+		"JWT_KEY":          {File: filepath.Join(dir, "secrets", "JWT_KEY")},
+		"SMTP_PASSWORD":    {Environment: "SMTP_PASSWORD"},
+		"SHARED":           {External: true},
+	}}
+	want := map[string]string{
+		"secret DB_ROOT_PASSWORD": sitevalidate.StatusOK,
+		"secret JWT_KEY":          sitevalidate.StatusFailed,
+		"secret SMTP_PASSWORD":    sitevalidate.StatusOK,
+		"secret SHARED":           sitevalidate.StatusWarning,
+	}
+	got := preflightStatuses(preflightSecretResults(ctx, doc))
+	if len(got) != len(want) {
+		t.Fatalf("results = %v", got)
+	}
+	for name, status := range want {
+		if got[name] != status {
+			t.Errorf("%s = %s, want %s", name, got[name], status)
+		}
+	}
+}
+
+func TestPreflightGitAndEndpointResults(t *testing.T) {
+	originalGit, originalDial := preflightGitStatus, preflightDial
+	t.Cleanup(func() { preflightGitStatus, preflightDial = originalGit, originalDial })
+
+	var gotBranch, gotRef string
+	output := "checkout clean\nref missing origin\n"
+	preflightGitStatus = func(_ context.Context, _ *config.Context, branch, ref string) (string, error) {
+		gotBranch, gotRef = branch, ref
+		return output, nil
+	}
+	results := preflightGitResults(context.Background(), &config.Context{}, preflightOptions{branch: "release"})
+	if gotBranch != "release" || gotRef != "" {
+		t.Errorf("git status called with branch %q, ref %q", gotBranch, gotRef)
+	}
+	statuses := preflightStatuses(results)
+	if statuses["git checkout"] != sitevalidate.StatusOK || statuses["git ref"] != sitevalidate.StatusFailed {
+		t.Errorf("git results = %+v", results)
+	}
+	if !strings.Contains(results[1].Detail, "release not found on remote origin") {
+		t.Errorf("git ref detail = %q", results[1].Detail)
+	}
+
+	output = "checkout dirty\nupstream found origin/main\n"
+	statuses = preflightStatuses(preflightGitResults(context.Background(), &config.Context{}, preflightOptions{}))
+	if statuses["git checkout"] != sitevalidate.StatusFailed || statuses["git ref"] != sitevalidate.StatusOK {
+		t.Errorf("dirty checkout statuses = %v", statuses)
+	}
+	if results := preflightGitResults(context.Background(), &config.Context{}, preflightOptions{ref: "4f2a9c1"}); results[0].Status != sitevalidate.StatusWarning {
+		t.Errorf("commit ref results = %+v", results)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("DOMAIN=museum.example.edu\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var dialed string
+	preflightDial = func(address string) error {
+		dialed = address
+		return errors.New("i/o timeout")
+	}
+	ctx := &config.Context{DockerHostType: config.ContextLocal, ProjectDir: dir}
+	if result := preflightEndpointResult(ctx); result.Status != sitevalidate.StatusFailed || dialed != "museum.example.edu:443" {
+		t.Errorf("endpoint result = %+v, dialed %q", result, dialed)
+	}
+}
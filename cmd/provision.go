@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/plugin"
+	"github.com/spf13/cobra"
+)
+
+var provisionInput = config.GetInput
+
+// ensureProvisionPrerequisites installs Docker, the compose plugin, git and
+// make on the host. It is a variable so tests can stand in for the host.
+var ensureProvisionPrerequisites = func(cmd *cobra.Command, ctx *config.Context, yolo bool) error {
+	return pluginSDK.EnsureRemoteCreatePrerequisitesContext(cmd.Context(), cmd.OutOrStdout(), ctx, plugin.RemoteCreatePrerequisitesOptions{
+		Yolo:  yolo,
+		Input: provisionInput,
+	})
+}
+
+var provisionCmd = &cobra.Command{
+	Use:   "provision [context-name]",
+	Short: "Prepare a fresh VM as a remote context and save the context",
+	Long: `Prepare a fresh Linux VM to run a site over SSH, then save it as a remote context.
+
+Provisioning installs Docker, the Docker Compose plugin, git and make with the host's package
+manager when they are missing, adds the SSH user to the docker group, creates the project
+directory owned by the SSH user and, with --repo, clones the project repository into it.
+Every step is skipped when it is already done, so provision can be rerun safely.
+
+The SSH user must be root or have passwordless sudo.
+
+Examples:
+  sitectl provision prod --ssh-hostname vm.example.org --ssh-user deploy --ssh-port 22 --project-dir /srv/museum
+  sitectl provision prod --ssh-hostname vm.example.org --ssh-user deploy --project-dir /srv/museum \
+    --repo https://github.com/example/museum.git --branch main --default`,
+	Args:    cobra.ExactArgs(1),
+	GroupID: "setup",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f := cmd.Flags()
+		repo, err := f.GetString("repo")
+		if err != nil {
+			return err
+		}
+		branch, err := f.GetString("branch")
+		if err != nil {
+			return err
+		}
+		yolo, err := f.GetBool("yolo")
+		if err != nil {
+			return err
+		}
+		defaultContext, err := f.GetBool("default")
+		if err != nil {
+			return err
+		}
+
+		existing, err := config.GetContext(args[0])
+		if err != nil {
+			if !errors.Is(err, config.ErrContextNotFound) {
+				return err
+			}
+			existing = config.Context{Name: args[0]}
+		}
+		ctx, err := config.LoadFromFlags(f, existing)
+		if err != nil {
+			return err
+		}
+		ctx.Name = args[0]
+		ctx.DockerHostType = config.ContextRemote
+		if err := ctx.VerifyRemoteInput(true); err != nil {
+			return err
+		}
+		ctx.ProjectDir = strings.TrimSpace(ctx.ProjectDir)
+		if !path.IsAbs(ctx.ProjectDir) {
+			return fmt.Errorf("--project-dir must be an absolute path on %s, got %q", ctx.SSHHostname, ctx.ProjectDir)
+		}
+		if strings.TrimSpace(branch) != "" && strings.TrimSpace(repo) == "" {
+			return fmt.Errorf("--branch requires --repo")
+		}
+
+		// The project directory may not exist yet, and remote commands
+		// start in it, so provisioning runs from the filesystem root.
+		bootstrap := *ctx
+		bootstrap.ProjectDir = "/"
+		if err := ensureProvisionPrerequisites(cmd, &bootstrap, yolo); err != nil {
+			return err
+		}
+		sudo, err := hostRootPrefix(cmd.Context(), &bootstrap, "provision")
+		if err != nil {
+			return err
+		}
+		slog.Info("provisioning host", "context", ctx.Name, "host", ctx.SSHHostname, "project-dir", ctx.ProjectDir)
+		if err := hostStreamScript(cmd.Context(), &bootstrap, provisionHostScript(sudo, ctx.SSHUser, ctx.ProjectDir)); err != nil {
+			return fmt.Errorf("provision %s: %w", ctx.SSHHostname, err)
+		}
+		if strings.TrimSpace(repo) != "" {
+			if err := hostStreamScript(cmd.Context(), &bootstrap, provisionCloneScript(ctx.ProjectDir, repo, branch)); err != nil {
+				return fmt.Errorf("clone %s on %s: %w", repo, ctx.SSHHostname, err)
+			}
+		}
+
+		if err := config.SaveContext(ctx, defaultContext); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Provisioned %s and saved context %q for %s\n", ctx.SSHHostname, ctx.Name, ctx.ProjectDir)
+		return nil
+	},
+}
+
+// provisionHostScript adds user to the docker group and creates dir owned by
+// user. Group membership applies to the next SSH session, which is the next
+// sitectl command.
+func provisionHostScript(sudo, user, dir string) string {
+	return `set -e
+SUDO="` + sudo + `"
+user=` + shellquote.Join(user) + `
+dir=` + shellquote.Join(dir) + `
+if [ "$(id -u)" != 0 ] && ! id -nG "$user" | tr ' ' '\n' | grep -qx docker; then
+  getent group docker >/dev/null || $SUDO groupadd docker
+  $SUDO usermod -aG docker "$user"
+  echo "Added $user to the docker group"
+fi
+if [ ! -d "$dir" ]; then
+  $SUDO mkdir -p "$dir"
+  $SUDO chown "$user": "$dir"
+  echo "Created $dir"
+fi`
+}
+
+// provisionCloneScript clones repo into dir unless dir already holds a git
+// checkout. A non-empty directory that is not a checkout is left untouched.
+func provisionCloneScript(dir, repo, branch string) string {
+	clone := []string{"git", "clone"}
+	if strings.TrimSpace(branch) != "" {
+		clone = append(clone, "--branch", strings.TrimSpace(branch))
+	}
+	clone = append(clone, "--", strings.TrimSpace(repo), dir)
+	return `set -e
+dir=` + shellquote.Join(dir) + `
+if [ -d "$dir/.git" ]; then
+  echo "$dir is already a git checkout; skipping clone"
+elif [ -n "$(ls -A "$dir")" ]; then
+  echo "$dir is not empty and not a git checkout; refusing to clone into it" >&2
+  exit 1
+else
+  ` + shellquote.Join(clone...) + `
+fi`
+}
+
+func init() {
+	flags := provisionCmd.Flags()
+	config.SetCommandFlags(flags)
+	_ = flags.MarkHidden("type")
+	flags.String("repo", "", "Git repository to clone into the project directory")
+	flags.String("branch", "", "Branch to check out when cloning --repo")
+	flags.Bool("default", false, "Set the provisioned context as the default context")
+	flags.Bool("yolo", false, "Install missing packages without a confirmation prompt")
+	RootCmd.AddCommand(provisionCmd)
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func TestProvisionScripts(t *testing.T) {
+	t.Parallel()
+
+	host := provisionHostScript("sudo -n", "deploy", "/srv/my museum")
+	for _, want := range []string{`SUDO="sudo -n"`, "user=deploy", "dir='/srv/my museum'", `$SUDO usermod -aG docker "$user"`, `$SUDO chown "$user": "$dir"`} {
+		if !strings.Contains(host, want) {
+			t.Errorf("host script missing %q:\n%s", want, host)
+		}
+	}
+
+	clone := provisionCloneScript("/srv/museum", "https://github.com/example/museum.git", "main")
+	if !strings.Contains(clone, "git clone --branch main -- https://github.com/example/museum.git /srv/museum") {
+		t.Errorf("clone script = %s", clone)
+	}
+	if strings.Contains(provisionCloneScript("/srv/museum", "git@example.org:museum.git", ""), "--branch") {
+		t.Error("clone script without --branch passes --branch")
+	}
+}
+
+func TestProvisionCommand(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	previousRun, previousStream, previousEnsure := hostRunScript, hostStreamScript, ensureProvisionPrerequisites
+	t.Cleanup(func() {
+		hostRunScript, hostStreamScript, ensureProvisionPrerequisites = previousRun, previousStream, previousEnsure
+	})
+
+	ensured := false
+	ensureProvisionPrerequisites = func(_ *cobra.Command, ctx *config.Context, yolo bool) error {
+		if ctx.ProjectDir != "/" || !yolo {
+			t.Fatalf("prerequisites ran in %q with yolo=%v", ctx.ProjectDir, yolo)
+		}
+		ensured = true
+		return nil
+	}
+	hostRunScript = func(context.Context, *config.Context, string) (string, error) {
+		return "@@uid\n1000\n@@sudo\nok\n", nil
+	}
+	var scripts []string
+	hostStreamScript = func(_ context.Context, ctx *config.Context, script string) error {
+		if ctx.ProjectDir != "/" {
+			t.Fatalf("script ran in %q", ctx.ProjectDir)
+		}
+		scripts = append(scripts, script)
+		return nil
+	}
+
+	for flag, value := range map[string]string{
+		"ssh-hostname":         "vm.example.org",
+		"ssh-user":             "deploy",
+		"ssh-port":             "22",
+		"ssh-key":              "/home/deploy/.ssh/id_ed25519",
+		"project-dir":          "/srv/museum",
+		"compose-project-name": "museum",
+		"repo":                 "https://github.com/example/museum.git",
+		"yolo":                 "true",
+	} {
+		if err := provisionCmd.Flags().Set(flag, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var out bytes.Buffer
+	provisionCmd.SetOut(&out)
+	provisionCmd.SetContext(context.Background())
+	if err := provisionCmd.RunE(provisionCmd, []string{"prod"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !ensured || len(scripts) != 2 || !strings.Contains(scripts[0], `SUDO="sudo -n"`) || !strings.Contains(scripts[1], "git clone") {
+		t.Fatalf("ensured = %v, scripts = %q", ensured, scripts)
+	}
+	saved, err := config.GetContext("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.DockerHostType != config.ContextRemote || saved.ProjectDir != "/srv/museum" || saved.SSHHostname != "vm.example.org" || saved.SSHPort != 22 {
+		t.Errorf("saved context = %+v", saved)
+	}
+	if !strings.Contains(out.String(), `saved context "prod"`) {
+		t.Errorf("output = %q", out.String())
+	}
+}
@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	dockertypes "github.com/docker/docker/api/types"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	dockerimage "github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/docker"
+	"github.com/libops/sitectl/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+// pruneAPI is the part of the Docker client used to size and remove the
+// unused resources of one compose project.
+type pruneAPI interface {
+	DiskUsage(ctx context.Context, options dockertypes.DiskUsageOptions) (dockertypes.DiskUsage, error)
+	NetworkList(ctx context.Context, options network.ListOptions) ([]network.Summary, error)
+	NetworkInspect(ctx context.Context, networkID string, options network.InspectOptions) (network.Inspect, error)
+	ContainersPrune(ctx context.Context, pruneFilters filters.Args) (dockercontainer.PruneReport, error)
+	ImagesPrune(ctx context.Context, pruneFilters filters.Args) (dockerimage.PruneReport, error)
+	VolumesPrune(ctx context.Context, pruneFilters filters.Args) (volume.PruneReport, error)
+	NetworksPrune(ctx context.Context, pruneFilters filters.Args) (network.PruneReport, error)
+}
+
+// pruneCandidate is one resource prune would remove.
+type pruneCandidate struct {
+	Kind string
+	Name string
+	Size int64
+}
+
+var pruneInput = config.GetInput
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove unused Docker resources of the active context's compose project",
+	Long: `Remove stopped containers, dangling images and unused networks that belong to the active
+context's compose project, and with --volumes its unused volumes. Resources are selected by
+the com.docker.compose.project label, so other projects on the same Docker host are never
+touched.
+
+The resources to remove and their sizes are listed before asking for confirmation. Volumes
+hold databases and uploaded files, so they are only pruned with --volumes.
+
+Examples:
+  sitectl prune --dry-run
+  sitectl prune --context prod
+  sitectl prune --volumes --yolo`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		includeVolumes, err := cmd.Flags().GetBool("volumes")
+		if err != nil {
+			return err
+		}
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			return err
+		}
+		yolo, err := cmd.Flags().GetBool("yolo")
+		if err != nil {
+			return err
+		}
+		ctx, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+		cli, err := docker.GetDockerCli(ctx)
+		if err != nil {
+			return err
+		}
+		defer cli.Close()
+		api, ok := cli.CLI.(pruneAPI)
+		if !ok {
+			return fmt.Errorf("docker client does not support pruning")
+		}
+
+		project := ctx.EffectiveComposeProjectName()
+		candidates, err := pruneCandidates(cmd.Context(), api, project, includeVolumes)
+		if err != nil {
+			return err
+		}
+		if len(candidates) == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "Nothing to prune in compose project %q\n", project)
+			return nil
+		}
+		if err := writePruneCandidates(cmd.OutOrStdout(), candidates); err != nil {
+			return err
+		}
+		if dryRun {
+			return nil
+		}
+		if includeVolumes {
+			if err := ctx.RequireUnprotected("prune volumes"); err != nil {
+				return err
+			}
+		}
+		if !config.AutoConfirm(fmt.Sprintf("prune compose project %q of context %q", project, ctx.Name), yolo) {
+			input, err := pruneInput(i18n.T(i18n.PruneWarning, project, ctx.Name), i18n.T(i18n.ContinuePrompt))
+			if err != nil {
+				return err
+			}
+			if !i18n.IsYes(input) {
+				return fmt.Errorf("prune cancelled")
+			}
+		}
+
+		reclaimed, err := pruneComposeProject(cmd.Context(), api, project, includeVolumes)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), i18n.T(i18n.Pruned, project, humanBytes(int64(reclaimed))))
+		return nil
+	},
+}
+
+// pruneCandidates lists what pruneComposeProject would remove from project,
+// sorted by kind and name.
+func pruneCandidates(ctx context.Context, api pruneAPI, project string, includeVolumes bool) ([]pruneCandidate, error) {
+	usage, err := api.DiskUsage(ctx, dockertypes.DiskUsageOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("read docker disk usage: %w", err)
+	}
+	candidates := []pruneCandidate{}
+	// Volumes mounted by containers that survive the prune stay in use, while
+	// those only mounted by pruned containers become unused and are removed.
+	keptVolumes := map[string]bool{}
+	for _, container := range usage.Containers {
+		if container == nil {
+			continue
+		}
+		if !inComposeProject(container.Labels, project) || container.State == "running" || container.State == "paused" {
+			for _, mount := range container.Mounts {
+				keptVolumes[mount.Name] = true
+			}
+			continue
+		}
+		candidates = append(candidates, pruneCandidate{Kind: "container", Name: docker.TrimContainerName(container.Names), Size: container.SizeRw})
+	}
+	for _, image := range usage.Images {
+		if image == nil || !inComposeProject(image.Labels, project) || !danglingImage(image) || image.Containers > 0 {
+			continue
+		}
+		candidates = append(candidates, pruneCandidate{Kind: "image", Name: shortImageID(image.ID), Size: image.Size})
+	}
+	if includeVolumes {
+		for _, vol := range usage.Volumes {
+			if vol == nil || !inComposeProject(vol.Labels, project) || keptVolumes[vol.Name] {
+				continue
+			}
+			var size int64
+			if vol.UsageData != nil {
+				size = vol.UsageData.Size
+			}
+			candidates = append(candidates, pruneCandidate{Kind: "volume", Name: vol.Name, Size: size})
+		}
+	}
+
+	networks, err := api.NetworkList(ctx, network.ListOptions{Filters: composeProjectFilter(project)})
+	if err != nil {
+		return nil, fmt.Errorf("list compose networks: %w", err)
+	}
+	for _, summary := range networks {
+		inspect, err := api.NetworkInspect(ctx, summary.ID, network.InspectOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("inspect network %s: %w", summary.Name, err)
+		}
+		if len(inspect.Containers) == 0 {
+			candidates = append(candidates, pruneCandidate{Kind: "network", Name: summary.Name})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Kind != candidates[j].Kind {
+			return candidates[i].Kind < candidates[j].Kind
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+	return candidates, nil
+}
+
+// pruneComposeProject removes the unused resources of project and returns
+// the bytes reclaimed. Containers go first so the images, volumes and
+// networks they held become unused.
+func pruneComposeProject(ctx context.Context, api pruneAPI, project string, includeVolumes bool) (uint64, error) {
+	var reclaimed uint64
+	containers, err := api.ContainersPrune(ctx, composeProjectFilter(project))
+	if err != nil {
+		return reclaimed, fmt.Errorf("prune containers: %w", err)
+	}
+	reclaimed += containers.SpaceReclaimed
+
+	imageFilter := composeProjectFilter(project)
+	imageFilter.Add("dangling", "true")
+	images, err := api.ImagesPrune(ctx, imageFilter)
+	if err != nil {
+		return reclaimed, fmt.Errorf("prune images: %w", err)
+	}
+	reclaimed += images.SpaceReclaimed
+
+	if includeVolumes {
+		// Since API 1.42 volume prune only removes anonymous volumes unless
+		// all=true; compose-named volumes are the ones that fill disks.
+		volumeFilter := composeProjectFilter(project)
+		volumeFilter.Add("all", "true")
+		volumes, err := api.VolumesPrune(ctx, volumeFilter)
+		if err != nil {
+			return reclaimed, fmt.Errorf("prune volumes: %w", err)
+		}
+		reclaimed += volumes.SpaceReclaimed
+	}
+
+	if _, err := api.NetworksPrune(ctx, composeProjectFilter(project)); err != nil {
+		return reclaimed, fmt.Errorf("prune networks: %w", err)
+	}
+	return reclaimed, nil
+}
+
+func composeProjectFilter(project string) filters.Args {
+	return filters.NewArgs(filters.Arg("label", "com.docker.compose.project="+project))
+}
+
+func inComposeProject(labels map[string]string, project string) bool {
+	return labels["com.docker.compose.project"] == project
+}
+
+func danglingImage(image *dockerimage.Summary) bool {
+	for _, tag := range image.RepoTags {
+		if tag != "<none>:<none>" {
+			return false
+		}
+	}
+	return true
+}
+
+func shortImageID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+func writePruneCandidates(out io.Writer, candidates []pruneCandidate) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tNAME\tSIZE")
+	var total int64
+	for _, candidate := range candidates {
+		size := "-"
+		if candidate.Size > 0 {
+			size = humanBytes(candidate.Size)
+			total += candidate.Size
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", candidate.Kind, candidate.Name, size)
+	}
+	fmt.Fprintf(w, "total\t\t%s\n", humanBytes(total))
+	return w.Flush()
+}
+
+func init() {
+	pruneCmd.Flags().Bool("volumes", false, "Also remove unused volumes of the compose project, including named volumes")
+	pruneCmd.Flags().Bool("dry-run", false, "List what would be removed without removing it")
+	pruneCmd.Flags().Bool("yolo", false, "Skip the confirmation prompt")
+	pruneCmd.GroupID = "ops"
+	RootCmd.AddCommand(pruneCmd)
+}
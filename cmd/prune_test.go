@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	dockertypes "github.com/docker/docker/api/types"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	dockerimage "github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+)
+
+type fakePruneAPI struct {
+	usage    dockertypes.DiskUsage
+	networks []network.Summary
+	inUse    map[string]bool
+	filters  map[string]filters.Args
+}
+
+func (f *fakePruneAPI) DiskUsage(context.Context, dockertypes.DiskUsageOptions) (dockertypes.DiskUsage, error) {
+	return f.usage, nil
+}
+
+func (f *fakePruneAPI) NetworkList(context.Context, network.ListOptions) ([]network.Summary, error) {
+	return f.networks, nil
+}
+
+func (f *fakePruneAPI) NetworkInspect(_ context.Context, id string, _ network.InspectOptions) (network.Inspect, error) {
+	inspect := network.Inspect{ID: id}
+	if f.inUse[id] {
+		inspect.Containers = map[string]network.EndpointResource{"c": {}}
+	}
+	return inspect, nil
+}
+
+func (f *fakePruneAPI) record(kind string, args filters.Args) {
+	if f.filters == nil {
+		f.filters = map[string]filters.Args{}
+	}
+	f.filters[kind] = args
+}
+
+func (f *fakePruneAPI) ContainersPrune(_ context.Context, args filters.Args) (dockercontainer.PruneReport, error) {
+	f.record("containers", args)
+	return dockercontainer.PruneReport{SpaceReclaimed: 1024}, nil
+}
+
+func (f *fakePruneAPI) ImagesPrune(_ context.Context, args filters.Args) (dockerimage.PruneReport, error) {
+	f.record("images", args)
+	return dockerimage.PruneReport{SpaceReclaimed: 2048}, nil
+}
+
+func (f *fakePruneAPI) VolumesPrune(_ context.Context, args filters.Args) (volume.PruneReport, error) {
+	f.record("volumes", args)
+	return volume.PruneReport{SpaceReclaimed: 4096}, nil
+}
+
+func (f *fakePruneAPI) NetworksPrune(_ context.Context, args filters.Args) (network.PruneReport, error) {
+	f.record("networks", args)
+	return network.PruneReport{}, nil
+}
+
+func pruneTestAPI() *fakePruneAPI {
+	site := map[string]string{"com.docker.compose.project": "site"}
+	other := map[string]string{"com.docker.compose.project": "other"}
+	return &fakePruneAPI{
+		usage: dockertypes.DiskUsage{
+			Containers: []*dockercontainer.Summary{
+				{Names: []string{"/site-drupal-1"}, State: "running", Labels: site, Mounts: []dockercontainer.MountPoint{{Name: "site_files"}}},
+				{Names: []string{"/site-init-1"}, State: "exited", Labels: site, SizeRw: 10, Mounts: []dockercontainer.MountPoint{{Name: "site_init"}}},
+				{Names: []string{"/other-db-1"}, State: "exited", Labels: other, Mounts: []dockercontainer.MountPoint{{Name: "shared"}}},
+			},
+			Images: []*dockerimage.Summary{
+				{ID: "sha256:0123456789abcdef", RepoTags: []string{"<none>:<none>"}, Labels: site, Size: 100},
+				{ID: "sha256:tagged", RepoTags: []string{"site-drupal:latest"}, Labels: site},
+				{ID: "sha256:otherdangling", Labels: other},
+			},
+			Volumes: []*volume.Volume{
+				{Name: "site_files", Labels: site},
+				{Name: "site_init", Labels: site, UsageData: &volume.UsageData{Size: 1000, RefCount: 1}},
+				{Name: "site_db", Labels: site, UsageData: &volume.UsageData{Size: 5000}},
+				{Name: "shared", Labels: site},
+			},
+		},
+		networks: []network.Summary{{ID: "n1", Name: "site_default"}, {ID: "n2", Name: "site_unused"}},
+		inUse:    map[string]bool{"n1": true},
+	}
+}
+
+func TestPruneCandidates(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		volumes bool
+		want    []string
+	}{
+		{
+			name: "without volumes",
+			want: []string{"container site-init-1", "image 0123456789ab", "network site_unused"},
+		},
+		{
+			name:    "with volumes",
+			volumes: true,
+			want:    []string{"container site-init-1", "image 0123456789ab", "network site_unused", "volume site_db", "volume site_init"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			candidates, err := pruneCandidates(context.Background(), pruneTestAPI(), "site", tt.volumes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, candidate := range candidates {
+				got = append(got, candidate.Kind+" "+candidate.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("candidates = %v, want %v", got, tt.want)
+			}
+
+			var out bytes.Buffer
+			if err := writePruneCandidates(&out, candidates); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out.String(), "total") {
+				t.Fatalf("output missing total:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestPruneComposeProjectScopesByLabel(t *testing.T) {
+	t.Parallel()
+
+	api := pruneTestAPI()
+	reclaimed, err := pruneComposeProject(context.Background(), api, "site", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reclaimed != 3072 {
+		t.Fatalf("reclaimed = %d, want 3072", reclaimed)
+	}
+	if _, ok := api.filters["volumes"]; ok {
+		t.Fatal("volumes pruned without --volumes")
+	}
+	for kind, args := range api.filters {
+		if !args.ExactMatch("label", "com.docker.compose.project=site") {
+			t.Errorf("%s prune filter = %v, want compose project label", kind, args)
+		}
+	}
+	if !api.filters["images"].ExactMatch("dangling", "true") {
+		t.Errorf("image prune filter = %v, want dangling only", api.filters["images"])
+	}
+
+	api = pruneTestAPI()
+	if _, err := pruneComposeProject(context.Background(), api, "site", true); err != nil {
+		t.Fatal(err)
+	}
+	if !api.filters["volumes"].ExactMatch("all", "true") {
+		t.Errorf("volume prune filter = %v, want all=true", api.filters["volumes"])
+	}
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/helpers"
+	"github.com/spf13/cobra"
+)
+
+// readOnlyCommands are the commands --read-only allows, by path below the
+// root. A value lists the only subcommands allowed as the first argument, as
+// for the docker compose passthrough; an empty value allows any arguments.
+// Everything else, including plugin commands, tunnels and commands that run
+// arbitrary programs, is refused. help and shell completion are always allowed.
+var readOnlyCommands = map[string]string{
+	"ci render":               "",
+	"component describe":      "",
+	"component list":          "",
+	"compose":                 "config events images logs ls port ps stats top version wait -h --help",
+	"config current-context":  "",
+	"config get-contexts":     "",
+	"config get-environments": "",
+	"config get-sites":        "",
+	"config validate":         "",
+	"config view":             "",
+	"console":                 "",
+	"daemon status":           "",
+	"debug":                   "",
+	"docs":                    "",
+	"domain check":            "",
+	"env":                     "",
+	"env diff":                "",
+	"healthcheck":             "",
+	"host df":                 "",
+	"host status":             "",
+	"image outdated":          "",
+	"info":                    "",
+	"job list":                "",
+	"logs":                    "",
+	"mariadb status":          "",
+	"memcached stats":         "",
+	"memcached status":        "",
+	"port-forward list":       "",
+	"preflight":               "",
+	"registry list":           "",
+	"scan":                    "",
+	"snapshot list":           "",
+	"solr info":               "",
+	"solr status":             "",
+	"stats":                   "",
+	"status":                  "",
+	"traefik status":          "",
+	"urls":                    "",
+	"validate":                "",
+	"valkey ping":             "",
+	"valkey status":           "",
+	"wait":                    "",
+}
+
+// readOnlyRefusedFlags are flags that make an otherwise read-only command
+// change something, by command path. --read-only refuses them when set.
+var readOnlyRefusedFlags = map[string][]string{
+	"image outdated": {"pull"},
+}
+
+// requireReadOnlyAllowed refuses cmd in read-only mode unless it is one of
+// readOnlyCommands.
+func requireReadOnlyAllowed(cmd *cobra.Command, args []string) error {
+	if !config.ReadOnly() {
+		return nil
+	}
+	path := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name())
+	path = strings.TrimSpace(path)
+	name, _, _ := strings.Cut(path, " ")
+	if name == "help" || name == "completion" || strings.HasPrefix(name, "__complete") {
+		return nil
+	}
+	subcommands, ok := readOnlyCommands[path]
+	if !ok {
+		return config.RequireWritable("run " + cmd.CommandPath())
+	}
+	for _, name := range readOnlyRefusedFlags[path] {
+		if flag := cmd.Flags().Lookup(name); flag != nil && flag.Changed && flag.Value.String() != "false" {
+			return config.RequireWritable(fmt.Sprintf("run %s --%s", cmd.CommandPath(), name))
+		}
+	}
+	if subcommands == "" {
+		return nil
+	}
+	filteredArgs, _, err := helpers.GetContextFromArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+	filteredArgs = slices.DeleteFunc(filteredArgs, isReadOnlyFlag)
+	if len(filteredArgs) == 0 || !slices.Contains(strings.Fields(subcommands), filteredArgs[0]) {
+		return config.RequireWritable(fmt.Sprintf("run %s %s", cmd.CommandPath(), strings.Join(filteredArgs, " ")))
+	}
+	return nil
+}
+
+// readOnlyRequested reports whether --read-only was passed to a command that
+// parses its own flags, such as compose or a plugin command, where the root
+// flag is left in args.
+func readOnlyRequested(cmd *cobra.Command, args []string) bool {
+	return cmd.DisableFlagParsing && slices.ContainsFunc(args, func(arg string) bool {
+		return arg == "--read-only" || arg == "--read-only=true"
+	})
+}
+
+func isReadOnlyFlag(arg string) bool {
+	return arg == "--read-only" || strings.HasPrefix(arg, "--read-only=")
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/libops/sitectl/pkg/config"
+)
+
+func TestReadOnlyCommandsExist(t *testing.T) {
+	for path := range readOnlyCommands {
+		cmd, _, err := RootCmd.Find(strings.Fields(path))
+		if err != nil || cmd.CommandPath() != "sitectl "+path {
+			t.Errorf("read-only command %q does not exist", path)
+		}
+	}
+	for path, flags := range readOnlyRefusedFlags {
+		cmd, _, err := RootCmd.Find(strings.Fields(path))
+		if err != nil {
+			t.Fatalf("command %q does not exist", path)
+		}
+		for _, name := range flags {
+			if cmd.Flags().Lookup(name) == nil {
+				t.Errorf("command %q has no --%s flag", path, name)
+			}
+		}
+	}
+}
+
+func TestRequireReadOnlyRefusesWriteFlags(t *testing.T) {
+	t.Setenv(config.ReadOnlyEnv, "1")
+	flag := imageOutdatedCmd.Flags().Lookup("pull")
+	t.Cleanup(func() {
+		_ = flag.Value.Set("false")
+		flag.Changed = false
+	})
+
+	if err := requireReadOnlyAllowed(imageOutdatedCmd, nil); err != nil {
+		t.Fatalf("image outdated refused: %v", err)
+	}
+	if err := imageOutdatedCmd.Flags().Set("pull", "true"); err != nil {
+		t.Fatal(err)
+	}
+	if err := requireReadOnlyAllowed(imageOutdatedCmd, nil); !errors.Is(err, config.ErrReadOnly) {
+		t.Errorf("image outdated --pull error = %v, want ErrReadOnly", err)
+	}
+	if err := imageOutdatedCmd.Flags().Set("pull", "false"); err != nil {
+		t.Fatal(err)
+	}
+	if err := requireReadOnlyAllowed(imageOutdatedCmd, nil); err != nil {
+		t.Errorf("image outdated --pull=false refused: %v", err)
+	}
+}
+
+func TestRequireReadOnlyAllowed(t *testing.T) {
+	tests := []struct {
+		args    []string
+		allowed bool
+	}{
+		{args: []string{"logs", "drupal"}, allowed: true},
+		{args: []string{"config", "view"}, allowed: true},
+		{args: []string{"config", "delete-context", "prod"}},
+		{args: []string{"config", "use-context", "prod"}},
+		{args: []string{"config", "protect-context", "prod"}},
+		{args: []string{"deploy"}},
+		{args: []string{"run", "ls"}},
+		{args: []string{"port-forward", "mariadb:3306"}},
+		{args: []string{"compose", "ps"}, allowed: true},
+		{args: []string{"compose", "--context", "prod", "logs", "-f"}, allowed: true},
+		{args: []string{"compose", "--read-only", "ps"}, allowed: true},
+		{args: []string{"compose", "--context", "prod", "down"}},
+		{args: []string{"compose", "up", "ps"}},
+		{args: []string{"compose"}},
+	}
+
+	t.Setenv(config.ReadOnlyEnv, "")
+	cmd, args, err := RootCmd.Find([]string{"deploy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := requireReadOnlyAllowed(cmd, args); err != nil {
+		t.Fatalf("refused outside read-only mode: %v", err)
+	}
+
+	t.Setenv(config.ReadOnlyEnv, "1")
+	for _, tt := range tests {
+		cmd, args, err := RootCmd.Find(tt.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = requireReadOnlyAllowed(cmd, args)
+		if tt.allowed && err != nil {
+			t.Errorf("%q refused: %v", tt.args, err)
+		}
+		if !tt.allowed && !errors.Is(err, config.ErrReadOnly) {
+			t.Errorf("%q error = %v, want ErrReadOnly", tt.args, err)
+		}
+	}
+}
+
+func TestReadOnlyRequested(t *testing.T) {
+	compose, args, err := RootCmd.Find([]string{"compose", "--read-only", "ps"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !readOnlyRequested(compose, args) {
+		t.Error("--read-only in compose args was not detected")
+	}
+	if readOnlyRequested(compose, []string{"--read-only=false", "ps"}) {
+		t.Error("--read-only=false was treated as read-only")
+	}
+	logs, _, _ := RootCmd.Find([]string{"logs"})
+	if readOnlyRequested(logs, []string{"--read-only"}) {
+		t.Error("commands that parse flags must not be scanned")
+	}
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/docker"
+	"github.com/libops/sitectl/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+// redisPasswordNames are the compose secrets or environment variables checked
+// for the cache password, in order.
+var redisPasswordNames = []string{"REDIS_PASSWORD", "VALKEY_PASSWORD"}
+
+type redisCLIOptions struct {
+	service string
+	monitor bool
+	flush   bool
+	yolo    bool
+}
+
+func redisCLICommand() *cobra.Command {
+	opts := redisCLIOptions{}
+	cmd := &cobra.Command{
+		Use:   "redis-cli [-- REDIS-CLI ARGS...]",
+		Short: "Open redis-cli (or valkey-cli) in the cache service container",
+		Long: `Run redis-cli inside the cache service container of the active context.
+
+sitectl finds the valkey or redis service (by service name or image), prefers valkey-cli
+when the image provides it, and authenticates with the REDIS_PASSWORD or VALKEY_PASSWORD
+compose secret or environment variable when one is set. The password is passed through the
+container environment, never on a command line.
+
+Examples:
+  sitectl redis-cli                     # Interactive prompt
+  sitectl redis-cli -- info memory      # Run a single command
+  sitectl redis-cli --monitor           # Stream every command the server receives
+  sitectl redis-cli --flush             # Flush all keys after confirmation`,
+		GroupID: "ops",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (opts.monitor || opts.flush) && len(args) > 0 {
+				return fmt.Errorf("--monitor and --flush do not accept extra redis-cli arguments")
+			}
+			ctx, err := resolveCurrentContext(cmd)
+			if err != nil {
+				return err
+			}
+			return runRedisCLI(cmd, ctx, opts, args)
+		},
+	}
+	cmd.Flags().StringVar(&opts.service, "service", "", "Cache compose service name (defaults to the valkey or redis service)")
+	cmd.Flags().BoolVar(&opts.monitor, "monitor", false, "Stream every command processed by the server until Ctrl+C")
+	cmd.Flags().BoolVar(&opts.flush, "flush", false, "Delete all keys in every database (FLUSHALL)")
+	cmd.Flags().BoolVar(&opts.yolo, "yolo", false, "Skip the confirmation prompt before flushing")
+	cmd.MarkFlagsMutuallyExclusive("monitor", "flush")
+	return cmd
+}
+
+func runRedisCLI(cmd *cobra.Command, ctx *config.Context, opts redisCLIOptions, args []string) error {
+	cli, err := docker.GetDockerCli(ctx)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	containerName, err := redisContainerName(cmd.Context(), cli, ctx, opts.service)
+	if err != nil {
+		return err
+	}
+
+	if opts.flush {
+		if err := ctx.RequireUnprotected("flush the cache"); err != nil {
+			return err
+		}
+		ok, err := confirmRedisFlush(ctx.Name, opts.yolo)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Flush cancelled.")
+			return nil
+		}
+	}
+
+	var env []string
+	password, err := docker.GetFirstSecretOrEnv(cmd.Context(), cli.CLI, ctx, containerName, redisPasswordNames...)
+	if err != nil {
+		slog.Debug("connecting to cache without a password", "container", containerName, "err", err)
+	} else {
+		env = append(env, "REDISCLI_AUTH="+strings.TrimSpace(password))
+	}
+
+	client := resolveContainerExecutable(cmd, cli, containerName, "valkey-cli", "redis-cli")
+	err = execInContainer(cmd.Context(), cli, containerName, redisCLIArgs(client, opts, args), env)
+	if opts.monitor && errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+func redisContainerName(ctx context.Context, cli *docker.DockerClient, siteCtx *config.Context, service string) (string, error) {
+	if strings.TrimSpace(service) != "" {
+		containerName, err := cli.GetContainerNameContext(ctx, siteCtx, service)
+		if err != nil {
+			return "", fmt.Errorf("find %s container: %w", service, err)
+		}
+		if strings.TrimSpace(containerName) == "" {
+			return "", fmt.Errorf("unable to find %s container for context %q", service, siteCtx.Name)
+		}
+		return strings.TrimPrefix(containerName, "/"), nil
+	}
+	detected, err := detectComposeService(ctx, cli.CLI, siteCtx, "valkey", "redis")
+	if err != nil {
+		return "", err
+	}
+	return detected.ContainerName, nil
+}
+
+func redisCLIArgs(client string, opts redisCLIOptions, args []string) []string {
+	switch {
+	case opts.monitor:
+		return []string{client, "monitor"}
+	case opts.flush:
+		return []string{client, "flushall"}
+	default:
+		return append([]string{client}, args...)
+	}
+}
+
+func confirmRedisFlush(contextName string, yolo bool) (bool, error) {
+	if config.AutoConfirm(fmt.Sprintf("flush the cache of context %q", contextName), yolo) {
+		return true, nil
+	}
+	input, err := config.GetInput(
+		i18n.T(i18n.CacheFlushWarning, contextName),
+		i18n.T(i18n.ContinuePrompt),
+	)
+	if err != nil {
+		return false, err
+	}
+	return i18n.IsYes(input), nil
+}
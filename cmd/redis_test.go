@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRedisCLIArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts redisCLIOptions
+		args []string
+		want []string
+	}{
+		{name: "interactive", want: []string{"valkey-cli"}},
+		{name: "passthrough", args: []string{"info", "memory"}, want: []string{"valkey-cli", "info", "memory"}},
+		{name: "monitor", opts: redisCLIOptions{monitor: true}, want: []string{"valkey-cli", "monitor"}},
+		{name: "flush", opts: redisCLIOptions{flush: true}, want: []string{"valkey-cli", "flushall"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := redisCLIArgs("valkey-cli", tc.opts, tc.args); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("redisCLIArgs() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestConfirmRedisFlushYolo(t *testing.T) {
+	t.Parallel()
+
+	ok, err := confirmRedisFlush("local", true)
+	if err != nil || !ok {
+		t.Fatalf("confirmRedisFlush(yolo) = %v, %v; want true, nil", ok, err)
+	}
+}
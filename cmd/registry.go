@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kballard/go-shellquote"
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/keyring"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// registryRunDocker runs a docker CLI command with stdin on the local machine,
+// or on the context's host over SSH when onContext is set and the context is
+// remote; tests replace it.
+var registryRunDocker = runRegistryDocker
+
+var registryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: "Manage container registry credentials for a context",
+	Long: `Manage the container registry credentials of a context.
+
+sitectl registry login stores the username in the context and the password in the
+system keyring (the macOS login keychain, or the Secret Service through secret-tool
+on Linux), then runs docker login on the context's Docker host: locally for a local
+context and over SSH for a remote one. sitectl deploy repeats the login on the
+context before pulling images, and sitectl build --push logs in locally before
+pushing, so rotated or expired daemon logins are refreshed from the keyring.`,
+}
+
+var registryLoginCmd = &cobra.Command{
+	Use:   "login HOST",
+	Short: "Store registry credentials for the context and log its Docker host in",
+	Long: `Store registry credentials for the context and log its Docker host in.
+
+The password is read from stdin with --password-stdin, or prompted for on a terminal.
+
+Examples:
+  sitectl registry login ghcr.io --username libops-bot --password-stdin < token.txt
+  sitectl registry login registry.example.edu:5000 --context museum-prod --username deploy`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host, err := config.NormalizeRegistryHost(args[0])
+		if err != nil {
+			return err
+		}
+		username, err := cmd.Flags().GetString("username")
+		if err != nil {
+			return err
+		}
+		passwordStdin, err := cmd.Flags().GetBool("password-stdin")
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(username) == "" {
+			return fmt.Errorf("--username is required")
+		}
+		ctx, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+		password, err := readRegistryPassword(cmd, passwordStdin)
+		if err != nil {
+			return err
+		}
+
+		login := config.RegistryLogin{Host: host, Username: strings.TrimSpace(username)}
+		if err := registryDockerLogin(cmd.Context(), ctx, true, login, password); err != nil {
+			return err
+		}
+		if err := keyring.Set(config.RegistryKeyringAccount(ctx.Name, host), password); err != nil {
+			return err
+		}
+		if err := updateStoredContext(ctx.Name, func(c *config.Context) { c.SetRegistryLogin(login) }); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Logged in to %s as %s on context %s\n", host, login.Username, ctx.Name)
+		return nil
+	},
+}
+
+var registryLogoutCmd = &cobra.Command{
+	Use:   "logout HOST",
+	Short: "Remove stored registry credentials and log the context's Docker host out",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host, err := config.NormalizeRegistryHost(args[0])
+		if err != nil {
+			return err
+		}
+		ctx, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+		if _, ok := ctx.RegistryLogin(host); !ok {
+			return fmt.Errorf("context %q has no login for %s", ctx.Name, host)
+		}
+		if err := registryRunDocker(cmd.Context(), ctx, true, "", "logout", host); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: docker logout %s failed: %v\n", host, err)
+		}
+		if err := keyring.Delete(config.RegistryKeyringAccount(ctx.Name, host)); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			return err
+		}
+		if err := updateStoredContext(ctx.Name, func(c *config.Context) { c.RemoveRegistryLogin(host) }); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Logged out of %s on context %s\n", host, ctx.Name)
+		return nil
+	},
+}
+
+var registryListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the registries the context is logged in to",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "HOST\tUSERNAME\tPASSWORD")
+		for _, login := range ctx.Registries {
+			state := "keyring"
+			if _, err := keyring.Get(config.RegistryKeyringAccount(ctx.Name, login.Host)); err != nil {
+				state = "missing"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", login.Host, login.Username, state)
+		}
+		return w.Flush()
+	},
+}
+
+func readRegistryPassword(cmd *cobra.Command, fromStdin bool) (string, error) {
+	if fromStdin {
+		data, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return "", fmt.Errorf("read password: %w", err)
+		}
+		password := strings.TrimRight(string(data), "\r\n")
+		if password == "" {
+			return "", fmt.Errorf("empty password on stdin")
+		}
+		return password, nil
+	}
+	if config.NonInteractive() || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("registry password required: pass --password-stdin")
+	}
+	fmt.Fprint(cmd.ErrOrStderr(), "Password: ")
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(cmd.ErrOrStderr())
+	if err != nil {
+		return "", fmt.Errorf("read password: %w", err)
+	}
+	if len(password) == 0 {
+		return "", fmt.Errorf("empty password")
+	}
+	return string(password), nil
+}
+
+func updateStoredContext(name string, update func(*config.Context)) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	for i := range cfg.Contexts {
+		if cfg.Contexts[i].Name == name {
+			update(&cfg.Contexts[i])
+			return config.Save(cfg)
+		}
+	}
+	return fmt.Errorf("%w: %s", config.ErrContextNotFound, name)
+}
+
+func registryDockerLogin(runCtx context.Context, ctx *config.Context, onContext bool, login config.RegistryLogin, password string) error {
+	if err := registryRunDocker(runCtx, ctx, onContext, password, "login", login.Host, "--username", login.Username, "--password-stdin"); err != nil {
+		return fmt.Errorf("docker login %s: %w", login.Host, err)
+	}
+	return nil
+}
+
+// loginContextRegistries repeats the context's stored registry logins, on the
+// context's Docker host when onContext is set and locally otherwise. When
+// hosts is not empty only those registries are logged in.
+func loginContextRegistries(runCtx context.Context, ctx *config.Context, onContext bool, hosts ...string) error {
+	for _, login := range ctx.Registries {
+		if len(hosts) > 0 && !containsString(hosts, login.Host) {
+			continue
+		}
+		password, err := keyring.Get(config.RegistryKeyringAccount(ctx.Name, login.Host))
+		if err != nil {
+			return fmt.Errorf("registry %s: %w; run sitectl registry login %s again", login.Host, err, login.Host)
+		}
+		if err := registryDockerLogin(runCtx, ctx, onContext, login, password); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runRegistryDocker(runCtx context.Context, ctx *config.Context, onContext bool, stdin string, args ...string) error {
+	if !onContext || ctx.DockerHostType != config.ContextRemote {
+		var stderr bytes.Buffer
+		command := exec.CommandContext(runCtx, "docker", args...) // #nosec G204 -- docker login/logout with a validated registry host.
+		command.Stdin = strings.NewReader(stdin)
+		command.Stdout, command.Stderr = io.Discard, &stderr
+		if err := command.Run(); err != nil {
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return nil
+	}
+
+	client, err := ctx.SSHClient()
+	if err != nil {
+		return err
+	}
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	session.Stdin = strings.NewReader(stdin)
+	output, err := session.CombinedOutput(shellquote.Join(append([]string{"docker"}, args...)...))
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func init() {
+	registryLoginCmd.Flags().StringP("username", "u", "", "Registry username")
+	registryLoginCmd.Flags().Bool("password-stdin", false, "Read the password or token from stdin")
+	registryCmd.AddCommand(registryLoginCmd)
+	registryCmd.AddCommand(registryLogoutCmd)
+	registryCmd.AddCommand(registryListCmd)
+	registryCmd.GroupID = "setup"
+	RootCmd.AddCommand(registryCmd)
+}
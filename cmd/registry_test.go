@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/libops/sitectl/pkg/config"
+)
+
+func TestUpdateContextRegistries(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	ctx := config.Context{Name: "museum-prod", Site: "museum", DockerHostType: config.ContextLocal, ProjectDir: tempHome}
+	if err := config.SaveContext(&ctx, true); err != nil {
+		t.Fatal(err)
+	}
+
+	login := config.RegistryLogin{Host: "ghcr.io", Username: "libops-bot"}
+	if err := updateStoredContext("museum-prod", func(c *config.Context) { c.SetRegistryLogin(login) }); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := config.GetContext("museum-prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := saved.RegistryLogin("ghcr.io"); !ok || got != login {
+		t.Errorf("saved login = %+v, %v", got, ok)
+	}
+	if err := updateStoredContext("missing", func(*config.Context) {}); err == nil {
+		t.Error("expected a missing context to fail")
+	}
+}
+
+func TestRegistryDockerLogin(t *testing.T) {
+	original := registryRunDocker
+	t.Cleanup(func() { registryRunDocker = original })
+	var gotStdin string
+	var gotArgs []string
+	var gotOnContext bool
+	registryRunDocker = func(_ context.Context, _ *config.Context, onContext bool, stdin string, args ...string) error {
+		gotOnContext, gotStdin, gotArgs = onContext, stdin, args
+		return nil
+	}
+
+	ctx := &config.Context{Name: "museum-prod", DockerHostType: config.ContextRemote}
+	if err := registryDockerLogin(context.Background(), ctx, true, config.RegistryLogin{Host: "ghcr.io", Username: "bot"}, "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+	if !gotOnContext || gotStdin != "s3cret" || !containsString(gotArgs, "--password-stdin") || containsString(gotArgs, "s3cret") {
+		t.Errorf("docker login ran with onContext=%v stdin=%q args=%q", gotOnContext, gotStdin, gotArgs)
+	}
+
+	// Registries that are not being pushed to are skipped without reading the keyring.
+	ctx.Registries = []config.RegistryLogin{{Host: "quay.io", Username: "bot"}}
+	gotArgs = nil
+	if err := loginContextRegistries(context.Background(), ctx, false, "ghcr.io"); err != nil || gotArgs != nil {
+		t.Errorf("loginContextRegistries() = %v, ran %q", err, gotArgs)
+	}
+}
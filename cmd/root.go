@@ -6,15 +6,21 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
 
 	"charm.land/fang/v2"
 	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/httplog"
+	"github.com/libops/sitectl/pkg/i18n"
+	"github.com/libops/sitectl/pkg/notify"
 	"github.com/libops/sitectl/pkg/plugin"
+	"github.com/libops/sitectl/pkg/profile"
 	"github.com/libops/sitectl/pkg/tui"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var RootCmd = &cobra.Command{
@@ -23,7 +29,11 @@ var RootCmd = &cobra.Command{
 	Long: `sitectl manages Docker Compose-based sites across local and remote environments.
 
 Run it with no arguments to open the interactive dashboard. Use subcommands to manage
-contexts, run compose operations, toggle components, forward ports, and collect diagnostics.`,
+contexts, run compose operations, toggle components, forward ports, and collect diagnostics.
+
+Exit statuses: 1 general failure, 2 usage, 3 authentication, 4 not found, 5 permission,
+6 network, 7 timeout, 130 cancelled. Commands that run another program (run, drush, wp, ...)
+exit with that program's status. Pass --error-format json for a machine-readable error on stderr.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		level := slog.LevelInfo
 		ll, err := cmd.Flags().GetString("log-level")
@@ -46,24 +56,246 @@ contexts, run compose operations, toggle components, forward ports, and collect
 		handler := slog.New(slog.NewTextHandler(os.Stdout, opts))
 		slog.SetDefault(handler)
 
+		nonInteractive, err := cmd.Flags().GetBool("non-interactive")
+		if err != nil {
+			return err
+		}
+		if nonInteractive {
+			// exported so plugin subprocesses refuse to prompt too
+			if err := os.Setenv(config.NonInteractiveEnv, "1"); err != nil {
+				return err
+			}
+		}
+		readOnly, err := cmd.Flags().GetBool("read-only")
+		if err != nil {
+			return err
+		}
+		if readOnly || readOnlyRequested(cmd, args) {
+			// exported so plugin subprocesses refuse changes too
+			if err := os.Setenv(config.ReadOnlyEnv, "1"); err != nil {
+				return err
+			}
+		}
+		if err := requireReadOnlyAllowed(cmd, args); err != nil {
+			return err
+		}
+		assumeYes, err := cmd.Flags().GetBool("yes")
+		if err != nil {
+			return err
+		}
+		switch {
+		case assumeYes && cmd.Flags().Changed("yes"):
+			if err := os.Setenv(config.AssumeYesEnv, "1"); err != nil {
+				return err
+			}
+		case assumeYes && !config.AssumeYes():
+			// defaults.confirm: yes, which leaves typed-name confirmations on
+			if err := os.Setenv(config.AssumeYesEnv, config.AssumeYesFromConfig); err != nil {
+				return err
+			}
+		}
+		traceHTTP, err := cmd.Flags().GetBool("trace-http")
+		if err != nil {
+			return err
+		}
+		traceHTTPBodies, err := cmd.Flags().GetBool("trace-http-bodies")
+		if err != nil {
+			return err
+		}
+		switch {
+		case traceHTTPBodies:
+			if err := os.Setenv(httplog.TraceEnv, "bodies"); err != nil {
+				return err
+			}
+		case traceHTTP:
+			if err := os.Setenv(httplog.TraceEnv, "1"); err != nil {
+				return err
+			}
+		}
+		profileCLI, err := cmd.Flags().GetBool("profile-cli")
+		if err != nil {
+			return err
+		}
+		if profileCLI {
+			if err := os.Setenv(profile.Env, "1"); err != nil {
+				return err
+			}
+		}
+		apiURL, err := cmd.Flags().GetString("api-url")
+		if err != nil {
+			return err
+		}
+		// set-context defines its own --api-url that pins the URL on the
+		// context instead of overriding it for this invocation.
+		if apiURL = strings.TrimSpace(apiURL); apiURL != "" && cmd.Flags().Lookup("api-url") == cmd.Root().PersistentFlags().Lookup("api-url") {
+			if err := config.ValidateAPIURL(apiURL); err != nil {
+				return err
+			}
+			if err := os.Setenv(config.APIURLEnv, apiURL); err != nil {
+				return err
+			}
+		}
+		notifyAfter, err := cmd.Flags().GetDuration("notify-after")
+		if err != nil {
+			return err
+		}
+		if cmd.Flags().Changed("notify-after") {
+			if err := os.Setenv(notify.Env, notifyAfter.String()); err != nil {
+				return err
+			}
+		}
+		notifyCommand, notifyStarted = notifyCommandName(cmd, args), time.Now()
+		lang, err := cmd.Flags().GetString("lang")
+		if err != nil {
+			return err
+		}
+		if lang = strings.TrimSpace(lang); lang != "" {
+			if !i18n.Supported(lang) {
+				return fmt.Errorf("invalid argument %q for \"--lang\": supported languages are en and es", lang)
+			}
+			if err := os.Setenv(i18n.LangEnv, lang); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if config.NonInteractive() {
+			return fmt.Errorf("the interactive dashboard is unavailable: %w; run a subcommand instead", config.ErrNonInteractive)
+		}
 		return tui.Run()
 	},
 }
 
+// The command whose completion is notified, and when it started. Both are
+// set in PersistentPreRunE; notifyCommand stays empty for commands without
+// the notify annotation.
+var (
+	notifyCommand string
+	notifyStarted time.Time
+)
+
+// notifyCommandName returns the name to notify for cmd, such as "deploy" or
+// "compose build", or "" when the invocation is not annotated as long-running.
+func notifyCommandName(cmd *cobra.Command, args []string) string {
+	qualifying, ok := cmd.Annotations[notify.Annotation]
+	if !ok {
+		return ""
+	}
+	name := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	if qualifying == "" {
+		return name
+	}
+	for _, arg := range args {
+		if slices.Contains(strings.Fields(qualifying), arg) {
+			return name + " " + arg
+		}
+	}
+	return ""
+}
+
 func Execute() {
 	runCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
+	if cfg, err := config.Load(); err == nil {
+		if err := applyConfigDefaults(RootCmd, cfg.Defaults); err != nil {
+			fmt.Fprintf(os.Stderr, "sitectl: ignoring config defaults: %v\n", err)
+		}
+	}
 	err := fang.Execute(
 		runCtx,
 		RootCmd,
 		fang.WithVersion(RootCmd.Version),
+		fang.WithErrorHandler(handleCommandError),
 	)
+	_ = config.CloseSSHClients()
+	profile.Report(os.Stderr)
+	if notifyCommand != "" {
+		notify.Finished(notifyCommand, time.Since(notifyStarted), err)
+	}
 	if err != nil {
-		os.Exit(1)
+		os.Exit(exitStatusForError(err))
+	}
+}
+
+// applyConfigDefaults replaces the built-in defaults of the flags the
+// defaults: section covers before flags are parsed, so passed flags still
+// override them. A default --format only applies to commands that declare
+// that format with declareFormats.
+func applyConfigDefaults(root *cobra.Command, defaults config.Defaults) error {
+	if err := defaults.Validate(); err != nil {
+		return err
+	}
+	switch strings.TrimSpace(defaults.Color) {
+	case config.ColorNever:
+		if err := setEnvDefault("NO_COLOR", "1"); err != nil {
+			return err
+		}
+	case config.ColorAlways:
+		if err := setEnvDefault("CLICOLOR_FORCE", "1"); err != nil {
+			return err
+		}
+	}
+	if strings.TrimSpace(defaults.Confirm) == config.ConfirmYes {
+		if err := setFlagDefault(root.PersistentFlags().Lookup("yes"), "true"); err != nil {
+			return err
+		}
+	}
+	if defaults.ReadOnly {
+		if err := setFlagDefault(root.PersistentFlags().Lookup("read-only"), "true"); err != nil {
+			return err
+		}
+	}
+	if format := strings.TrimSpace(defaults.Format); format != "" {
+		var walk func(*cobra.Command) error
+		walk = func(cmd *cobra.Command) error {
+			if flag := cmd.Flags().Lookup("format"); flag != nil && slices.Contains(flag.Annotations[formatsAnnotation], format) {
+				if err := setFlagDefault(flag, format); err != nil {
+					return fmt.Errorf("%s --format: %w", cmd.CommandPath(), err)
+				}
+			}
+			for _, child := range cmd.Commands() {
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return walk(root)
+	}
+	return nil
+}
+
+// formatsAnnotation is the --format flag annotation that lists the named
+// formats a command accepts, so defaults.format only reaches commands that
+// support it.
+const formatsAnnotation = "sitectl_formats"
+
+// declareFormats records the named formats the --format flag of cmd accepts.
+func declareFormats(cmd *cobra.Command, formats ...string) {
+	if err := cmd.Flags().SetAnnotation("format", formatsAnnotation, formats); err != nil {
+		panic(err)
+	}
+}
+
+func setFlagDefault(flag *pflag.Flag, value string) error {
+	if flag == nil {
+		return nil
+	}
+	if err := flag.Value.Set(value); err != nil {
+		return err
+	}
+	flag.DefValue = value
+	return nil
+}
+
+// setEnvDefault sets an environment variable the user has not set.
+func setEnvDefault(name, value string) error {
+	if _, ok := os.LookupEnv(name); ok {
+		return nil
 	}
+	return os.Setenv(name, value)
 }
 
 func SetVersionInfo(version, commit, date string) {
@@ -81,6 +313,16 @@ func init() {
 
 	RootCmd.PersistentFlags().String("context", "", "The sitectl context to use. See sitectl config --help for more info")
 	RootCmd.PersistentFlags().String("log-level", ll, "The logging level for the command")
+	RootCmd.PersistentFlags().String("error-format", "text", "How to print errors on stderr: text or json")
+	RootCmd.PersistentFlags().Bool("yes", false, "Answer yes to every confirmation prompt of destructive operations; each auto-confirmation is logged")
+	RootCmd.PersistentFlags().Bool("trace-http", false, "Log method, URL, status and latency of every Docker API and HTTP request to stderr, with credentials redacted")
+	RootCmd.PersistentFlags().Bool("trace-http-bodies", false, "Like --trace-http, and also log headers and small JSON or text bodies, with credentials redacted")
+	RootCmd.PersistentFlags().Bool("profile-cli", false, "Print a timing breakdown of config loading, SSH dials, Docker API calls, remote commands, plugin RPCs and formatting to stderr when the command finishes")
+	RootCmd.PersistentFlags().String("api-url", "", "libops API endpoint for this invocation, overriding the api-url pinned on the context")
+	RootCmd.PersistentFlags().Duration("notify-after", 0, "Show a desktop notification when a build, database sync, deploy or wait runs longer than this, e.g. 2m (also SITECTL_NOTIFY_AFTER; 0 disables)")
+	RootCmd.PersistentFlags().String("lang", "", "Language for prompts and messages: en or es (default: from SITECTL_LANG, LC_ALL, LC_MESSAGES or LANG)")
+	RootCmd.PersistentFlags().Bool("non-interactive", false, "Fail instead of prompting for input (also enabled by SITECTL_NON_INTERACTIVE=1 or CI=true)")
+	RootCmd.PersistentFlags().Bool("read-only", false, "Refuse every command that can change a site, for demos, screen-shares and untrusted scripts (also enabled by SITECTL_READ_ONLY=1)")
 
 	RootCmd.AddGroup(
 		&cobra.Group{ID: "setup", Title: "Setup:"},
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func TestNotifyCommandName(t *testing.T) {
+	db, _, err := RootCmd.Find([]string{"db", "pull"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "deploy", want: "deploy"},
+		{name: "compose", args: []string{"--context", "prod", "build", "drupal"}, want: "compose build"},
+		{name: "compose", args: []string{"logs", "-f"}, want: ""},
+		{name: "config", want: ""},
+	} {
+		cmd, _, err := RootCmd.Find([]string{tc.name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := notifyCommandName(cmd, tc.args); got != tc.want {
+			t.Errorf("notifyCommandName(%s %q) = %q, want %q", tc.name, tc.args, got, tc.want)
+		}
+	}
+	if got := notifyCommandName(db, nil); got != "db pull" {
+		t.Errorf("notifyCommandName(db pull) = %q", got)
+	}
+}
+
+func TestApplyConfigDefaults(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	if err := os.Unsetenv("NO_COLOR"); err != nil {
+		t.Fatal(err)
+	}
+
+	root := &cobra.Command{Use: "sitectl"}
+	root.PersistentFlags().Bool("yes", false, "")
+	root.PersistentFlags().Bool("read-only", false, "")
+	env := &cobra.Command{Use: "env"}
+	env.Flags().String("format", "table", "Output format: table or json")
+	declareFormats(env, "table", "json")
+	stats := &cobra.Command{Use: "stats"}
+	stats.Flags().String("format", "json", "Output format. Only json is supported.")
+	declareFormats(stats, "json")
+	describe := &cobra.Command{Use: "describe"}
+	describe.Flags().String("format", "", "Output format, such as json (default: table).")
+	root.AddCommand(env, stats, describe)
+
+	if err := applyConfigDefaults(root, config.Defaults{Format: "json", Color: config.ColorNever, Confirm: config.ConfirmYes, ReadOnly: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := env.Flags().Lookup("format"); got.Value.String() != "json" || got.DefValue != "json" || got.Changed {
+		t.Errorf("env --format = %q (default %q, changed %v)", got.Value, got.DefValue, got.Changed)
+	}
+	if got := describe.Flags().Lookup("format").Value.String(); got != "" {
+		t.Errorf("describe --format = %q, want it untouched without declared formats", got)
+	}
+	if yes, _ := root.PersistentFlags().GetBool("yes"); !yes {
+		t.Error("confirm: yes did not default --yes to true")
+	}
+	if readOnly, _ := root.PersistentFlags().GetBool("read-only"); !readOnly {
+		t.Error("read-only: true did not default --read-only to true")
+	}
+	if os.Getenv("NO_COLOR") != "1" {
+		t.Error("color: never did not set NO_COLOR")
+	}
+
+	// A passed flag still wins over the configured default.
+	if err := env.ParseFlags([]string{"--format", "table"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := env.Flags().GetString("format"); got != "table" {
+		t.Errorf("env --format table = %q", got)
+	}
+
+	if err := applyConfigDefaults(root, config.Defaults{Color: "sometimes"}); err == nil {
+		t.Error("expected an error for an invalid color")
+	}
+}
+
+func TestDeclaredFormatsAreAccepted(t *testing.T) {
+	var walk func(*cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		if flag := cmd.Flags().Lookup("format"); flag != nil {
+			for _, format := range flag.Annotations[formatsAnnotation] {
+				if !strings.Contains(flag.Usage, format) {
+					t.Errorf("%s --format declares %q, which its usage does not mention", cmd.CommandPath(), format)
+				}
+			}
+		}
+		for _, child := range cmd.Commands() {
+			walk(child)
+		}
+	}
+	walk(RootCmd)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+
+	"github.com/spf13/cobra"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run [flags] -- COMMAND [ARGS...]",
+	Args:  cobra.MinimumNArgs(1),
+	Short: "Run a shell command in the project directory of a context",
+	Long: `Run an arbitrary command in the project directory of the active context.
+
+Local contexts run the command on this machine. Remote contexts run it over SSH on the
+context host. A single argument is interpreted by sh -c so pipes and redirects work;
+multiple arguments are executed as-is without a shell.
+
+The exit status of the command becomes the exit status of sitectl.
+
+Examples:
+  sitectl run -- git status                        # Show the project checkout state
+  sitectl run -- 'docker compose ps | grep drupal' # Run a shell pipeline
+  sitectl run --sudo -- ls -la /var/lib/docker     # Run as root on the context host
+  sitectl run --no-tty -- cat .env > prod.env      # Script-friendly output capture
+  sitectl run --context prod -- df -h              # Run on a specific context`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+		useSudo, err := cmd.Flags().GetBool("sudo")
+		if err != nil {
+			return err
+		}
+		noTTY, err := cmd.Flags().GetBool("no-tty")
+		if err != nil {
+			return err
+		}
+
+		commandArgs := runCommandArgs(args, useSudo)
+		c := exec.Command(commandArgs[0], commandArgs[1:]...) // #nosec G204 -- the user explicitly asked sitectl to run this command.
+		c.Dir = ctx.ProjectDir
+		if noTTY {
+			_, err = ctx.RunNoTTYCommandContext(cmd.Context(), c)
+		} else {
+			_, err = ctx.RunCommandContext(cmd.Context(), c)
+		}
+		return commandExitStatusError(err)
+	},
+}
+
+// runCommandArgs builds the argv for `sitectl run`. A lone argument is treated
+// as a shell snippet so `sitectl run -- 'a | b'` behaves like ssh host 'a | b'.
+func runCommandArgs(args []string, useSudo bool) []string {
+	commandArgs := args
+	if len(args) == 1 {
+		commandArgs = []string{"sh", "-c", args[0]}
+	}
+	if useSudo {
+		commandArgs = append([]string{"sudo"}, commandArgs...)
+	}
+	return commandArgs
+}
+
+// exitStatusError carries the exit status of a command sitectl ran on behalf
+// of the user so Execute can propagate it as the process exit status.
+type exitStatusError struct {
+	status int
+	err    error
+}
+
+func (e *exitStatusError) Error() string {
+	return fmt.Sprintf("command exited with status %d", e.status)
+}
+
+func (e *exitStatusError) Unwrap() error {
+	return e.err
+}
+
+// commandExitStatusError converts local and SSH exit errors into an
+// exitStatusError. Other errors are returned unchanged.
+func commandExitStatusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var remoteErr interface{ ExitStatus() int }
+	if errors.As(err, &remoteErr) && remoteErr.ExitStatus() > 0 {
+		return &exitStatusError{status: remoteErr.ExitStatus(), err: err}
+	}
+	var localErr *exec.ExitError
+	if errors.As(err, &localErr) && localErr.ExitCode() > 0 {
+		return &exitStatusError{status: localErr.ExitCode(), err: err}
+	}
+	return err
+}
+
+func init() {
+	runCmd.GroupID = "ops"
+	runCmd.Flags().Bool("sudo", false, "Run the command with sudo")
+	runCmd.Flags().Bool("no-tty", false, "Do not allocate a pseudo-terminal on remote contexts; keeps stdout and stderr separate for scripting")
+	RootCmd.AddCommand(runCmd)
+}
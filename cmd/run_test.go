@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+type testRunExitError struct {
+	status int
+}
+
+func (e *testRunExitError) Error() string {
+	return fmt.Sprintf("Process exited with status %d", e.status)
+}
+
+func (e *testRunExitError) ExitStatus() int {
+	return e.status
+}
+
+func TestRunCommandArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		args    []string
+		useSudo bool
+		want    []string
+	}{
+		{name: "single argument uses shell", args: []string{"ls | wc -l"}, want: []string{"sh", "-c", "ls | wc -l"}},
+		{name: "multiple arguments exec directly", args: []string{"git", "status"}, want: []string{"git", "status"}},
+		{name: "sudo prefixes shell", args: []string{"id -u"}, useSudo: true, want: []string{"sudo", "sh", "-c", "id -u"}},
+		{name: "sudo prefixes argv", args: []string{"ls", "-la"}, useSudo: true, want: []string{"sudo", "ls", "-la"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := runCommandArgs(tc.args, tc.useSudo); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("runCommandArgs() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCommandExitStatusErrorPropagatesRemoteStatus(t *testing.T) {
+	t.Parallel()
+
+	err := commandExitStatusError(fmt.Errorf("error waiting for remote command: %w", &testRunExitError{status: 3}))
+	if got := exitStatusForError(err); got != 3 {
+		t.Fatalf("exitStatusForError() = %d, want 3", got)
+	}
+}
+
+func TestCommandExitStatusErrorPropagatesLocalStatus(t *testing.T) {
+	t.Parallel()
+
+	runErr := exec.Command("sh", "-c", "exit 7").Run()
+	err := commandExitStatusError(fmt.Errorf("error waiting for command: %w", runErr))
+	if got := exitStatusForError(err); got != 7 {
+		t.Fatalf("exitStatusForError() = %d, want 7", got)
+	}
+}
+
+func TestCommandExitStatusErrorLeavesOtherErrors(t *testing.T) {
+	t.Parallel()
+
+	base := errors.New("error establishing SSH connection")
+	err := commandExitStatusError(base)
+	if err != base {
+		t.Fatalf("commandExitStatusError() = %v, want original error", err)
+	}
+	if got := exitStatusForError(err); got != 1 {
+		t.Fatalf("exitStatusForError() = %d, want 1", got)
+	}
+}
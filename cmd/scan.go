@@ -0,0 +1,416 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/httplog"
+	"github.com/libops/sitectl/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+// Checks reported by sitectl scan.
+const (
+	scanCheckSecret     = "secret"
+	scanCheckKeyFile    = "key-permissions"
+	scanCheckTrackedEnv = "tracked-env"
+)
+
+const (
+	scanMaxFileSize = 1024 * 1024
+	// scanMinEntropy is the Shannon entropy, in bits per character, above
+	// which a credential-named value looks like a real secret rather than a
+	// placeholder such as "changeme".
+	scanMinEntropy     = 3.5
+	scanMinSecretChars = 16
+)
+
+// scanFinding is one potential leak found in a project directory.
+type scanFinding struct {
+	Check  string `json:"check"`
+	Path   string `json:"path"`
+	Line   int    `json:"line,omitempty"`
+	Detail string `json:"detail"`
+}
+
+type scanPattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+var scanPatterns = []scanPattern{
+	{name: "private key", re: regexp.MustCompile(`-----BEGIN ((RSA|EC|DSA|OPENSSH|ENCRYPTED|PGP) )?PRIVATE KEY( BLOCK)?-----`)},
+	{name: "AWS access key", re: regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{name: "GitHub token", re: regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{name: "Slack token", re: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`)},
+	{name: "Stripe live key", re: regexp.MustCompile(`\b[rs]k_live_[A-Za-z0-9]{20,}\b`)},
+	{name: "Google API key", re: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+}
+
+// scanAssignment matches NAME=value and name: value lines in env, YAML, shell
+// and PHP-style config files.
+var scanAssignment = regexp.MustCompile(`^\s*(?:export\s+)?["'$]?([A-Za-z0-9_.-]+)["']?\s*(?:=>|[:=])\s*["']?([^\s"',;#]+)`)
+
+var (
+	// scanSkipDirs are never descended into when walking a project.
+	scanSkipDirs = []string{".git", "node_modules", "vendor"}
+	// scanSkipFiles hold checksums and hashes that look random by design.
+	scanSkipFiles = []string{"go.sum", "package-lock.json", "yarn.lock", "pnpm-lock.yaml", "composer.lock", "Cargo.lock"}
+	// scanKeyExtensions and scanKeyNames identify private key material.
+	scanKeyExtensions = []string{".pem", ".key", ".p12", ".pfx", ".jks"}
+	scanKeyNames      = []string{"id_rsa", "id_dsa", "id_ecdsa", "id_ed25519"}
+	// scanEnvTemplates are env file suffixes meant to be committed.
+	scanEnvTemplates = []string{".example", ".sample", ".dist", ".template", ".tmpl", ".defaults"}
+)
+
+var scanInput = config.GetInput
+
+var scanCmd = &cobra.Command{
+	Use:   "scan [PATH]",
+	Short: "Check a project for committed secrets and exposed key files",
+	Long: `Check a project directory for credentials that should not be deployed.
+
+sitectl scan reports:
+  - secrets in files tracked by git (or every file outside a git checkout): private keys,
+    cloud and API tokens, and high-entropy values assigned to credential-like names
+  - private key files and files under secrets/ that are readable by every user
+  - .env files tracked by git (.env.example and similar templates are allowed)
+
+PATH defaults to the project directory of a local context, or the current directory for a
+remote context. The command exits non-zero when anything is found, so it can gate CI.
+
+sitectl deploy and sitectl compose up run the same scan before touching a production
+context (environment prod, production or live) and ask for confirmation when it finds
+anything. Pass --skip-scan or --yes to proceed without asking.
+
+Examples:
+  sitectl scan
+  sitectl scan ./my-site --format json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		if format != "table" && format != "json" {
+			return fmt.Errorf("unsupported format %q: use table or json", format)
+		}
+		dir := ""
+		if len(args) > 0 {
+			dir = args[0]
+		} else {
+			ctx, err := resolveCurrentContext(cmd)
+			if err != nil {
+				return err
+			}
+			if dir = scanTargetDir(ctx); dir == "" {
+				return fmt.Errorf("no project directory to scan; pass PATH")
+			}
+		}
+
+		findings, err := scanProject(cmd.Context(), dir)
+		if err != nil {
+			return err
+		}
+		if format == "json" {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(findings); err != nil {
+				return err
+			}
+		} else if len(findings) == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "No secrets or exposed key files found in %s\n", dir)
+		} else if err := writeScanFindings(cmd.OutOrStdout(), findings); err != nil {
+			return err
+		}
+		if len(findings) > 0 {
+			return fmt.Errorf("scan found %d potential secret leak(s) in %s", len(findings), dir)
+		}
+		return nil
+	},
+}
+
+// scanTargetDir returns the directory scanned for ctx: the project directory
+// of a local context, or the current directory when it is a git checkout,
+// since a remote context deploys what was pushed from there.
+func scanTargetDir(ctx *config.Context) string {
+	if ctx.DockerHostType == config.ContextLocal {
+		return ctx.ProjectDir
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	if _, err := os.Stat(filepath.Join(wd, ".git")); err != nil {
+		return ""
+	}
+	return wd
+}
+
+// scanProject runs every scan check on dir. Content and tracked .env checks
+// use the files git tracks when dir is a checkout, and every file otherwise.
+func scanProject(runCtx context.Context, dir string) ([]scanFinding, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	walked, err := scanWalk(dir)
+	if err != nil {
+		return nil, err
+	}
+	files, tracked := scanGitFiles(runCtx, dir)
+	if !tracked {
+		files = walked
+	}
+
+	findings := []scanFinding{}
+	if tracked {
+		findings = append(findings, scanTrackedEnvFiles(files)...)
+	}
+	for _, rel := range files {
+		fileFindings, err := scanFileContents(dir, rel)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, fileFindings...)
+	}
+	findings = append(findings, scanKeyPermissions(dir, walked)...)
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Path != findings[j].Path {
+			return findings[i].Path < findings[j].Path
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	return findings, nil
+}
+
+// scanGitFiles lists the files git tracks in dir, relative to dir. It reports
+// false when dir is not inside a git checkout or git is unavailable.
+func scanGitFiles(runCtx context.Context, dir string) ([]string, bool) {
+	out, err := exec.CommandContext(runCtx, "git", "-C", dir, "ls-files", "-z").Output() // #nosec G204 -- git args are fixed and dir is passed as a single argument.
+	if err != nil {
+		return nil, false
+	}
+	files := []string{}
+	for _, name := range strings.Split(string(out), "\x00") {
+		if name != "" {
+			files = append(files, filepath.FromSlash(name))
+		}
+	}
+	return files, true
+}
+
+func scanWalk(dir string) ([]string, error) {
+	files := []string{}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != dir && slices.Contains(scanSkipDirs, entry.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, rel)
+		return nil
+	})
+	return files, err
+}
+
+func scanTrackedEnvFiles(files []string) []scanFinding {
+	findings := []scanFinding{}
+	for _, rel := range files {
+		name := filepath.Base(rel)
+		if name != ".env" && !strings.HasPrefix(name, ".env.") {
+			continue
+		}
+		if slices.ContainsFunc(scanEnvTemplates, func(suffix string) bool { return strings.HasSuffix(name, suffix) }) {
+			continue
+		}
+		findings = append(findings, scanFinding{Check: scanCheckTrackedEnv, Path: rel, Detail: "env file is tracked by git; run git rm --cached " + rel})
+	}
+	return findings
+}
+
+func scanFileContents(dir, rel string) ([]scanFinding, error) {
+	if slices.Contains(scanSkipFiles, filepath.Base(rel)) {
+		return nil, nil
+	}
+	path := filepath.Join(dir, rel)
+	info, err := os.Stat(path)
+	if err != nil {
+		// Tracked files deleted from the working tree are not deployed.
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !info.Mode().IsRegular() || info.Size() > scanMaxFileSize {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path) // #nosec G304 -- path is a file inside the scanned project directory.
+	if err != nil {
+		return nil, err
+	}
+	if bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+		return nil, nil
+	}
+	findings := []scanFinding{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), scanMaxFileSize)
+	for line := 1; scanner.Scan(); line++ {
+		if detail := scanLine(scanner.Text()); detail != "" {
+			findings = append(findings, scanFinding{Check: scanCheckSecret, Path: rel, Line: line, Detail: detail})
+		}
+	}
+	return findings, scanner.Err()
+}
+
+// scanLine describes the secret found on line, or returns "" when the line
+// looks clean. Matched values are abbreviated so the report does not repeat
+// the secret.
+func scanLine(line string) string {
+	for _, pattern := range scanPatterns {
+		if match := pattern.re.FindString(line); match != "" {
+			return fmt.Sprintf("%s (%s)", pattern.name, abbreviateSecret(match))
+		}
+	}
+	match := scanAssignment.FindStringSubmatch(line)
+	if match == nil || !httplog.SensitiveName(match[1]) {
+		return ""
+	}
+	value := match[2]
+	if len(value) < scanMinSecretChars || strings.HasPrefix(value, "$") || strings.HasPrefix(value, "{{") || strings.HasPrefix(value, "/run/secrets/") {
+		return ""
+	}
+	if shannonEntropy(value) < scanMinEntropy {
+		return ""
+	}
+	return fmt.Sprintf("high-entropy value for %s (%s)", match[1], abbreviateSecret(value))
+}
+
+func abbreviateSecret(value string) string {
+	if len(value) <= 8 {
+		return "…"
+	}
+	return value[:4] + "…"
+}
+
+// shannonEntropy returns the entropy of value in bits per character.
+func shannonEntropy(value string) float64 {
+	counts := map[rune]int{}
+	total := 0
+	for _, r := range value {
+		counts[r]++
+		total++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+func scanKeyPermissions(dir string, files []string) []scanFinding {
+	findings := []scanFinding{}
+	for _, rel := range files {
+		name := filepath.Base(rel)
+		isKey := slices.Contains(scanKeyExtensions, strings.ToLower(filepath.Ext(name))) ||
+			slices.Contains(scanKeyNames, name) ||
+			strings.HasPrefix(filepath.ToSlash(rel), "secrets/")
+		if !isKey {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(dir, rel))
+		if err != nil || info.Mode().Perm()&0o004 == 0 {
+			continue
+		}
+		findings = append(findings, scanFinding{
+			Check:  scanCheckKeyFile,
+			Path:   rel,
+			Detail: fmt.Sprintf("readable by every user (mode %04o); run chmod 600 %s", info.Mode().Perm(), rel),
+		})
+	}
+	return findings
+}
+
+func writeScanFindings(out io.Writer, findings []scanFinding) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tLOCATION\tDETAIL")
+	for _, finding := range findings {
+		location := finding.Path
+		if finding.Line > 0 {
+			location = fmt.Sprintf("%s:%d", finding.Path, finding.Line)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", finding.Check, location, finding.Detail)
+	}
+	return tw.Flush()
+}
+
+// confirmProductionScan scans the project before action touches a production
+// context and asks for confirmation when the scan finds anything. skip
+// bypasses the prompt, like --yes.
+func confirmProductionScan(cmd *cobra.Command, ctx *config.Context, action string, skip bool) error {
+	if !ctx.IsProduction() {
+		return nil
+	}
+	dir := scanTargetDir(ctx)
+	if dir == "" {
+		return nil
+	}
+	findings, err := scanProject(cmd.Context(), dir)
+	if err != nil {
+		return fmt.Errorf("pre-deploy scan: %w", err)
+	}
+	if len(findings) == 0 {
+		return nil
+	}
+	if err := writeScanFindings(cmd.ErrOrStderr(), findings); err != nil {
+		return err
+	}
+	if config.AutoConfirm(fmt.Sprintf("%s on production context %q despite %d scan finding(s)", action, ctx.Name, len(findings)), skip) {
+		return nil
+	}
+	input, err := scanInput(i18n.T(i18n.ScanProductionWarning, len(findings), dir, ctx.Name), i18n.T(i18n.ContinuePrompt))
+	if err != nil {
+		return err
+	}
+	if !i18n.IsYes(input) {
+		return fmt.Errorf("%s cancelled after scan findings; fix them or pass --skip-scan", action)
+	}
+	return nil
+}
+
+func init() {
+	scanCmd.Flags().String("format", "table", "Output format: table or json")
+	declareFormats(scanCmd, "table", "json")
+	scanCmd.GroupID = "troubleshoot"
+	RootCmd.AddCommand(scanCmd)
+}
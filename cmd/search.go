@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/docker"
+	"github.com/libops/sitectl/pkg/healthcheck"
+	"github.com/spf13/cobra"
+)
+
+// searchBackend describes where a search engine serves its admin UI.
+type searchBackend struct {
+	name string
+	port int
+	path string
+}
+
+// searchBackends are checked in order; dashboards win over the bare
+// OpenSearch API because they are the UI people actually want to open.
+var searchBackends = []searchBackend{
+	{name: "solr", port: 8983, path: "/solr/"},
+	{name: "opensearch-dashboards", port: 5601, path: "/"},
+	{name: "opensearch", port: 9200, path: "/_cat/indices?v"},
+}
+
+func searchCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "search",
+		Short:   "Work with the search index (Solr or OpenSearch) of a context",
+		GroupID: "workflow",
+	}
+	cmd.AddCommand(
+		searchUICommand(),
+		searchReindexCommand(),
+	)
+	return cmd
+}
+
+func searchUICommand() *cobra.Command {
+	opts := struct {
+		localPort int
+		noOpen    bool
+	}{}
+	cmd := &cobra.Command{
+		Use:   "ui",
+		Short: "Open the Solr or OpenSearch admin UI",
+		Long: `Open the admin UI of the search service in the active context.
+
+sitectl looks for a running solr, opensearch-dashboards or opensearch service (by service
+name or image). Local contexts that publish the admin port are opened directly; otherwise
+a local port is forwarded to the service, over SSH for remote contexts, until Ctrl+C.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := resolveCurrentContext(cmd)
+			if err != nil {
+				return err
+			}
+			cli, err := docker.GetDockerCli(ctx)
+			if err != nil {
+				return err
+			}
+			names := make([]string, 0, len(searchBackends))
+			for _, backend := range searchBackends {
+				names = append(names, backend.name)
+			}
+			service, err := detectComposeService(cmd.Context(), cli.CLI, ctx, names...)
+			_ = cli.Close()
+			if err != nil {
+				return err
+			}
+			backend := searchBackendForService(service)
+			return openServiceUI(cmd, ctx, service, backend.port, opts.localPort, backend.path, opts.noOpen)
+		},
+	}
+	cmd.Flags().IntVar(&opts.localPort, "local-port", 0, "Local port for the tunnel (defaults to the service's admin port)")
+	cmd.Flags().BoolVar(&opts.noOpen, "no-open", false, "Print the UI URL without opening a browser")
+	return cmd
+}
+
+func searchReindexCommand() *cobra.Command {
+	opts := struct {
+		clear bool
+	}{}
+	cmd := &cobra.Command{
+		Use:   "reindex [INDEX...]",
+		Short: "Queue every item for reindexing and index it",
+		Long: `Reindex search content through the application container.
+
+For Drupal contexts this runs drush search-api:reset-tracker followed by
+search-api:index for the given Search API indexes, or every index when none are named.
+Use --clear to delete the indexed documents first instead of only resetting the tracker.
+
+Examples:
+  sitectl search reindex                   # Reindex all Search API indexes
+  sitectl search reindex default_solr_index
+  sitectl search reindex --clear --context stage`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := resolveCurrentContext(cmd)
+			if err != nil {
+				return err
+			}
+			if !isDrupalContext(ctx) {
+				return fmt.Errorf("search reindex supports Drupal contexts; context %q uses plugin %q", ctx.Name, ctx.Plugin)
+			}
+			uri := healthcheck.PublicURLFromEnv(ctx, "", "")
+			for _, step := range searchReindexDrushSteps(args, opts.clear) {
+				if err := runInServiceContainer(cmd.Context(), ctx, defaultIngressAppService(ctx), ctx.EffectiveDrupalContainerRoot(), drushCommandArgs(step, uri)); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&opts.clear, "clear", false, "Delete all indexed documents before reindexing")
+	return cmd
+}
+
+func searchBackendForService(service detectedService) searchBackend {
+	for _, backend := range searchBackends {
+		if service.Service == backend.name || imageMatches(service.Image, backend.name) {
+			return backend
+		}
+	}
+	return searchBackends[0]
+}
+
+// searchReindexDrushSteps returns the drush invocations that mark indexes
+// for reindexing and then index them.
+func searchReindexDrushSteps(indexes []string, clear bool) [][]string {
+	reset := "search-api:reset-tracker"
+	if clear {
+		reset = "search-api:clear"
+	}
+	return [][]string{
+		append([]string{reset}, indexes...),
+		append([]string{"search-api:index"}, indexes...),
+	}
+}
+
+func isDrupalContext(ctx *config.Context) bool {
+	switch strings.TrimSpace(ctx.Plugin) {
+	case "drupal", "isle":
+		return true
+	}
+	return false
+}
+
+func init() {
+	RootCmd.AddCommand(searchCommand())
+}
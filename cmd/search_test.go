@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearchReindexDrushSteps(t *testing.T) {
+	t.Parallel()
+
+	got := searchReindexDrushSteps(nil, false)
+	want := [][]string{{"search-api:reset-tracker"}, {"search-api:index"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("searchReindexDrushSteps() = %v, want %v", got, want)
+	}
+
+	got = searchReindexDrushSteps([]string{"default_solr_index"}, true)
+	want = [][]string{{"search-api:clear", "default_solr_index"}, {"search-api:index", "default_solr_index"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("searchReindexDrushSteps(clear) = %v, want %v", got, want)
+	}
+}
+
+func TestSearchBackendForService(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		service detectedService
+		want    int
+	}{
+		{service: detectedService{Service: "solr"}, want: 8983},
+		{service: detectedService{Service: "search", Image: "opensearchproject/opensearch:2"}, want: 9200},
+		{service: detectedService{Service: "opensearch-dashboards"}, want: 5601},
+	}
+	for _, tc := range tests {
+		if got := searchBackendForService(tc.service); got.port != tc.want {
+			t.Errorf("searchBackendForService(%+v).port = %d, want %d", tc.service, got.port, tc.want)
+		}
+	}
+}
@@ -16,6 +16,7 @@ func registerCoreServiceCommands() {
 		solrCommand(),
 		valkeyCommand(),
 		memcachedCommand(),
+		redisCLICommand(),
 	)
 }
 
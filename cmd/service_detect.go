@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/docker"
+)
+
+// detectedService is a running Compose service matched by name or image.
+type detectedService struct {
+	Service       string
+	ContainerName string
+	Image         string
+	Ports         []dockercontainer.Port
+}
+
+// detectComposeService returns the first running service in the context's
+// Compose project whose service name or image matches one of candidates, in
+// candidate order. Matching the image catches projects that name the service
+// something generic like "mail" or "search".
+func detectComposeService(ctx context.Context, cli docker.DockerAPI, siteCtx *config.Context, candidates ...string) (detectedService, error) {
+	filterArgs := filters.NewArgs()
+	filterArgs.Add("label", "com.docker.compose.project="+siteCtx.EffectiveComposeProjectName())
+	containers, err := cli.ContainerList(ctx, dockercontainer.ListOptions{Filters: filterArgs})
+	if err != nil {
+		return detectedService{}, fmt.Errorf("list compose containers: %w", err)
+	}
+	for _, candidate := range candidates {
+		for _, container := range containers {
+			service := container.Labels["com.docker.compose.service"]
+			if service != candidate && !imageMatches(container.Image, candidate) {
+				continue
+			}
+			name := ""
+			if len(container.Names) > 0 {
+				name = strings.TrimPrefix(container.Names[0], "/")
+			}
+			return detectedService{
+				Service:       service,
+				ContainerName: name,
+				Image:         container.Image,
+				Ports:         container.Ports,
+			}, nil
+		}
+	}
+	return detectedService{}, fmt.Errorf("no running %s service found in compose project %q", strings.Join(candidates, " or "), siteCtx.EffectiveComposeProjectName())
+}
+
+// imageMatches reports whether the repository name of image (without
+// registry, namespace or tag) equals name.
+func imageMatches(image, name string) bool {
+	repository := image
+	if index := strings.LastIndex(repository, "/"); index >= 0 {
+		repository = repository[index+1:]
+	}
+	if index := strings.IndexAny(repository, ":@"); index >= 0 {
+		repository = repository[:index]
+	}
+	return repository == name
+}
+
+// publishedHostPort returns the host port Docker published for privatePort.
+func (s detectedService) publishedHostPort(privatePort int) (int, bool) {
+	for _, port := range s.Ports {
+		if int(port.PrivatePort) == privatePort && port.PublicPort != 0 {
+			return int(port.PublicPort), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/libops/sitectl/pkg/config"
+)
+
+type fakeServiceDetectAPI struct {
+	containers []dockercontainer.Summary
+}
+
+func (f fakeServiceDetectAPI) ContainerInspect(context.Context, string) (dockercontainer.InspectResponse, error) {
+	return dockercontainer.InspectResponse{}, nil
+}
+
+func (f fakeServiceDetectAPI) ContainerList(context.Context, dockercontainer.ListOptions) ([]dockercontainer.Summary, error) {
+	return f.containers, nil
+}
+
+func TestDetectComposeServiceMatchesServiceNameOrImage(t *testing.T) {
+	t.Parallel()
+
+	api := fakeServiceDetectAPI{containers: []dockercontainer.Summary{
+		{
+			Names:  []string{"/site-drupal-1"},
+			Image:  "libops/drupal:main",
+			Labels: map[string]string{"com.docker.compose.service": "drupal"},
+		},
+		{
+			Names:  []string{"/site-mail-1"},
+			Image:  "docker.io/axllent/mailpit:v1.20",
+			Labels: map[string]string{"com.docker.compose.service": "mail"},
+			Ports:  []dockercontainer.Port{{PrivatePort: 8025, PublicPort: 18025}},
+		},
+	}}
+	ctx := &config.Context{ProjectName: "site"}
+
+	got, err := detectComposeService(context.Background(), api, ctx, "mailpit", "mailhog")
+	if err != nil {
+		t.Fatalf("detectComposeService() error = %v", err)
+	}
+	if got.Service != "mail" || got.ContainerName != "site-mail-1" {
+		t.Fatalf("detectComposeService() = %+v, want mail service", got)
+	}
+	if port, ok := got.publishedHostPort(8025); !ok || port != 18025 {
+		t.Fatalf("publishedHostPort() = %d, %v; want 18025, true", port, ok)
+	}
+
+	if _, err := detectComposeService(context.Background(), api, ctx, "opensearch"); err == nil {
+		t.Fatal("expected an error when no service matches")
+	}
+}
+
+func TestImageMatches(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		image string
+		name  string
+		want  bool
+	}{
+		{image: "mailhog/mailhog", name: "mailhog", want: true},
+		{image: "axllent/mailpit:latest", name: "mailpit", want: true},
+		{image: "registry.example.com/ops/solr@sha256:abc", name: "solr", want: true},
+		{image: "libops/solr-config", name: "solr", want: false},
+	}
+	for _, tc := range tests {
+		if got := imageMatches(tc.image, tc.name); got != tc.want {
+			t.Errorf("imageMatches(%q, %q) = %v, want %v", tc.image, tc.name, got, tc.want)
+		}
+	}
+}
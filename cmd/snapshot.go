@@ -0,0 +1,701 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/docker"
+	"github.com/libops/sitectl/pkg/i18n"
+	corejob "github.com/libops/sitectl/pkg/job"
+	"github.com/spf13/cobra"
+)
+
+const (
+	snapshotHelperImage   = "busybox:stable"
+	snapshotTimeFormat    = "20060102T150405Z"
+	snapshotArchiveSuffix = ".tar.gz"
+	snapshotManifestName  = "snapshot.json"
+	snapshotDatabaseName  = "database.sql.gz"
+	snapshotVolumesDir    = "volumes"
+	snapshotProjectDir    = "project"
+)
+
+// snapshotProjectPaths are copied from the project directory when present.
+var snapshotProjectPaths = []string{".env", "secrets"}
+
+// snapshotManifest is stored at the root of every snapshot archive.
+type snapshotManifest struct {
+	Context  string    `json:"context"`
+	Project  string    `json:"project"`
+	Created  time.Time `json:"created"`
+	Database bool      `json:"database"`
+	Volumes  []string  `json:"volumes"`
+	Files    []string  `json:"files"`
+}
+
+type snapshotInfo struct {
+	Name    string
+	Path    string
+	Created time.Time
+	Size    int64
+}
+
+func snapshotCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "snapshot",
+		Short:   "Capture and restore the state of a local context",
+		GroupID: "workflow",
+		Long: `Capture and restore the state of a local context.
+
+A snapshot is a timestamped archive under ~/.sitectl/snapshots/<context>/ that holds a dump of
+the MariaDB databases, the contents of every named volume of the Compose project (except the
+database volumes, which the dump covers), and the project's .env and secrets/ files.`,
+	}
+	cmd.AddCommand(
+		snapshotCreateCommand(),
+		snapshotListCommand(),
+		snapshotRestoreCommand(),
+	)
+	return cmd
+}
+
+func snapshotCreateCommand() *cobra.Command {
+	opts := struct {
+		service string
+	}{}
+	cmd := &cobra.Command{
+		Use:   "create [LABEL]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "Snapshot the database, volumes and env files of the active local context",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := snapshotContext(cmd)
+			if err != nil {
+				return err
+			}
+			label := ""
+			if len(args) == 1 {
+				label = sanitizeArtifactPart(args[0])
+			}
+			path, manifest, err := createSnapshot(cmd, ctx, opts.service, label, time.Now().UTC())
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), i18n.T(i18n.SnapshotCreated,
+				snapshotNameFromPath(path), manifest.Database, len(manifest.Volumes), len(manifest.Files)))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&opts.service, "service", defaultMariaDBService, "MariaDB compose service name")
+	return cmd
+}
+
+func snapshotListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Args:  cobra.NoArgs,
+		Short: "List snapshots of the active context",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := resolveCurrentContext(cmd)
+			if err != nil {
+				return err
+			}
+			dir, err := snapshotDir(ctx.Name)
+			if err != nil {
+				return err
+			}
+			snapshots, err := listSnapshots(dir)
+			if err != nil {
+				return err
+			}
+			if len(snapshots) == 0 {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), i18n.T(i18n.NoSnapshots, ctx.Name))
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tCREATED\tSIZE")
+			for _, snapshot := range snapshots {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", snapshot.Name, snapshot.Created.Local().Format(time.DateTime), formatKiB(snapshot.Size/1024))
+			}
+			return w.Flush()
+		},
+	}
+	enableOutputFile(cmd)
+	return cmd
+}
+
+func snapshotRestoreCommand() *cobra.Command {
+	opts := struct {
+		service string
+		yolo    bool
+	}{}
+	cmd := &cobra.Command{
+		Use:   "restore NAME",
+		Args:  cobra.ExactArgs(1),
+		Short: "Restore a snapshot into the active local context",
+		Long: `Restore a snapshot into the active local context.
+
+The project's .env and secrets files are overwritten, every captured volume is emptied and
+refilled, and the database dump is imported. Stop services that write to volumes first if
+you need a consistent restore.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := snapshotContext(cmd)
+			if err != nil {
+				return err
+			}
+			dir, err := snapshotDir(ctx.Name)
+			if err != nil {
+				return err
+			}
+			name := strings.TrimSuffix(strings.TrimSpace(args[0]), snapshotArchiveSuffix)
+			path := filepath.Join(dir, name+snapshotArchiveSuffix)
+			if filepath.Dir(path) != dir {
+				return fmt.Errorf("invalid snapshot name %q", args[0])
+			}
+			if _, err := os.Stat(path); err != nil {
+				return fmt.Errorf("snapshot %q not found for context %q: %w", name, ctx.Name, err)
+			}
+			if err := ctx.RequireUnprotected("restore a snapshot"); err != nil {
+				return err
+			}
+			if !config.AutoConfirmTyped(fmt.Sprintf("restore snapshot %s into context %q", name, ctx.Name), opts.yolo) {
+				if err := confirmSnapshotRestore(ctx.Name, name); err != nil {
+					return err
+				}
+			}
+			if err := restoreSnapshot(cmd, ctx, opts.service, path); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), i18n.T(i18n.SnapshotRestored, name, ctx.Name))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&opts.service, "service", defaultMariaDBService, "MariaDB compose service name")
+	cmd.Flags().BoolVar(&opts.yolo, "yolo", false, "Skip the confirmation prompt before restoring")
+	return cmd
+}
+
+func snapshotContext(cmd *cobra.Command) (*config.Context, error) {
+	ctx, err := resolveCurrentContext(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if ctx.DockerHostType != config.ContextLocal {
+		return nil, fmt.Errorf("snapshots require a local context; %q is %s", ctx.Name, ctx.DockerHostType)
+	}
+	if strings.TrimSpace(ctx.ProjectDir) == "" {
+		return nil, fmt.Errorf("context %q does not define a project directory", ctx.Name)
+	}
+	return ctx, nil
+}
+
+func snapshotDir(contextName string) (string, error) {
+	configPath, err := config.ConfigFilePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "snapshots", sanitizeArtifactPart(contextName)), nil
+}
+
+func snapshotNameFromPath(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), snapshotArchiveSuffix)
+}
+
+func createSnapshot(cmd *cobra.Command, ctx *config.Context, service, label string, now time.Time) (string, snapshotManifest, error) {
+	manifest := snapshotManifest{
+		Context: ctx.Name,
+		Project: ctx.EffectiveComposeProjectName(),
+		Created: now,
+	}
+	staging, cleanup, err := corejob.MakeTempWorkDir("sitectl-snapshot-*")
+	if err != nil {
+		return "", manifest, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer cleanup()
+
+	databaseVolumes, err := snapshotDatabase(cmd, ctx, service, filepath.Join(staging, snapshotDatabaseName))
+	if err != nil {
+		return "", manifest, err
+	}
+	manifest.Database = databaseVolumes != nil
+
+	api, closeAPI, err := volumeClient(ctx)
+	if err != nil {
+		return "", manifest, err
+	}
+	defer closeAPI()
+	volumes, err := composeProjectVolumes(cmd.Context(), api, manifest.Project)
+	if err != nil {
+		return "", manifest, err
+	}
+	volumesDir := filepath.Join(staging, snapshotVolumesDir)
+	if err := os.MkdirAll(volumesDir, 0o750); err != nil {
+		return "", manifest, err
+	}
+	for _, volume := range snapshotVolumes(volumes, databaseVolumes) {
+		if err := exportVolume(cmd.Context(), api, volume, filepath.Join(volumesDir, volume+".tar")); err != nil {
+			return "", manifest, err
+		}
+		manifest.Volumes = append(manifest.Volumes, volume)
+	}
+
+	for _, rel := range snapshotProjectPaths {
+		copied, err := copyTree(filepath.Join(ctx.ProjectDir, rel), filepath.Join(staging, snapshotProjectDir, rel))
+		if err != nil {
+			return "", manifest, err
+		}
+		if copied {
+			manifest.Files = append(manifest.Files, rel)
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", manifest, err
+	}
+	if err := os.WriteFile(filepath.Join(staging, snapshotManifestName), data, 0o600); err != nil {
+		return "", manifest, err
+	}
+
+	dir, err := snapshotDir(ctx.Name)
+	if err != nil {
+		return "", manifest, err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", manifest, fmt.Errorf("create snapshot directory: %w", err)
+	}
+	name := now.Format(snapshotTimeFormat)
+	if label != "" {
+		name += "-" + label
+	}
+	path := filepath.Join(dir, name+snapshotArchiveSuffix)
+	if err := writeTarGz(staging, path); err != nil {
+		return "", manifest, err
+	}
+	return path, manifest, nil
+}
+
+// snapshotDatabase dumps every MariaDB database into output and returns the
+// names of the volumes mounted into the database container. A nil slice means
+// the context has no running database service.
+func snapshotDatabase(cmd *cobra.Command, ctx *config.Context, service, output string) ([]string, error) {
+	cli, containerName, _, err := mariaDBContainer(cmd, ctx, service)
+	if err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Skipping database: %v\n", err)
+		return nil, nil
+	}
+	inspect, err := cli.CLI.ContainerInspect(cmd.Context(), containerName)
+	_ = cli.Close()
+	if err != nil {
+		return nil, fmt.Errorf("inspect %s container: %w", service, err)
+	}
+	volumes := []string{}
+	for _, mount := range inspect.Mounts {
+		if mount.Type == "volume" && mount.Name != "" {
+			volumes = append(volumes, mount.Name)
+		}
+	}
+
+	file, err := os.Create(output) // #nosec G304 -- output lives in a temp dir created by this process.
+	if err != nil {
+		return nil, err
+	}
+	err = writeMariaDBDump(cmd, ctx, mariaDBBackupOptions{service: service, compress: true}, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dump database: %w", err)
+	}
+	return volumes, nil
+}
+
+// composeProjectVolumes lists the volumes labelled as belonging to the compose
+// project.
+func composeProjectVolumes(ctx context.Context, api volumeAPI, project string) ([]string, error) {
+	list, err := api.VolumeList(ctx, volume.ListOptions{Filters: filters.NewArgs(filters.Arg("label", "com.docker.compose.project="+project))})
+	if err != nil {
+		return nil, fmt.Errorf("list volumes for project %s: %w", project, err)
+	}
+	names := make([]string, 0, len(list.Volumes))
+	for _, vol := range list.Volumes {
+		if vol != nil {
+			names = append(names, vol.Name)
+		}
+	}
+	return names, nil
+}
+
+// snapshotVolumes drops the database volumes, which are captured by the dump
+// instead of a file copy of a running database.
+func snapshotVolumes(volumes, skip []string) []string {
+	skipped := map[string]bool{}
+	for _, volume := range skip {
+		skipped[volume] = true
+	}
+	out := []string{}
+	for _, volume := range volumes {
+		if !skipped[volume] {
+			out = append(out, volume)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// exportVolume writes the contents of volume name to output as a tar archive
+// whose entries are relative to the volume root.
+func exportVolume(ctx context.Context, api volumeAPI, name, output string) error {
+	id, cleanup, err := createVolumeHelper(ctx, api, name, true, nil)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	archive, _, err := api.CopyFromContainer(ctx, id, volumeMountPath)
+	if err != nil {
+		return fmt.Errorf("read volume %s: %w", name, err)
+	}
+	defer archive.Close()
+
+	file, err := os.Create(output) // #nosec G304 -- output lives in a temp dir created by this process.
+	if err != nil {
+		return err
+	}
+	// CopyFromContainer nests everything under the mount point's base name
+	_, err = docker.FilterTar(archive, file, docker.TarFilter{RootName: "."})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("export volume %s: %w", name, err)
+	}
+	return nil
+}
+
+// importVolume empties volume name, creating it for the compose project when
+// missing, and extracts the tar archive written by exportVolume into it.
+func importVolume(ctx context.Context, api volumeAPI, project, name, input string) error {
+	if err := ensureVolume(ctx, api, project, name); err != nil {
+		return err
+	}
+	file, err := os.Open(input) // #nosec G304 -- input was extracted from the archive into a temp dir.
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	id, cleanup, err := emptyVolume(ctx, api, name)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if err := api.CopyToContainer(ctx, id, volumeMountPath, file, dockercontainer.CopyToContainerOptions{CopyUIDGID: true}); err != nil {
+		return fmt.Errorf("restore volume %s: %w", name, err)
+	}
+	return nil
+}
+
+func restoreSnapshot(cmd *cobra.Command, ctx *config.Context, service, path string) error {
+	staging, cleanup, err := corejob.MakeTempWorkDir("sitectl-snapshot-restore-*")
+	if err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
+	}
+	defer cleanup()
+	if err := extractTarGz(path, staging); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(filepath.Join(staging, snapshotManifestName)) // #nosec G304 -- manifest was extracted into a temp dir.
+	if err != nil {
+		return fmt.Errorf("read snapshot manifest: %w", err)
+	}
+	var manifest snapshotManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("parse snapshot manifest: %w", err)
+	}
+
+	// check every entry before writing anything
+	targets := make([]string, 0, len(manifest.Files))
+	for _, rel := range manifest.Files {
+		target, err := projectFilePath(ctx.ProjectDir, rel)
+		if err != nil {
+			return fmt.Errorf("snapshot %w", err)
+		}
+		targets = append(targets, target)
+	}
+	for _, volume := range manifest.Volumes {
+		if volume == "" || strings.ContainsAny(volume, `/\`) {
+			return fmt.Errorf("invalid volume name %q in snapshot manifest", volume)
+		}
+	}
+	for i, rel := range manifest.Files {
+		if _, err := copyTree(filepath.Join(staging, snapshotProjectDir, filepath.FromSlash(rel)), targets[i]); err != nil {
+			return err
+		}
+	}
+	if len(manifest.Volumes) > 0 {
+		api, closeAPI, err := volumeClient(ctx)
+		if err != nil {
+			return err
+		}
+		defer closeAPI()
+		for _, volume := range manifest.Volumes {
+			if err := importVolume(cmd.Context(), api, manifest.Project, volume, filepath.Join(staging, snapshotVolumesDir, volume+".tar")); err != nil {
+				return err
+			}
+		}
+	}
+	if manifest.Database {
+		if err := runMariaDBImport(cmd, ctx, mariaDBImportOptions{
+			service: service,
+			input:   filepath.Join(staging, snapshotDatabaseName),
+			yolo:    true,
+		}); err != nil {
+			return fmt.Errorf("restore database: %w", err)
+		}
+	}
+	return nil
+}
+
+// projectFilePath resolves rel, a project file from a snapshot or backup
+// manifest, inside projectDir, rejecting absolute paths and paths that escape
+// it.
+func projectFilePath(projectDir, rel string) (string, error) {
+	if rel == "" || filepath.IsAbs(filepath.FromSlash(rel)) || strings.HasPrefix(rel, "/") {
+		return "", fmt.Errorf("file %q is not a relative path", rel)
+	}
+	root := filepath.Clean(projectDir)
+	target := filepath.Join(root, filepath.FromSlash(rel))
+	if !strings.HasPrefix(target, root+string(os.PathSeparator)) {
+		return "", fmt.Errorf("file %q escapes the project directory", rel)
+	}
+	return target, nil
+}
+
+func confirmSnapshotRestore(contextName, name string) error {
+	token := "restore " + name
+	input, err := config.GetInput(
+		i18n.T(i18n.SnapshotRestoreWarning, contextName, name),
+		i18n.T(i18n.TypeToContinuePrompt, token),
+	)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(input) != token {
+		return fmt.Errorf("snapshot restore cancelled")
+	}
+	return nil
+}
+
+func listSnapshots(dir string) ([]snapshotInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	snapshots := []snapshotInfo{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), snapshotArchiveSuffix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(entry.Name(), snapshotArchiveSuffix)
+		stamp, _, _ := strings.Cut(name, "-")
+		created, err := time.Parse(snapshotTimeFormat, stamp)
+		if err != nil {
+			created = info.ModTime()
+		}
+		snapshots = append(snapshots, snapshotInfo{
+			Name:    name,
+			Path:    filepath.Join(dir, entry.Name()),
+			Created: created,
+			Size:    info.Size(),
+		})
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Created.After(snapshots[j].Created)
+	})
+	return snapshots, nil
+}
+
+// copyTree copies a file or directory from src to dst and reports whether src
+// existed.
+func copyTree(src, dst string) (bool, error) {
+	if _, err := os.Lstat(src); errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		default:
+			return nil
+		}
+	})
+	if err != nil {
+		return false, fmt.Errorf("copy %s: %w", src, err)
+	}
+	return true, nil
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return err
+	}
+	in, err := os.Open(src) // #nosec G304 -- src is a project or snapshot path selected by sitectl.
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode) // #nosec G304 -- dst is a project or snapshot path selected by sitectl.
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// writeTarGz archives the contents of dir into a gzip-compressed tar at
+// output. The archive is written next to output and renamed into place.
+func writeTarGz(dir, output string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(output), ".snapshot-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		_ = os.Remove(tmpPath)
+	}()
+
+	gz := gzip.NewWriter(tmp)
+	tw := tar.NewWriter(gz)
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		file, err := os.Open(path) // #nosec G304 -- path is inside the staging dir created by this process.
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(tw, file)
+		return err
+	})
+	if err == nil {
+		err = tw.Close()
+	}
+	if err == nil {
+		err = gz.Close()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("write snapshot archive: %w", err)
+	}
+	return os.Rename(tmpPath, output)
+}
+
+// extractTarGz unpacks a gzip-compressed tar into dir, rejecting entries that
+// would escape it.
+func extractTarGz(archive, dir string) error {
+	file, err := os.Open(archive) // #nosec G304 -- archive is a snapshot under ~/.sitectl/snapshots.
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("read snapshot archive: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read snapshot archive: %w", err)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if target != dir && !strings.HasPrefix(target, dir+string(os.PathSeparator)) {
+			return fmt.Errorf("snapshot archive entry %q escapes the extraction directory", header.Name)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o750); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(header.Mode).Perm()) // #nosec G304,G115 -- target is checked to stay under dir; mode comes from a sitectl-written archive.
+			if err != nil {
+				return err
+			}
+			_, copyErr := io.Copy(out, tr) // #nosec G110 -- snapshot archives are written by sitectl for the same user.
+			closeErr := out.Close()
+			if copyErr != nil {
+				return copyErr
+			}
+			if closeErr != nil {
+				return closeErr
+			}
+		}
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(snapshotCommand())
+}
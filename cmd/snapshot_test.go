@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/volume"
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func TestSnapshotVolumesSkipsDatabaseVolumes(t *testing.T) {
+	got := snapshotVolumes([]string{"site_solr-data", "site_mariadb-data", "site_drupal-files"}, []string{"site_mariadb-data"})
+	want := []string{"site_drupal-files", "site_solr-data"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("snapshotVolumes() = %v, want %v", got, want)
+	}
+}
+
+func TestSnapshotArchiveRoundTrip(t *testing.T) {
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, ".env"), []byte("DOMAIN=example.test\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(project, "secrets"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, "secrets", "DB_ROOT_PASSWORD"), []byte("hunter2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	staging := t.TempDir()
+	for _, rel := range []string{".env", "secrets", "missing"} {
+		copied, err := copyTree(filepath.Join(project, rel), filepath.Join(staging, snapshotProjectDir, rel))
+		if err != nil {
+			t.Fatalf("copyTree(%s) error = %v", rel, err)
+		}
+		if copied != (rel != "missing") {
+			t.Fatalf("copyTree(%s) copied = %v", rel, copied)
+		}
+	}
+
+	archive := filepath.Join(t.TempDir(), "20260102T030405Z"+snapshotArchiveSuffix)
+	if err := writeTarGz(staging, archive); err != nil {
+		t.Fatalf("writeTarGz() error = %v", err)
+	}
+	restored := t.TempDir()
+	if err := extractTarGz(archive, restored); err != nil {
+		t.Fatalf("extractTarGz() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(restored, snapshotProjectDir, "secrets", "DB_ROOT_PASSWORD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hunter2" {
+		t.Fatalf("restored secret = %q", data)
+	}
+	info, err := os.Stat(filepath.Join(restored, snapshotProjectDir, ".env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("restored .env mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestListSnapshotsNewestFirst(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"20260101T000000Z", "20260301T000000Z-before-upgrade", "20260201T000000Z"} {
+		if err := os.WriteFile(filepath.Join(dir, name+snapshotArchiveSuffix), []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snapshots, err := listSnapshots(dir)
+	if err != nil {
+		t.Fatalf("listSnapshots() error = %v", err)
+	}
+	var names []string
+	for _, snapshot := range snapshots {
+		names = append(names, snapshot.Name)
+	}
+	want := []string{"20260301T000000Z-before-upgrade", "20260201T000000Z", "20260101T000000Z"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("listSnapshots() = %v, want %v", names, want)
+	}
+
+	missing, err := listSnapshots(filepath.Join(dir, "missing"))
+	if err != nil || len(missing) != 0 {
+		t.Fatalf("listSnapshots(missing) = %v, %v", missing, err)
+	}
+}
+
+func TestRestoreSnapshotRejectsEscapingFiles(t *testing.T) {
+	for _, rel := range []string{"../evil", "/etc/evil"} {
+		t.Run(rel, func(t *testing.T) {
+			root := t.TempDir()
+			project := filepath.Join(root, "site")
+			staging := filepath.Join(root, "staging")
+			if err := os.MkdirAll(project, 0o700); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.MkdirAll(filepath.Join(staging, snapshotProjectDir), 0o700); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(staging, snapshotProjectDir, "evil"), []byte("pwned\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			manifest, err := json.Marshal(snapshotManifest{Project: "site", Files: []string{rel}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(staging, snapshotManifestName), manifest, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			archive := filepath.Join(root, "evil"+snapshotArchiveSuffix)
+			if err := writeTarGz(staging, archive); err != nil {
+				t.Fatal(err)
+			}
+
+			err = restoreSnapshot(&cobra.Command{}, &config.Context{ProjectDir: project}, "", archive)
+			if err == nil || !strings.Contains(err.Error(), rel) {
+				t.Fatalf("restoreSnapshot() error = %v, want it to reject %q", err, rel)
+			}
+			if _, err := os.Stat(filepath.Join(root, "evil")); !os.IsNotExist(err) {
+				t.Fatalf("file outside the project directory was written: %v", err)
+			}
+		})
+	}
+}
+
+func TestProjectFilePath(t *testing.T) {
+	project := t.TempDir()
+	got, err := projectFilePath(project, "drupal/web/sites/default/settings.php")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(project, "drupal", "web", "sites", "default", "settings.php"); got != want {
+		t.Errorf("projectFilePath() = %q, want %q", got, want)
+	}
+	for _, rel := range []string{"", ".", "..", "../evil", "a/../../evil", "/etc/passwd"} {
+		if _, err := projectFilePath(project, rel); err == nil {
+			t.Errorf("projectFilePath(%q) succeeded, want an error", rel)
+		}
+	}
+}
+
+func TestSnapshotVolumesThroughDockerAPI(t *testing.T) {
+	t.Parallel()
+
+	api := &fakeVolumeAPI{
+		volumes: map[string]volume.Volume{
+			"museum_solr-data": {Labels: map[string]string{"com.docker.compose.project": "museum"}},
+			"other_files":      {Labels: map[string]string{"com.docker.compose.project": "other"}},
+		},
+		archive: testTar(t, "data/", "data/cores/", "data/cores/core.properties"),
+	}
+	volumes, err := composeProjectVolumes(context.Background(), api, "museum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"museum_solr-data"}; !reflect.DeepEqual(volumes, want) {
+		t.Fatalf("composeProjectVolumes() = %v, want %v", volumes, want)
+	}
+
+	output := filepath.Join(t.TempDir(), "museum_solr-data.tar")
+	if err := exportVolume(context.Background(), api, "museum_solr-data", output); err != nil {
+		t.Fatal(err)
+	}
+	if len(api.created) != 1 || api.created[0].Binds[0] != "museum_solr-data:/data:ro" || api.removed != 1 {
+		t.Fatalf("export helper created = %+v, removed = %d", api.created, api.removed)
+	}
+	file, err := os.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	names := []string{}
+	tr := tar.NewReader(file)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, header.Name)
+	}
+	if want := []string{"./", "./cores/", "./cores/core.properties"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("exported entries = %q, want %q", names, want)
+	}
+
+	if err := importVolume(context.Background(), api, "museum", "museum_files", output); err != nil {
+		t.Fatal(err)
+	}
+	created := api.volumes["museum_files"]
+	if created.Labels["com.docker.compose.project"] != "museum" || created.Labels["com.docker.compose.volume"] != "files" {
+		t.Errorf("created volume labels = %v", created.Labels)
+	}
+	if api.created[1].Binds[0] != "museum_files:/data" || api.started != 1 || api.removed != 2 {
+		t.Errorf("import helper created = %+v, started = %d, removed = %d", api.created[1], api.started, api.removed)
+	}
+	if want := []string{"./", "./cores/", "./cores/core.properties"}; api.copiedTo != volumeMountPath || !reflect.DeepEqual(api.copied, want) {
+		t.Errorf("copied %q to %q, want %q to %s", api.copied, api.copiedTo, want, volumeMountPath)
+	}
+}
@@ -132,7 +132,9 @@ var statsCmd = &cobra.Command{
 func init() {
 	statsCmd.Flags().StringVar(&statsFlags.Path, "path", "", "Project path override")
 	statsCmd.Flags().StringVar(&statsFlags.Format, "format", "json", "Output format. Only json is supported.")
+	declareFormats(statsCmd, "json")
 	statsCmd.GroupID = "ops"
+	enableOutputFile(statsCmd)
 	RootCmd.AddCommand(statsCmd)
 }
 
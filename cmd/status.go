@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/libops/sitectl/pkg/docker"
+	"github.com/libops/sitectl/pkg/format"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the state and health of every service container in the context",
+	Long: `Inspect every container of the context's compose project, running or not, through the
+Docker API, for local and remote contexts alike. For each container it shows the state,
+health check status, restart count, image, uptime and published ports.
+
+Examples:
+  sitectl status
+  sitectl status --context prod --format json
+  sitectl status --format '{{range .}}{{.Service}} {{.Health}}{{"\n"}}{{end}}'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		outputFormat, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		formatter, err := format.NewFormatter(outputFormat)
+		if err != nil {
+			return err
+		}
+		formatter.SetOutput(cmd.OutOrStdout())
+		ctx, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+		cli, err := docker.GetDockerCli(ctx)
+		if err != nil {
+			return err
+		}
+		defer cli.Close()
+
+		statuses, err := docker.ProjectStatus(cmd.Context(), cli.CLI, ctx.EffectiveComposeProjectName())
+		if err != nil {
+			return fmt.Errorf("read service status of context %q: %w", ctx.Name, err)
+		}
+		return formatter.Print(statuses, []string{"SERVICE", "CONTAINER", "STATE", "HEALTH", "RESTARTS", "IMAGE", "UPTIME", "PORTS"}, statusRows(statuses, time.Now()))
+	},
+}
+
+func statusRows(statuses []docker.ContainerStatus, now time.Time) [][]string {
+	rows := make([][]string, 0, len(statuses))
+	for _, status := range statuses {
+		uptime := "-"
+		if !status.StartedAt.IsZero() {
+			uptime = formatUptime(now.Sub(status.StartedAt))
+		}
+		ports := "-"
+		if len(status.Ports) > 0 {
+			ports = strings.Join(status.Ports, ", ")
+		}
+		health := status.Health
+		if health == "" {
+			health = "-"
+		}
+		rows = append(rows, []string{
+			status.Service,
+			status.Container,
+			status.State,
+			health,
+			strconv.Itoa(status.RestartCount),
+			status.Image,
+			uptime,
+			ports,
+		})
+	}
+	return rows
+}
+
+func init() {
+	statusCmd.GroupID = "troubleshoot"
+	statusCmd.Flags().String("format", "table", "Output format: table, json, or a Go template")
+	declareFormats(statusCmd, "table", "json")
+	RootCmd.AddCommand(statusCmd)
+}
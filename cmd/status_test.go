@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/libops/sitectl/pkg/docker"
+)
+
+func TestStatusRows(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	rows := statusRows([]docker.ContainerStatus{
+		{
+			Service:      "drupal",
+			Container:    "site-drupal-1",
+			State:        "running",
+			Health:       "unhealthy",
+			RestartCount: 3,
+			Image:        "islandora/drupal:4",
+			StartedAt:    now.Add(-26*time.Hour - 5*time.Minute),
+			Ports:        []string{"0.0.0.0:8080->80/tcp", "127.0.0.1:8443->443/tcp"},
+		},
+		{Service: "mariadb", Container: "site-mariadb-1", State: "exited", Image: "mariadb:11"},
+	}, now)
+	want := [][]string{
+		{"drupal", "site-drupal-1", "running", "unhealthy", "3", "islandora/drupal:4", "1d 2h 5m", "0.0.0.0:8080->80/tcp, 127.0.0.1:8443->443/tcp"},
+		{"mariadb", "site-mariadb-1", "exited", "-", "0", "mariadb:11", "-", "-"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q\nwant %q", rows, want)
+	}
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/docker"
+	"github.com/spf13/cobra"
+)
+
+// serviceURL is one way to reach a Compose service.
+type serviceURL struct {
+	Service string `json:"service"`
+	URL     string `json:"url"`
+	Source  string `json:"source"`
+	Access  string `json:"access"`
+}
+
+var (
+	traefikRuleHostPattern   = regexp.MustCompile("Host\\(([^)]*)\\)")
+	traefikRulePathPattern   = regexp.MustCompile("PathPrefix\\(`([^`]*)`\\)")
+	traefikRuleQuotedPattern = regexp.MustCompile("`([^`]*)`")
+)
+
+var urlsCmd = &cobra.Command{
+	Use:   "urls",
+	Short: "List reachable URLs for the services of a context",
+	Long: `List the URLs that reach the running services of the active context.
+
+Routes come from Traefik router labels (Host and PathPrefix rules, with https when the router
+uses TLS or the websecure entrypoint) and from ports published on the Docker host. Ports a
+remote host only publishes on loopback, and ports that are not published at all, are listed
+with the sitectl port-forward command that makes them reachable from this machine.
+
+Examples:
+  sitectl urls                    # Table of URLs for the current context
+  sitectl urls --context prod     # URLs for a remote context
+  sitectl urls --format json      # Machine-readable output`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		if format != "table" && format != "json" {
+			return fmt.Errorf("unsupported format %q: use table or json", format)
+		}
+
+		cli, err := docker.GetDockerCli(ctx)
+		if err != nil {
+			return err
+		}
+		defer cli.Close()
+
+		filterArgs := filters.NewArgs()
+		filterArgs.Add("label", "com.docker.compose.project="+ctx.EffectiveComposeProjectName())
+		containers, err := cli.CLI.ContainerList(cmd.Context(), dockercontainer.ListOptions{Filters: filterArgs})
+		if err != nil {
+			return fmt.Errorf("list compose containers: %w", err)
+		}
+
+		urls := serviceURLsFromContainers(ctx, containers)
+		if format == "json" {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(urls)
+		}
+		if len(urls) == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "No running services found for context %q\n", ctx.Name)
+			return nil
+		}
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SERVICE\tURL\tSOURCE\tACCESS")
+		for _, u := range urls {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", u.Service, u.URL, u.Source, u.Access)
+		}
+		return w.Flush()
+	},
+}
+
+// serviceURLsFromContainers derives reachable URLs from container labels and
+// port bindings, sorted by service.
+func serviceURLsFromContainers(ctx *config.Context, containers []dockercontainer.Summary) []serviceURL {
+	var urls []serviceURL
+	seen := map[string]bool{}
+	add := func(u serviceURL) {
+		key := u.Service + " " + u.URL
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		urls = append(urls, u)
+	}
+
+	for _, container := range containers {
+		service := container.Labels["com.docker.compose.service"]
+		if service == "" {
+			continue
+		}
+		for _, url := range traefikRouterURLs(container.Labels) {
+			add(serviceURL{Service: service, URL: url, Source: "traefik", Access: "direct"})
+		}
+		for _, port := range container.Ports {
+			if port.Type != "" && port.Type != "tcp" {
+				continue
+			}
+			add(publishedPortURL(ctx, service, port))
+		}
+	}
+
+	sort.SliceStable(urls, func(i, j int) bool {
+		if urls[i].Service != urls[j].Service {
+			return urls[i].Service < urls[j].Service
+		}
+		return urls[i].URL < urls[j].URL
+	})
+	return urls
+}
+
+// traefikRouterURLs turns enabled Traefik HTTP router labels into URLs.
+func traefikRouterURLs(labels map[string]string) []string {
+	if strings.EqualFold(labels["traefik.enable"], "false") {
+		return nil
+	}
+	var urls []string
+	for key, rule := range labels {
+		router, ok := strings.CutPrefix(key, "traefik.http.routers.")
+		if !ok {
+			continue
+		}
+		router, ok = strings.CutSuffix(router, ".rule")
+		if !ok {
+			continue
+		}
+		scheme := "http"
+		prefix := "traefik.http.routers." + router + "."
+		if labels[prefix+"tls"] == "true" || labels[prefix+"tls.certresolver"] != "" || strings.Contains(labels[prefix+"entrypoints"], "websecure") {
+			scheme = "https"
+		}
+		path := "/"
+		if match := traefikRulePathPattern.FindStringSubmatch(rule); match != nil {
+			path = match[1]
+		}
+		for _, hostMatch := range traefikRuleHostPattern.FindAllStringSubmatch(rule, -1) {
+			for _, host := range traefikRuleQuotedPattern.FindAllStringSubmatch(hostMatch[1], -1) {
+				urls = append(urls, scheme+"://"+host[1]+path)
+			}
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// publishedPortURL describes how to reach a container port from this machine.
+func publishedPortURL(ctx *config.Context, service string, port dockercontainer.Port) serviceURL {
+	forwardHint := fmt.Sprintf("sitectl port-forward %d:%s:%d", port.PrivatePort, service, port.PrivatePort)
+	if port.PublicPort == 0 {
+		return serviceURL{
+			Service: service,
+			URL:     "http://127.0.0.1:" + strconv.Itoa(int(port.PrivatePort)),
+			Source:  "container port " + strconv.Itoa(int(port.PrivatePort)),
+			Access:  forwardHint,
+		}
+	}
+
+	source := fmt.Sprintf("published %d->%d", port.PublicPort, port.PrivatePort)
+	host := "127.0.0.1"
+	access := "direct"
+	if ctx.DockerHostType == config.ContextRemote {
+		if ip := net.ParseIP(port.IP); ip != nil && ip.IsLoopback() {
+			// loopback on the remote host is only reachable through a tunnel
+			forwardHint = fmt.Sprintf("sitectl port-forward %d:%s:%d", port.PublicPort, service, port.PrivatePort)
+			return serviceURL{Service: service, URL: "http://127.0.0.1:" + strconv.Itoa(int(port.PublicPort)), Source: source, Access: forwardHint}
+		}
+		host = contextSSHHost(ctx)
+	} else if port.IP != "" && port.IP != "0.0.0.0" && port.IP != "::" {
+		host = port.IP
+	}
+	return serviceURL{
+		Service: service,
+		URL:     "http://" + net.JoinHostPort(host, strconv.Itoa(int(port.PublicPort))),
+		Source:  source,
+		Access:  access,
+	}
+}
+
+func init() {
+	urlsCmd.GroupID = "workflow"
+	urlsCmd.Flags().String("format", "table", "Output format: table or json")
+	declareFormats(urlsCmd, "table", "json")
+	enableOutputFile(urlsCmd)
+	RootCmd.AddCommand(urlsCmd)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/libops/sitectl/pkg/config"
+)
+
+func TestTraefikRouterURLs(t *testing.T) {
+	t.Parallel()
+
+	labels := map[string]string{
+		"traefik.enable":                                  "true",
+		"traefik.http.routers.drupal.rule":                "Host(`site.localhost`) || Host(`www.site.localhost`)",
+		"traefik.http.routers.drupal.entrypoints":         "websecure",
+		"traefik.http.routers.cantaloupe.rule":            "Host(`site.localhost`) && PathPrefix(`/cantaloupe`)",
+		"traefik.http.routers.multi.rule":                 "Host(`a.test`, `b.test`)",
+		"traefik.http.routers.multi.tls.certresolver":     "le",
+		"traefik.http.services.drupal.loadbalancer.port":  "80",
+		"traefik.http.middlewares.drupal.headers.foo.bar": "baz",
+	}
+	got := traefikRouterURLs(labels)
+	want := []string{
+		"http://site.localhost/cantaloupe",
+		"https://a.test/",
+		"https://b.test/",
+		"https://site.localhost/",
+		"https://www.site.localhost/",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("traefikRouterURLs() = %v, want %v", got, want)
+	}
+
+	if got := traefikRouterURLs(map[string]string{"traefik.enable": "false", "traefik.http.routers.x.rule": "Host(`x.test`)"}); got != nil {
+		t.Fatalf("traefikRouterURLs(disabled) = %v, want nil", got)
+	}
+}
+
+func TestServiceURLsFromContainers(t *testing.T) {
+	t.Parallel()
+
+	containers := []dockercontainer.Summary{
+		{
+			Labels: map[string]string{"com.docker.compose.service": "solr"},
+			Ports:  []dockercontainer.Port{{PrivatePort: 8983, Type: "tcp"}},
+		},
+		{
+			Labels: map[string]string{"com.docker.compose.service": "traefik"},
+			Ports: []dockercontainer.Port{
+				{IP: "0.0.0.0", PrivatePort: 80, PublicPort: 80, Type: "tcp"},
+				{IP: "::", PrivatePort: 80, PublicPort: 80, Type: "tcp"},
+				{IP: "127.0.0.1", PrivatePort: 8080, PublicPort: 8080, Type: "tcp"},
+			},
+		},
+	}
+
+	local := serviceURLsFromContainers(&config.Context{DockerHostType: config.ContextLocal}, containers)
+	wantLocal := []serviceURL{
+		{Service: "solr", URL: "http://127.0.0.1:8983", Source: "container port 8983", Access: "sitectl port-forward 8983:solr:8983"},
+		{Service: "traefik", URL: "http://127.0.0.1:80", Source: "published 80->80", Access: "direct"},
+		{Service: "traefik", URL: "http://127.0.0.1:8080", Source: "published 8080->8080", Access: "direct"},
+	}
+	if !reflect.DeepEqual(local, wantLocal) {
+		t.Fatalf("local urls = %+v, want %+v", local, wantLocal)
+	}
+
+	remote := serviceURLsFromContainers(&config.Context{DockerHostType: config.ContextRemote, SSHHostname: "prod.example.edu"}, containers)
+	wantRemote := []serviceURL{
+		{Service: "solr", URL: "http://127.0.0.1:8983", Source: "container port 8983", Access: "sitectl port-forward 8983:solr:8983"},
+		{Service: "traefik", URL: "http://127.0.0.1:8080", Source: "published 8080->8080", Access: "sitectl port-forward 8080:traefik:8080"},
+		{Service: "traefik", URL: "http://prod.example.edu:80", Source: "published 80->80", Access: "direct"},
+	}
+	if !reflect.DeepEqual(remote, wantRemote) {
+		t.Fatalf("remote urls = %+v, want %+v", remote, wantRemote)
+	}
+}
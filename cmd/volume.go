@@ -0,0 +1,410 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	cerrdefs "github.com/containerd/errdefs"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	dockerimage "github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/docker"
+	"github.com/libops/sitectl/pkg/i18n"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+)
+
+// volumeMountPath is where helper containers mount the volume. Archives hold
+// the volume contents under this directory name.
+const volumeMountPath = "/data"
+
+// volumeAPI is the part of the Docker client used to stream a volume through
+// a short-lived helper container.
+type volumeAPI interface {
+	VolumeInspect(ctx context.Context, volumeID string) (volume.Volume, error)
+	VolumeCreate(ctx context.Context, options volume.CreateOptions) (volume.Volume, error)
+	VolumeList(ctx context.Context, options volume.ListOptions) (volume.ListResponse, error)
+	ContainerList(ctx context.Context, options dockercontainer.ListOptions) ([]dockercontainer.Summary, error)
+	ImageInspect(ctx context.Context, imageID string, inspectOpts ...client.ImageInspectOption) (dockerimage.InspectResponse, error)
+	ImagePull(ctx context.Context, refStr string, options dockerimage.PullOptions) (io.ReadCloser, error)
+	ContainerCreate(ctx context.Context, config *dockercontainer.Config, hostConfig *dockercontainer.HostConfig, networkingConfig *network.NetworkingConfig, platform *ocispec.Platform, containerName string) (dockercontainer.CreateResponse, error)
+	ContainerStart(ctx context.Context, containerID string, options dockercontainer.StartOptions) error
+	ContainerWait(ctx context.Context, containerID string, condition dockercontainer.WaitCondition) (<-chan dockercontainer.WaitResponse, <-chan error)
+	ContainerRemove(ctx context.Context, containerID string, options dockercontainer.RemoveOptions) error
+	CopyFromContainer(ctx context.Context, containerID, srcPath string) (io.ReadCloser, dockercontainer.PathStat, error)
+	CopyToContainer(ctx context.Context, containerID, dstPath string, content io.Reader, options dockercontainer.CopyToContainerOptions) error
+}
+
+var volumeCmd = &cobra.Command{
+	Use:     "volume",
+	Short:   "Back up and restore individual volumes of the compose project",
+	GroupID: "ops",
+}
+
+var volumeBackupCmd = &cobra.Command{
+	Use:   "backup VOLUME",
+	Short: "Stream the contents of a volume into a local tar.gz archive",
+	Long: `Stream the contents of one Docker volume into a gzip-compressed tar archive on this machine.
+
+The volume is read by a temporary helper container through the Docker API, over SSH for
+remote contexts, so nothing is staged on the Docker host. VOLUME is either the full volume
+name or the name used in the compose file, which is prefixed with the compose project name.
+
+Examples:
+  sitectl volume backup solr-data
+  sitectl volume backup museum_files --context prod --output files.tar.gz`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+		ctx, api, closeCli, err := volumeContext(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeCli()
+
+		name, err := resolveVolumeName(cmd.Context(), api, ctx.EffectiveComposeProjectName(), args[0])
+		if err != nil {
+			return err
+		}
+		if output == "" {
+			output = sanitizeArtifactPart(name) + "-" + time.Now().UTC().Format(snapshotTimeFormat) + ".tar.gz"
+		}
+		size, err := backupVolume(cmd.Context(), api, name, output)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), i18n.T(i18n.VolumeBackedUp, name, output, formatKiB(size/1024)))
+		return nil
+	},
+}
+
+var volumeRestoreCmd = &cobra.Command{
+	Use:   "restore VOLUME",
+	Short: "Replace the contents of a volume with a tar.gz archive",
+	Long: `Empty a Docker volume and refill it from a gzip-compressed tar archive, such as one written by
+sitectl volume backup. The archive must hold a single top-level directory, whose contents
+become the volume contents. The volume is created with compose project labels when it does
+not exist yet. Containers using the volume must be stopped first.
+
+Examples:
+  sitectl volume restore solr-data --input solr-data-20260101T020000Z.tar.gz
+  sitectl volume restore files --context staging --input files.tar.gz --yolo`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		input, err := cmd.Flags().GetString("input")
+		if err != nil {
+			return err
+		}
+		yolo, err := cmd.Flags().GetBool("yolo")
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(input); err != nil {
+			return fmt.Errorf("archive %s: %w", input, err)
+		}
+		ctx, api, closeCli, err := volumeContext(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeCli()
+		if err := ctx.RequireUnprotected("restore a volume"); err != nil {
+			return err
+		}
+
+		project := ctx.EffectiveComposeProjectName()
+		name, err := resolveVolumeName(cmd.Context(), api, project, args[0])
+		if err != nil && !cerrdefs.IsNotFound(err) {
+			return err
+		}
+		if err := requireVolumeUnused(cmd.Context(), api, name); err != nil {
+			return err
+		}
+		if !config.AutoConfirm(fmt.Sprintf("restore volume %s in context %q", name, ctx.Name), yolo) {
+			answer, err := config.GetInput(i18n.T(i18n.VolumeRestoreWarning, name, ctx.Name, input), i18n.T(i18n.ContinuePrompt))
+			if err != nil {
+				return err
+			}
+			if !i18n.IsYes(answer) {
+				return fmt.Errorf("volume restore cancelled")
+			}
+		}
+		if err := restoreVolume(cmd.Context(), api, project, name, input); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), i18n.T(i18n.VolumeRestored, input, name, ctx.Name))
+		return nil
+	},
+}
+
+func volumeContext(cmd *cobra.Command) (*config.Context, volumeAPI, func(), error) {
+	ctx, err := resolveCurrentContext(cmd)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	api, closeAPI, err := volumeClient(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return ctx, api, closeAPI, nil
+}
+
+// volumeClient connects to the Docker daemon of ctx for volume copies. The
+// returned func closes the connection.
+func volumeClient(ctx *config.Context) (volumeAPI, func(), error) {
+	cli, err := docker.GetDockerCli(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	api, ok := cli.CLI.(volumeAPI)
+	if !ok {
+		_ = cli.Close()
+		return nil, nil, fmt.Errorf("docker client does not support volume copies")
+	}
+	return api, func() { _ = cli.Close() }, nil
+}
+
+// resolveVolumeName finds the volume named name, or the compose volume name
+// prefixed with the project. When neither exists it returns the prefixed name
+// with a not-found error.
+func resolveVolumeName(ctx context.Context, api volumeAPI, project, name string) (string, error) {
+	candidates := []string{name}
+	if project != "" && !strings.HasPrefix(name, project+"_") {
+		candidates = append(candidates, project+"_"+name)
+	}
+	for _, candidate := range candidates {
+		_, err := api.VolumeInspect(ctx, candidate)
+		if err == nil {
+			return candidate, nil
+		}
+		if !cerrdefs.IsNotFound(err) {
+			return "", fmt.Errorf("inspect volume %s: %w", candidate, err)
+		}
+	}
+	last := candidates[len(candidates)-1]
+	return last, fmt.Errorf("volume %s: %w", name, cerrdefs.ErrNotFound)
+}
+
+func requireVolumeUnused(ctx context.Context, api volumeAPI, name string) error {
+	containers, err := api.ContainerList(ctx, dockercontainer.ListOptions{
+		Filters: filters.NewArgs(filters.Arg("volume", name), filters.Arg("status", "running")),
+	})
+	if err != nil {
+		return fmt.Errorf("list containers using volume %s: %w", name, err)
+	}
+	if len(containers) > 0 {
+		return fmt.Errorf("volume %s is in use by running container %s; stop it first", name, containerDisplayName(containers[0]))
+	}
+	return nil
+}
+
+func containerDisplayName(container dockercontainer.Summary) string {
+	if len(container.Names) > 0 {
+		return strings.TrimPrefix(container.Names[0], "/")
+	}
+	return shortImageID(container.ID)
+}
+
+// backupVolume streams volume name through a stopped helper container into a
+// gzip-compressed archive at output and returns the archive size.
+func backupVolume(ctx context.Context, api volumeAPI, name, output string) (int64, error) {
+	id, cleanup, err := createVolumeHelper(ctx, api, name, true, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer cleanup()
+	archive, _, err := api.CopyFromContainer(ctx, id, volumeMountPath)
+	if err != nil {
+		return 0, fmt.Errorf("read volume %s: %w", name, err)
+	}
+	defer archive.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(output), ".volume-*")
+	if err != nil {
+		return 0, fmt.Errorf("create archive: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		_ = os.Remove(tmpPath)
+	}()
+	gz := gzip.NewWriter(tmp)
+	_, err = io.Copy(gz, archive)
+	if err == nil {
+		err = gz.Close()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("write archive %s: %w", output, err)
+	}
+	if err := os.Rename(tmpPath, output); err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(output)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// restoreVolume empties volume name, creating it for the compose project when
+// missing, and extracts the archive at input into it. The archive's top-level
+// directory is renamed to the helper mount point, so archives of any single
+// directory can be restored.
+func restoreVolume(ctx context.Context, api volumeAPI, project, name, input string) error {
+	if err := ensureVolume(ctx, api, project, name); err != nil {
+		return err
+	}
+
+	file, err := os.Open(input) // #nosec G304 -- input is the archive the user asked to restore.
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("read archive %s: %w", input, err)
+	}
+	defer gz.Close()
+
+	id, cleanup, err := emptyVolume(ctx, api, name)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	reader, writer := io.Pipe()
+	go func() {
+		_, err := docker.FilterTar(gz, writer, docker.TarFilter{RootName: filepath.Base(volumeMountPath)})
+		_ = writer.CloseWithError(err)
+	}()
+	if err := api.CopyToContainer(ctx, id, "/", reader, dockercontainer.CopyToContainerOptions{CopyUIDGID: true}); err != nil {
+		_ = reader.CloseWithError(err)
+		return fmt.Errorf("restore volume %s: %w", name, err)
+	}
+	return nil
+}
+
+// ensureVolume creates volume name when it does not exist, labelled as a
+// volume of the compose project when the name carries the project prefix.
+func ensureVolume(ctx context.Context, api volumeAPI, project, name string) error {
+	if _, err := api.VolumeInspect(ctx, name); cerrdefs.IsNotFound(err) {
+		labels := map[string]string{}
+		if composeName, ok := strings.CutPrefix(name, project+"_"); ok && project != "" {
+			labels["com.docker.compose.project"] = project
+			labels["com.docker.compose.volume"] = composeName
+		}
+		slog.Info("creating volume", "volume", name)
+		if _, err := api.VolumeCreate(ctx, volume.CreateOptions{Name: name, Labels: labels}); err != nil {
+			return fmt.Errorf("create volume %s: %w", name, err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("inspect volume %s: %w", name, err)
+	}
+	return nil
+}
+
+// emptyVolume deletes everything in volume name and returns the stopped
+// helper container that mounts it, ready for CopyToContainer. The returned
+// cleanup removes the container.
+func emptyVolume(ctx context.Context, api volumeAPI, name string) (string, func(), error) {
+	id, cleanup, err := createVolumeHelper(ctx, api, name, false, []string{"sh", "-c", "find " + volumeMountPath + " -mindepth 1 -delete"})
+	if err != nil {
+		return "", nil, err
+	}
+	if err := runVolumeHelper(ctx, api, id); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("empty volume %s: %w", name, err)
+	}
+	return id, cleanup, nil
+}
+
+// createVolumeHelper creates a helper container with volume name mounted at
+// volumeMountPath, pulling the helper image when needed. The returned cleanup
+// removes the container.
+func createVolumeHelper(ctx context.Context, api volumeAPI, name string, readOnly bool, command []string) (string, func(), error) {
+	if err := ensureHelperImage(ctx, api, snapshotHelperImage); err != nil {
+		return "", nil, err
+	}
+	if len(command) == 0 {
+		command = []string{"true"}
+	}
+	bind := name + ":" + volumeMountPath
+	if readOnly {
+		bind += ":ro"
+	}
+	created, err := api.ContainerCreate(ctx,
+		&dockercontainer.Config{Image: snapshotHelperImage, Cmd: command, Labels: map[string]string{"io.libops.sitectl.helper": "volume"}},
+		&dockercontainer.HostConfig{Binds: []string{bind}, NetworkMode: "none"},
+		nil, nil, "")
+	if err != nil {
+		return "", nil, fmt.Errorf("create helper container for volume %s: %w", name, err)
+	}
+	cleanup := func() {
+		// The caller's context may already be cancelled; the helper must go regardless.
+		if err := api.ContainerRemove(context.WithoutCancel(ctx), created.ID, dockercontainer.RemoveOptions{Force: true}); err != nil {
+			slog.Warn("remove helper container", "container", created.ID, "err", err)
+		}
+	}
+	return created.ID, cleanup, nil
+}
+
+func runVolumeHelper(ctx context.Context, api volumeAPI, id string) error {
+	if err := api.ContainerStart(ctx, id, dockercontainer.StartOptions{}); err != nil {
+		return err
+	}
+	results, errs := api.ContainerWait(ctx, id, dockercontainer.WaitConditionNotRunning)
+	select {
+	case result := <-results:
+		if result.Error != nil {
+			return fmt.Errorf("%s", result.Error.Message)
+		}
+		if result.StatusCode != 0 {
+			return fmt.Errorf("helper container exited with status %d", result.StatusCode)
+		}
+		return nil
+	case err := <-errs:
+		return err
+	}
+}
+
+func ensureHelperImage(ctx context.Context, api volumeAPI, image string) error {
+	if _, err := api.ImageInspect(ctx, image); err == nil {
+		return nil
+	} else if !cerrdefs.IsNotFound(err) {
+		return fmt.Errorf("inspect image %s: %w", image, err)
+	}
+	slog.Info("pulling helper image", "image", image)
+	reader, err := api.ImagePull(ctx, image, dockerimage.PullOptions{})
+	if err != nil {
+		return fmt.Errorf("pull image %s: %w", image, err)
+	}
+	defer reader.Close()
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		return fmt.Errorf("pull image %s: %w", image, err)
+	}
+	return nil
+}
+
+func init() {
+	volumeBackupCmd.Flags().StringP("output", "o", "", "Archive to write (default: <volume>-<timestamp>.tar.gz)")
+	volumeRestoreCmd.Flags().StringP("input", "i", "", "tar.gz archive to restore")
+	volumeRestoreCmd.Flags().Bool("yolo", false, "Skip the confirmation prompt before restoring")
+	markRequired(volumeRestoreCmd, "input")
+	volumeCmd.AddCommand(volumeBackupCmd, volumeRestoreCmd)
+	RootCmd.AddCommand(volumeCmd)
+}
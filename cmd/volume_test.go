@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	cerrdefs "github.com/containerd/errdefs"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	dockerimage "github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type fakeVolumeAPI struct {
+	volumes  map[string]volume.Volume
+	running  []dockercontainer.Summary
+	archive  []byte
+	created  []*dockercontainer.HostConfig
+	removed  int
+	started  int
+	copiedTo string
+	copied   []string
+}
+
+func (f *fakeVolumeAPI) VolumeInspect(_ context.Context, name string) (volume.Volume, error) {
+	if vol, ok := f.volumes[name]; ok {
+		return vol, nil
+	}
+	return volume.Volume{}, cerrdefs.ErrNotFound
+}
+
+func (f *fakeVolumeAPI) VolumeCreate(_ context.Context, options volume.CreateOptions) (volume.Volume, error) {
+	vol := volume.Volume{Name: options.Name, Labels: options.Labels}
+	f.volumes[options.Name] = vol
+	return vol, nil
+}
+
+func (f *fakeVolumeAPI) VolumeList(_ context.Context, options volume.ListOptions) (volume.ListResponse, error) {
+	list := volume.ListResponse{}
+	for name, vol := range f.volumes {
+		matches := true
+		for _, label := range options.Filters.Get("label") {
+			key, value, _ := strings.Cut(label, "=")
+			matches = matches && vol.Labels[key] == value
+		}
+		if matches {
+			vol.Name = name
+			list.Volumes = append(list.Volumes, &vol)
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeVolumeAPI) ContainerList(context.Context, dockercontainer.ListOptions) ([]dockercontainer.Summary, error) {
+	return f.running, nil
+}
+
+func (f *fakeVolumeAPI) ImageInspect(context.Context, string, ...client.ImageInspectOption) (dockerimage.InspectResponse, error) {
+	return dockerimage.InspectResponse{}, nil
+}
+
+func (f *fakeVolumeAPI) ImagePull(context.Context, string, dockerimage.PullOptions) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(nil)), nil
+}
+
+func (f *fakeVolumeAPI) ContainerCreate(_ context.Context, _ *dockercontainer.Config, hostConfig *dockercontainer.HostConfig, _ *network.NetworkingConfig, _ *ocispec.Platform, _ string) (dockercontainer.CreateResponse, error) {
+	f.created = append(f.created, hostConfig)
+	return dockercontainer.CreateResponse{ID: "helper"}, nil
+}
+
+func (f *fakeVolumeAPI) ContainerStart(context.Context, string, dockercontainer.StartOptions) error {
+	f.started++
+	return nil
+}
+
+func (f *fakeVolumeAPI) ContainerWait(context.Context, string, dockercontainer.WaitCondition) (<-chan dockercontainer.WaitResponse, <-chan error) {
+	results := make(chan dockercontainer.WaitResponse, 1)
+	results <- dockercontainer.WaitResponse{}
+	return results, make(chan error)
+}
+
+func (f *fakeVolumeAPI) ContainerRemove(context.Context, string, dockercontainer.RemoveOptions) error {
+	f.removed++
+	return nil
+}
+
+func (f *fakeVolumeAPI) CopyFromContainer(_ context.Context, _, srcPath string) (io.ReadCloser, dockercontainer.PathStat, error) {
+	if srcPath != volumeMountPath {
+		return nil, dockercontainer.PathStat{}, errors.New("unexpected path " + srcPath)
+	}
+	return io.NopCloser(bytes.NewReader(f.archive)), dockercontainer.PathStat{}, nil
+}
+
+func (f *fakeVolumeAPI) CopyToContainer(_ context.Context, _, dstPath string, content io.Reader, _ dockercontainer.CopyToContainerOptions) error {
+	f.copiedTo = dstPath
+	tr := tar.NewReader(content)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		f.copied = append(f.copied, header.Name)
+	}
+}
+
+func testTar(t *testing.T, names ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range names {
+		header := &tar.Header{Name: name, Mode: 0o644, Typeflag: tar.TypeReg, Size: int64(len(name))}
+		if name[len(name)-1] == '/' {
+			header = &tar.Header{Name: name, Mode: 0o755, Typeflag: tar.TypeDir}
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(name)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestResolveVolumeName(t *testing.T) {
+	t.Parallel()
+
+	api := &fakeVolumeAPI{volumes: map[string]volume.Volume{"museum_solr-data": {}, "shared": {}}}
+	for input, want := range map[string]string{"solr-data": "museum_solr-data", "museum_solr-data": "museum_solr-data", "shared": "shared"} {
+		if got, err := resolveVolumeName(context.Background(), api, "museum", input); err != nil || got != want {
+			t.Errorf("resolveVolumeName(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+	got, err := resolveVolumeName(context.Background(), api, "museum", "files")
+	if !cerrdefs.IsNotFound(err) || got != "museum_files" {
+		t.Errorf("missing volume = %q, %v", got, err)
+	}
+}
+
+func TestBackupAndRestoreVolume(t *testing.T) {
+	t.Parallel()
+
+	api := &fakeVolumeAPI{
+		volumes: map[string]volume.Volume{"museum_solr-data": {}},
+		archive: testTar(t, "data/", "data/cores/", "data/cores/core.properties"),
+	}
+	output := filepath.Join(t.TempDir(), "solr.tar.gz")
+	if _, err := backupVolume(context.Background(), api, "museum_solr-data", output); err != nil {
+		t.Fatal(err)
+	}
+	if len(api.created) != 1 || api.created[0].Binds[0] != "museum_solr-data:/data:ro" || api.started != 0 || api.removed != 1 {
+		t.Fatalf("backup helper created = %+v, started = %d, removed = %d", api.created, api.started, api.removed)
+	}
+	file, err := os.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil || !bytes.Equal(data, api.archive) {
+		t.Fatalf("archive contents differ: %v", err)
+	}
+
+	if err := restoreVolume(context.Background(), api, "museum", "museum_files", output); err != nil {
+		t.Fatal(err)
+	}
+	created := api.volumes["museum_files"]
+	if created.Labels["com.docker.compose.project"] != "museum" || created.Labels["com.docker.compose.volume"] != "files" {
+		t.Errorf("created volume labels = %v", created.Labels)
+	}
+	if api.created[1].Binds[0] != "museum_files:/data" || api.started != 1 || api.removed != 2 {
+		t.Errorf("restore helper created = %+v, started = %d, removed = %d", api.created[1], api.started, api.removed)
+	}
+	if want := []string{"data/", "data/cores/", "data/cores/core.properties"}; api.copiedTo != "/" || !reflect.DeepEqual(api.copied, want) {
+		t.Errorf("copied %q to %q, want %q to /", api.copied, api.copiedTo, want)
+	}
+
+	api.running = []dockercontainer.Summary{{Names: []string{"/museum-solr-1"}}}
+	if err := requireVolumeUnused(context.Background(), api, "museum_solr-data"); err == nil {
+		t.Error("requireVolumeUnused() allowed a volume used by a running container")
+	}
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/docker"
+	"github.com/libops/sitectl/pkg/notify"
+	"github.com/libops/sitectl/pkg/plugin"
+	"github.com/spf13/cobra"
+)
+
+type waitOptions struct {
+	services []string
+	healthy  bool
+	timeout  time.Duration
+	interval time.Duration
+}
+
+// waitServiceState is the readiness of one Compose service at a single poll.
+type waitServiceState struct {
+	Service string
+	Ready   bool
+	Detail  string
+}
+
+var waitOpts waitOptions
+
+var waitCmd = &cobra.Command{
+	Use:   "wait",
+	Short: "Block until Compose services in the active context are running or healthy",
+	Long: `Wait until the named Docker Compose services of the active context are running, or with
+--healthy, until their Docker healthchecks report healthy. Services without a healthcheck
+count as healthy once they are running. Without --service every service in the compose
+project is waited on.
+
+The command exits 0 once every service is ready and with status 7 when --timeout elapses,
+so scripts can replace sleep loops with a single call.
+
+Examples:
+  sitectl wait --service db --healthy --timeout 120s
+  sitectl wait --service drupal --service solr
+  sitectl wait --healthy --context prod`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if waitOpts.interval <= 0 {
+			return fmt.Errorf("invalid argument %q for \"--interval\": must be greater than zero", waitOpts.interval)
+		}
+		ctx, err := resolveCurrentContext(cmd)
+		if err != nil {
+			return err
+		}
+		cli, err := docker.GetDockerCli(ctx)
+		if err != nil {
+			return err
+		}
+		defer cli.Close()
+
+		runCtx := cmd.Context()
+		if waitOpts.timeout > 0 {
+			var cancel context.CancelFunc
+			runCtx, cancel = context.WithTimeout(runCtx, waitOpts.timeout)
+			defer cancel()
+		}
+		var progress *plugin.ProgressLine
+		defer func() {
+			if progress != nil {
+				progress.Close()
+			}
+		}()
+		return waitForServices(runCtx, cli.CLI, ctx, waitOpts, func(message string) {
+			if progress == nil {
+				progress = plugin.NewProgressLine(cmd.ErrOrStderr(), message, "")
+				return
+			}
+			progress.Report(message, "")
+		})
+	},
+}
+
+// waitForServices polls the compose project until every requested service is
+// ready. report is called with a summary of the services still pending after
+// each unsuccessful poll.
+func waitForServices(runCtx context.Context, cli docker.DockerAPI, ctx *config.Context, opts waitOptions, report func(string)) error {
+	for {
+		states, err := pollWaitServices(runCtx, cli, ctx, opts.services, opts.healthy)
+		if err != nil {
+			return err
+		}
+		pending := []string{}
+		for _, state := range states {
+			if !state.Ready {
+				pending = append(pending, state.Service+" "+state.Detail)
+			}
+		}
+		if len(states) > 0 && len(pending) == 0 {
+			return nil
+		}
+		if len(states) == 0 {
+			pending = append(pending, "no containers in compose project "+ctx.EffectiveComposeProjectName())
+		}
+		summary := strings.Join(pending, ", ")
+		if report != nil {
+			report("Waiting for " + summary)
+		}
+
+		timer := time.NewTimer(opts.interval)
+		select {
+		case <-runCtx.Done():
+			timer.Stop()
+			return fmt.Errorf("services not ready (%s): %w", summary, runCtx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// pollWaitServices reports the readiness of each requested service, or of
+// every service in the compose project when services is empty. A service that
+// has no container yet is reported as not ready rather than as an error,
+// because it may still be created by a concurrent `compose up`.
+func pollWaitServices(runCtx context.Context, cli docker.DockerAPI, ctx *config.Context, services []string, healthy bool) ([]waitServiceState, error) {
+	filterArgs := filters.NewArgs()
+	filterArgs.Add("label", "com.docker.compose.project="+ctx.EffectiveComposeProjectName())
+	containers, err := cli.ContainerList(runCtx, dockercontainer.ListOptions{All: true, Filters: filterArgs})
+	if err != nil {
+		return nil, fmt.Errorf("list compose containers: %w", err)
+	}
+
+	byService := map[string][]dockercontainer.Summary{}
+	for _, container := range containers {
+		service := container.Labels["com.docker.compose.service"]
+		byService[service] = append(byService[service], container)
+	}
+	if len(services) == 0 {
+		for service := range byService {
+			services = append(services, service)
+		}
+		sort.Strings(services)
+	}
+
+	states := make([]waitServiceState, 0, len(services))
+	for _, service := range services {
+		state := waitServiceState{Service: service, Detail: "not created"}
+		for _, container := range byService[service] {
+			inspect, err := cli.ContainerInspect(runCtx, container.ID)
+			if err != nil {
+				return nil, fmt.Errorf("inspect %s container: %w", service, err)
+			}
+			ready, detail := waitContainerReady(inspect, healthy)
+			state.Ready, state.Detail = ready, detail
+			if !ready {
+				break
+			}
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// waitContainerReady reports whether one container satisfies the wait
+// condition, with a short description of its state.
+func waitContainerReady(inspect dockercontainer.InspectResponse, healthy bool) (bool, string) {
+	if inspect.State == nil {
+		return false, "unknown"
+	}
+	if inspect.State.Status != "running" {
+		return false, inspect.State.Status
+	}
+	if !healthy || inspect.State.Health == nil {
+		return true, "running"
+	}
+	health := inspect.State.Health.Status
+	return health == "healthy", "health=" + health
+}
+
+func init() {
+	waitCmd.Flags().StringArrayVar(&waitOpts.services, "service", nil, "Compose service to wait for (repeatable; default: every service in the project)")
+	waitCmd.Flags().BoolVar(&waitOpts.healthy, "healthy", false, "Wait for Docker healthchecks to report healthy instead of only running")
+	waitCmd.Flags().DurationVar(&waitOpts.timeout, "timeout", 2*time.Minute, "Give up after this long; 0 waits forever")
+	waitCmd.Flags().DurationVar(&waitOpts.interval, "interval", 2*time.Second, "Time between checks")
+	waitCmd.GroupID = "workflow"
+	waitCmd.Annotations = map[string]string{notify.Annotation: ""}
+	RootCmd.AddCommand(waitCmd)
+}
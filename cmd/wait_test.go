@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/libops/sitectl/pkg/config"
+)
+
+type fakeWaitDockerAPI struct {
+	containers []dockercontainer.Summary
+	states     map[string]*dockercontainer.State
+}
+
+func (f fakeWaitDockerAPI) ContainerInspect(_ context.Context, id string) (dockercontainer.InspectResponse, error) {
+	return dockercontainer.InspectResponse{ContainerJSONBase: &dockercontainer.ContainerJSONBase{State: f.states[id]}}, nil
+}
+
+func (f fakeWaitDockerAPI) ContainerList(context.Context, dockercontainer.ListOptions) ([]dockercontainer.Summary, error) {
+	return f.containers, nil
+}
+
+func waitTestAPI() fakeWaitDockerAPI {
+	return fakeWaitDockerAPI{
+		containers: []dockercontainer.Summary{
+			{ID: "db", Labels: map[string]string{"com.docker.compose.service": "db"}},
+			{ID: "drupal", Labels: map[string]string{"com.docker.compose.service": "drupal"}},
+			{ID: "cron", Labels: map[string]string{"com.docker.compose.service": "cron"}},
+		},
+		states: map[string]*dockercontainer.State{
+			"db":     {Status: "running", Health: &dockercontainer.Health{Status: "healthy"}},
+			"drupal": {Status: "running", Health: &dockercontainer.Health{Status: "starting"}},
+			"cron":   {Status: "exited"},
+		},
+	}
+}
+
+func TestPollWaitServices(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		services []string
+		healthy  bool
+		want     map[string]bool
+	}{
+		{name: "running", services: []string{"db", "drupal"}, want: map[string]bool{"db": true, "drupal": true}},
+		{name: "healthy", services: []string{"db", "drupal"}, healthy: true, want: map[string]bool{"db": true, "drupal": false}},
+		{name: "missing service", services: []string{"solr"}, want: map[string]bool{"solr": false}},
+		{name: "all services", want: map[string]bool{"cron": false, "db": true, "drupal": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			states, err := pollWaitServices(context.Background(), waitTestAPI(), &config.Context{ProjectName: "site"}, tt.services, tt.healthy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(states) != len(tt.want) {
+				t.Fatalf("states = %+v, want %d services", states, len(tt.want))
+			}
+			for _, state := range states {
+				if want, ok := tt.want[state.Service]; !ok || state.Ready != want {
+					t.Errorf("%s ready = %v (%s), want %v", state.Service, state.Ready, state.Detail, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWaitForServicesTimesOut(t *testing.T) {
+	t.Parallel()
+
+	runCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var reports []string
+	err := waitForServices(runCtx, waitTestAPI(), &config.Context{ProjectName: "site"}, waitOptions{
+		services: []string{"drupal"},
+		healthy:  true,
+		interval: 10 * time.Millisecond,
+	}, func(message string) { reports = append(reports, message) })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("waitForServices() error = %v, want deadline exceeded", err)
+	}
+	if classifyError(err) != errorClassTimeout {
+		t.Fatalf("classifyError() = %s, want timeout", classifyError(err))
+	}
+	if len(reports) == 0 || reports[0] != "Waiting for drupal health=starting" {
+		t.Fatalf("reports = %q", reports)
+	}
+}
+
+func TestWaitForServicesReady(t *testing.T) {
+	t.Parallel()
+
+	err := waitForServices(context.Background(), waitTestAPI(), &config.Context{ProjectName: "site"}, waitOptions{
+		services: []string{"db"},
+		healthy:  true,
+		interval: time.Millisecond,
+	}, nil)
+	if err != nil {
+		t.Fatalf("waitForServices() error = %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/healthcheck"
+	"github.com/libops/sitectl/pkg/helpers"
+	"github.com/spf13/cobra"
+)
+
+// commonWPCommands seeds shell completion; `sitectl wp help` lists the rest.
+var commonWPCommands = []string{
+	"cache\tAdd, remove, fetch and flush the object cache",
+	"core\tDownload, install, update and manage WordPress",
+	"cron\tTest, run and delete WP-Cron events",
+	"db\tPerform basic database operations",
+	"eval\tExecute arbitrary PHP code",
+	"export\tExport WordPress content to a WXR file",
+	"help\tGet help on WP-CLI or a specific command",
+	"import\tImport content from a WXR file",
+	"media\tImport new attachments or regenerate thumbnails",
+	"option\tRetrieve and set site options",
+	"plugin\tManage plugins",
+	"rewrite\tList or flush rewrite rules",
+	"search-replace\tSearch and replace strings in the database",
+	"shell\tOpen an interactive PHP console",
+	"theme\tManage themes",
+	"transient\tAdd, get and delete transients",
+	"user\tManage users",
+}
+
+var wpCmd = &cobra.Command{
+	Use:                "wp [WP-CLI ARGS...]",
+	DisableFlagParsing: true,
+	Args:               cobra.ArbitraryArgs,
+	Short:              "Run wp-cli inside the WordPress container of a context",
+	Long: `Run wp-cli inside the WordPress container of the active context.
+
+sitectl finds the container of --service (default: the context's app service) through its
+Compose labels and passes --path for the WordPress install (default: the context's
+wp-container-root, /var/www/html) and --url from SITE_URL (or the ingress domain in .env).
+Pass --path or --url yourself to override them. --allow-root is added because docker exec
+runs as root by default. All other arguments are passed to wp-cli unchanged.
+
+Examples:
+  sitectl wp plugin list                     # List installed plugins
+  sitectl wp --service blog theme list       # Run wp-cli in the blog service
+  sitectl wp cache flush                     # Flush the object cache
+  sitectl wp shell                           # Open an interactive PHP console
+  sitectl wp --context prod core version     # Show the WordPress version on prod`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		return commonWPCommands, cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filteredArgs, contextName, err := getContextFromArgs(cmd, args)
+		if err != nil {
+			return err
+		}
+		ctx, err := config.GetContext(contextName)
+		if err != nil {
+			return err
+		}
+
+		service, filteredArgs := takeFlag(filteredArgs, "--service")
+		service = helpers.FirstNonEmpty(service, defaultIngressAppService(&ctx))
+		url := healthcheck.PublicURLFromEnv(&ctx, "", "")
+		return runInServiceContainer(cmd.Context(), &ctx, service, "", wpCommandArgs(filteredArgs, ctx.EffectiveWPContainerRoot(), url))
+	},
+}
+
+// wpCommandArgs adds --path, --url and --allow-root unless the caller
+// already set them.
+func wpCommandArgs(args []string, path, url string) []string {
+	command := []string{"wp"}
+	if !hasAnyFlag(args, "--path") {
+		command = append(command, "--path="+path)
+	}
+	if strings.TrimSpace(url) != "" && !hasAnyFlag(args, "--url") {
+		command = append(command, "--url="+url)
+	}
+	if !hasAnyFlag(args, "--allow-root") {
+		command = append(command, "--allow-root")
+	}
+	return append(command, args...)
+}
+
+func init() {
+	wpCmd.GroupID = "workflow"
+	RootCmd.AddCommand(wpCmd)
+}
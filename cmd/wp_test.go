@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWPCommandArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+		url  string
+		want []string
+	}{
+		{
+			name: "adds defaults",
+			args: []string{"plugin", "list"},
+			url:  "http://wp.localhost/",
+			want: []string{"wp", "--path=/var/www/html", "--url=http://wp.localhost/", "--allow-root", "plugin", "list"},
+		},
+		{
+			name: "keeps explicit path and url",
+			args: []string{"--path=/srv/wp", "--url=https://example.edu", "core", "version"},
+			url:  "http://wp.localhost/",
+			want: []string{"wp", "--allow-root", "--path=/srv/wp", "--url=https://example.edu", "core", "version"},
+		},
+		{
+			name: "keeps explicit allow-root without url",
+			args: []string{"--allow-root", "option", "get", "home"},
+			want: []string{"wp", "--path=/var/www/html", "--allow-root", "option", "get", "home"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := wpCommandArgs(tc.args, "/var/www/html", tc.url); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("wpCommandArgs() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTakeFlag(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		args  []string
+		value string
+		rest  []string
+	}{
+		{name: "separate value", args: []string{"--service", "blog", "plugin", "list"}, value: "blog", rest: []string{"plugin", "list"}},
+		{name: "equals value", args: []string{"plugin", "--service=blog", "list"}, value: "blog", rest: []string{"plugin", "list"}},
+		{name: "absent", args: []string{"--path=/srv/wp", "core", "version"}, rest: []string{"--path=/srv/wp", "core", "version"}},
+		{name: "after double dash", args: []string{"eval-file", "--", "--service", "x"}, rest: []string{"eval-file", "--", "--service", "x"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			value, rest := takeFlag(tc.args, "--service")
+			if value != tc.value || !reflect.DeepEqual(rest, tc.rest) {
+				t.Fatalf("takeFlag() = %q, %v, want %q, %v", value, rest, tc.value, tc.rest)
+			}
+		})
+	}
+}
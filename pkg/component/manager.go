@@ -437,7 +437,7 @@ func (m *Manager) checkGates(spec ComponentSpec, opts ApplyOptions, enable bool)
 	if prompt == "" {
 		return nil
 	}
-	if opts.Yolo || opts.AutoApprove {
+	if config.AutoConfirm(action+" component "+spec.Name, opts.Yolo || opts.AutoApprove) {
 		return nil
 	}
 
@@ -144,7 +144,7 @@ func PromptChoice(name string, choices []Choice, defaultValue string, input Inpu
 }
 
 func promptChoiceInteractive(name string, choices []Choice, defaultValue string, sections []string) (string, bool, error) {
-	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+	if config.NonInteractive() || !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
 		return "", false, nil
 	}
 	uiChoices := make([]ui.Choice, 0, len(choices))
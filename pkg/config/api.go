@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// APIURLEnv overrides the libops API endpoint for every context. sitectl sets
+// it for itself and plugin subprocesses when --api-url is passed.
+const APIURLEnv = "SITECTL_API_URL"
+
+// ValidateAPIURL checks that raw is an absolute http or https URL.
+func ValidateAPIURL(raw string) error {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return fmt.Errorf("invalid api-url %q: %w", raw, err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid api-url %q: expected an http:// or https:// URL", raw)
+	}
+	return nil
+}
+
+// ResolveAPIURL returns the API endpoint commands against this context should
+// use: --api-url (SITECTL_API_URL) when given, otherwise the api-url pinned on
+// the context. It returns "" when neither is set, meaning the default API.
+func (c Context) ResolveAPIURL() string {
+	if override := strings.TrimSpace(os.Getenv(APIURLEnv)); override != "" {
+		return strings.TrimRight(override, "/")
+	}
+	return strings.TrimRight(strings.TrimSpace(c.APIURL), "/")
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestValidateAPIURL(t *testing.T) {
+	for _, good := range []string{"https://api.staging.example.com", "http://localhost:8080/v1"} {
+		if err := ValidateAPIURL(good); err != nil {
+			t.Errorf("ValidateAPIURL(%q) error = %v", good, err)
+		}
+	}
+	for _, bad := range []string{"api.example.com", "ftp://api.example.com", "https://", "://x"} {
+		if err := ValidateAPIURL(bad); err == nil {
+			t.Errorf("ValidateAPIURL(%q) expected error", bad)
+		}
+	}
+}
+
+func TestResolveAPIURL(t *testing.T) {
+	t.Setenv(APIURLEnv, "")
+	ctx := Context{APIURL: "https://api.staging.example.com/"}
+	if got := ctx.ResolveAPIURL(); got != "https://api.staging.example.com" {
+		t.Fatalf("ResolveAPIURL() = %q, want the context api-url", got)
+	}
+	if got := (Context{}).ResolveAPIURL(); got != "" {
+		t.Fatalf("ResolveAPIURL() without api-url = %q, want empty", got)
+	}
+
+	t.Setenv(APIURLEnv, "https://api.example.com")
+	if got := ctx.ResolveAPIURL(); got != "https://api.example.com" {
+		t.Fatalf("ResolveAPIURL() = %q, want the --api-url override", got)
+	}
+}
+
+func TestLoadFromFlagsAPIURL(t *testing.T) {
+	flags := pflag.NewFlagSet("set-context", pflag.ContinueOnError)
+	SetCommandFlags(flags)
+	if err := flags.Parse([]string{"--api-url", "https://api.staging.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := LoadFromFlags(flags, Context{Name: "staging", Environment: "staging"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.APIURL != "https://api.staging.example.com" || ctx.Environment != "staging" {
+		t.Fatalf("LoadFromFlags() = %+v", ctx)
+	}
+}
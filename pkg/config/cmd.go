@@ -1,203 +1,85 @@
 package config
 
 import (
-	"bufio"
 	"context"
 	"fmt"
-	"io"
-	"log/slog"
 	"os"
 	"os/exec"
 	"strings"
-	"sync"
 
-	"github.com/kballard/go-shellquote"
-	"golang.org/x/crypto/ssh"
 	"golang.org/x/term"
 )
 
 func (c *Context) RunCommand(cmd *exec.Cmd) (string, error) {
-	return c.runCommandContext(context.Background(), cmd, true)
+	return c.runCommandContext(context.Background(), cmd, ExecOptions{}, true, true)
 }
 
 func (c *Context) RunQuietCommand(cmd *exec.Cmd) (string, error) {
-	return c.runCommandContext(context.Background(), cmd, false)
+	return c.runCommandContext(context.Background(), cmd, ExecOptions{}, false, false)
 }
 
 func (c *Context) RunCommandContext(ctx context.Context, cmd *exec.Cmd) (string, error) {
-	return c.runCommandContext(ctx, cmd, true)
+	return c.runCommandContext(ctx, cmd, ExecOptions{}, true, true)
+}
+
+// RunComposeScriptContext runs cmd like RunCommandContext for shell scripts
+// that call docker compose, exporting the context's ComposeEnvironment.
+func (c *Context) RunComposeScriptContext(ctx context.Context, cmd *exec.Cmd) (string, error) {
+	return c.runCommandContext(ctx, cmd, ExecOptions{ComposeEnv: true}, true, true)
 }
 
 func (c *Context) RunQuietCommandContext(ctx context.Context, cmd *exec.Cmd) (string, error) {
-	return c.runCommandContext(ctx, cmd, false)
+	return c.runCommandContext(ctx, cmd, ExecOptions{}, false, false)
 }
 
-func (c *Context) runCommandContext(ctx context.Context, cmd *exec.Cmd, printOutput bool) (string, error) {
-	runCtx, cancel := context.WithCancel(ctx)
-	defer cancel()
-	var output strings.Builder
-	if c.DockerHostType == ContextLocal {
-		original := cmd
-		cmd = exec.CommandContext(runCtx, original.Path, original.Args[1:]...) // #nosec G204 -- command path is selected by sitectl and arguments are forwarded without a shell.
-		cmd.Env = original.Env
-		if len(cmd.Env) == 0 {
-			cmd.Env = os.Environ()
-		}
-		cmd.Dir = original.Dir
-		if cmd.Dir == "" {
-			cmd.Dir = c.ProjectDir
-		}
-		if printOutput {
-			cmd.Stdin = original.Stdin
-			if cmd.Stdin == nil {
-				cmd.Stdin = os.Stdin
-			}
-		}
-		stdoutPipe, err := cmd.StdoutPipe()
-		if err != nil {
-			return "", fmt.Errorf("error creating stdout pipe for command %s: %v", cmd.String(), err)
-		}
+// RunNoTTYCommandContext streams output like RunCommandContext but never
+// requests a remote pseudo-terminal. Remote stderr stays on stderr and stdin is
+// only forwarded when it is not a terminal, which keeps the command usable in
+// pipelines and scripts.
+func (c *Context) RunNoTTYCommandContext(ctx context.Context, cmd *exec.Cmd) (string, error) {
+	return c.runCommandContext(ctx, cmd, ExecOptions{}, true, false)
+}
+
+func (c *Context) runCommandContext(ctx context.Context, cmd *exec.Cmd, opts ExecOptions, printOutput, requestPTY bool) (string, error) {
+	// the legacy return value is stdout and stderr merged in arrival order for
+	// remote commands, and stdout alone for local ones
+	var merged strings.Builder
+	opts.OnStdout = func(chunk []byte) {
 		if printOutput {
-			cmd.Stderr = os.Stderr
-		} else {
-			cmd.Stderr = io.Discard
+			_, _ = os.Stdout.Write(chunk)
 		}
-		if err := cmd.Start(); err != nil {
-			return "", fmt.Errorf("error starting command %s: %v", cmd.String(), err)
-		}
-		scanner := bufio.NewScanner(stdoutPipe)
-		for scanner.Scan() {
-			line := scanner.Text()
-			if printOutput {
-				fmt.Println(line)
-			}
-			output.WriteString(line)
-			output.WriteString("\n")
-		}
-		if err := scanner.Err(); err != nil {
-			slog.Error("Error reading stdout", "err", err)
-		}
-		if err := cmd.Wait(); err != nil {
-			return "", fmt.Errorf("error waiting for command %s: %v", cmd.String(), err)
-		}
-		return strings.TrimRight(output.String(), "\n"), nil
-	}
-
-	sshClient, err := c.DialSSH()
-	if err != nil {
-		return "", fmt.Errorf("error establishing SSH connection: %v", err)
-	}
-
-	remoteCmd := fmt.Sprintf("cd %s && ", shellquote.Join(c.ProjectDir))
-	remoteCmd += shellquote.Join(cmd.Args...)
-
-	slog.Info("Running remote command", "host", c.SSHHostname, "cmd", remoteCmd)
-	session, err := sshClient.NewSession()
-	if err != nil {
-		_ = sshClient.Close()
-		return "", fmt.Errorf("error creating SSH session: %v", err)
-	}
-
-	// closeOnce ensures session and client are closed exactly once,
-	// whether by the watchdog goroutine (context cancellation) or by deferred cleanup.
-	var closeOnce sync.Once
-	closeResources := func() {
-		_ = session.Close()
-		_ = sshClient.Close()
+		merged.Write(chunk)
 	}
-	defer closeOnce.Do(closeResources)
-	go func() {
-		<-runCtx.Done()
-		closeOnce.Do(closeResources)
-	}()
-
-	if printOutput {
-		modes := ssh.TerminalModes{
-			ssh.ECHO:          0,
-			ssh.TTY_OP_ISPEED: 14400,
-			ssh.TTY_OP_OSPEED: 14400,
-		}
-		width, height, err := term.GetSize(int(os.Stdin.Fd()))
-		if err != nil {
-			width = 80
-			height = 40
-		}
-		if err := session.RequestPty("xterm", width, height, modes); err != nil {
-			return "", fmt.Errorf("error requesting pseudo terminal: %w", err)
-		}
-
-		if term.IsTerminal(int(os.Stdin.Fd())) {
-			oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
-			if err != nil {
-				return "", fmt.Errorf("failed to set terminal to raw mode: %v", err)
-			}
-			defer func() {
-				if err := term.Restore(int(os.Stdin.Fd()), oldState); err != nil {
-					slog.Error("Unable to return terminal to original state.", "err", err)
-				}
-			}()
+	opts.OnStderr = func(chunk []byte) {
+		if printOutput {
+			// without a pty the remote streams stay separate, so keep them
+			// separate locally too for callers redirecting stdout
+			_, _ = os.Stderr.Write(chunk)
 		}
-
-		session.Stdin = os.Stdin
-	}
-	stdoutPipe, err := session.StdoutPipe()
-	if err != nil {
-		return "", fmt.Errorf("error obtaining stdout pipe: %v", err)
-	}
-	stderrPipe, err := session.StderrPipe()
-	if err != nil {
-		return "", fmt.Errorf("error obtaining stderr pipe: %v", err)
+		merged.Write(chunk)
 	}
-
-	// call ssh foo@host.tld "remoteCmd"
-	if err := session.Start(remoteCmd); err != nil {
-		return "", fmt.Errorf("error starting remote command %q: %v", remoteCmd, err)
-	}
-
-	// save the output from the command so we can return it
-	outputChunks := make(chan string, 16)
-	var outputReaders sync.WaitGroup
-	readRemoteOutput := func(r io.Reader) {
-		defer outputReaders.Done()
-		buf := make([]byte, 4096)
-		for {
-			n, readErr := r.Read(buf)
-			if n > 0 {
-				chunk := string(buf[:n])
-				select {
-				case outputChunks <- chunk:
-				case <-runCtx.Done():
-					return
-				}
-			}
-			if readErr != nil {
-				if readErr != io.EOF && runCtx.Err() == nil {
-					slog.Error("Error reading remote output", "err", readErr)
-				}
-				return
+	if c.DockerHostType == ContextLocal {
+		if printOutput {
+			opts.Stdin = cmd.Stdin
+			if opts.Stdin == nil {
+				opts.Stdin = os.Stdin
 			}
 		}
-	}
-	outputReaders.Add(2)
-	go readRemoteOutput(stdoutPipe)
-	go readRemoteOutput(stderrPipe)
-	go func() {
-		outputReaders.Wait()
-		close(outputChunks)
-	}()
-
-	for chunk := range outputChunks {
-		if printOutput {
-			fmt.Print(chunk)
+		result, err := c.Exec(ctx, cmd, opts)
+		if err != nil {
+			return "", err
 		}
-		output.WriteString(chunk)
+		return strings.TrimRight(string(result.Stdout), "\n"), nil
 	}
 
-	if err = session.Wait(); err != nil {
-		return output.String(), remoteCommandWaitError(runCtx, remoteCmd, err)
+	if printOutput && requestPTY {
+		opts.TTY = true
+		opts.Stdin = os.Stdin
+	} else if printOutput && !term.IsTerminal(int(os.Stdin.Fd())) {
+		opts.Stdin = os.Stdin
 	}
-
-	return output.String(), nil
+	_, err := c.Exec(ctx, cmd, opts)
+	return merged.String(), err
 }
 
 func remoteCommandWaitError(runCtx context.Context, remoteCmd string, waitErr error) error {
@@ -51,6 +51,20 @@ func TestRunCommandLocalPreservesCommandEnv(t *testing.T) {
 	}
 }
 
+func TestRunNoTTYCommandContextLocalPreservesExitError(t *testing.T) {
+	ctx := &Context{
+		DockerHostType: ContextLocal,
+	}
+	_, err := ctx.RunNoTTYCommandContext(context.Background(), exec.Command("sh", "-c", "exit 4"))
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("RunNoTTYCommandContext() = %v, want wrapped exec.ExitError", err)
+	}
+	if exitErr.ExitCode() != 4 {
+		t.Fatalf("exit code = %d, want 4", exitErr.ExitCode())
+	}
+}
+
 func TestRunCommandRemoteSudoUnsupported(t *testing.T) {
 	ctx := &Context{
 		DockerHostType: ContextRemote,
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+	"github.com/libops/sitectl/pkg/keyring"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// Secret reference prefixes for ComposeEnv values.
+const (
+	composeEnvKeyringPrefix = "keyring:"
+	composeEnvEnvPrefix     = "env:"
+)
+
+// keyringGet reads a keyring secret; tests replace it.
+var keyringGet = keyring.Get
+
+// ComposeEnvironment resolves the context's compose-env values. A value of
+// keyring:ACCOUNT is read from the system keyring and env:NAME from sitectl's
+// own environment; anything else is used as is.
+func (c Context) ComposeEnvironment() (map[string]string, error) {
+	if len(c.ComposeEnv) == 0 {
+		return nil, nil
+	}
+	values := make(map[string]string, len(c.ComposeEnv))
+	for name, value := range c.ComposeEnv {
+		name = strings.TrimSpace(name)
+		if !isShellAssignment(name + "=") {
+			return nil, fmt.Errorf("context %q: invalid compose-env name %q", c.Name, name)
+		}
+		switch {
+		case strings.HasPrefix(value, composeEnvKeyringPrefix):
+			account := strings.TrimPrefix(value, composeEnvKeyringPrefix)
+			secret, err := keyringGet(account)
+			if err != nil {
+				return nil, fmt.Errorf("context %q: compose-env %s: %w", c.Name, name, err)
+			}
+			value = secret
+		case strings.HasPrefix(value, composeEnvEnvPrefix):
+			variable := strings.TrimPrefix(value, composeEnvEnvPrefix)
+			resolved, ok := os.LookupEnv(variable)
+			if !ok {
+				return nil, fmt.Errorf("context %q: compose-env %s: environment variable %s is not set", c.Name, name, variable)
+			}
+			value = resolved
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// isDockerComposeCommand reports whether args run docker compose.
+func isDockerComposeCommand(args []string) bool {
+	return len(args) >= 2 && filepath.Base(args[0]) == "docker" && args[1] == "compose"
+}
+
+// composeEnvAssignments returns NAME=VALUE shell assignments in name order,
+// one per line, for the env file sourced by remote compose commands.
+func composeEnvAssignments(values map[string]string) string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var lines strings.Builder
+	for _, name := range names {
+		// names are shell identifiers; quoting them would turn the line into a command
+		lines.WriteString(name + "=" + shellquote.Join(values[name]) + "\n")
+	}
+	return lines.String()
+}
+
+// remoteCommand runs args in projectDir on the remote host, exporting envFile
+// when set; see ComposeEnvCommand.
+func remoteCommand(projectDir, envFile string, args []string) string {
+	return "cd " + shellquote.Join(projectDir) + " && " + ComposeEnvCommand(envFile, args...)
+}
+
+// ComposeEnvCommand returns the remote shell command for args. With an
+// envFile from UploadComposeEnv, a POSIX shell exports its assignments,
+// deletes it and then runs args, so compose-env values travel over SFTP and
+// never appear on a command line.
+func ComposeEnvCommand(envFile string, args ...string) string {
+	if envFile == "" {
+		return shellquote.Join(args...)
+	}
+	script := `set -a && . "$1" && set +a && rm -f "$1" && shift && exec "$@"`
+	return shellquote.Join(append([]string{"sh", "-c", script, "sh", envFile}, args...)...)
+}
+
+// UploadComposeEnv writes the context's ComposeEnvironment to a private file
+// on the remote host for ComposeEnvCommand, for callers that run remote
+// commands on their own SSH session. It returns an empty path when there is
+// nothing to export. The returned cleanup is always safe to call.
+func (c *Context) UploadComposeEnv() (string, func(), error) {
+	values, err := c.ComposeEnvironment()
+	if err != nil || len(values) == 0 {
+		return "", func() {}, err
+	}
+	sshClient, err := c.SSHClient()
+	if err != nil {
+		return "", func() {}, fmt.Errorf("error establishing SSH connection: %w", err)
+	}
+	envFile, cleanup, err := uploadComposeEnv(sshClient, values)
+	if err != nil {
+		return "", func() {}, err
+	}
+	return envFile, cleanup, nil
+}
+
+// uploadComposeEnv writes values to a new file on the remote host that only
+// the SSH user can read and returns its path. The returned cleanup removes
+// the file in case the remote command never got to it.
+func uploadComposeEnv(sshClient *ssh.Client, values map[string]string) (string, func(), error) {
+	client, err := sftp.NewClient(sshClient)
+	if err != nil {
+		return "", nil, fmt.Errorf("open SFTP session for compose-env: %w", err)
+	}
+	suffix := make([]byte, 12)
+	if _, err := rand.Read(suffix); err != nil {
+		_ = client.Close()
+		return "", nil, err
+	}
+	name := path.Join("/tmp", ".sitectl-compose-env-"+hex.EncodeToString(suffix))
+	cleanup := func() {
+		if err := client.Remove(name); err != nil && !isSFTPNotExist(err) {
+			slog.Warn("remove remote compose-env file", "path", name, "err", err)
+		}
+		_ = client.Close()
+	}
+	file, err := client.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL)
+	if err != nil {
+		_ = client.Close()
+		return "", nil, fmt.Errorf("create remote compose-env file: %w", err)
+	}
+	// restrict the file before any value is written to it
+	err = file.Chmod(0o600)
+	if err == nil {
+		_, err = file.Write([]byte(composeEnvAssignments(values)))
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("write remote compose-env file: %w", err)
+	}
+	return name, cleanup, nil
+}
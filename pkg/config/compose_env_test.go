@@ -0,0 +1,119 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/libops/sitectl/pkg/keyring"
+)
+
+func TestComposeEnvironmentResolvesSecretReferences(t *testing.T) {
+	originalGet := keyringGet
+	t.Cleanup(func() { keyringGet = originalGet })
+	keyringGet = func(account string) (string, error) {
+		if account != "sites/prod/build" {
+			return "", keyring.ErrNotFound
+		}
+		return "s3cret", nil
+	}
+	t.Setenv("SITECTL_TEST_BUILD_TAG", "v1.2.3")
+
+	ctx := Context{Name: "prod", ComposeEnv: map[string]string{
+		"COMPOSE_PROJECT_NAME": "museum",
+		"BUILD_TAG":            "env:SITECTL_TEST_BUILD_TAG",
+		"REGISTRY_TOKEN":       "keyring:sites/prod/build",
+	}}
+	values, err := ctx.ComposeEnvironment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"COMPOSE_PROJECT_NAME": "museum", "BUILD_TAG": "v1.2.3", "REGISTRY_TOKEN": "s3cret"}
+	for name, value := range want {
+		if values[name] != value {
+			t.Errorf("%s = %q, want %q", name, values[name], value)
+		}
+	}
+
+	ctx.ComposeEnv = map[string]string{"TOKEN": "keyring:missing"}
+	if _, err := ctx.ComposeEnvironment(); !errors.Is(err, keyring.ErrNotFound) {
+		t.Errorf("missing keyring secret error = %v", err)
+	}
+	ctx.ComposeEnv = map[string]string{"TAG": "env:SITECTL_TEST_UNSET_VARIABLE"}
+	if _, err := ctx.ComposeEnvironment(); err == nil || !strings.Contains(err.Error(), "is not set") {
+		t.Errorf("unset variable error = %v", err)
+	}
+	ctx.ComposeEnv = map[string]string{"BAD NAME": "x"}
+	if _, err := ctx.ComposeEnvironment(); err == nil {
+		t.Error("expected invalid name error")
+	}
+}
+
+func TestExecInjectsComposeEnvironmentIntoDockerCompose(t *testing.T) {
+	bin := t.TempDir()
+	script := "#!/bin/sh\nprintf '%s' \"$COMPOSE_PROJECT_NAME\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "docker"), []byte(script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	ctx := &Context{DockerHostType: ContextLocal, ComposeEnv: map[string]string{"COMPOSE_PROJECT_NAME": "museum"}}
+
+	result, err := ctx.Exec(context.Background(), exec.Command(filepath.Join(bin, "docker"), "compose", "ps"), ExecOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(result.Stdout) != "museum" {
+		t.Errorf("docker compose saw COMPOSE_PROJECT_NAME=%q", result.Stdout)
+	}
+
+	result, err = ctx.Exec(context.Background(), exec.Command("sh", "-c", "printf '%s' \"$COMPOSE_PROJECT_NAME\""), ExecOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(result.Stdout), "museum") {
+		t.Errorf("non-compose command saw COMPOSE_PROJECT_NAME=%q", result.Stdout)
+	}
+}
+
+func TestComposeEnvAssignmentsQuotes(t *testing.T) {
+	values := map[string]string{"B": "two words", "A": "it's"}
+	if got := composeEnvAssignments(values); got != "A=it\\'s\nB='two words'\n" {
+		t.Errorf("assignments = %q", got)
+	}
+}
+
+func TestRemoteCommandKeepsComposeEnvOffTheCommandLine(t *testing.T) {
+	const secret = "s3cr3t value; $(touch pwned)"
+	values := map[string]string{"DB_PASSWORD": secret, "BUILD_TAG": "v1"}
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, "compose.env")
+	if err := os.WriteFile(envFile, []byte(composeEnvAssignments(values)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	command := remoteCommand(dir, envFile, []string{"sh", "-c", `printf '%s|%s' "$DB_PASSWORD" "$BUILD_TAG"`})
+	if strings.Contains(command, "s3cr3t") {
+		t.Fatalf("remote command contains the secret: %s", command)
+	}
+	// run it the way the remote login shell would
+	out, err := exec.Command("sh", "-c", command).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(out); got != secret+"|v1" {
+		t.Errorf("command saw %q, want %q", got, secret+"|v1")
+	}
+	if _, err := os.Stat(envFile); !os.IsNotExist(err) {
+		t.Errorf("env file was not removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pwned")); !os.IsNotExist(err) {
+		t.Error("a compose-env value was evaluated by the shell")
+	}
+
+	if got := remoteCommand("/srv/site", "", []string{"docker", "compose", "ps"}); got != "cd /srv/site && docker compose ps" {
+		t.Errorf("remoteCommand() without env = %q", got)
+	}
+}
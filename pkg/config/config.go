@@ -7,11 +7,13 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/libops/sitectl/pkg/profile"
 	yaml "gopkg.in/yaml.v3"
 )
 
 type Config struct {
 	CurrentContext string    `yaml:"current-context"`
+	Defaults       Defaults  `yaml:"defaults,omitempty"`
 	Contexts       []Context `yaml:"contexts"`
 }
 
@@ -32,6 +34,7 @@ func ConfigFilePath() (string, error) {
 }
 
 func Load() (*Config, error) {
+	defer profile.Start(profile.Config, "load config")()
 	path, err := ConfigFilePath()
 	if err != nil {
 		return nil, err
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/libops/sitectl/pkg/helpers"
+	"github.com/libops/sitectl/pkg/profile"
 	"github.com/spf13/pflag"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/knownhosts"
@@ -33,9 +34,11 @@ const (
 type Context struct {
 	Name                string      `yaml:"name"`
 	Site                string      `yaml:"site"`
+	SiteID              string      `yaml:"site-id,omitempty"`
 	Plugin              string      `yaml:"plugin"`
 	DockerHostType      ContextType `mapstructure:"type" yaml:"type"`
 	Environment         string      `yaml:"environment,omitempty"`
+	APIURL              string      `yaml:"api-url,omitempty"`
 	DockerSocket        string      `yaml:"docker-socket"`
 	ProjectName         string      `yaml:"project-name"`
 	ComposeProjectName  string      `yaml:"compose-project-name,omitempty"`
@@ -43,6 +46,7 @@ type Context struct {
 	ProjectDir          string      `yaml:"project-dir"`
 	DrupalRootfs        string      `yaml:"drupal-rootfs,omitempty"`
 	DrupalContainerRoot string      `yaml:"drupal-container-root,omitempty"`
+	WPContainerRoot     string      `yaml:"wp-container-root,omitempty"`
 	SSHUser             string      `yaml:"ssh-user"`
 	SSHHostname         string      `yaml:"ssh-hostname,omitempty"`
 	SSHPort             uint        `yaml:"ssh-port,omitempty"`
@@ -50,6 +54,37 @@ type Context struct {
 	EnvFile             []string    `yaml:"env-file"`
 	ComposeFile         []string    `yaml:"compose-file,omitempty"`
 
+	// SSHConfigHost names a Host in ~/.ssh/config. When set, its HostName,
+	// Port, User, IdentityFile and ProxyJump replace the ssh-* fields above.
+	SSHConfigHost string `yaml:"ssh-config-host,omitempty"`
+
+	// SSHJumpHost is a bastion to connect through, with the same key. The
+	// jump user and port default to ssh-user and 22.
+	SSHJumpHost string `yaml:"ssh-jump-host,omitempty"`
+	SSHJumpUser string `yaml:"ssh-jump-user,omitempty"`
+	SSHJumpPort uint   `yaml:"ssh-jump-port,omitempty"`
+
+	// ComposeEnv is exported into the environment of docker compose commands,
+	// deploy rollouts and plugin compose steps run on the context, locally or
+	// over SSH. Values may reference secrets; see ComposeEnvironment.
+	ComposeEnv map[string]string `yaml:"compose-env,omitempty"`
+
+	// Labels are free-form key=value tags, such as owner=web-team, used to
+	// select contexts with --selector.
+	Labels map[string]string `yaml:"labels,omitempty"`
+
+	// Protected makes destructive commands refuse to touch this context, even
+	// with --yes, until it is unprotected.
+	Protected bool `yaml:"protected,omitempty"`
+
+	// PortForwards are named port-forward presets, each a list of
+	// LOCAL-PORT:SERVICE:REMOTE-PORT specs, used with port-forward --preset.
+	PortForwards map[string][]string `yaml:"port-forwards,omitempty"`
+
+	// Registries are the container registries logged in with sitectl registry
+	// login. Their passwords are kept in the system keyring.
+	Registries []RegistryLogin `yaml:"registries,omitempty"`
+
 	// Database connection configuration
 	DatabaseService        string `yaml:"database-service,omitempty"`
 	DatabaseUser           string `yaml:"database-user,omitempty"`
@@ -71,6 +106,10 @@ type FileReader interface {
 
 var ErrContextNotFound = errors.New("context not found")
 
+// ErrContextProtected is returned when a destructive operation targets a
+// protected context.
+var ErrContextProtected = errors.New("context is protected")
+
 func ContextExists(name string) (bool, error) {
 	c, err := Load()
 	if err != nil {
@@ -249,37 +288,37 @@ func (c Context) EffectiveComposeNetwork() string {
 	return helpers.FirstNonEmpty(c.ComposeNetwork, c.EffectiveComposeProjectName()+"_default")
 }
 
-func (c *Context) DialSSH() (*ssh.Client, error) {
-	key, err := os.ReadFile(c.SSHKeyPath)
-	if err != nil {
-		return nil, fmt.Errorf("error reading SSH key: %w", err)
+// IsProduction reports whether the context is tagged as a production
+// environment. Destructive cross-context commands refuse to target these
+// without an explicit override.
+func (c Context) IsProduction() bool {
+	switch strings.ToLower(strings.TrimSpace(c.Environment)) {
+	case "prod", "production", "live":
+		return true
+	default:
+		return false
 	}
+}
 
-	// Try to parse the key without a passphrase first
-	signer, err := ssh.ParsePrivateKey(key)
-	if err != nil {
-		// Check if the error is due to encryption (passphrase required)
-		var ppErr *ssh.PassphraseMissingError
-		if errors.As(err, &ppErr) {
-			if !term.IsTerminal(int(os.Stdin.Fd())) {
-				return nil, fmt.Errorf("ssh key %s requires a passphrase, but no interactive terminal is available", c.SSHKeyPath)
-			}
-			// Key is encrypted, prompt for passphrase
-			fmt.Printf("Enter passphrase for SSH key %s: ", c.SSHKeyPath)
-			passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
-			fmt.Println() // Print newline after password input
-			if err != nil {
-				return nil, fmt.Errorf("error reading passphrase: %w", err)
-			}
+// RequireUnprotected returns ErrContextProtected when the context is
+// protected, and ErrReadOnly in read-only mode. action describes the refused
+// operation, e.g. "delete volumes".
+func (c Context) RequireUnprotected(action string) error {
+	if err := RequireWritable(action); err != nil {
+		return err
+	}
+	if !c.Protected {
+		return nil
+	}
+	return fmt.Errorf("%w: refusing to %s in context %q; run `sitectl config unprotect-context %s` first", ErrContextProtected, action, c.Name, c.Name)
+}
 
-			// Try to parse with the passphrase
-			signer, err = ssh.ParsePrivateKeyWithPassphrase(key, passphrase)
-			if err != nil {
-				return nil, fmt.Errorf("error parsing SSH key with passphrase: %w", err)
-			}
-		} else {
-			return nil, fmt.Errorf("error parsing SSH key: %w", err)
-		}
+// DialSSH opens a new SSH connection to the context's host, through any jump
+// hosts, that the caller must close. See SSHClient for the shared one.
+func (c *Context) DialSSH() (*ssh.Client, error) {
+	route, err := c.sshRoute()
+	if err != nil {
+		return nil, err
 	}
 
 	knownHostsPath, err := defaultKnownHostsPath()
@@ -295,8 +334,39 @@ func (c *Context) DialSSH() (*ssh.Client, error) {
 		return nil, fmt.Errorf("error creating known_hosts callback: %w", err)
 	}
 
+	signers := map[string]ssh.Signer{}
+	var client *ssh.Client
+	for _, endpoint := range route {
+		signer, ok := signers[endpoint.KeyPath]
+		if !ok {
+			if signer, err = loadSSHSigner(endpoint.KeyPath); err != nil {
+				closeSSHClient(client)
+				return nil, err
+			}
+			signers[endpoint.KeyPath] = signer
+		}
+		next, err := c.dialSSHEndpoint(client, endpoint, signer, hostKeyCallback)
+		if err != nil {
+			closeSSHClient(client)
+			return nil, err
+		}
+		if client != nil {
+			// the jump host connection lives as long as the connection through it
+			jump := client
+			go func() {
+				_ = next.Wait()
+				_ = jump.Close()
+			}()
+		}
+		client = next
+	}
+	return client, nil
+}
+
+// dialSSHEndpoint connects to endpoint directly, or through jump when set.
+func (c *Context) dialSSHEndpoint(jump *ssh.Client, endpoint SSHEndpoint, signer ssh.Signer, hostKeyCallback ssh.HostKeyCallback) (*ssh.Client, error) {
 	sshConfig := &ssh.ClientConfig{
-		User: c.SSHUser,
+		User: endpoint.User,
 		Auth: []ssh.AuthMethod{
 			ssh.PublicKeys(signer),
 		},
@@ -304,14 +374,16 @@ func (c *Context) DialSSH() (*ssh.Client, error) {
 		Timeout:         5 * time.Second,
 	}
 
-	sshAddr := net.JoinHostPort(c.SSHHostname, strconv.Itoa(int(c.SSHPort)))
+	sshAddr := endpoint.address()
 	sshConfig.HostKeyAlgorithms = knownHostKeyAlgorithms(hostKeyCallback, sshAddr)
 	if len(sshConfig.HostKeyAlgorithms) > 0 {
 		slog.Debug("Restricting SSH host key algorithms from known_hosts", "host", sshAddr, "algorithms", sshConfig.HostKeyAlgorithms)
 	}
 
 	slog.Debug("Dialing " + sshAddr)
-	client, err := ssh.Dial("tcp", sshAddr, sshConfig)
+	stopDial := profile.Start(profile.SSH, "dial "+sshAddr)
+	client, err := dialSSHThrough(jump, sshAddr, sshConfig)
+	stopDial()
 	if err != nil {
 		var keyErr *knownhosts.KeyError
 		if errors.As(err, &keyErr) {
@@ -324,15 +396,79 @@ func (c *Context) DialSSH() (*ssh.Client, error) {
 				fmt.Println("Please verify the new key with your host administrator.")
 				fmt.Println("If the change is legitimate, update your known_hosts file by removing the old key and adding the new one.")
 			}
-			fmt.Printf("\nTry running `ssh -p %d -t %s@%s` and trying again\n\n", c.SSHPort, c.SSHUser, c.SSHHostname)
-
+			if alias := strings.TrimSpace(c.SSHConfigHost); alias != "" {
+				fmt.Printf("\nTry running `ssh -t %s` and trying again\n\n", alias)
+			} else {
+				fmt.Printf("\nTry running `ssh -p %d -t %s@%s` and trying again\n\n", endpoint.Port, endpoint.User, endpoint.Hostname)
+			}
 		}
 		return nil, fmt.Errorf("error dialing SSH at %s: %w", sshAddr, err)
 	}
-
 	return client, nil
 }
 
+func dialSSHThrough(jump *ssh.Client, addr string, sshConfig *ssh.ClientConfig) (*ssh.Client, error) {
+	if jump == nil {
+		return ssh.Dial("tcp", addr, sshConfig)
+	}
+	conn, err := jump.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	clientConn, channels, requests, err := ssh.NewClientConn(conn, addr, sshConfig)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return ssh.NewClient(clientConn, channels, requests), nil
+}
+
+func closeSSHClient(client *ssh.Client) {
+	if client != nil {
+		_ = client.Close()
+	}
+}
+
+// loadSSHSigner reads a private key, prompting for its passphrase when it is
+// encrypted and a terminal is available.
+func loadSSHSigner(keyPath string) (ssh.Signer, error) {
+	key, err := os.ReadFile(keyPath) // #nosec G304 -- key path is the context's configured SSH identity.
+	if err != nil {
+		return nil, fmt.Errorf("error reading SSH key: %w", err)
+	}
+
+	// Try to parse the key without a passphrase first
+	signer, err := ssh.ParsePrivateKey(key)
+	if err == nil {
+		return signer, nil
+	}
+	// Check if the error is due to encryption (passphrase required)
+	var ppErr *ssh.PassphraseMissingError
+	if !errors.As(err, &ppErr) {
+		return nil, fmt.Errorf("error parsing SSH key: %w", err)
+	}
+	if NonInteractive() {
+		return nil, fmt.Errorf("ssh key %s requires a passphrase: %w", keyPath, ErrNonInteractive)
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("ssh key %s requires a passphrase, but no interactive terminal is available", keyPath)
+	}
+	// Key is encrypted, prompt for passphrase
+	fmt.Printf("Enter passphrase for SSH key %s: ", keyPath)
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println() // Print newline after password input
+	if err != nil {
+		return nil, fmt.Errorf("error reading passphrase: %w", err)
+	}
+
+	// Try to parse with the passphrase
+	signer, err = ssh.ParsePrivateKeyWithPassphrase(key, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing SSH key with passphrase: %w", err)
+	}
+	return signer, nil
+}
+
 func knownHostKeyAlgorithms(hostKeyCallback ssh.HostKeyCallback, hostWithPort string) []string {
 	if hostKeyCallback == nil {
 		return nil
@@ -429,6 +565,45 @@ func (c *Context) ProjectDirExists() (bool, error) {
 }
 
 func (cc *Context) VerifyRemoteInput(existingSite bool) error {
+	// an ~/.ssh/config alias supplies the host, user, port and key
+	testSsh := strings.TrimSpace(cc.SSHConfigHost) != ""
+	if !testSsh {
+		var err error
+		if testSsh, err = cc.promptSSHSettings(existingSite); err != nil {
+			return err
+		}
+	}
+
+	if testSsh {
+		sshClient, err := cc.DialSSH()
+		if err != nil {
+			return fmt.Errorf("ssh config does not seem correct: %v", err)
+		}
+		if err := sshClient.Close(); err != nil {
+			return fmt.Errorf("close test SSH connection: %w", err)
+		}
+		fmt.Println("Tested SSH connection OK!")
+	}
+
+	if cc.EffectiveComposeProjectName() == "docker-compose" || cc.EffectiveComposeProjectName() == "" {
+		question := []string{
+			"What is the docker compose project name (COMPOSE_PROJECT_NAME in your .env)? [docker-compose]: ",
+		}
+		pn, err := GetInput(question...)
+		if err != nil {
+			return fmt.Errorf("error reading input")
+		}
+		if pn != "" {
+			cc.ComposeProjectName = pn
+		}
+	}
+
+	return nil
+}
+
+// promptSSHSettings asks for the SSH settings the context is missing and
+// reports whether any changed, so the connection should be tested.
+func (cc *Context) promptSSHSettings(existingSite bool) (bool, error) {
 	testSsh := false
 	if cc.SSHHostname == "" {
 		question := []string{
@@ -436,7 +611,7 @@ func (cc *Context) VerifyRemoteInput(existingSite bool) error {
 		}
 		h, err := GetInput(question...)
 		if err != nil || h == "" {
-			return fmt.Errorf("error reading input")
+			return false, fmt.Errorf("error reading input")
 		}
 		testSsh = true
 		cc.SSHHostname = h
@@ -445,7 +620,7 @@ func (cc *Context) VerifyRemoteInput(existingSite bool) error {
 	if cc.SSHUser == "" {
 		u, err := user.Current()
 		if err != nil {
-			return fmt.Errorf("unable to determine current user: %v", err)
+			return false, fmt.Errorf("unable to determine current user: %v", err)
 		}
 		cc.SSHUser = u.Username
 		question := []string{
@@ -453,7 +628,7 @@ func (cc *Context) VerifyRemoteInput(existingSite bool) error {
 		}
 		un, err := GetInput(question...)
 		if err != nil {
-			return fmt.Errorf("error reading input")
+			return false, fmt.Errorf("error reading input")
 		}
 		if un != "" {
 			testSsh = true
@@ -475,12 +650,12 @@ func (cc *Context) VerifyRemoteInput(existingSite bool) error {
 		}
 		p, err := GetInput(question...)
 		if err != nil {
-			return fmt.Errorf("error reading input")
+			return false, fmt.Errorf("error reading input")
 		}
 		if p != "" {
 			port, err := strconv.Atoi(p)
 			if err != nil {
-				return fmt.Errorf("unable to convert port to an integer: %v", err)
+				return false, fmt.Errorf("unable to convert port to an integer: %v", err)
 			}
 			cc.SSHPort = uint(port)
 			testSsh = true
@@ -497,44 +672,20 @@ func (cc *Context) VerifyRemoteInput(existingSite bool) error {
 		}
 		k, err := GetInput(question...)
 		if err != nil {
-			return fmt.Errorf("error reading input")
+			return false, fmt.Errorf("error reading input")
 		}
 		if k != "" {
 			cc.SSHKeyPath = k
 		}
 		_, err = os.Stat(cc.SSHKeyPath)
 		if os.IsNotExist(err) {
-			return fmt.Errorf("SSH key does not exist: %s", cc.SSHKeyPath)
+			return false, fmt.Errorf("SSH key does not exist: %s", cc.SSHKeyPath)
 		} else if err != nil {
-			return fmt.Errorf("could not determine if SSH key exists: %v", err)
-		}
-	}
-
-	if testSsh {
-		sshClient, err := cc.DialSSH()
-		if err != nil {
-			return fmt.Errorf("ssh config does not seem correct: %v", err)
-		}
-		if err := sshClient.Close(); err != nil {
-			return fmt.Errorf("close test SSH connection: %w", err)
-		}
-		fmt.Println("Tested SSH connection OK!")
-	}
-
-	if cc.EffectiveComposeProjectName() == "docker-compose" || cc.EffectiveComposeProjectName() == "" {
-		question := []string{
-			"What is the docker compose project name (COMPOSE_PROJECT_NAME in your .env)? [docker-compose]: ",
-		}
-		pn, err := GetInput(question...)
-		if err != nil {
-			return fmt.Errorf("error reading input")
-		}
-		if pn != "" {
-			cc.ComposeProjectName = pn
+			return false, fmt.Errorf("could not determine if SSH key exists: %v", err)
 		}
 	}
 
-	return nil
+	return testSsh, nil
 }
 
 func (c *Context) UploadFile(source, destination string) error {
@@ -146,6 +146,34 @@ func TestContextString(t *testing.T) {
 	}
 }
 
+func TestContextIsProduction(t *testing.T) {
+	for environment, want := range map[string]bool{
+		"":            false,
+		"local":       false,
+		"staging":     false,
+		"prod":        true,
+		" Production": true,
+		"live":        true,
+	} {
+		if got := (Context{Environment: environment}).IsProduction(); got != want {
+			t.Fatalf("IsProduction(%q) = %v, want %v", environment, got, want)
+		}
+	}
+}
+
+func TestContextRequireUnprotected(t *testing.T) {
+	if err := (Context{Name: "stage"}).RequireUnprotected("flush the cache"); err != nil {
+		t.Fatalf("RequireUnprotected() on unprotected context = %v", err)
+	}
+	err := (Context{Name: "prod", Protected: true}).RequireUnprotected("flush the cache")
+	if !errors.Is(err, ErrContextProtected) {
+		t.Fatalf("RequireUnprotected() = %v, want ErrContextProtected", err)
+	}
+	if !strings.Contains(err.Error(), `flush the cache in context "prod"`) {
+		t.Fatalf("RequireUnprotected() message = %q", err)
+	}
+}
+
 func TestSaveContext(t *testing.T) {
 	tempHome := t.TempDir()
 	t.Setenv("HOME", tempHome)
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Color preferences for Defaults.Color.
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// Confirmation behaviors for Defaults.Confirm.
+const (
+	ConfirmPrompt = "prompt"
+	ConfirmYes    = "yes"
+)
+
+// Defaults are the defaults: section of the config file. They replace the
+// built-in defaults of flags, so a flag passed on the command line still wins.
+type Defaults struct {
+	// Format is the default --format of commands that accept it, such as json.
+	Format string `yaml:"format,omitempty"`
+	// Color is auto, always or never.
+	Color string `yaml:"color,omitempty"`
+	// Confirm is prompt, or yes to auto-confirm like --yes. It does not
+	// answer confirmations that ask to type a name, such as db push.
+	Confirm string `yaml:"confirm,omitempty"`
+	// ReadOnly turns on --read-only, for credentials shared in demos and
+	// screen-shares.
+	ReadOnly bool `yaml:"read-only,omitempty"`
+}
+
+// Validate checks the color and confirm values.
+func (d Defaults) Validate() error {
+	switch strings.TrimSpace(d.Color) {
+	case "", ColorAuto, ColorAlways, ColorNever:
+	default:
+		return fmt.Errorf("invalid defaults.color %q: use %s, %s or %s", d.Color, ColorAuto, ColorAlways, ColorNever)
+	}
+	switch strings.TrimSpace(d.Confirm) {
+	case "", ConfirmPrompt, ConfirmYes:
+	default:
+		return fmt.Errorf("invalid defaults.confirm %q: use %s or %s", d.Confirm, ConfirmPrompt, ConfirmYes)
+	}
+	return nil
+}
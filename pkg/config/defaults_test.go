@@ -0,0 +1,36 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultsLoadAndValidate(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, ".sitectl"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	data := "current-context: local\ndefaults:\n  format: json\n  color: never\n  confirm: yes\ncontexts: []\n"
+	if err := os.WriteFile(filepath.Join(home, ".sitectl", "config.yaml"), []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Defaults{Format: "json", Color: ColorNever, Confirm: ConfirmYes}
+	if cfg.Defaults != want {
+		t.Errorf("Defaults = %+v, want %+v", cfg.Defaults, want)
+	}
+	if err := cfg.Defaults.Validate(); err != nil {
+		t.Error(err)
+	}
+	for _, invalid := range []Defaults{{Color: "sometimes"}, {Confirm: "no"}} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded", invalid)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"sync"
+
+	"github.com/kballard/go-shellquote"
+	"github.com/libops/sitectl/pkg/profile"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+)
+
+// ExecOptions configure Context.Exec.
+type ExecOptions struct {
+	// Stdin is the command's standard input; nil means none.
+	Stdin io.Reader
+	// OnStdout and OnStderr receive output as it arrives, one call at a
+	// time. The chunk is only valid during the call.
+	OnStdout func(chunk []byte)
+	OnStderr func(chunk []byte)
+	// TTY requests a pseudo-terminal for remote commands and puts a local
+	// terminal on stdin in raw mode. The remote side then merges stderr into
+	// stdout. Local commands never get a pseudo-terminal.
+	TTY bool
+	// ComposeEnv exports the context's ComposeEnvironment for commands that
+	// run docker compose indirectly, such as a bash -lc rollout script. A
+	// docker compose argv gets it regardless.
+	ComposeEnv bool
+}
+
+// ExecResult is the captured outcome of Context.Exec.
+type ExecResult struct {
+	Stdout []byte
+	Stderr []byte
+	// ExitCode is the command's exit status, or -1 when it did not exit
+	// normally, such as when it failed to start or was killed by a signal.
+	ExitCode int
+}
+
+// Exec runs cmd on the context: locally in cmd.Dir (default: the project
+// directory), or over SSH in the project directory. It captures stdout and
+// stderr in full and separately. A non-zero exit status is returned as an
+// error wrapping *exec.ExitError or *ssh.ExitError, with ExitCode set.
+// docker compose commands, and others with opts.ComposeEnv, also get the
+// context's ComposeEnvironment.
+func (c *Context) Exec(ctx context.Context, cmd *exec.Cmd, opts ExecOptions) (ExecResult, error) {
+	var mu sync.Mutex
+	var stdout, stderr bytes.Buffer
+	stdoutWriter := &execOutput{mu: &mu, buf: &stdout, callback: opts.OnStdout}
+	stderrWriter := &execOutput{mu: &mu, buf: &stderr, callback: opts.OnStderr}
+	result := func(exitCode int) ExecResult {
+		mu.Lock()
+		defer mu.Unlock()
+		return ExecResult{Stdout: stdout.Bytes(), Stderr: stderr.Bytes(), ExitCode: exitCode}
+	}
+
+	var composeEnv map[string]string
+	if opts.ComposeEnv || isDockerComposeCommand(cmd.Args) {
+		var err error
+		if composeEnv, err = c.ComposeEnvironment(); err != nil {
+			return result(-1), err
+		}
+	}
+
+	if c.DockerHostType == ContextLocal {
+		local := exec.CommandContext(ctx, cmd.Path, cmd.Args[1:]...) // #nosec G204 -- command path is selected by sitectl and arguments are forwarded without a shell.
+		local.Env = cmd.Env
+		if len(local.Env) == 0 {
+			local.Env = os.Environ()
+		}
+		local.Env = AppendEnvOverrides(local.Env, composeEnv)
+		local.Dir = cmd.Dir
+		if local.Dir == "" {
+			local.Dir = c.ProjectDir
+		}
+		local.Stdin = opts.Stdin
+		local.Stdout, local.Stderr = stdoutWriter, stderrWriter
+		if err := local.Start(); err != nil {
+			return result(-1), fmt.Errorf("error starting command %s: %v", local.String(), err)
+		}
+		if err := local.Wait(); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				return result(exitErr.ExitCode()), fmt.Errorf("error waiting for command %s: %w", local.String(), err)
+			}
+			return result(-1), fmt.Errorf("error waiting for command %s: %w", local.String(), err)
+		}
+		return result(0), nil
+	}
+
+	sshClient, err := c.SSHClient()
+	if err != nil {
+		return result(-1), fmt.Errorf("error establishing SSH connection: %v", err)
+	}
+
+	envFile := ""
+	if len(composeEnv) > 0 {
+		var removeEnvFile func()
+		envFile, removeEnvFile, err = uploadComposeEnv(sshClient, composeEnv)
+		if err != nil {
+			return result(-1), err
+		}
+		defer removeEnvFile()
+	}
+	remoteCmd := remoteCommand(c.ProjectDir, envFile, cmd.Args)
+	defer profile.Start(profile.Remote, shellquote.Join(cmd.Args...))()
+
+	slog.Info("Running remote command", "host", c.SSHHostname, "cmd", remoteCmd)
+	session, err := sshClient.NewSession()
+	if err != nil {
+		return result(-1), fmt.Errorf("error creating SSH session: %v", err)
+	}
+
+	// The client is shared through the SSH pool, so only the session is
+	// closed, by the watchdog goroutine on context cancellation or by
+	// deferred cleanup.
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var closeOnce sync.Once
+	closeSession := func() { _ = session.Close() }
+	defer closeOnce.Do(closeSession)
+	go func() {
+		<-runCtx.Done()
+		closeOnce.Do(closeSession)
+	}()
+
+	if opts.TTY {
+		modes := ssh.TerminalModes{
+			ssh.ECHO:          0,
+			ssh.TTY_OP_ISPEED: 14400,
+			ssh.TTY_OP_OSPEED: 14400,
+		}
+		width, height, err := term.GetSize(int(os.Stdin.Fd()))
+		if err != nil {
+			width = 80
+			height = 40
+		}
+		if err := session.RequestPty("xterm", width, height, modes); err != nil {
+			return result(-1), fmt.Errorf("error requesting pseudo terminal: %w", err)
+		}
+		if opts.Stdin == os.Stdin && term.IsTerminal(int(os.Stdin.Fd())) {
+			oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
+			if err != nil {
+				return result(-1), fmt.Errorf("failed to set terminal to raw mode: %v", err)
+			}
+			defer func() {
+				if err := term.Restore(int(os.Stdin.Fd()), oldState); err != nil {
+					slog.Error("Unable to return terminal to original state.", "err", err)
+				}
+			}()
+		}
+	}
+	session.Stdin = opts.Stdin
+	session.Stdout, session.Stderr = stdoutWriter, stderrWriter
+
+	// call ssh foo@host.tld "remoteCmd"
+	if err := session.Start(remoteCmd); err != nil {
+		return result(-1), fmt.Errorf("error starting remote command %q: %v", remoteCmd, err)
+	}
+	if err := session.Wait(); err != nil {
+		exitCode := -1
+		var exitErr *ssh.ExitError
+		if errors.As(err, &exitErr) && runCtx.Err() == nil {
+			exitCode = exitErr.ExitStatus()
+		}
+		return result(exitCode), remoteCommandWaitError(runCtx, remoteCmd, err)
+	}
+	return result(0), nil
+}
+
+// execOutput captures one output stream of Exec and forwards it to its
+// callback. The mutex is shared by both streams so callbacks never overlap.
+type execOutput struct {
+	mu       *sync.Mutex
+	buf      *bytes.Buffer
+	callback func([]byte)
+}
+
+func (w *execOutput) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf.Write(p)
+	if w.callback != nil {
+		w.callback(p)
+	}
+	return len(p), nil
+}
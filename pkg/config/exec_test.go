@@ -0,0 +1,55 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestExecLocalCapturesStreamsAndExitCode(t *testing.T) {
+	ctx := &Context{DockerHostType: ContextLocal}
+	var streamed bytes.Buffer
+	result, err := ctx.Exec(context.Background(), exec.Command("sh", "-c", "printf 'out\\n'; printf 'err\\n' >&2; exit 3"), ExecOptions{
+		OnStdout: func(chunk []byte) { streamed.Write(chunk) },
+	})
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("Exec() error = %v, want wrapped exec.ExitError", err)
+	}
+	if result.ExitCode != 3 {
+		t.Errorf("ExitCode = %d, want 3", result.ExitCode)
+	}
+	if string(result.Stdout) != "out\n" || string(result.Stderr) != "err\n" {
+		t.Errorf("Stdout = %q, Stderr = %q", result.Stdout, result.Stderr)
+	}
+	if streamed.String() != "out\n" {
+		t.Errorf("OnStdout received %q", streamed.String())
+	}
+}
+
+func TestExecLocalKeepsTrailingOutputAndStdin(t *testing.T) {
+	ctx := &Context{DockerHostType: ContextLocal}
+	result, err := ctx.Exec(context.Background(), exec.Command("cat"), ExecOptions{
+		Stdin: strings.NewReader("line\n\n"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 0 || string(result.Stdout) != "line\n\n" {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestExecLocalStartFailureHasNoExitCode(t *testing.T) {
+	ctx := &Context{DockerHostType: ContextLocal}
+	result, err := ctx.Exec(context.Background(), exec.Command("/nonexistent/sitectl-test"), ExecOptions{})
+	if err == nil {
+		t.Fatal("expected start error")
+	}
+	if result.ExitCode != -1 {
+		t.Errorf("ExitCode = %d, want -1", result.ExitCode)
+	}
+}
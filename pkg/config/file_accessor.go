@@ -31,8 +31,17 @@ func (c *Context) NewFileAccessor() (*FileAccessor, error) {
 	return NewFileAccessor(c)
 }
 
+// NewFileAccessor opens SFTP over the context's pooled SSH client; see
+// Context.SSHClient.
 func NewFileAccessor(ctx *Context) (*FileAccessor, error) {
-	return NewFileAccessorWithSSH(ctx, nil, true)
+	if ctx == nil || ctx.DockerHostType == ContextLocal {
+		return NewFileAccessorWithSSH(ctx, nil, true)
+	}
+	sshClient, err := ctx.SSHClient()
+	if err != nil {
+		return nil, err
+	}
+	return NewFileAccessorWithSSH(ctx, sshClient, false)
 }
 
 func NewFileAccessorWithSSH(ctx *Context, sshClient *ssh.Client, ownsSSH bool) (*FileAccessor, error) {
@@ -264,6 +273,15 @@ func (a *FileAccessor) RemoveFile(filename string) error {
 	return nil
 }
 
+// Link creates newname as a hard link to oldname, so a later atomic replace
+// of oldname leaves the previous contents, mode and owner at newname.
+func (a *FileAccessor) Link(oldname, newname string) error {
+	if a == nil || a.ctx == nil || a.ctx.DockerHostType == ContextLocal {
+		return os.Link(oldname, newname)
+	}
+	return a.sftp.Link(oldname, newname)
+}
+
 func (a *FileAccessor) RemoveAll(path string) error {
 	if strings.TrimSpace(path) == "" {
 		return nil
@@ -362,55 +380,16 @@ func (a *FileAccessor) Stat(path string) (fs.FileInfo, error) {
 	return a.sftp.Stat(path)
 }
 
+// UploadFile copies the local file or directory source to destination with
+// mode 0600. See Upload for progress, permission and resume options.
 func (a *FileAccessor) UploadFile(source, destination string) error {
-	localFile, err := os.Open(source) // #nosec G304 -- source is an explicit caller-selected upload path.
-	if err != nil {
-		return err
-	}
-	defer localFile.Close()
-
-	if a == nil || a.ctx == nil || a.ctx.DockerHostType == ContextLocal {
-		if err := os.MkdirAll(filepath.Dir(destination), 0o750); err != nil {
-			return err
-		}
-		return atomicCopyLocal(localFile, destination)
-	}
+	return a.Upload(context.Background(), source, destination, TransferOptions{})
+}
 
-	if err := mkdirAllRemote(a.sftp, path.Dir(destination)); err != nil {
-		return err
-	}
-	tempDestination, err := remoteUploadTempPath(destination)
-	if err != nil {
-		return err
-	}
-	remoteFile, err := a.sftp.OpenFile(tempDestination, os.O_CREATE|os.O_EXCL|os.O_WRONLY)
-	if err != nil {
-		return err
-	}
-	committed := false
-	defer func() {
-		_ = remoteFile.Close()
-		if !committed {
-			_ = a.sftp.Remove(tempDestination)
-		}
-	}()
-	if err := a.sftp.Chmod(tempDestination, 0o600); err != nil {
-		return err
-	}
-	if _, err := io.Copy(remoteFile, localFile); err != nil {
-		return err
-	}
-	if err := remoteFile.Sync(); err != nil {
-		return err
-	}
-	if err := remoteFile.Close(); err != nil {
-		return err
-	}
-	if err := a.sftp.PosixRename(tempDestination, destination); err != nil {
-		return fmt.Errorf("atomically publish remote upload: %w", err)
-	}
-	committed = true
-	return nil
+// WriteFileAtomic writes source to a temporary file next to destination and
+// renames it into place, so destination is never left truncated or partial.
+func WriteFileAtomic(destination string, source io.Reader) error {
+	return atomicCopyLocal(source, destination)
 }
 
 func atomicCopyLocal(source io.Reader, destination string) (err error) {
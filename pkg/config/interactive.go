@@ -0,0 +1,108 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/libops/sitectl/pkg/i18n"
+)
+
+// NonInteractiveEnv turns every prompt into an error when truthy. sitectl
+// sets it for itself and plugin subprocesses when --non-interactive is passed;
+// when it is unset, a truthy CI variable has the same effect.
+const NonInteractiveEnv = "SITECTL_NON_INTERACTIVE"
+
+// AssumeYesEnv answers every confirmation prompt with yes when truthy. sitectl
+// sets it for itself and plugin subprocesses when --yes is passed, and to
+// AssumeYesFromConfig when --yes comes from defaults.confirm.
+const AssumeYesEnv = "SITECTL_ASSUME_YES"
+
+// AssumeYesFromConfig is the AssumeYesEnv value for defaults.confirm: yes. It
+// answers confirmation prompts like --yes, except those that ask to type the
+// name of what is about to be replaced or deleted.
+const AssumeYesFromConfig = "config"
+
+// ReadOnlyEnv refuses every operation that can change a site when truthy.
+// sitectl sets it for itself and plugin subprocesses when --read-only is
+// passed or defaults.read-only is set.
+const ReadOnlyEnv = "SITECTL_READ_ONLY"
+
+// ErrNonInteractive is returned in place of prompting for input.
+var ErrNonInteractive = errors.New("input required, but sitectl is running non-interactively")
+
+// NonInteractive reports whether prompts are disabled.
+func NonInteractive() bool {
+	if value := strings.TrimSpace(os.Getenv(NonInteractiveEnv)); value != "" {
+		return envTruthy(value)
+	}
+	return envTruthy(os.Getenv("CI"))
+}
+
+// ErrReadOnly is returned in place of an operation that would change a site.
+var ErrReadOnly = errors.New("sitectl is running in read-only mode")
+
+// ReadOnly reports whether read-only mode is in effect.
+func ReadOnly() bool {
+	return envTruthy(os.Getenv(ReadOnlyEnv))
+}
+
+// RequireWritable returns ErrReadOnly in read-only mode. action describes the
+// refused operation, e.g. "delete volumes".
+func RequireWritable(action string) error {
+	if !ReadOnly() {
+		return nil
+	}
+	return fmt.Errorf("%w (--read-only, %s or defaults.read-only): refusing to %s", ErrReadOnly, ReadOnlyEnv, action)
+}
+
+// AssumeYes reports whether the global --yes flag is in effect.
+func AssumeYes() bool {
+	value := os.Getenv(AssumeYesEnv)
+	return envTruthy(value) || strings.TrimSpace(value) == AssumeYesFromConfig
+}
+
+// AutoConfirm reports whether the confirmation for action can be skipped,
+// either because the command's own flag (such as --yolo) is set or because
+// of the global --yes flag. Skipped confirmations are logged so unattended
+// runs record what they agreed to.
+func AutoConfirm(action string, skip bool) bool {
+	source := "flag"
+	if !skip {
+		if !AssumeYes() {
+			return false
+		}
+		source = "--yes"
+	}
+	slog.Info("auto-confirmed", "action", action, "via", source)
+	return true
+}
+
+// AutoConfirmTyped is AutoConfirm for confirmations that ask to type a name,
+// such as the target context. defaults.confirm: yes does not skip them; only
+// the command's own flag or --yes on the command line does.
+func AutoConfirmTyped(action string, skip bool) bool {
+	if !skip && strings.TrimSpace(os.Getenv(AssumeYesEnv)) == AssumeYesFromConfig {
+		return false
+	}
+	return AutoConfirm(action, skip)
+}
+
+func nonInteractiveError(question []string) error {
+	prompt := ""
+	if len(question) > 0 {
+		prompt = strings.TrimSpace(question[len(question)-1])
+	}
+	return fmt.Errorf("%w (prompt: %q); %s", ErrNonInteractive, prompt, i18n.T(i18n.NonInteractiveHint, NonInteractiveEnv))
+}
+
+func envTruthy(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "true", "yes", "on":
+		return true
+	default:
+		return false
+	}
+}
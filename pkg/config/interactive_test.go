@@ -0,0 +1,84 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNonInteractive(t *testing.T) {
+	tests := []struct {
+		name string
+		flag string
+		ci   string
+		want bool
+	}{
+		{name: "default", want: false},
+		{name: "explicit", flag: "1", want: true},
+		{name: "ci detected", ci: "true", want: true},
+		{name: "explicit opt out wins over ci", flag: "0", ci: "true", want: false},
+		{name: "ci false", ci: "false", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(NonInteractiveEnv, tt.flag)
+			t.Setenv("CI", tt.ci)
+			if got := NonInteractive(); got != tt.want {
+				t.Fatalf("NonInteractive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetInputFailsWhenNonInteractive(t *testing.T) {
+	t.Setenv(NonInteractiveEnv, "1")
+	_, err := GetInput("About to delete things.", "Continue? [y/N]: ")
+	if !errors.Is(err, ErrNonInteractive) {
+		t.Fatalf("GetInput() error = %v, want ErrNonInteractive", err)
+	}
+}
+
+func TestAutoConfirm(t *testing.T) {
+	t.Setenv(AssumeYesEnv, "")
+	if AutoConfirm("delete things", false) {
+		t.Fatal("expected a prompt without --yolo or --yes")
+	}
+	if !AutoConfirm("delete things", true) {
+		t.Fatal("expected the command flag to skip the prompt")
+	}
+	t.Setenv(AssumeYesEnv, "1")
+	if !AutoConfirm("delete things", false) {
+		t.Fatal("expected global --yes to skip the prompt")
+	}
+}
+
+func TestAutoConfirmTypedIgnoresConfigDefault(t *testing.T) {
+	t.Setenv(AssumeYesEnv, AssumeYesFromConfig)
+	if !AutoConfirm("delete things", false) {
+		t.Fatal("expected defaults.confirm to skip a yes/no prompt")
+	}
+	if AutoConfirmTyped("replace prod", false) {
+		t.Fatal("expected defaults.confirm to leave a typed-name prompt on")
+	}
+	if !AutoConfirmTyped("replace prod", true) {
+		t.Fatal("expected the command flag to skip a typed-name prompt")
+	}
+	t.Setenv(AssumeYesEnv, "1")
+	if !AutoConfirmTyped("replace prod", false) {
+		t.Fatal("expected --yes on the command line to skip a typed-name prompt")
+	}
+}
+
+func TestReadOnlyRefusesDestructiveOperations(t *testing.T) {
+	ctx := Context{Name: "prod"}
+	t.Setenv(ReadOnlyEnv, "")
+	if err := ctx.RequireUnprotected("prune volumes"); err != nil {
+		t.Fatalf("RequireUnprotected() = %v outside read-only mode", err)
+	}
+	t.Setenv(ReadOnlyEnv, "1")
+	if !ReadOnly() {
+		t.Fatal("ReadOnly() = false")
+	}
+	if err := ctx.RequireUnprotected("prune volumes"); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("RequireUnprotected() = %v, want ErrReadOnly", err)
+	}
+}
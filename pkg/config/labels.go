@@ -0,0 +1,171 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var labelKeyPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]*[A-Za-z0-9])?$`)
+
+// LabelChange is a parsed --label argument: key=value sets a label and key-
+// removes it.
+type LabelChange struct {
+	Key    string
+	Value  string
+	Remove bool
+}
+
+// ParseLabelChanges parses key=value and key- arguments.
+func ParseLabelChanges(args []string) ([]LabelChange, error) {
+	changes := make([]LabelChange, 0, len(args))
+	for _, arg := range args {
+		arg = strings.TrimSpace(arg)
+		if key, value, ok := strings.Cut(arg, "="); ok {
+			key = strings.TrimSpace(key)
+			if err := validateLabelKey(key); err != nil {
+				return nil, err
+			}
+			changes = append(changes, LabelChange{Key: key, Value: strings.TrimSpace(value)})
+			continue
+		}
+		if key, ok := strings.CutSuffix(arg, "-"); ok {
+			if err := validateLabelKey(key); err != nil {
+				return nil, err
+			}
+			changes = append(changes, LabelChange{Key: key, Remove: true})
+			continue
+		}
+		return nil, fmt.Errorf("invalid label %q: use key=value to set or key- to remove", arg)
+	}
+	return changes, nil
+}
+
+// ApplyLabelChanges applies changes to the context's labels and reports
+// whether anything changed.
+func (c *Context) ApplyLabelChanges(changes []LabelChange) bool {
+	changed := false
+	for _, change := range changes {
+		if change.Remove {
+			if _, ok := c.Labels[change.Key]; ok {
+				delete(c.Labels, change.Key)
+				changed = true
+			}
+			continue
+		}
+		if current, ok := c.Labels[change.Key]; ok && current == change.Value {
+			continue
+		}
+		if c.Labels == nil {
+			c.Labels = map[string]string{}
+		}
+		c.Labels[change.Key] = change.Value
+		changed = true
+	}
+	if len(c.Labels) == 0 {
+		c.Labels = nil
+	}
+	return changed
+}
+
+// FormatLabels renders labels as sorted key=value pairs separated by commas.
+func FormatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+labels[key])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// LabelSelector matches contexts by label. Each requirement must hold.
+type LabelSelector []labelRequirement
+
+type labelRequirement struct {
+	key      string
+	value    string
+	operator string
+}
+
+// ParseLabelSelector parses comma-separated requirements: key=value,
+// key!=value, key (label is set) and !key (label is not set). An empty
+// selector matches every context.
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	var parsed LabelSelector
+	for _, part := range strings.Split(selector, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		var req labelRequirement
+		switch {
+		case strings.Contains(part, "!="):
+			key, value, _ := strings.Cut(part, "!=")
+			req = labelRequirement{key: strings.TrimSpace(key), value: strings.TrimSpace(value), operator: "!="}
+		case strings.Contains(part, "="):
+			key, value, _ := strings.Cut(part, "=")
+			req = labelRequirement{key: strings.TrimSpace(key), value: strings.TrimSpace(value), operator: "="}
+		case strings.HasPrefix(part, "!"):
+			req = labelRequirement{key: strings.TrimSpace(strings.TrimPrefix(part, "!")), operator: "!"}
+		default:
+			req = labelRequirement{key: part, operator: "exists"}
+		}
+		if err := validateLabelKey(req.key); err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", selector, err)
+		}
+		parsed = append(parsed, req)
+	}
+	return parsed, nil
+}
+
+// Matches reports whether labels satisfy every requirement of s.
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for _, req := range s {
+		value, ok := labels[req.key]
+		switch req.operator {
+		case "=":
+			if !ok || value != req.value {
+				return false
+			}
+		case "!=":
+			if ok && value == req.value {
+				return false
+			}
+		case "!":
+			if ok {
+				return false
+			}
+		default:
+			if !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// FilterContexts returns the contexts whose labels match s.
+func (s LabelSelector) FilterContexts(contexts []Context) []Context {
+	if len(s) == 0 {
+		return contexts
+	}
+	filtered := make([]Context, 0, len(contexts))
+	for _, ctx := range contexts {
+		if s.Matches(ctx.Labels) {
+			filtered = append(filtered, ctx)
+		}
+	}
+	return filtered
+}
+
+func validateLabelKey(key string) error {
+	if !labelKeyPattern.MatchString(key) {
+		return fmt.Errorf("invalid label key %q: use letters, digits, '.', '_', '/' or '-', starting and ending with a letter or digit", key)
+	}
+	return nil
+}
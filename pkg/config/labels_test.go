@@ -0,0 +1,97 @@
+package config
+
+import "testing"
+
+func TestParseLabelChanges(t *testing.T) {
+	changes, err := ParseLabelChanges([]string{"owner=web-team", "tier-", "note="})
+	if err != nil {
+		t.Fatalf("ParseLabelChanges() error = %v", err)
+	}
+	want := []LabelChange{
+		{Key: "owner", Value: "web-team"},
+		{Key: "tier", Remove: true},
+		{Key: "note", Value: ""},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("ParseLabelChanges() = %#v, want %#v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("change %d = %#v, want %#v", i, changes[i], want[i])
+		}
+	}
+
+	for _, bad := range []string{"owner", "=value", "-", "bad key=1", "-owner=1"} {
+		if _, err := ParseLabelChanges([]string{bad}); err == nil {
+			t.Errorf("ParseLabelChanges(%q) expected error", bad)
+		}
+	}
+}
+
+func TestApplyLabelChanges(t *testing.T) {
+	ctx := Context{}
+	if !ctx.ApplyLabelChanges([]LabelChange{{Key: "owner", Value: "a"}, {Key: "tier", Value: "public"}}) {
+		t.Fatal("expected first apply to change labels")
+	}
+	if ctx.ApplyLabelChanges([]LabelChange{{Key: "owner", Value: "a"}}) {
+		t.Fatal("expected re-applying the same value to be a no-op")
+	}
+	if got := FormatLabels(ctx.Labels); got != "owner=a,tier=public" {
+		t.Fatalf("FormatLabels() = %q", got)
+	}
+	if !ctx.ApplyLabelChanges([]LabelChange{{Key: "owner", Remove: true}, {Key: "tier", Remove: true}}) {
+		t.Fatal("expected removal to change labels")
+	}
+	if ctx.Labels != nil {
+		t.Fatalf("expected labels to be cleared, got %#v", ctx.Labels)
+	}
+}
+
+func TestLabelSelector(t *testing.T) {
+	labels := map[string]string{"owner": "web-team", "tier": "public"}
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{selector: "", want: true},
+		{selector: "owner=web-team", want: true},
+		{selector: "owner=web-team,tier=public", want: true},
+		{selector: "owner=ops", want: false},
+		{selector: "tier!=internal", want: true},
+		{selector: "tier!=public", want: false},
+		{selector: "owner", want: true},
+		{selector: "backup", want: false},
+		{selector: "!backup", want: true},
+		{selector: "!owner", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			selector, err := ParseLabelSelector(tt.selector)
+			if err != nil {
+				t.Fatalf("ParseLabelSelector() error = %v", err)
+			}
+			if got := selector.Matches(labels); got != tt.want {
+				t.Fatalf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseLabelSelector("bad key=1"); err == nil {
+		t.Fatal("expected invalid selector error")
+	}
+}
+
+func TestLabelSelectorFilterContexts(t *testing.T) {
+	contexts := []Context{
+		{Name: "prod", Labels: map[string]string{"owner": "web-team"}},
+		{Name: "stage"},
+	}
+	selector, err := ParseLabelSelector("owner=web-team")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := selector.FilterContexts(contexts)
+	if len(got) != 1 || got[0].Name != "prod" {
+		t.Fatalf("FilterContexts() = %#v", got)
+	}
+}
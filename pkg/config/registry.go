@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// RegistryLogin is a container registry a context is logged in to.
+type RegistryLogin struct {
+	Host     string `yaml:"host"`
+	Username string `yaml:"username"`
+}
+
+// NormalizeRegistryHost returns the registry host of a host name or URL, such
+// as ghcr.io for https://ghcr.io/. Docker Hub aliases map to docker.io.
+func NormalizeRegistryHost(host string) (string, error) {
+	host = strings.TrimSpace(host)
+	if strings.Contains(host, "://") {
+		parsed, err := url.Parse(host)
+		if err != nil {
+			return "", fmt.Errorf("invalid registry %q: %w", host, err)
+		}
+		host = parsed.Host
+	}
+	host = strings.ToLower(strings.TrimRight(host, "/"))
+	if host == "" || strings.ContainsAny(host, "/ @") {
+		return "", fmt.Errorf("invalid registry %q: expected a host such as ghcr.io or registry.example.com:5000", host)
+	}
+	switch host {
+	case "index.docker.io", "registry-1.docker.io", "hub.docker.com":
+		host = "docker.io"
+	}
+	return host, nil
+}
+
+// RegistryKeyringAccount is the keyring account holding the password of a
+// context's registry login.
+func RegistryKeyringAccount(contextName, host string) string {
+	return "registry/" + contextName + "/" + host
+}
+
+// RegistryLogin returns the context's login for host.
+func (c Context) RegistryLogin(host string) (RegistryLogin, bool) {
+	i := slices.IndexFunc(c.Registries, func(login RegistryLogin) bool { return login.Host == host })
+	if i < 0 {
+		return RegistryLogin{}, false
+	}
+	return c.Registries[i], true
+}
+
+// SetRegistryLogin adds or replaces the context's login for login.Host.
+func (c *Context) SetRegistryLogin(login RegistryLogin) {
+	c.RemoveRegistryLogin(login.Host)
+	c.Registries = append(c.Registries, login)
+	slices.SortFunc(c.Registries, func(a, b RegistryLogin) int { return strings.Compare(a.Host, b.Host) })
+}
+
+// RemoveRegistryLogin removes the context's login for host and reports whether
+// there was one.
+func (c *Context) RemoveRegistryLogin(host string) bool {
+	n := len(c.Registries)
+	c.Registries = slices.DeleteFunc(c.Registries, func(login RegistryLogin) bool { return login.Host == host })
+	return len(c.Registries) != n
+}
+
+// ImageRegistryHost returns the registry host of an image reference, docker.io
+// for Docker Hub images.
+func ImageRegistryHost(image string) string {
+	first, _, found := strings.Cut(strings.TrimSpace(image), "/")
+	if !found || !strings.ContainsAny(first, ".:") && first != "localhost" {
+		return "docker.io"
+	}
+	return strings.ToLower(first)
+}
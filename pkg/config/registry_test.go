@@ -0,0 +1,52 @@
+package config
+
+import "testing"
+
+func TestNormalizeRegistryHost(t *testing.T) {
+	for input, want := range map[string]string{
+		"ghcr.io":                          "ghcr.io",
+		"https://GHCR.io/":                 "ghcr.io",
+		"registry.example.edu:5000":        "registry.example.edu:5000",
+		"index.docker.io":                  "docker.io",
+		"https://registry-1.docker.io/v2/": "docker.io",
+	} {
+		if got, err := NormalizeRegistryHost(input); err != nil || got != want {
+			t.Errorf("NormalizeRegistryHost(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "ghcr.io/libops", "user@ghcr.io"} {
+		if _, err := NormalizeRegistryHost(input); err == nil {
+			t.Errorf("NormalizeRegistryHost(%q) expected error", input)
+		}
+	}
+}
+
+func TestContextRegistryLogins(t *testing.T) {
+	var ctx Context
+	ctx.SetRegistryLogin(RegistryLogin{Host: "quay.io", Username: "a"})
+	ctx.SetRegistryLogin(RegistryLogin{Host: "ghcr.io", Username: "b"})
+	ctx.SetRegistryLogin(RegistryLogin{Host: "quay.io", Username: "c"})
+	if len(ctx.Registries) != 2 || ctx.Registries[0].Host != "ghcr.io" {
+		t.Fatalf("Registries = %+v", ctx.Registries)
+	}
+	if login, ok := ctx.RegistryLogin("quay.io"); !ok || login.Username != "c" {
+		t.Errorf("RegistryLogin(quay.io) = %+v, %v", login, ok)
+	}
+	if !ctx.RemoveRegistryLogin("quay.io") || ctx.RemoveRegistryLogin("quay.io") {
+		t.Error("RemoveRegistryLogin should only report the first removal")
+	}
+}
+
+func TestImageRegistryHost(t *testing.T) {
+	for image, want := range map[string]string{
+		"drupal:11":                       "docker.io",
+		"islandora/drupal:4":              "docker.io",
+		"ghcr.io/libops/site:abc":         "ghcr.io",
+		"localhost/site":                  "localhost",
+		"registry.example.edu:5000/a/b:1": "registry.example.edu:5000",
+	} {
+		if got := ImageRegistryHost(image); got != want {
+			t.Errorf("ImageRegistryHost(%q) = %q, want %q", image, got, want)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/libops/sitectl/pkg/helpers"
+)
+
+// maxSSHJumps bounds ProxyJump chains, which may refer to each other.
+const maxSSHJumps = 8
+
+// SSHEndpoint is one SSH server on the route to a context's host.
+// KeyPath is the identity file sitectl authenticates with.
+type SSHEndpoint struct {
+	User     string
+	Hostname string
+	Port     uint
+	KeyPath  string
+}
+
+func (e SSHEndpoint) address() string {
+	return net.JoinHostPort(e.Hostname, strconv.Itoa(int(e.Port)))
+}
+
+// sshConfigQuery prints OpenSSH's effective configuration for a host, as
+// ssh -G does; tests replace it.
+var sshConfigQuery = func(args ...string) (string, error) {
+	command := exec.Command("ssh", append([]string{"-G"}, args...)...) // #nosec G204 -- runs the fixed ssh client with a configured host alias.
+	output, err := command.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	return string(output), nil
+}
+
+// sshRoute returns the SSH servers to connect through, ending with the
+// context's host. Contexts with ssh-config-host take their host, port, user,
+// key and ProxyJump hosts from ~/.ssh/config; others use their ssh-* fields,
+// going through ssh-jump-host when it is set.
+func (c *Context) sshRoute() ([]SSHEndpoint, error) {
+	alias := strings.TrimSpace(c.SSHConfigHost)
+	if alias == "" {
+		host := SSHEndpoint{User: c.SSHUser, Hostname: c.SSHHostname, Port: c.SSHPort, KeyPath: c.SSHKeyPath}
+		jumpHost := strings.TrimSpace(c.SSHJumpHost)
+		if jumpHost == "" {
+			return []SSHEndpoint{host}, nil
+		}
+		jump := SSHEndpoint{
+			User:     helpers.FirstNonEmpty(strings.TrimSpace(c.SSHJumpUser), c.SSHUser),
+			Hostname: jumpHost,
+			Port:     c.SSHJumpPort,
+			KeyPath:  c.SSHKeyPath,
+		}
+		if jump.Port == 0 {
+			jump.Port = 22
+		}
+		return []SSHEndpoint{jump, host}, nil
+	}
+	route, err := resolveSSHConfigRoute(alias, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("resolve ssh-config-host %q: %w", alias, err)
+	}
+	return route, nil
+}
+
+// ResolvedSSHEndpoint returns the host, user, port and identity file sitectl
+// connects to for a remote context. Contexts with ssh-config-host leave the
+// ssh-* fields empty, so read the connection details from here rather than
+// from the fields.
+func (c *Context) ResolvedSSHEndpoint() (SSHEndpoint, error) {
+	route, err := c.sshRoute()
+	if err != nil {
+		return SSHEndpoint{}, err
+	}
+	return route[len(route)-1], nil
+}
+
+// SSHJumpHosts returns the jump hosts, in order, that sitectl goes through to
+// reach a remote context's host. It is empty when sitectl connects directly.
+func (c *Context) SSHJumpHosts() ([]SSHEndpoint, error) {
+	route, err := c.sshRoute()
+	if err != nil {
+		return nil, err
+	}
+	return route[:len(route)-1], nil
+}
+
+// resolveSSHConfigRoute resolves host, with extra ssh -G options such as -l
+// and -p, into its endpoint preceded by the endpoints of its ProxyJump hosts.
+func resolveSSHConfigRoute(host string, options []string, depth int) ([]SSHEndpoint, error) {
+	if depth > maxSSHJumps {
+		return nil, fmt.Errorf("more than %d ProxyJump hosts", maxSSHJumps)
+	}
+	output, err := sshConfigQuery(append(append([]string{}, options...), host)...)
+	if err != nil {
+		return nil, fmt.Errorf("ssh -G %s: %w", host, err)
+	}
+	values, identityFiles := parseSSHConfigDump(output)
+	if command := values["proxycommand"]; command != "" && command != "none" {
+		return nil, fmt.Errorf("host %s uses ProxyCommand, which sitectl does not support; use ProxyJump", host)
+	}
+	port, err := strconv.ParseUint(values["port"], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("host %s: invalid port %q", host, values["port"])
+	}
+	endpoint := SSHEndpoint{
+		User:     values["user"],
+		Hostname: values["hostname"],
+		Port:     uint(port),
+	}
+	endpoint.KeyPath = firstSSHIdentityFile(identityFiles, endpoint)
+	if endpoint.Hostname == "" || endpoint.User == "" {
+		return nil, fmt.Errorf("host %s: ssh -G did not report a hostname and user", host)
+	}
+
+	route := []SSHEndpoint{}
+	if jumps := values["proxyjump"]; jumps != "" && jumps != "none" {
+		for _, jump := range strings.Split(jumps, ",") {
+			jumpUser, jumpHost, jumpPort := parseSSHJump(jump)
+			jumpOptions := []string{}
+			if jumpUser != "" {
+				jumpOptions = append(jumpOptions, "-l", jumpUser)
+			}
+			if jumpPort != "" {
+				jumpOptions = append(jumpOptions, "-p", jumpPort)
+			}
+			hops, err := resolveSSHConfigRoute(jumpHost, jumpOptions, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			route = append(route, hops...)
+		}
+	}
+	return append(route, endpoint), nil
+}
+
+// parseSSHConfigDump reads ssh -G output: one lowercase keyword and value
+// per line. identityfile may repeat and is returned separately, in order.
+func parseSSHConfigDump(output string) (map[string]string, []string) {
+	values := map[string]string{}
+	identityFiles := []string{}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(key)
+		value = strings.TrimSpace(value)
+		if key == "identityfile" {
+			identityFiles = append(identityFiles, value)
+			continue
+		}
+		if _, seen := values[key]; !seen {
+			values[key] = value
+		}
+	}
+	return values, identityFiles
+}
+
+// firstSSHIdentityFile returns the first identity file that exists. ssh -G
+// lists the default keys when none is configured, so most are usually
+// missing. With none present it returns the first, for a clear read error.
+func firstSSHIdentityFile(files []string, endpoint SSHEndpoint) string {
+	expanded := make([]string, 0, len(files))
+	for _, file := range files {
+		expanded = append(expanded, expandSSHPath(file, endpoint))
+	}
+	for _, file := range expanded {
+		if _, err := os.Stat(file); err == nil {
+			return file
+		}
+	}
+	if len(expanded) == 0 {
+		return ""
+	}
+	return expanded[0]
+}
+
+// expandSSHPath expands ~ and the ssh_config tokens %d, %u, %h, %r and %% in
+// an IdentityFile path.
+func expandSSHPath(path string, endpoint SSHEndpoint) string {
+	home, _ := os.UserHomeDir()
+	if path == "~" {
+		path = home
+	} else if strings.HasPrefix(path, "~/") {
+		path = filepath.Join(home, path[2:])
+	}
+	localUser := ""
+	if current, err := user.Current(); err == nil {
+		localUser = current.Username
+	}
+	return strings.NewReplacer(
+		"%%", "%",
+		"%d", home,
+		"%u", localUser,
+		"%h", endpoint.Hostname,
+		"%r", endpoint.User,
+	).Replace(path)
+}
+
+// parseSSHJump splits a ProxyJump entry, [user@]host[:port] or
+// ssh://[user@]host[:port].
+func parseSSHJump(jump string) (jumpUser, host, port string) {
+	jump = strings.TrimPrefix(strings.TrimSpace(jump), "ssh://")
+	if at := strings.LastIndex(jump, "@"); at >= 0 {
+		jumpUser, jump = jump[:at], jump[at+1:]
+	}
+	host = jump
+	if strings.HasPrefix(jump, "[") {
+		if end := strings.Index(jump, "]"); end > 0 {
+			host = jump[1:end]
+			port = strings.TrimPrefix(jump[end+1:], ":")
+		}
+		return jumpUser, host, port
+	}
+	if colon := strings.LastIndex(jump, ":"); colon >= 0 && strings.Count(jump, ":") == 1 {
+		host, port = jump[:colon], jump[colon+1:]
+	}
+	return jumpUser, host, port
+}
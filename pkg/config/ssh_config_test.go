@@ -0,0 +1,191 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func stubSSHConfigQuery(t *testing.T, hosts map[string]string) *[][]string {
+	t.Helper()
+	calls := [][]string{}
+	original := sshConfigQuery
+	sshConfigQuery = func(args ...string) (string, error) {
+		calls = append(calls, args)
+		return hosts[args[len(args)-1]], nil
+	}
+	t.Cleanup(func() { sshConfigQuery = original })
+	return &calls
+}
+
+func TestSSHRouteResolvesAliasThroughProxyJump(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	key := filepath.Join(home, ".ssh", "id_ed25519")
+	if err := os.MkdirAll(filepath.Dir(key), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(key, []byte("key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	calls := stubSSHConfigQuery(t, map[string]string{
+		"prod": strings.Join([]string{
+			"user deploy",
+			"hostname 10.0.0.5",
+			"port 22",
+			"identityfile ~/.ssh/id_rsa",
+			"identityfile ~/.ssh/id_ed25519",
+			"proxyjump ops@bastion.example.org:2200",
+			"proxycommand none",
+		}, "\n"),
+		"bastion.example.org": strings.Join([]string{
+			"user ops",
+			"hostname bastion.example.org",
+			"port 2200",
+			"identityfile %d/.ssh/id_ed25519",
+			"proxyjump none",
+		}, "\n"),
+	})
+
+	route, err := (&Context{SSHConfigHost: "prod", SSHHostname: "ignored"}).sshRoute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SSHEndpoint{
+		{User: "ops", Hostname: "bastion.example.org", Port: 2200, KeyPath: key},
+		{User: "deploy", Hostname: "10.0.0.5", Port: 22, KeyPath: key},
+	}
+	if !reflect.DeepEqual(route, want) {
+		t.Fatalf("route = %+v, want %+v", route, want)
+	}
+	if got := (*calls)[1]; !reflect.DeepEqual(got, []string{"-l", "ops", "-p", "2200", "bastion.example.org"}) {
+		t.Errorf("jump host query = %q", got)
+	}
+}
+
+func TestSSHRouteWithoutAliasUsesContextFields(t *testing.T) {
+	stubSSHConfigQuery(t, nil)
+	ctx := &Context{SSHUser: "deploy", SSHHostname: "stage.example.org", SSHPort: 2222, SSHKeyPath: "/keys/id"}
+	route, err := ctx.sshRoute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SSHEndpoint{{User: "deploy", Hostname: "stage.example.org", Port: 2222, KeyPath: "/keys/id"}}
+	if !reflect.DeepEqual(route, want) {
+		t.Fatalf("route = %+v, want %+v", route, want)
+	}
+}
+
+func TestSSHRouteGoesThroughJumpHost(t *testing.T) {
+	stubSSHConfigQuery(t, nil)
+	ctx := &Context{SSHUser: "deploy", SSHHostname: "10.0.0.5", SSHPort: 22, SSHKeyPath: "/keys/id", SSHJumpHost: "bastion.example.org"}
+	route, err := ctx.sshRoute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SSHEndpoint{
+		{User: "deploy", Hostname: "bastion.example.org", Port: 22, KeyPath: "/keys/id"},
+		{User: "deploy", Hostname: "10.0.0.5", Port: 22, KeyPath: "/keys/id"},
+	}
+	if !reflect.DeepEqual(route, want) {
+		t.Fatalf("route = %+v, want %+v", route, want)
+	}
+
+	ctx.SSHJumpUser = "ops"
+	ctx.SSHJumpPort = 2200
+	route, err = ctx.sshRoute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := route[0]; got.User != "ops" || got.Port != 2200 {
+		t.Errorf("jump endpoint = %+v, want ops on port 2200", got)
+	}
+}
+
+func TestResolvedSSHEndpointReadsAlias(t *testing.T) {
+	stubSSHConfigQuery(t, map[string]string{
+		"prod": strings.Join([]string{
+			"user deploy",
+			"hostname 10.0.0.5",
+			"port 2222",
+			"identityfile /keys/prod",
+			"proxyjump ops@bastion.example.org",
+		}, "\n"),
+		"bastion.example.org": "user ops\nhostname bastion.example.org\nport 22\nidentityfile /keys/bastion",
+	})
+	endpoint, err := (&Context{SSHConfigHost: "prod"}).ResolvedSSHEndpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SSHEndpoint{User: "deploy", Hostname: "10.0.0.5", Port: 2222, KeyPath: "/keys/prod"}
+	if endpoint != want {
+		t.Errorf("endpoint = %+v, want %+v", endpoint, want)
+	}
+
+	if _, err := (&Context{SSHConfigHost: "missing"}).ResolvedSSHEndpoint(); err == nil {
+		t.Error("expected an error for an alias ssh -G cannot resolve")
+	}
+
+	jumps, err := (&Context{SSHConfigHost: "prod"}).SSHJumpHosts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jumps) != 1 || jumps[0].Hostname != "bastion.example.org" {
+		t.Errorf("jump hosts = %+v, want bastion.example.org", jumps)
+	}
+}
+
+func TestSSHRouteRejectsProxyCommand(t *testing.T) {
+	stubSSHConfigQuery(t, map[string]string{
+		"prod": "user deploy\nhostname prod.internal\nport 22\nproxycommand nc %h %p\n",
+	})
+	_, err := (&Context{SSHConfigHost: "prod"}).sshRoute()
+	if err == nil || !strings.Contains(err.Error(), "ProxyCommand") {
+		t.Fatalf("err = %v, want a ProxyCommand error", err)
+	}
+}
+
+func TestSSHRouteStopsJumpLoops(t *testing.T) {
+	stubSSHConfigQuery(t, map[string]string{
+		"a": "user deploy\nhostname a.internal\nport 22\nproxyjump b\n",
+		"b": "user deploy\nhostname b.internal\nport 22\nproxyjump a\n",
+	})
+	_, err := (&Context{SSHConfigHost: "a"}).sshRoute()
+	if err == nil || !strings.Contains(err.Error(), "ProxyJump hosts") {
+		t.Fatalf("err = %v, want a jump limit error", err)
+	}
+}
+
+func TestParseSSHJump(t *testing.T) {
+	tests := []struct {
+		jump, user, host, port string
+	}{
+		{"bastion", "", "bastion", ""},
+		{"ops@bastion:2200", "ops", "bastion", "2200"},
+		{"ssh://ops@bastion.example.org:22", "ops", "bastion.example.org", "22"},
+		{"[2001:db8::1]:2200", "", "2001:db8::1", "2200"},
+		{"ops@2001:db8::1", "ops", "2001:db8::1", ""},
+	}
+	for _, tt := range tests {
+		user, host, port := parseSSHJump(tt.jump)
+		if user != tt.user || host != tt.host || port != tt.port {
+			t.Errorf("parseSSHJump(%q) = %q, %q, %q; want %q, %q, %q", tt.jump, user, host, port, tt.user, tt.host, tt.port)
+		}
+	}
+}
+
+func TestSSHPoolKeySeparatesRoutes(t *testing.T) {
+	a := &Context{SSHConfigHost: "prod", SSHHostname: "one"}
+	b := &Context{SSHConfigHost: "prod", SSHHostname: "two"}
+	if a.sshPoolKey() != b.sshPoolKey() {
+		t.Error("contexts with the same alias use different pool keys")
+	}
+	direct := &Context{SSHUser: "deploy", SSHHostname: "10.0.0.5", SSHPort: 22}
+	viaBastion := *direct
+	viaBastion.SSHJumpHost = "bastion.example.org"
+	if direct.sshPoolKey() == viaBastion.sshPoolKey() {
+		t.Error("a jump host did not change the pool key")
+	}
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// sshPool holds one SSH client per destination for the life of the process,
+// so commands that run several remote commands, SFTP transfers and Docker
+// API calls share a single connection instead of dialing for each.
+var sshPool = struct {
+	mu      sync.Mutex
+	clients map[string]*pooledSSHClient
+}{clients: map[string]*pooledSSHClient{}}
+
+// dialPooledSSH dials a pooled connection; tests replace it.
+var dialPooledSSH = func(c *Context) (*ssh.Client, error) { return c.DialSSH() }
+
+type pooledSSHClient struct {
+	ready  chan struct{}
+	client *ssh.Client
+	err    error
+}
+
+// SSHClient returns the shared SSH client for the context's destination,
+// dialing it on first use. Callers must not close it; it is closed by
+// CloseSSHClients, or dropped from the pool when the connection ends. Use
+// DialSSH for a connection of your own.
+func (c *Context) SSHClient() (*ssh.Client, error) {
+	key := c.sshPoolKey()
+	sshPool.mu.Lock()
+	entry, ok := sshPool.clients[key]
+	if !ok {
+		entry = &pooledSSHClient{ready: make(chan struct{})}
+		sshPool.clients[key] = entry
+	}
+	sshPool.mu.Unlock()
+
+	if ok {
+		<-entry.ready
+		if entry.err != nil {
+			return nil, entry.err
+		}
+		return entry.client, nil
+	}
+
+	entry.client, entry.err = dialPooledSSH(c)
+	close(entry.ready)
+	if entry.err != nil {
+		// do not cache failures; the next caller dials again
+		removePooledSSH(key, entry)
+		return nil, entry.err
+	}
+	go func() {
+		_ = entry.client.Wait()
+		removePooledSSH(key, entry)
+	}()
+	return entry.client, nil
+}
+
+// CloseSSHClients closes every pooled SSH client.
+func CloseSSHClients() error {
+	sshPool.mu.Lock()
+	entries := make([]*pooledSSHClient, 0, len(sshPool.clients))
+	for key, entry := range sshPool.clients {
+		entries = append(entries, entry)
+		delete(sshPool.clients, key)
+	}
+	sshPool.mu.Unlock()
+
+	var errs []error
+	for _, entry := range entries {
+		<-entry.ready
+		if entry.client == nil {
+			continue
+		}
+		if err := entry.client.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func removePooledSSH(key string, entry *pooledSSHClient) {
+	sshPool.mu.Lock()
+	defer sshPool.mu.Unlock()
+	if sshPool.clients[key] == entry {
+		delete(sshPool.clients, key)
+	}
+}
+
+// sshPoolKey identifies an SSH destination and identity, so contexts for the
+// same host share a connection only when they authenticate the same way.
+// Contexts using an ~/.ssh/config alias share by alias.
+func (c *Context) sshPoolKey() string {
+	if alias := strings.TrimSpace(c.SSHConfigHost); alias != "" {
+		return "ssh-config:" + alias
+	}
+	key := fmt.Sprintf("%s@%s|%s", c.SSHUser, net.JoinHostPort(c.SSHHostname, strconv.Itoa(int(c.SSHPort))), c.SSHKeyPath)
+	if jump := strings.TrimSpace(c.SSHJumpHost); jump != "" {
+		key += fmt.Sprintf("|via %s@%s:%d", c.SSHJumpUser, jump, c.SSHJumpPort)
+	}
+	return key
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// loopbackSSHClient connects an SSH client to a loopback server that accepts
+// any user and rejects every channel. Closing the returned server conn ends
+// the connection.
+func loopbackSSHClient(t *testing.T) (*ssh.Client, net.Conn) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverConfig := &ssh.ServerConfig{NoClientAuth: true}
+	serverConfig.AddHostKey(signer)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	clientSide, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverSide, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_, channels, requests, err := ssh.NewServerConn(serverSide, serverConfig)
+		if err != nil {
+			return
+		}
+		go ssh.DiscardRequests(requests)
+		for channel := range channels {
+			_ = channel.Reject(ssh.Prohibited, "test server")
+		}
+	}()
+	conn, channels, requests, err := ssh.NewClientConn(clientSide, "loopback", &ssh.ClientConfig{
+		User:            "deploy",
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // #nosec G106 -- loopback test server.
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ssh.NewClient(conn, channels, requests), serverSide
+}
+
+func stubPooledSSH(t *testing.T, dial func(*Context) (*ssh.Client, error)) {
+	t.Helper()
+	original := dialPooledSSH
+	dialPooledSSH = dial
+	t.Cleanup(func() {
+		dialPooledSSH = original
+		_ = CloseSSHClients()
+	})
+}
+
+func TestSSHClientSharesOneConnectionPerDestination(t *testing.T) {
+	var dials atomic.Int32
+	stubPooledSSH(t, func(*Context) (*ssh.Client, error) {
+		dials.Add(1)
+		client, _ := loopbackSSHClient(t)
+		return client, nil
+	})
+
+	ctx := &Context{SSHUser: "deploy", SSHHostname: "stage.example.org", SSHPort: 22, SSHKeyPath: "/keys/id"}
+	var wg sync.WaitGroup
+	clients := make([]*ssh.Client, 8)
+	for i := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client, err := ctx.SSHClient()
+			if err != nil {
+				t.Error(err)
+			}
+			clients[i] = client
+		}()
+	}
+	wg.Wait()
+	for _, client := range clients[1:] {
+		if client != clients[0] {
+			t.Fatal("concurrent callers got different clients")
+		}
+	}
+
+	other := *ctx
+	other.SSHKeyPath = "/keys/other"
+	if client, err := other.SSHClient(); err != nil || client == clients[0] {
+		t.Fatalf("a different identity shared the connection: %v", err)
+	}
+	if got := dials.Load(); got != 2 {
+		t.Errorf("dials = %d, want 2", got)
+	}
+}
+
+func TestSSHClientRedialsAfterConnectionEndsOrFails(t *testing.T) {
+	var servers []net.Conn
+	fail := true
+	stubPooledSSH(t, func(*Context) (*ssh.Client, error) {
+		if fail {
+			fail = false
+			return nil, errors.New("connection refused")
+		}
+		client, server := loopbackSSHClient(t)
+		servers = append(servers, server)
+		return client, nil
+	})
+
+	ctx := &Context{SSHUser: "deploy", SSHHostname: "stage.example.org", SSHPort: 22}
+	if _, err := ctx.SSHClient(); err == nil {
+		t.Fatal("expected the first dial to fail")
+	}
+	first, err := ctx.SSHClient()
+	if err != nil {
+		t.Fatalf("dial failure was cached: %v", err)
+	}
+
+	_ = servers[0].Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		second, err := ctx.SSHClient()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if second != first {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("closed connection stayed in the pool")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCloseSSHClientsClosesPooledConnections(t *testing.T) {
+	stubPooledSSH(t, func(*Context) (*ssh.Client, error) {
+		client, _ := loopbackSSHClient(t)
+		return client, nil
+	})
+	ctx := &Context{SSHUser: "deploy", SSHHostname: "stage.example.org", SSHPort: 22}
+	client, err := ctx.SSHClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CloseSSHClients(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.NewSession(); err == nil {
+		t.Error("session opened on a closed client")
+	}
+}
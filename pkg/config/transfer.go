@@ -0,0 +1,438 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+// partialSuffix names the file a resumable transfer writes to before it is
+// renamed into place, next to the destination.
+const partialSuffix = ".sitectl-partial"
+
+// partialStampSuffix names the file next to a partial file that records the
+// size and modification time of the source it was copied from.
+const partialStampSuffix = ".source"
+
+// TransferOptions configure FileAccessor Upload and Download.
+type TransferOptions struct {
+	// Progress is called as data is copied with the bytes transferred so
+	// far, including resumed bytes, and the total size of the transfer.
+	Progress func(done, total int64)
+	// Preserve copies permission bits and modification times. Otherwise
+	// files are created with mode 0600.
+	Preserve bool
+	// Resume continues from the partial file an interrupted transfer left
+	// next to the destination, and keeps the partial file when a transfer
+	// fails. A partial file is discarded unless its source still has the
+	// size and modification time recorded when the partial file was started.
+	Resume bool
+}
+
+// transferFile is an open file on either side of a transfer.
+type transferFile interface {
+	io.ReadWriteSeeker
+	io.Closer
+}
+
+// transferFS is the local filesystem or an SFTP session.
+type transferFS interface {
+	Open(name string) (transferFile, error)
+	// Create opens name for writing. When exclusive is set it creates a new
+	// file and fails if name exists; otherwise name must already exist.
+	Create(name string, exclusive bool) (transferFile, error)
+	Stat(name string) (fs.FileInfo, error)
+	ReadDir(name string) ([]fs.FileInfo, error)
+	MkdirAll(name string) error
+	Chmod(name string, mode fs.FileMode) error
+	Chtimes(name string, atime, mtime time.Time) error
+	Rename(oldname, newname string) error
+	Remove(name string) error
+	Join(elem ...string) string
+	Split(name string) (dir, file string)
+}
+
+// Upload copies the local file or directory source to destination on the
+// context. Directories are copied recursively; symlinks and special files in
+// them are skipped. Each file is written next to its destination and renamed
+// into place, so an interrupted transfer never leaves a partial destination.
+func (a *FileAccessor) Upload(runCtx context.Context, source, destination string, opts TransferOptions) error {
+	return copyTree(runCtx, localTransferFS{}, source, a.transferFS(), destination, opts)
+}
+
+// Download copies the file or directory source on the context to the local
+// destination, like Upload in reverse.
+func (a *FileAccessor) Download(runCtx context.Context, source, destination string, opts TransferOptions) error {
+	return copyTree(runCtx, a.transferFS(), source, localTransferFS{}, destination, opts)
+}
+
+// Upload copies a local file or directory to the context. See FileAccessor.Upload.
+func (c *Context) Upload(runCtx context.Context, source, destination string, opts TransferOptions) error {
+	accessor, err := c.NewFileAccessor()
+	if err != nil {
+		return fmt.Errorf("create file accessor: %w", err)
+	}
+	defer accessor.Close()
+	return accessor.Upload(runCtx, source, destination, opts)
+}
+
+// Download copies a file or directory from the context. See FileAccessor.Download.
+func (c *Context) Download(runCtx context.Context, source, destination string, opts TransferOptions) error {
+	accessor, err := c.NewFileAccessor()
+	if err != nil {
+		return fmt.Errorf("create file accessor: %w", err)
+	}
+	defer accessor.Close()
+	return accessor.Download(runCtx, source, destination, opts)
+}
+
+func (a *FileAccessor) transferFS() transferFS {
+	if a == nil || a.ctx == nil || a.ctx.DockerHostType == ContextLocal {
+		return localTransferFS{}
+	}
+	return sftpTransferFS{client: a.sftp}
+}
+
+type transferEntry struct {
+	source, destination string
+	info                fs.FileInfo
+}
+
+func copyTree(runCtx context.Context, src transferFS, source string, dst transferFS, destination string, opts TransferOptions) error {
+	root, err := src.Stat(source)
+	if err != nil {
+		return err
+	}
+	var files, dirs []transferEntry
+	var total int64
+	var walk func(source, destination string, info fs.FileInfo) error
+	walk = func(source, destination string, info fs.FileInfo) error {
+		switch {
+		case info.IsDir():
+			dirs = append(dirs, transferEntry{source, destination, info})
+			entries, err := src.ReadDir(source)
+			if err != nil {
+				return err
+			}
+			for _, entry := range entries {
+				if err := walk(src.Join(source, entry.Name()), dst.Join(destination, entry.Name()), entry); err != nil {
+					return err
+				}
+			}
+		case info.Mode().IsRegular():
+			files = append(files, transferEntry{source, destination, info})
+			total += info.Size()
+		}
+		return nil
+	}
+	if err := walk(source, destination, root); err != nil {
+		return err
+	}
+	if !root.IsDir() && len(files) == 0 {
+		return fmt.Errorf("%s is not a regular file or directory", source)
+	}
+
+	for _, dir := range dirs {
+		if err := dst.MkdirAll(dir.destination); err != nil {
+			return fmt.Errorf("create directory %s: %w", dir.destination, err)
+		}
+	}
+	if !root.IsDir() {
+		if parent, _ := dst.Split(destination); parent != "" {
+			if err := dst.MkdirAll(parent); err != nil {
+				return fmt.Errorf("create directory %s: %w", parent, err)
+			}
+		}
+	}
+
+	progress := &transferProgress{report: opts.Progress, total: total}
+	for _, file := range files {
+		if err := copyTransferFile(runCtx, src, file, dst, opts, progress); err != nil {
+			return fmt.Errorf("copy %s: %w", file.source, err)
+		}
+	}
+	if opts.Preserve {
+		// Deepest first, since creating entries updates a directory's mtime.
+		for i := len(dirs) - 1; i >= 0; i-- {
+			if err := preserveAttributes(dst, dirs[i].destination, dirs[i].info); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func copyTransferFile(runCtx context.Context, src transferFS, file transferEntry, dst transferFS, opts TransferOptions, progress *transferProgress) (err error) {
+	dir, name := dst.Split(file.destination)
+	temp := dst.Join(dir, "."+name+partialSuffix)
+	if !opts.Resume {
+		random, err := remoteUploadTempPath(file.destination)
+		if err != nil {
+			return err
+		}
+		_, randomName := dst.Split(random)
+		temp = dst.Join(dir, randomName)
+	}
+
+	stamp := temp + partialStampSuffix
+
+	var offset int64
+	if opts.Resume {
+		offset = resumeOffset(dst, temp, stamp, file.info)
+		if offset == 0 {
+			if err := startPartialFile(dst, temp, stamp, file.info); err != nil {
+				return err
+			}
+		}
+	}
+	out, err := dst.Create(temp, offset == 0)
+	if err != nil {
+		return err
+	}
+	committed := false
+	defer func() {
+		_ = out.Close()
+		if !committed && !opts.Resume {
+			_ = dst.Remove(temp)
+		}
+	}()
+	if err := dst.Chmod(temp, 0o600); err != nil {
+		return err
+	}
+
+	in, err := src.Open(file.source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if offset > 0 {
+		if _, err := in.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := out.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+		progress.add(offset)
+	}
+	if _, err := io.Copy(out, &transferReader{ctx: runCtx, reader: in, progress: progress}); err != nil {
+		return err
+	}
+	if syncer, ok := out.(interface{ Sync() error }); ok {
+		if err := syncer.Sync(); err != nil {
+			return err
+		}
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	mode := fs.FileMode(0o600)
+	if opts.Preserve {
+		mode = file.info.Mode().Perm()
+	}
+	if err := dst.Chmod(temp, mode); err != nil {
+		return err
+	}
+	if opts.Preserve {
+		if err := dst.Chtimes(temp, file.info.ModTime(), file.info.ModTime()); err != nil {
+			return err
+		}
+	}
+	if err := dst.Rename(temp, file.destination); err != nil {
+		return fmt.Errorf("publish %s: %w", file.destination, err)
+	}
+	committed = true
+	if opts.Resume {
+		_ = dst.Remove(stamp)
+	}
+	return nil
+}
+
+// partialStamp identifies the version of a source file a partial file was
+// copied from.
+func partialStamp(info fs.FileInfo) string {
+	return fmt.Sprintf("%d %d\n", info.Size(), info.ModTime().Unix())
+}
+
+// resumeOffset returns how much of temp can be kept: its size when stamp
+// matches the current source and temp is no larger than it, otherwise 0.
+func resumeOffset(dst transferFS, temp, stamp string, source fs.FileInfo) int64 {
+	info, err := dst.Stat(temp)
+	if err != nil || info.Size() > source.Size() {
+		return 0
+	}
+	in, err := dst.Open(stamp)
+	if err != nil {
+		return 0
+	}
+	defer in.Close()
+	recorded, err := io.ReadAll(io.LimitReader(in, 64))
+	if err != nil || string(recorded) != partialStamp(source) {
+		return 0
+	}
+	return info.Size()
+}
+
+// startPartialFile discards any partial file at temp and records the source
+// it is about to be copied from in stamp.
+func startPartialFile(dst transferFS, temp, stamp string, source fs.FileInfo) error {
+	for _, name := range []string{temp, stamp} {
+		if err := dst.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	out, err := dst.Create(stamp, true)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(out, partialStamp(source)); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func preserveAttributes(dst transferFS, name string, info fs.FileInfo) error {
+	if err := dst.Chmod(name, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return dst.Chtimes(name, info.ModTime(), info.ModTime())
+}
+
+type transferProgress struct {
+	report      func(done, total int64)
+	done, total int64
+}
+
+func (p *transferProgress) add(n int64) {
+	p.done += n
+	if p.report != nil {
+		p.report(p.done, p.total)
+	}
+}
+
+// transferReader reports progress and stops the copy when ctx is done.
+type transferReader struct {
+	ctx      context.Context
+	reader   io.Reader
+	progress *transferProgress
+}
+
+func (r *transferReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.progress.add(int64(n))
+	}
+	return n, err
+}
+
+type localTransferFS struct{}
+
+func (localTransferFS) Open(name string) (transferFile, error) {
+	return os.Open(name) // #nosec G304 -- transfers copy caller-selected paths.
+}
+
+func (localTransferFS) Create(name string, exclusive bool) (transferFile, error) {
+	flag := os.O_WRONLY
+	if exclusive {
+		flag |= os.O_CREATE | os.O_EXCL
+	}
+	return os.OpenFile(name, flag, 0o600) // #nosec G304 -- transfers copy to caller-selected paths.
+}
+
+func (localTransferFS) Stat(name string) (fs.FileInfo, error) { return os.Stat(name) }
+
+func (localTransferFS) ReadDir(name string) ([]fs.FileInfo, error) {
+	entries, err := os.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]fs.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (localTransferFS) MkdirAll(name string) error { return os.MkdirAll(name, 0o750) }
+
+func (localTransferFS) Chmod(name string, mode fs.FileMode) error { return os.Chmod(name, mode) }
+
+func (localTransferFS) Chtimes(name string, atime, mtime time.Time) error {
+	return os.Chtimes(name, atime, mtime)
+}
+
+// Rename renames and syncs the directory so the rename survives a crash.
+func (localTransferFS) Rename(oldname, newname string) error {
+	if err := os.Rename(oldname, newname); err != nil {
+		return err
+	}
+	dir, err := os.Open(filepath.Dir(newname)) // #nosec G304 -- directory of a caller-selected destination.
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
+
+func (localTransferFS) Remove(name string) error { return os.Remove(name) }
+
+func (localTransferFS) Join(elem ...string) string { return filepath.Join(elem...) }
+
+func (localTransferFS) Split(name string) (string, string) { return filepath.Split(name) }
+
+type sftpTransferFS struct {
+	client *sftp.Client
+}
+
+func (s sftpTransferFS) Open(name string) (transferFile, error) { return s.client.Open(name) }
+
+func (s sftpTransferFS) Create(name string, exclusive bool) (transferFile, error) {
+	flag := os.O_WRONLY
+	if exclusive {
+		flag |= os.O_CREATE | os.O_EXCL
+	}
+	return s.client.OpenFile(name, flag)
+}
+
+func (s sftpTransferFS) Stat(name string) (fs.FileInfo, error) { return s.client.Stat(name) }
+
+func (s sftpTransferFS) ReadDir(name string) ([]fs.FileInfo, error) { return s.client.ReadDir(name) }
+
+func (s sftpTransferFS) MkdirAll(name string) error { return mkdirAllRemote(s.client, name) }
+
+func (s sftpTransferFS) Chmod(name string, mode fs.FileMode) error { return s.client.Chmod(name, mode) }
+
+func (s sftpTransferFS) Chtimes(name string, atime, mtime time.Time) error {
+	return s.client.Chtimes(name, atime, mtime)
+}
+
+func (s sftpTransferFS) Rename(oldname, newname string) error {
+	return s.client.PosixRename(oldname, newname)
+}
+
+func (s sftpTransferFS) Remove(name string) error {
+	if err := s.client.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s sftpTransferFS) Join(elem ...string) string { return path.Join(elem...) }
+
+func (s sftpTransferFS) Split(name string) (string, string) { return path.Split(name) }
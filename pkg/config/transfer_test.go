@@ -0,0 +1,203 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUploadDirectoryPreservesModesAndTimes(t *testing.T) {
+	t.Parallel()
+
+	source := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(source, "sites", "default"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"settings.php":                    "<?php",
+		"sites/default/services.yml":      "parameters: {}",
+		"sites/default/files-placeholder": "",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(source, name), []byte(content), 0o640); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("settings.php", filepath.Join(source, "settings.link")); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(source, "settings.php"), mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	destination := filepath.Join(t.TempDir(), "copy")
+	accessor := &FileAccessor{ctx: &Context{DockerHostType: ContextLocal}}
+	var reports []int64
+	err := accessor.Upload(context.Background(), source, destination, TransferOptions{
+		Preserve: true,
+		Progress: func(done, total int64) {
+			reports = append(reports, done)
+			if total != int64(len("<?php")+len("parameters: {}")) {
+				t.Errorf("total = %d", total)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(destination, name))
+		if err != nil || string(data) != content {
+			t.Errorf("%s = %q, %v", name, data, err)
+		}
+	}
+	info, err := os.Stat(filepath.Join(destination, "settings.php"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o640 || !info.ModTime().Equal(mtime) {
+		t.Errorf("settings.php mode %v, mtime %v", info.Mode().Perm(), info.ModTime())
+	}
+	if _, err := os.Lstat(filepath.Join(destination, "settings.link")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("symlink was copied: %v", err)
+	}
+	if len(reports) == 0 || reports[len(reports)-1] != int64(len("<?php")+len("parameters: {}")) {
+		t.Errorf("progress reports = %v", reports)
+	}
+}
+
+func TestUploadFileDefaultsToPrivateMode(t *testing.T) {
+	t.Parallel()
+
+	source := filepath.Join(t.TempDir(), "dump.sql")
+	if err := os.WriteFile(source, []byte("CREATE TABLE node;"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	destination := filepath.Join(t.TempDir(), "nested", "dump.sql")
+	accessor := &FileAccessor{ctx: &Context{DockerHostType: ContextLocal}}
+	if err := accessor.UploadFile(source, destination); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(destination)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	assertNoUploadTemps(t, filepath.Dir(destination))
+}
+
+func TestDownloadResumesPartialFile(t *testing.T) {
+	t.Parallel()
+
+	content := strings.Repeat("0123456789", 1000)
+	source := filepath.Join(t.TempDir(), "files.tar")
+	if err := os.WriteFile(source, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	destination := filepath.Join(dir, "files.tar")
+	partial := filepath.Join(dir, ".files.tar"+partialSuffix)
+	if err := os.WriteFile(partial, []byte(content[:4000]), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(partial+partialStampSuffix, []byte(partialStamp(info)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	accessor := &FileAccessor{ctx: &Context{DockerHostType: ContextLocal}}
+	var first int64 = -1
+	err = accessor.Download(context.Background(), source, destination, TransferOptions{
+		Resume: true,
+		Progress: func(done, total int64) {
+			if first < 0 {
+				first = done
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(destination); err != nil || string(data) != content {
+		t.Fatalf("destination has %d bytes, %v", len(data), err)
+	}
+	if first != 4000 {
+		t.Errorf("first progress report = %d, want the resumed 4000 bytes", first)
+	}
+	for _, name := range []string{partial, partial + partialStampSuffix} {
+		if _, err := os.Stat(name); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s left behind: %v", name, err)
+		}
+	}
+}
+
+func TestDownloadDiscardsPartialFileOfChangedSource(t *testing.T) {
+	t.Parallel()
+
+	source := filepath.Join(t.TempDir(), "files.tar")
+	if err := os.WriteFile(source, []byte("new archive contents"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	destination := filepath.Join(dir, "files.tar")
+	partial := filepath.Join(dir, ".files.tar"+partialSuffix)
+	for name, data := range map[string]string{
+		"unstamped": "",
+		"stale":     "20 1\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := os.WriteFile(partial, []byte("old archive"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_ = os.Remove(partial + partialStampSuffix)
+			if data != "" {
+				if err := os.WriteFile(partial+partialStampSuffix, []byte(data), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			accessor := &FileAccessor{ctx: &Context{DockerHostType: ContextLocal}}
+			if err := accessor.Download(context.Background(), source, destination, TransferOptions{Resume: true}); err != nil {
+				t.Fatal(err)
+			}
+			if data, err := os.ReadFile(destination); err != nil || string(data) != "new archive contents" {
+				t.Fatalf("destination = %q, %v", data, err)
+			}
+		})
+	}
+}
+
+func TestUploadKeepsPartialFileWhenResumableTransferIsCancelled(t *testing.T) {
+	t.Parallel()
+
+	source := filepath.Join(t.TempDir(), "files.tar")
+	if err := os.WriteFile(source, []byte("archive"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	accessor := &FileAccessor{ctx: &Context{DockerHostType: ContextLocal}}
+	err := accessor.Upload(ctx, source, filepath.Join(dir, "files.tar"), TransferOptions{Resume: true})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Upload() error = %v, want context.Canceled", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".files.tar"+partialSuffix)); err != nil {
+		t.Errorf("partial file was removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "files.tar")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("destination was published: %v", err)
+	}
+}
@@ -19,6 +19,9 @@ import (
 )
 
 func GetInput(question ...string) (string, error) {
+	if NonInteractive() {
+		return "", nonInteractiveError(question)
+	}
 	if term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd())) && len(question) > 0 {
 		prompt := question[len(question)-1]
 		sections := append([]string{}, question[:len(question)-1]...)
@@ -129,9 +132,11 @@ func LoadFromFlags(f *pflag.FlagSet, context Context) (*Context, error) {
 
 func contextHasStoredValues(context Context) bool {
 	return context.Site != "" ||
+		context.SiteID != "" ||
 		context.Plugin != "" ||
 		context.DockerHostType != "" ||
 		context.Environment != "" ||
+		context.APIURL != "" ||
 		context.DockerSocket != "" ||
 		context.ProjectName != "" ||
 		context.ComposeProjectName != "" ||
@@ -141,12 +146,21 @@ func contextHasStoredValues(context Context) bool {
 		context.SSHHostname != "" ||
 		context.SSHPort != 0 ||
 		context.SSHKeyPath != "" ||
+		context.SSHConfigHost != "" ||
+		context.SSHJumpHost != "" ||
+		context.SSHJumpUser != "" ||
+		context.SSHJumpPort != 0 ||
 		len(context.EnvFile) > 0 ||
 		len(context.ComposeFile) > 0 ||
+		len(context.ComposeEnv) > 0 ||
 		context.DatabaseService != "" ||
 		context.DatabaseUser != "" ||
 		context.DatabasePasswordSecret != "" ||
 		context.DatabaseName != "" ||
+		len(context.Labels) > 0 ||
+		context.Protected ||
+		len(context.PortForwards) > 0 ||
+		len(context.Registries) > 0 ||
 		len(context.Extra) > 0
 }
 
@@ -214,13 +228,19 @@ func SetCommandFlags(flags *pflag.FlagSet) {
 	flags.Uint("ssh-port", 2222, "Port number")
 	flags.String("ssh-user", "", "SSH user for remote context")
 	flags.String("ssh-key", "", "Path to SSH private key for remote context. e.g. "+key)
+	flags.String("ssh-config-host", "", "Host alias in ~/.ssh/config for remote context; its HostName, Port, User, IdentityFile and ProxyJump replace the ssh-* settings")
+	flags.String("ssh-jump-host", "", "Bastion host to connect to the remote context through (like ssh -J)")
+	flags.String("ssh-jump-user", "", "SSH user on the bastion host; defaults to --ssh-user")
+	flags.Uint("ssh-jump-port", 0, "SSH port on the bastion host; defaults to 22")
 	flags.String("project-dir", "", "Path to docker compose project directory")
 	flags.String("site", "", "Logical site name this context belongs to")
+	flags.String("site-id", "", "libops site ID this context is linked to (see sitectl link)")
 	flags.String("plugin", "core", "Owning plugin identifier for this context, such as core, isle, or drupal")
 	flags.String("project-name", "docker-compose", "Logical project name for this context")
 	flags.String("compose-project-name", "", "Docker Compose project name, matching COMPOSE_PROJECT_NAME or compose name:")
 	flags.String("compose-network", "", "Primary Docker Compose network name for this environment")
 	flags.String("environment", "", "Environment name for this context, such as local, dev, staging, or prod")
+	flags.String("api-url", "", "libops API endpoint for this context, such as a staging API (overridden by the global --api-url)")
 	flags.Bool("sudo", false, "for remote contexts, run docker commands as sudo")
 	flags.StringSlice("env-file", []string{}, "when running remote docker commands, the --env-file paths to pass to docker compose")
 	flags.StringSliceP("compose-file", "f", []string{}, "docker compose file paths to use (equivalent to docker compose -f flag). Multiple files can be specified.")
@@ -117,6 +117,7 @@ func TestLoadFromFlags(t *testing.T) {
 }
 
 func TestGetInput(t *testing.T) {
+	t.Setenv(NonInteractiveEnv, "0")
 	input := "hello\n"
 	inR, inW, err := os.Pipe()
 	if err != nil {
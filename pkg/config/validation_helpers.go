@@ -10,7 +10,10 @@ import (
 	"github.com/kballard/go-shellquote"
 )
 
-const defaultDrupalContainerRoot = "/var/www/drupal"
+const (
+	defaultDrupalContainerRoot = "/var/www/drupal"
+	defaultWPContainerRoot     = "/var/www/html"
+)
 
 func IsDockerSocketAlive(socket string) bool {
 	return isDockerSocketAlive(socket)
@@ -68,6 +71,13 @@ func (c *Context) EffectiveDrupalContainerRoot() string {
 	return strings.TrimSpace(c.DrupalContainerRoot)
 }
 
+func (c *Context) EffectiveWPContainerRoot() string {
+	if c == nil || strings.TrimSpace(c.WPContainerRoot) == "" {
+		return defaultWPContainerRoot
+	}
+	return strings.TrimSpace(c.WPContainerRoot)
+}
+
 func (c *Context) HasComposeProject() (bool, error) {
 	if c == nil {
 		return false, fmt.Errorf("context is nil")
@@ -0,0 +1,139 @@
+package docker
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// CopyFromContainer streams srcPath out of container as a tar archive whose
+// top-level entry is the base name of srcPath. Callers must close the reader.
+func (d *DockerClient) CopyFromContainer(ctx context.Context, container, srcPath string) (io.ReadCloser, error) {
+	cli, ok := d.CLI.(*client.Client)
+	if !ok {
+		return nil, fmt.Errorf("CLI is not a *client.Client")
+	}
+	reader, _, err := cli.CopyFromContainer(ctx, container, srcPath)
+	if err != nil {
+		return nil, fmt.Errorf("copy %s from container %s: %w", srcPath, container, err)
+	}
+	return reader, nil
+}
+
+// CopyToContainer extracts the tar archive content into dstDir inside
+// container, keeping the ownership recorded in the archive.
+func (d *DockerClient) CopyToContainer(ctx context.Context, container, dstDir string, content io.Reader) error {
+	cli, ok := d.CLI.(*client.Client)
+	if !ok {
+		return fmt.Errorf("CLI is not a *client.Client")
+	}
+	err := cli.CopyToContainer(ctx, container, dstDir, content, dockercontainer.CopyToContainerOptions{CopyUIDGID: true})
+	if err != nil {
+		return fmt.Errorf("copy into %s on container %s: %w", dstDir, container, err)
+	}
+	return nil
+}
+
+// TarFilter selects archive entries by shell glob patterns matched against
+// the entry path relative to the archive root (the top-level directory is
+// stripped), or against the entry's base name. An empty Include keeps
+// everything that is not excluded. RootName, when set, renames the top-level
+// entry so a directory can be extracted under a different name.
+type TarFilter struct {
+	Include  []string
+	Exclude  []string
+	RootName string
+}
+
+// TarCopyStats summarizes what FilterTar wrote.
+type TarCopyStats struct {
+	Files int
+	Bytes int64
+}
+
+// FilterTar copies the entries of the tar stream r that pass filter to w.
+// Directories are always kept so included files have somewhere to land.
+func FilterTar(r io.Reader, w io.Writer, filter TarFilter) (TarCopyStats, error) {
+	var stats TarCopyStats
+	tr := tar.NewReader(r)
+	tw := tar.NewWriter(w)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return stats, fmt.Errorf("read archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeDir && !filter.Allows(header.Name) {
+			continue
+		}
+		header.Name = filter.rename(header.Name)
+		if err := tw.WriteHeader(header); err != nil {
+			return stats, fmt.Errorf("write archive header %s: %w", header.Name, err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			written, err := io.Copy(tw, tr) // #nosec G110 -- archive comes from the user's own container and is streamed, not decompressed.
+			if err != nil {
+				return stats, fmt.Errorf("write archive entry %s: %w", header.Name, err)
+			}
+			stats.Files++
+			stats.Bytes += written
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return stats, fmt.Errorf("finish archive: %w", err)
+	}
+	return stats, nil
+}
+
+// Allows reports whether the archive entry name passes the filter.
+func (f TarFilter) Allows(name string) bool {
+	relative := strings.TrimPrefix(path.Clean(name), "./")
+	if _, rest, ok := strings.Cut(relative, "/"); ok {
+		relative = rest
+	}
+	if matchesAnyGlob(relative, f.Exclude) {
+		return false
+	}
+	return len(f.Include) == 0 || matchesAnyGlob(relative, f.Include)
+}
+
+func (f TarFilter) rename(name string) string {
+	if strings.TrimSpace(f.RootName) == "" {
+		return name
+	}
+	_, rest, ok := strings.Cut(strings.TrimPrefix(name, "./"), "/")
+	if !ok {
+		return f.RootName
+	}
+	return f.RootName + "/" + rest
+}
+
+func matchesAnyGlob(name string, patterns []string) bool {
+	base := path.Base(name)
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, base); ok {
+			return true
+		}
+		// a bare directory pattern such as "styles" or "css/" covers its contents
+		if dir := strings.TrimSuffix(pattern, "/"); name == dir || strings.HasPrefix(name, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestFilterTar(t *testing.T) {
+	var src bytes.Buffer
+	tw := tar.NewWriter(&src)
+	entries := []struct {
+		name string
+		body string
+	}{
+		{name: "files/"},
+		{name: "files/styles/"},
+		{name: "files/styles/thumb.jpg", body: "thumb"},
+		{name: "files/report.pdf", body: "pdf-body"},
+		{name: "files/photo.jpg", body: "jpeg"},
+		{name: "files/php/twig.php", body: "<?php"},
+	}
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Mode: 0o644, Size: int64(len(entry.body)), Typeflag: tar.TypeReg}
+		if entry.body == "" {
+			header.Typeflag = tar.TypeDir
+			header.Mode = 0o755
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(entry.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var dst bytes.Buffer
+	stats, err := FilterTar(&src, &dst, TarFilter{Include: []string{"*.jpg", "*.pdf"}, Exclude: []string{"styles"}})
+	if err != nil {
+		t.Fatalf("FilterTar() error = %v", err)
+	}
+	if stats.Files != 2 || stats.Bytes != int64(len("pdf-body")+len("jpeg")) {
+		t.Fatalf("FilterTar() stats = %+v", stats)
+	}
+
+	var got []string
+	tr := tar.NewReader(&dst)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, header.Name)
+	}
+	want := []string{"files/", "files/styles/", "files/report.pdf", "files/photo.jpg"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("filtered entries = %v, want %v", got, want)
+	}
+}
+
+func TestTarFilterAllowsEverythingByDefault(t *testing.T) {
+	if !(TarFilter{}).Allows("files/a/b.txt") {
+		t.Fatal("expected empty filter to allow entries")
+	}
+	if (TarFilter{Exclude: []string{"css/"}}).Allows("files/css/site.css") {
+		t.Fatal("expected directory exclude to match nested entries")
+	}
+	renamed := TarFilter{RootName: "uploads"}
+	if got := renamed.rename("files/"); got != "uploads/" {
+		t.Fatalf("rename(files/) = %q", got)
+	}
+	if got := renamed.rename("files/a/b.txt"); got != "uploads/a/b.txt" {
+		t.Fatalf("rename(files/a/b.txt) = %q", got)
+	}
+}
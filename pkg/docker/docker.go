@@ -21,7 +21,10 @@ import (
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/go-connections/sockets"
 	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/httplog"
+	"github.com/libops/sitectl/pkg/profile"
 	"golang.org/x/crypto/ssh"
 )
 
@@ -55,28 +58,22 @@ func (d *DockerClient) Close() error {
 
 func GetDockerCli(activeCtx *config.Context) (*DockerClient, error) {
 	if activeCtx.DockerHostType == config.ContextLocal {
-		cli, err := client.NewClientWithOpts(
-			client.WithHost("unix://"+activeCtx.DockerSocket),
-			client.WithAPIVersionNegotiation(),
-		)
+		cli, err := newLocalDockerClient(activeCtx.DockerSocket)
 		if err != nil {
 			return nil, fmt.Errorf("error creating local Docker client: %v", err)
 		}
 		return &DockerClient{CLI: cli}, nil
 	}
-	sshConn, err := activeCtx.DialSSH()
+	sshConn, err := activeCtx.SSHClient()
 	if err != nil {
 		return nil, fmt.Errorf("error establishing SSH connection: %v", err)
 	}
-	return GetDockerCliWithSSH(activeCtx, sshConn, true)
+	return GetDockerCliWithSSH(activeCtx, sshConn, false)
 }
 
 func GetDockerCliWithSSH(activeCtx *config.Context, sshConn *ssh.Client, ownsSSH bool) (*DockerClient, error) {
 	if activeCtx.DockerHostType == config.ContextLocal {
-		cli, err := client.NewClientWithOpts(
-			client.WithHost("unix://"+activeCtx.DockerSocket),
-			client.WithAPIVersionNegotiation(),
-		)
+		cli, err := newLocalDockerClient(activeCtx.DockerSocket)
 		if err != nil {
 			return nil, fmt.Errorf("error creating local Docker client: %v", err)
 		}
@@ -91,7 +88,7 @@ func GetDockerCliWithSSH(activeCtx *config.Context, sshConn *ssh.Client, ownsSSH
 		},
 	}
 	httpClient := &http.Client{
-		Transport: transport,
+		Transport: profile.Wrap(httplog.Wrap(transport)),
 	}
 	cli, err := client.NewClientWithOpts(
 		client.WithHost("http://docker"),
@@ -112,6 +109,24 @@ func GetDockerCliWithSSH(activeCtx *config.Context, sshConn *ssh.Client, ownsSSH
 	}, nil
 }
 
+// newLocalDockerClient connects to the Docker socket of a local context. When
+// HTTP tracing or profiling is on, the client gets its own transport so
+// requests can be logged and timed; otherwise the Docker client's default transport is kept.
+func newLocalDockerClient(socket string) (*client.Client, error) {
+	opts := []client.Opt{
+		client.WithHost("unix://" + socket),
+		client.WithAPIVersionNegotiation(),
+	}
+	if httplog.Enabled() || profile.Enabled() {
+		transport := &http.Transport{}
+		if err := sockets.ConfigureTransport(transport, "unix", socket); err != nil {
+			return nil, err
+		}
+		opts = append(opts, client.WithHTTPClient(&http.Client{Transport: profile.Wrap(httplog.Wrap(transport))}))
+	}
+	return client.NewClientWithOpts(opts...)
+}
+
 func GetSecret(ctx context.Context, cli DockerAPI, c *config.Context, containerName, secretName string) (string, error) {
 	containerJSON, err := cli.ContainerInspect(ctx, containerName)
 	if err != nil {
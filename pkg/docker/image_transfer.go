@@ -0,0 +1,116 @@
+package docker
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+)
+
+// ImageSaveAPI is the part of the Docker client that exports images.
+type ImageSaveAPI interface {
+	ImageSave(ctx context.Context, imageIDs []string, saveOpts ...client.ImageSaveOption) (io.ReadCloser, error)
+}
+
+// ImageLoadAPI is the part of the Docker client that imports images.
+type ImageLoadAPI interface {
+	ImageLoad(ctx context.Context, input io.Reader, loadOpts ...client.ImageLoadOption) (image.LoadResponse, error)
+}
+
+// ImageTransferOptions controls TransferImages.
+type ImageTransferOptions struct {
+	// Compress gzips the image archive on the way to the destination daemon,
+	// which trades CPU for bandwidth on slow links.
+	Compress bool
+	// Progress is called with the number of archive bytes read from the
+	// source so far.
+	Progress func(transferred int64)
+}
+
+// TransferImages streams images from the src daemon to the dst daemon, as
+// docker save | docker load would, without staging the archive on disk. It
+// returns the images the destination reported as loaded.
+func TransferImages(runCtx context.Context, src ImageSaveAPI, dst ImageLoadAPI, images []string, opts ImageTransferOptions) ([]string, error) {
+	archive, err := src.ImageSave(runCtx, images)
+	if err != nil {
+		return nil, fmt.Errorf("save %s: %w", strings.Join(images, ", "), err)
+	}
+	defer archive.Close()
+
+	source := &countingReader{r: archive, progress: opts.Progress}
+	var input io.Reader = source
+	if opts.Compress {
+		pr, pw := io.Pipe()
+		go func() {
+			gz := gzip.NewWriter(pw)
+			_, err := io.Copy(gz, source)
+			if closeErr := gz.Close(); err == nil {
+				err = closeErr
+			}
+			_ = pw.CloseWithError(err)
+		}()
+		defer pr.Close()
+		input = pr
+	}
+
+	response, err := dst.ImageLoad(runCtx, input, client.ImageLoadWithQuiet(true))
+	if err != nil {
+		return nil, fmt.Errorf("load images: %w", err)
+	}
+	defer response.Body.Close()
+	return readImageLoadResponse(response.Body)
+}
+
+// readImageLoadResponse reads the JSON message stream of an image load and
+// returns the loaded images, or the first error the daemon reported.
+func readImageLoadResponse(body io.Reader) ([]string, error) {
+	var loaded []string
+	decoder := json.NewDecoder(body)
+	for {
+		var message imageLoadMessage
+		if err := decoder.Decode(&message); errors.Is(err, io.EOF) {
+			return loaded, nil
+		} else if err != nil {
+			return loaded, fmt.Errorf("read image load response: %w", err)
+		}
+		if message.ErrorDetail != nil {
+			return loaded, fmt.Errorf("load images: %s", message.ErrorDetail.Message)
+		}
+		for _, line := range strings.Split(message.Stream, "\n") {
+			if _, name, ok := strings.Cut(line, "Loaded image: "); ok {
+				loaded = append(loaded, strings.TrimSpace(name))
+			} else if _, id, ok := strings.Cut(line, "Loaded image ID: "); ok {
+				loaded = append(loaded, strings.TrimSpace(id))
+			}
+		}
+	}
+}
+
+// imageLoadMessage is one message of the Docker image load JSON stream.
+type imageLoadMessage struct {
+	Stream      string `json:"stream"`
+	ErrorDetail *struct {
+		Message string `json:"message"`
+	} `json:"errorDetail"`
+}
+
+type countingReader struct {
+	r        io.Reader
+	n        int64
+	progress func(int64)
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	if n > 0 && c.progress != nil {
+		c.progress(c.n)
+	}
+	return n, err
+}
@@ -0,0 +1,73 @@
+package docker
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+)
+
+type fakeImageTransfer struct {
+	archive  string
+	response string
+	saved    []string
+	received []byte
+}
+
+func (f *fakeImageTransfer) ImageSave(_ context.Context, images []string, _ ...client.ImageSaveOption) (io.ReadCloser, error) {
+	f.saved = images
+	return io.NopCloser(strings.NewReader(f.archive)), nil
+}
+
+func (f *fakeImageTransfer) ImageLoad(_ context.Context, input io.Reader, _ ...client.ImageLoadOption) (image.LoadResponse, error) {
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return image.LoadResponse{}, err
+	}
+	f.received = data
+	return image.LoadResponse{Body: io.NopCloser(strings.NewReader(f.response)), JSON: true}, nil
+}
+
+func TestTransferImages(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeImageTransfer{
+		archive:  strings.Repeat("layer", 1000),
+		response: `{"stream":"Loaded image: museum-drupal:abc\n"}` + "\n" + `{"stream":"Loaded image ID: sha256:123\n"}`,
+	}
+	var progress int64
+	loaded, err := TransferImages(context.Background(), fake, fake, []string{"museum-drupal:abc"}, ImageTransferOptions{
+		Compress: true,
+		Progress: func(n int64) { progress = n },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(loaded, []string{"museum-drupal:abc", "sha256:123"}) || !slices.Equal(fake.saved, []string{"museum-drupal:abc"}) {
+		t.Errorf("loaded = %q, saved = %q", loaded, fake.saved)
+	}
+	if progress != int64(len(fake.archive)) {
+		t.Errorf("progress = %d, want %d", progress, len(fake.archive))
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(fake.received))
+	if err != nil {
+		t.Fatalf("received archive is not gzipped: %v", err)
+	}
+	if data, _ := io.ReadAll(gz); string(data) != fake.archive {
+		t.Error("received archive does not match the saved one")
+	}
+
+	fake.response = `{"errorDetail":{"message":"no space left on device"},"error":"no space left on device"}`
+	if _, err := TransferImages(context.Background(), fake, fake, []string{"x"}, ImageTransferOptions{}); err == nil || !strings.Contains(err.Error(), "no space left") {
+		t.Errorf("TransferImages() error = %v", err)
+	}
+	if string(fake.received) != fake.archive {
+		t.Error("uncompressed transfer changed the archive")
+	}
+}
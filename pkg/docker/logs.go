@@ -0,0 +1,197 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// LogsAPI is the part of the Docker client used to stream container logs.
+type LogsAPI interface {
+	DockerAPI
+	ContainerLogs(ctx context.Context, container string, options dockercontainer.LogsOptions) (io.ReadCloser, error)
+}
+
+// LogEntry is one line of a compose service container's log.
+type LogEntry struct {
+	Service   string    `json:"service"`
+	Container string    `json:"container"`
+	Timestamp time.Time `json:"timestamp"`
+	Stream    string    `json:"stream"`
+	Message   string    `json:"message"`
+}
+
+// LogsOptions selects the containers and log window of StreamComposeLogs.
+type LogsOptions struct {
+	// Services limits the stream to these compose services. Empty streams
+	// every service of the project.
+	Services []string
+	Since    string
+	Until    string
+	Tail     string
+	Follow   bool
+}
+
+// StreamComposeLogs streams the logs of every container in the compose
+// project, calling emit once per line. Containers are read concurrently, and
+// emit is never called concurrently. stdout and stderr stay separate except
+// for containers with a TTY, whose output Docker reports as stdout.
+func StreamComposeLogs(runCtx context.Context, api LogsAPI, project string, opts LogsOptions, emit func(LogEntry) error) error {
+	filterArgs := filters.NewArgs()
+	filterArgs.Add("label", "com.docker.compose.project="+project)
+	for _, service := range opts.Services {
+		filterArgs.Add("label", "com.docker.compose.service="+service)
+	}
+	containers, err := api.ContainerList(runCtx, dockercontainer.ListOptions{All: true, Filters: filterArgs})
+	if err != nil {
+		return fmt.Errorf("list compose containers: %w", err)
+	}
+	if len(containers) == 0 {
+		return fmt.Errorf("no containers found in compose project %s", project)
+	}
+	sort.Slice(containers, func(i, j int) bool {
+		return containerName(containers[i]) < containerName(containers[j])
+	})
+
+	runCtx, cancel := context.WithCancel(runCtx)
+	defer cancel()
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+	locked := func(entry LogEntry) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr != nil {
+			return firstErr
+		}
+		return emit(entry)
+	}
+	for _, container := range containers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := streamContainerLogs(runCtx, api, container, opts, locked); err != nil && !errors.Is(err, context.Canceled) {
+				fail(err)
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+func streamContainerLogs(runCtx context.Context, api LogsAPI, container dockercontainer.Summary, opts LogsOptions, emit func(LogEntry) error) error {
+	name := containerName(container)
+	service := container.Labels["com.docker.compose.service"]
+	inspect, err := api.ContainerInspect(runCtx, container.ID)
+	if err != nil {
+		return fmt.Errorf("inspect %s: %w", name, err)
+	}
+	reader, err := api.ContainerLogs(runCtx, container.ID, dockercontainer.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Since:      opts.Since,
+		Until:      opts.Until,
+		Tail:       opts.Tail,
+		Follow:     opts.Follow,
+		Timestamps: true,
+	})
+	if err != nil {
+		return fmt.Errorf("read logs of %s: %w", name, err)
+	}
+	defer reader.Close()
+
+	newLines := func(stream string) (*io.PipeWriter, <-chan error) {
+		pr, pw := io.Pipe()
+		done := make(chan error, 1)
+		go func() {
+			done <- scanLogLines(pr, func(line string) error {
+				timestamp, message := splitLogTimestamp(line)
+				return emit(LogEntry{Service: service, Container: name, Timestamp: timestamp, Stream: stream, Message: message})
+			})
+			_ = pr.Close()
+		}()
+		return pw, done
+	}
+	stdout, stdoutDone := newLines("stdout")
+	if inspect.Config != nil && inspect.Config.Tty {
+		_, err = io.Copy(stdout, reader)
+		_ = stdout.CloseWithError(err)
+		if scanErr := <-stdoutDone; scanErr != nil {
+			err = scanErr
+		}
+		return err
+	}
+	stderr, stderrDone := newLines("stderr")
+	_, err = stdcopy.StdCopy(stdout, stderr, reader)
+	_ = stdout.CloseWithError(err)
+	_ = stderr.CloseWithError(err)
+	// A failed emit closes its pipe, so prefer the emit error over the copy
+	// error it causes.
+	for _, done := range []<-chan error{stdoutDone, stderrDone} {
+		if scanErr := <-done; scanErr != nil {
+			err = scanErr
+		}
+	}
+	return err
+}
+
+func scanLogLines(r io.Reader, emit func(string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if err := emit(scanner.Text()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// splitLogTimestamp separates the RFC3339Nano timestamp Docker prefixes to
+// each line when Timestamps is set.
+func splitLogTimestamp(line string) (time.Time, string) {
+	prefix, message, ok := strings.Cut(line, " ")
+	if !ok {
+		prefix, message = line, ""
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, prefix)
+	if err != nil {
+		return time.Time{}, line
+	}
+	return timestamp, message
+}
+
+func containerName(container dockercontainer.Summary) string {
+	if len(container.Names) > 0 {
+		return strings.TrimPrefix(container.Names[0], "/")
+	}
+	return container.ID
+}
+
+// Logs returns the client's log streaming API.
+func (d *DockerClient) Logs() (LogsAPI, error) {
+	api, ok := d.CLI.(LogsAPI)
+	if !ok {
+		return nil, fmt.Errorf("docker client does not support streaming logs")
+	}
+	return api, nil
+}
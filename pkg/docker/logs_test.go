@@ -0,0 +1,95 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+type fakeLogsAPI struct {
+	FakeDockerClient
+	logs map[string][]byte
+}
+
+var _ LogsAPI = (*fakeLogsAPI)(nil)
+
+func (f *fakeLogsAPI) ContainerLogs(_ context.Context, container string, options dockercontainer.LogsOptions) (io.ReadCloser, error) {
+	if !options.Timestamps || !options.ShowStdout || !options.ShowStderr {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return io.NopCloser(bytes.NewReader(f.logs[container])), nil
+}
+
+func TestStreamComposeLogs(t *testing.T) {
+	t.Parallel()
+
+	var multiplexed bytes.Buffer
+	_, _ = stdcopy.NewStdWriter(&multiplexed, stdcopy.Stdout).Write([]byte("2026-01-02T15:04:05.000000001Z GET /user 200\n"))
+	_, _ = stdcopy.NewStdWriter(&multiplexed, stdcopy.Stderr).Write([]byte("2026-01-02T15:04:06Z PHP Warning: x\n"))
+
+	var listed dockercontainer.ListOptions
+	api := &fakeLogsAPI{
+		FakeDockerClient: FakeDockerClient{
+			ListFunc: func(_ context.Context, options dockercontainer.ListOptions) ([]dockercontainer.Summary, error) {
+				listed = options
+				return []dockercontainer.Summary{
+					{ID: "db", Names: []string{"/site-mariadb-1"}, Labels: map[string]string{"com.docker.compose.service": "mariadb"}},
+					{ID: "web", Names: []string{"/site-drupal-1"}, Labels: map[string]string{"com.docker.compose.service": "drupal"}},
+				}, nil
+			},
+			InspectFunc: func(_ context.Context, container string) (dockercontainer.InspectResponse, error) {
+				return dockercontainer.InspectResponse{Config: &dockercontainer.Config{Tty: container == "db"}}, nil
+			},
+		},
+		logs: map[string][]byte{
+			"web": multiplexed.Bytes(),
+			"db":  []byte("2026-01-02T15:04:07Z ready for connections\r\n"),
+		},
+	}
+
+	var entries []LogEntry
+	err := StreamComposeLogs(context.Background(), api, "site", LogsOptions{Services: []string{"drupal", "mariadb"}}, func(entry LogEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels := listed.Filters.Get("label")
+	for _, want := range []string{"com.docker.compose.project=site", "com.docker.compose.service=drupal", "com.docker.compose.service=mariadb"} {
+		if !slices.Contains(labels, want) {
+			t.Errorf("list filters %q missing %q", labels, want)
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b LogEntry) int { return a.Timestamp.Compare(b.Timestamp) })
+	want := []LogEntry{
+		{Service: "drupal", Container: "site-drupal-1", Timestamp: time.Date(2026, 1, 2, 15, 4, 5, 1, time.UTC), Stream: "stdout", Message: "GET /user 200"},
+		{Service: "drupal", Container: "site-drupal-1", Timestamp: time.Date(2026, 1, 2, 15, 4, 6, 0, time.UTC), Stream: "stderr", Message: "PHP Warning: x"},
+		{Service: "mariadb", Container: "site-mariadb-1", Timestamp: time.Date(2026, 1, 2, 15, 4, 7, 0, time.UTC), Stream: "stdout", Message: "ready for connections"},
+	}
+	if !slices.EqualFunc(entries, want, func(a, b LogEntry) bool {
+		return a.Service == b.Service && a.Container == b.Container && a.Timestamp.Equal(b.Timestamp) && a.Stream == b.Stream && a.Message == b.Message
+	}) {
+		t.Fatalf("entries = %+v, want %+v", entries, want)
+	}
+}
+
+func TestSplitLogTimestamp(t *testing.T) {
+	t.Parallel()
+
+	timestamp, message := splitLogTimestamp("2026-01-02T15:04:05Z")
+	if timestamp.IsZero() || message != "" {
+		t.Errorf("bare timestamp = %v, %q", timestamp, message)
+	}
+	timestamp, message = splitLogTimestamp("no timestamp here")
+	if !timestamp.IsZero() || message != "no timestamp here" {
+		t.Errorf("untimestamped line = %v, %q", timestamp, message)
+	}
+}
@@ -0,0 +1,101 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/libops/sitectl/pkg/helpers"
+)
+
+// ContainerStatus is the state of one container of a compose project.
+type ContainerStatus struct {
+	Service   string `json:"service"`
+	Container string `json:"container"`
+	State     string `json:"state"`
+	// Health is healthy, unhealthy or starting, or empty for containers
+	// without a health check.
+	Health       string    `json:"health,omitempty"`
+	RestartCount int       `json:"restart_count"`
+	Image        string    `json:"image"`
+	StartedAt    time.Time `json:"started_at,omitzero"`
+	// Ports are the published ports, such as 127.0.0.1:8080->80/tcp.
+	Ports []string `json:"ports"`
+}
+
+// ProjectStatus inspects every container of the compose project, running or
+// not, ordered by service and container name.
+func ProjectStatus(ctx context.Context, api DockerAPI, project string) ([]ContainerStatus, error) {
+	filterArgs := filters.NewArgs()
+	filterArgs.Add("label", "com.docker.compose.project="+project)
+	containers, err := api.ContainerList(ctx, dockercontainer.ListOptions{All: true, Filters: filterArgs})
+	if err != nil {
+		return nil, fmt.Errorf("list compose containers: %w", err)
+	}
+
+	statuses := make([]ContainerStatus, 0, len(containers))
+	for _, container := range containers {
+		name := containerName(container)
+		inspect, err := api.ContainerInspect(ctx, container.ID)
+		if err != nil {
+			return nil, fmt.Errorf("inspect %s: %w", name, err)
+		}
+		status := ContainerStatus{
+			Service:      helpers.FirstNonEmpty(container.Labels["com.docker.compose.service"], name),
+			Container:    name,
+			State:        container.State,
+			RestartCount: inspect.RestartCount,
+			Image:        container.Image,
+			Ports:        publishedPorts(container.Ports),
+		}
+		if inspect.Config != nil && inspect.Config.Image != "" {
+			status.Image = inspect.Config.Image
+		}
+		if state := inspect.State; state != nil {
+			status.State = helpers.FirstNonEmpty(string(state.Status), status.State)
+			if state.Health != nil {
+				status.Health = string(state.Health.Status)
+			}
+			if state.Running {
+				status.StartedAt, _ = time.Parse(time.RFC3339Nano, state.StartedAt)
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Service != statuses[j].Service {
+			return statuses[i].Service < statuses[j].Service
+		}
+		return statuses[i].Container < statuses[j].Container
+	})
+	return statuses, nil
+}
+
+// publishedPorts formats the ports bound on the host, skipping the duplicate
+// IPv6 binding Docker reports next to each 0.0.0.0 one.
+func publishedPorts(ports []dockercontainer.Port) []string {
+	published := []string{}
+	for _, port := range ports {
+		if port.PublicPort == 0 {
+			continue
+		}
+		ip := port.IP
+		if ip == "" || ip == "::" {
+			ip = "0.0.0.0"
+		}
+		if strings.Contains(ip, ":") {
+			ip = "[" + ip + "]"
+		}
+		binding := fmt.Sprintf("%s:%d->%d/%s", ip, port.PublicPort, port.PrivatePort, port.Type)
+		if !slices.Contains(published, binding) {
+			published = append(published, binding)
+		}
+	}
+	sort.Strings(published)
+	return published
+}
@@ -0,0 +1,94 @@
+package docker
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+)
+
+func TestProjectStatus(t *testing.T) {
+	t.Parallel()
+
+	var listed dockercontainer.ListOptions
+	api := &FakeDockerClient{
+		ListFunc: func(_ context.Context, options dockercontainer.ListOptions) ([]dockercontainer.Summary, error) {
+			listed = options
+			return []dockercontainer.Summary{
+				{
+					ID:     "b",
+					Names:  []string{"/site-mariadb-1"},
+					Image:  "sha256:abc",
+					State:  "exited",
+					Labels: map[string]string{"com.docker.compose.service": "mariadb"},
+				},
+				{
+					ID:     "a",
+					Names:  []string{"/site-drupal-1"},
+					State:  "running",
+					Labels: map[string]string{"com.docker.compose.service": "drupal"},
+					Ports: []dockercontainer.Port{
+						{IP: "0.0.0.0", PrivatePort: 80, PublicPort: 8080, Type: "tcp"},
+						{IP: "::", PrivatePort: 80, PublicPort: 8080, Type: "tcp"},
+						{PrivatePort: 9000, Type: "tcp"},
+						{IP: "127.0.0.1", PrivatePort: 443, PublicPort: 8443, Type: "tcp"},
+					},
+				},
+			}, nil
+		},
+		InspectFunc: func(_ context.Context, id string) (dockercontainer.InspectResponse, error) {
+			if id == "a" {
+				return dockercontainer.InspectResponse{
+					ContainerJSONBase: &dockercontainer.ContainerJSONBase{
+						RestartCount: 2,
+						State: &dockercontainer.State{
+							Status:    "running",
+							Running:   true,
+							StartedAt: "2026-10-16T09:00:00.5Z",
+							Health:    &dockercontainer.Health{Status: "healthy"},
+						},
+					},
+					Config: &dockercontainer.Config{Image: "islandora/drupal:4"},
+				}, nil
+			}
+			return dockercontainer.InspectResponse{
+				ContainerJSONBase: &dockercontainer.ContainerJSONBase{
+					State: &dockercontainer.State{Status: "exited", StartedAt: "2026-10-15T09:00:00Z"},
+				},
+				Config: &dockercontainer.Config{Image: "mariadb:11"},
+			}, nil
+		},
+	}
+
+	statuses, err := ProjectStatus(context.Background(), api, "site")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !listed.All || !listed.Filters.ExactMatch("label", "com.docker.compose.project=site") {
+		t.Errorf("list options = %+v", listed)
+	}
+	want := []ContainerStatus{
+		{
+			Service:      "drupal",
+			Container:    "site-drupal-1",
+			State:        "running",
+			Health:       "healthy",
+			RestartCount: 2,
+			Image:        "islandora/drupal:4",
+			StartedAt:    time.Date(2026, 10, 16, 9, 0, 0, 500000000, time.UTC),
+			Ports:        []string{"0.0.0.0:8080->80/tcp", "127.0.0.1:8443->443/tcp"},
+		},
+		{
+			Service:   "mariadb",
+			Container: "site-mariadb-1",
+			State:     "exited",
+			Image:     "mariadb:11",
+			Ports:     []string{},
+		},
+	}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Errorf("statuses = %+v\nwant %+v", statuses, want)
+	}
+}
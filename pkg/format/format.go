@@ -8,6 +8,8 @@ import (
 	"strings"
 	"text/tabwriter"
 	"text/template"
+
+	"github.com/libops/sitectl/pkg/profile"
 )
 
 // OutputFormat represents the output format type.
@@ -61,10 +63,17 @@ func NewFormatter(formatStr string) (*Formatter, error) {
 	}, nil
 }
 
+// SetOutput sets the destination for formatted output. It defaults to
+// os.Stdout.
+func (f *Formatter) SetOutput(w io.Writer) {
+	f.writer = w
+}
+
 // Print formats and prints the data according to the format specification.
 // For table format, headers and rows should be provided.
 // For JSON and template formats, data should be the object to format.
 func (f *Formatter) Print(data interface{}, headers []string, rows [][]string) error {
+	defer profile.Start(profile.Format, f.format.Type)()
 	switch f.format.Type {
 	case "table":
 		return f.printTable(data, headers, rows)
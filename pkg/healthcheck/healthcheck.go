@@ -9,6 +9,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
@@ -21,6 +22,7 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/libops/sitectl/pkg/config"
 	sitectldocker "github.com/libops/sitectl/pkg/docker"
+	"github.com/libops/sitectl/pkg/httplog"
 	sitevalidate "github.com/libops/sitectl/pkg/validate"
 )
 
@@ -466,8 +468,13 @@ func (c *DockerChecker) readComposeDependencyConfig(ctx context.Context) (compos
 		}
 		return document, nil
 	}
+	composeEnv, err := c.Context.ComposeEnvironment()
+	if err != nil {
+		return composeDependencyConfigDocument{}, err
+	}
 	command := exec.CommandContext(ctx, "docker", args...) // #nosec G204 -- fixed docker compose command with context-owned compose/env file arguments.
 	command.Dir = c.Context.ProjectDir
+	command.Env = config.AppendEnvOverrides(os.Environ(), composeEnv)
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
 	command.Stdout = &stdout
@@ -581,10 +588,7 @@ func checkHTTPURLWithClient(ctx context.Context, name, targetURL, hostHeader str
 	if strings.TrimSpace(hostHeader) != "" {
 		req.Host = hostHeader
 	}
-	if client == nil {
-		client = http.DefaultClient
-	}
-	resp, err := client.Do(req)
+	resp, err := httplog.WrapClient(client).Do(req)
 	if err != nil {
 		result := failed(name, err.Error())
 		return result, err
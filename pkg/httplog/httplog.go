@@ -0,0 +1,228 @@
+// Package httplog traces the HTTP requests sitectl makes (Docker Engine API
+// calls and site probes) with credentials redacted, so traces can be attached
+// to bug reports.
+package httplog
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TraceEnv enables tracing when set to 1/true, or tracing with headers and
+// bodies when set to "bodies". sitectl sets it for itself and plugin
+// subprocesses when --trace-http or --trace-http-bodies is passed.
+const TraceEnv = "SITECTL_TRACE_HTTP"
+
+const (
+	traceBodies = "bodies"
+	redacted    = "REDACTED"
+
+	// maxBodyBytes bounds the bodies that are buffered for logging; larger
+	// and streamed bodies (image pulls, tar copies, logs) are never read.
+	maxBodyBytes = 64 * 1024
+)
+
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Registry-Auth":     true,
+	"X-Registry-Config":   true,
+}
+
+var (
+	sensitiveName     = regexp.MustCompile(`(?i)(pass(word|wd)?|[_.-]pwd|secret|token|auth|credential|api[_-]?key|private[_-]?key)`)
+	sensitiveJSONPair = regexp.MustCompile(`(?i)("[^"]*(?:pass(?:word|wd)?|[_.-]pwd|secret|token|auth|credential|api[_-]?key|private[_-]?key)[^"]*"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	sensitiveEnvPair  = regexp.MustCompile(`(?i)("[A-Za-z0-9_.-]*(?:pass(?:word|wd)?|[_.-]pwd|secret|token|auth|credential|api[_-]?key|private[_-]?key)[A-Za-z0-9_.-]*=)(?:[^"\\]|\\.)*"`)
+)
+
+// Mode reports whether tracing is enabled and whether headers and bodies are
+// included.
+func Mode() (enabled, bodies bool) {
+	value := strings.ToLower(strings.TrimSpace(os.Getenv(TraceEnv)))
+	switch value {
+	case "", "0", "false", "no", "off":
+		return false, false
+	case traceBodies:
+		return true, true
+	}
+	return true, false
+}
+
+// Enabled reports whether HTTP tracing is on.
+func Enabled() bool {
+	enabled, _ := Mode()
+	return enabled
+}
+
+// Transport logs each round trip through Base.
+type Transport struct {
+	Base   http.RoundTripper
+	Bodies bool
+	Logger *slog.Logger
+}
+
+// Wrap returns base wrapped in a tracing Transport when tracing is enabled,
+// and base unchanged otherwise.
+func Wrap(base http.RoundTripper) http.RoundTripper {
+	enabled, bodies := Mode()
+	if !enabled {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{
+		Base:   base,
+		Bodies: bodies,
+		Logger: slog.New(slog.NewTextHandler(os.Stderr, nil)),
+	}
+}
+
+// WrapClient returns a copy of client whose transport is traced when tracing
+// is enabled, and client unchanged otherwise. A nil client stands for
+// http.DefaultClient.
+func WrapClient(client *http.Client) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if !Enabled() {
+		return client
+	}
+	traced := *client
+	traced.Transport = Wrap(client.Transport)
+	return &traced
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	logger := t.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	attrs := []any{"method", req.Method, "url", RedactURL(req.URL)}
+	if t.Bodies {
+		attrs = append(attrs, "request_headers", RedactHeaders(req.Header))
+		if body, ok := captureRequestBody(req); ok {
+			attrs = append(attrs, "request_body", body)
+		}
+	}
+
+	started := time.Now()
+	resp, err := t.Base.RoundTrip(req)
+	attrs = append(attrs, "duration", time.Since(started).Round(time.Millisecond))
+	if err != nil {
+		logger.Info("http request failed", append(attrs, "error", err)...)
+		return resp, err
+	}
+	attrs = append(attrs, "status", resp.StatusCode)
+	if t.Bodies {
+		attrs = append(attrs, "response_headers", RedactHeaders(resp.Header))
+		if body, ok := captureResponseBody(resp); ok {
+			attrs = append(attrs, "response_body", body)
+		}
+	}
+	logger.Info("http request", attrs...)
+	return resp, nil
+}
+
+// CloseIdleConnections lets http.Client.CloseIdleConnections reach Base.
+func (t *Transport) CloseIdleConnections() {
+	if closer, ok := t.Base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// SensitiveName reports whether name looks like it holds a credential, such
+// as DB_PASSWORD or api_key.
+func SensitiveName(name string) bool {
+	return sensitiveName.MatchString(name)
+}
+
+// RedactURL returns u as a string with userinfo passwords and sensitive
+// query parameters replaced.
+func RedactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	clean := *u
+	if _, ok := clean.User.Password(); ok {
+		clean.User = url.UserPassword(clean.User.Username(), redacted)
+	}
+	if clean.RawQuery != "" {
+		query := clean.Query()
+		for name := range query {
+			if sensitiveName.MatchString(name) {
+				query.Set(name, redacted)
+			}
+		}
+		clean.RawQuery = query.Encode()
+	}
+	return clean.String()
+}
+
+// RedactHeaders returns a copy of header with credential headers replaced.
+func RedactHeaders(header http.Header) http.Header {
+	clean := header.Clone()
+	for name := range clean {
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			clean[name] = []string{redacted}
+		}
+	}
+	return clean
+}
+
+// RedactBody replaces values of sensitive JSON keys and of sensitive
+// NAME=value environment entries, as found in container create and inspect
+// payloads.
+func RedactBody(body string) string {
+	body = sensitiveJSONPair.ReplaceAllString(body, `$1"`+redacted+`"`)
+	return sensitiveEnvPair.ReplaceAllString(body, `${1}`+redacted+`"`)
+}
+
+func captureRequestBody(req *http.Request) (string, bool) {
+	if req.Body == nil || req.Body == http.NoBody || !loggableBody(req.Header, req.ContentLength) {
+		return "", false
+	}
+	data, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	if err != nil {
+		return "", false
+	}
+	return RedactBody(string(data)), true
+}
+
+func captureResponseBody(resp *http.Response) (string, bool) {
+	if resp.Body == nil || !loggableBody(resp.Header, resp.ContentLength) {
+		return "", false
+	}
+	data, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	if err != nil {
+		return "", false
+	}
+	return RedactBody(string(data)), true
+}
+
+// loggableBody reports whether a body is small, of known length and textual,
+// so reading it up front cannot stall a stream.
+func loggableBody(header http.Header, length int64) bool {
+	if length <= 0 || length > maxBodyBytes {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") || strings.HasPrefix(mediaType, "text/")
+}
@@ -0,0 +1,199 @@
+// Package i18n holds the catalog of user-facing sitectl messages and selects
+// a locale from the environment.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LangEnv selects the message locale. sitectl sets it for plugin subprocesses
+// when --lang is passed so they render the same language.
+const LangEnv = "SITECTL_LANG"
+
+const defaultLocale = "en"
+
+// Message identifies a catalog entry. The English text is the fallback for
+// locales that do not translate a message.
+type Message string
+
+const (
+	ContinuePrompt             Message = "continue_prompt"
+	TypeToContinuePrompt       Message = "type_to_continue_prompt"
+	DatabaseReplaceWarning     Message = "database_replace_warning"
+	DatabaseWipeWarning        Message = "database_wipe_warning"
+	DatabaseSynced             Message = "database_synced"
+	DatabaseSanitized          Message = "database_sanitized"
+	DatabaseBackedUp           Message = "database_backed_up"
+	DatabasePushWarning        Message = "database_push_warning"
+	CacheFlushWarning          Message = "cache_flush_warning"
+	FilesCopyWarning           Message = "files_copy_warning"
+	FilesOverwriteWarning      Message = "files_overwrite_warning"
+	FilesSizeWarning           Message = "files_size_warning"
+	FilesCopied                Message = "files_copied"
+	SnapshotCreated            Message = "snapshot_created"
+	SnapshotRestoreWarning     Message = "snapshot_restore_warning"
+	SnapshotRestored           Message = "snapshot_restored"
+	NoSnapshots                Message = "no_snapshots"
+	ComposeCleanWarning        Message = "compose_clean_warning"
+	ComposeCleanProjectDir     Message = "compose_clean_project_dir"
+	ComposeCleanDataLossNotice Message = "compose_clean_data_loss_notice"
+	ComposeCleaned             Message = "compose_cleaned"
+	PruneWarning               Message = "prune_warning"
+	Pruned                     Message = "pruned"
+	HostRebootWarning          Message = "host_reboot_warning"
+	HostUpdateDockerWarning    Message = "host_update_docker_warning"
+	BackupCreated              Message = "backup_created"
+	BackupRestoreWarning       Message = "backup_restore_warning"
+	BackupRestored             Message = "backup_restored"
+	VolumeBackedUp             Message = "volume_backed_up"
+	VolumeRestoreWarning       Message = "volume_restore_warning"
+	VolumeRestored             Message = "volume_restored"
+	ScanProductionWarning      Message = "scan_production_warning"
+	NonInteractiveHint         Message = "non_interactive_hint"
+)
+
+var catalog = map[string]map[Message]string{
+	"en": {
+		ContinuePrompt:             "Continue? [y/N]: ",
+		TypeToContinuePrompt:       "Type %q to continue: ",
+		DatabaseReplaceWarning:     "About to import %s database artifact %q into context %q.",
+		DatabaseWipeWarning:        "This will wipe out the target database.",
+		DatabaseSynced:             "MariaDB database synced from %s to %s",
+		DatabaseSanitized:          "Sanitized database in %s",
+		DatabaseBackedUp:           "Backed up %s database to %s",
+		DatabasePushWarning:        "About to replace the database of context %q with the database from %q.",
+		CacheFlushWarning:          "About to delete every key in the cache for context %q.",
+		FilesCopyWarning:           "About to copy %s of files from context %q into %s on context %q.",
+		FilesOverwriteWarning:      "Files with the same name in the target will be overwritten.",
+		FilesSizeWarning:           "WARNING: this is larger than %d MiB; consider --include/--exclude to limit the copy.",
+		FilesCopied:                "Copied %d files (%s) from %s:%s to %s:%s",
+		SnapshotCreated:            "Created snapshot %s (database: %t, volumes: %d, files: %d)",
+		SnapshotRestoreWarning:     "This will overwrite the database, volumes and env files of context %q with snapshot %s.",
+		SnapshotRestored:           "Restored snapshot %s into %s",
+		NoSnapshots:                "No snapshots for context %s",
+		ComposeCleanWarning:        "This will permanently delete local Docker Compose volumes and plugin init files for %q.",
+		ComposeCleanProjectDir:     "Project directory: %s",
+		ComposeCleanDataLossNotice: "Database contents, uploaded files stored in named volumes, generated secrets, certificates, and declared env files can be lost.",
+		ComposeCleaned:             "Compose project cleaned",
+		PruneWarning:               "About to remove the unused Docker resources above from compose project %q on context %q.",
+		Pruned:                     "Pruned compose project %q, reclaimed %s",
+		HostRebootWarning:          "About to reboot %s (context %q); every site on the host is offline until it returns.",
+		HostUpdateDockerWarning:    "About to upgrade Docker on %s (context %q); containers restart with the daemon.",
+		BackupCreated:              "Created backup %s (volumes: %d, files: %d)",
+		BackupRestoreWarning:       "This will overwrite the volumes, secrets and env files of context %q with backup %s (taken from context %q at %s).",
+		BackupRestored:             "Restored backup %s into %s",
+		VolumeBackedUp:             "Backed up volume %s to %s (%s)",
+		VolumeRestoreWarning:       "This will delete everything in volume %s of context %q and replace it with %s.",
+		VolumeRestored:             "Restored %s into volume %s of %s",
+		ScanProductionWarning:      "The scan found %d potential secret leak(s) in %s before deploying to production context %q.",
+		NonInteractiveHint:         "pass the command's confirmation flag such as --yolo or --yes, or unset %s/CI to answer interactively",
+	},
+	"es": {
+		ContinuePrompt:             "¿Continuar? [s/N]: ",
+		TypeToContinuePrompt:       "Escriba %q para continuar: ",
+		DatabaseReplaceWarning:     "Se importará el artefacto de base de datos %s %q en el contexto %q.",
+		DatabaseWipeWarning:        "Esto borrará la base de datos de destino.",
+		DatabaseSynced:             "Base de datos MariaDB sincronizada de %s a %s",
+		DatabaseSanitized:          "Base de datos saneada en %s",
+		DatabaseBackedUp:           "Copia de seguridad de la base de datos de %s guardada en %s",
+		DatabasePushWarning:        "Se reemplazará la base de datos del contexto %q con la base de datos de %q.",
+		CacheFlushWarning:          "Se eliminarán todas las claves de la caché del contexto %q.",
+		FilesCopyWarning:           "Se copiarán %s de archivos del contexto %q a %s en el contexto %q.",
+		FilesOverwriteWarning:      "Los archivos con el mismo nombre en el destino se sobrescribirán.",
+		FilesSizeWarning:           "AVISO: supera %d MiB; considere --include/--exclude para limitar la copia.",
+		FilesCopied:                "Se copiaron %d archivos (%s) de %s:%s a %s:%s",
+		SnapshotCreated:            "Instantánea %s creada (base de datos: %t, volúmenes: %d, archivos: %d)",
+		SnapshotRestoreWarning:     "Esto sobrescribirá la base de datos, los volúmenes y los archivos env del contexto %q con la instantánea %s.",
+		SnapshotRestored:           "Instantánea %s restaurada en %s",
+		NoSnapshots:                "No hay instantáneas para el contexto %s",
+		ComposeCleanWarning:        "Esto eliminará de forma permanente los volúmenes locales de Docker Compose y los archivos de inicialización del plugin de %q.",
+		ComposeCleanProjectDir:     "Directorio del proyecto: %s",
+		ComposeCleanDataLossNotice: "Se pueden perder el contenido de la base de datos, los archivos subidos en volúmenes con nombre, los secretos generados, los certificados y los archivos env declarados.",
+		ComposeCleaned:             "Proyecto Compose limpiado",
+		PruneWarning:               "Se eliminarán los recursos de Docker sin uso listados arriba del proyecto Compose %q en el contexto %q.",
+		Pruned:                     "Proyecto Compose %q depurado, se liberaron %s",
+		HostRebootWarning:          "Se reiniciará %s (contexto %q); todos los sitios del host estarán fuera de línea hasta que vuelva.",
+		HostUpdateDockerWarning:    "Se actualizará Docker en %s (contexto %q); los contenedores se reiniciarán con el daemon.",
+		BackupCreated:              "Copia de seguridad %s creada (volúmenes: %d, archivos: %d)",
+		BackupRestoreWarning:       "Esto sobrescribirá los volúmenes, secretos y archivos env del contexto %q con la copia de seguridad %s (tomada del contexto %q el %s).",
+		BackupRestored:             "Copia de seguridad %s restaurada en %s",
+		VolumeBackedUp:             "Volumen %s respaldado en %s (%s)",
+		VolumeRestoreWarning:       "Esto borrará todo el contenido del volumen %s del contexto %q y lo reemplazará con %s.",
+		VolumeRestored:             "%s restaurado en el volumen %s de %s",
+		ScanProductionWarning:      "El análisis encontró %d posible(s) filtración(es) de secretos en %s antes de desplegar en el contexto de producción %q.",
+		NonInteractiveHint:         "use la opción de confirmación del comando, como --yolo o --yes, o quite %s/CI para responder de forma interactiva",
+	},
+}
+
+// affirmatives lists the answers accepted as "yes" for each locale. English
+// answers are always accepted.
+var affirmatives = map[string][]string{
+	"en": {"y", "yes"},
+	"es": {"s", "si", "sí"},
+}
+
+// Locale returns the active catalog locale from the first set variable of
+// SITECTL_LANG, LC_ALL, LC_MESSAGES and LANG. Unsupported locales fall back
+// to English.
+func Locale() string {
+	for _, name := range []string{LangEnv, "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+			return normalizeLocale(value)
+		}
+	}
+	return defaultLocale
+}
+
+// Supported reports whether locale, such as es or es_MX.UTF-8, has a catalog.
+func Supported(locale string) bool {
+	_, ok := catalog[baseLanguage(locale)]
+	return ok
+}
+
+// T formats message in the active locale.
+func T(message Message, args ...any) string {
+	format, ok := catalog[Locale()][message]
+	if !ok {
+		format, ok = catalog[defaultLocale][message]
+	}
+	if !ok {
+		format = string(message)
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// IsYes reports whether answer is an affirmative reply in English or the
+// active locale.
+func IsYes(answer string) bool {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	for _, locale := range []string{defaultLocale, Locale()} {
+		for _, yes := range affirmatives[locale] {
+			if answer == yes {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// normalizeLocale maps values such as es_MX.UTF-8 or es-419 to a catalog
+// locale.
+func normalizeLocale(value string) string {
+	if Supported(value) {
+		return baseLanguage(value)
+	}
+	return defaultLocale
+}
+
+func baseLanguage(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if i := strings.IndexAny(value, "_-.@"); i >= 0 {
+		value = value[:i]
+	}
+	return value
+}
@@ -0,0 +1,92 @@
+package i18n
+
+import "testing"
+
+func setLocaleEnv(t *testing.T, values map[string]string) {
+	t.Helper()
+	for _, name := range []string{LangEnv, "LC_ALL", "LC_MESSAGES", "LANG"} {
+		t.Setenv(name, values[name])
+	}
+}
+
+func TestLocale(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{name: "unset", env: nil, want: "en"},
+		{name: "LANG spanish", env: map[string]string{"LANG": "es_MX.UTF-8"}, want: "es"},
+		{name: "LC_ALL wins over LANG", env: map[string]string{"LC_ALL": "en_US.UTF-8", "LANG": "es_ES.UTF-8"}, want: "en"},
+		{name: "LC_MESSAGES wins over LANG", env: map[string]string{"LC_MESSAGES": "es", "LANG": "en_US.UTF-8"}, want: "es"},
+		{name: "SITECTL_LANG wins", env: map[string]string{LangEnv: "es-419", "LC_ALL": "en_US.UTF-8"}, want: "es"},
+		{name: "unsupported falls back", env: map[string]string{"LANG": "fr_FR.UTF-8"}, want: "en"},
+		{name: "POSIX locale", env: map[string]string{"LANG": "C.UTF-8"}, want: "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setLocaleEnv(t, tt.env)
+			if got := Locale(); got != tt.want {
+				t.Fatalf("Locale() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestT(t *testing.T) {
+	setLocaleEnv(t, map[string]string{LangEnv: "es"})
+	if got, want := T(NoSnapshots, "local"), "No hay instantáneas para el contexto local"; got != want {
+		t.Fatalf("T() = %q, want %q", got, want)
+	}
+
+	setLocaleEnv(t, map[string]string{LangEnv: "fr"})
+	if got, want := T(NoSnapshots, "local"), "No snapshots for context local"; got != want {
+		t.Fatalf("T() = %q, want %q", got, want)
+	}
+	if got, want := T(Message("missing_key")), "missing_key"; got != want {
+		t.Fatalf("T() = %q, want %q", got, want)
+	}
+}
+
+func TestCatalogsTranslateEveryEnglishMessage(t *testing.T) {
+	for locale, messages := range catalog {
+		for message := range catalog[defaultLocale] {
+			if _, ok := messages[message]; !ok {
+				t.Errorf("locale %q is missing message %q", locale, message)
+			}
+		}
+	}
+}
+
+func TestIsYes(t *testing.T) {
+	tests := []struct {
+		locale string
+		answer string
+		want   bool
+	}{
+		{locale: "en", answer: "y", want: true},
+		{locale: "en", answer: " YES ", want: true},
+		{locale: "en", answer: "si", want: false},
+		{locale: "en", answer: "", want: false},
+		{locale: "es", answer: "s", want: true},
+		{locale: "es", answer: "Sí", want: true},
+		{locale: "es", answer: "yes", want: true},
+		{locale: "es", answer: "no", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale+"/"+tt.answer, func(t *testing.T) {
+			setLocaleEnv(t, map[string]string{LangEnv: tt.locale})
+			if got := IsYes(tt.answer); got != tt.want {
+				t.Fatalf("IsYes(%q) = %v, want %v", tt.answer, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSupported(t *testing.T) {
+	for value, want := range map[string]bool{"en": true, "es": true, "es_AR.UTF-8": true, "fr": false, "": false} {
+		if got := Supported(value); got != want {
+			t.Errorf("Supported(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
@@ -2,9 +2,9 @@ package job
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/i18n"
 )
 
 // ConfirmDatabaseReplacement prompts the user to confirm a destructive
@@ -14,14 +14,14 @@ import (
 // yolo skips the prompt and returns true immediately, intended for
 // non-interactive pipelines where the caller has already passed a --yolo flag.
 func ConfirmDatabaseReplacement(targetContext, databaseName, inputPath string, yolo bool) (bool, error) {
-	if yolo {
+	if config.AutoConfirm(fmt.Sprintf("import %s into context %q", databaseName, targetContext), yolo) {
 		return true, nil
 	}
 
 	prompt := []string{
-		fmt.Sprintf("About to import %s database artifact %q into context %q.", databaseName, inputPath, targetContext),
-		"This will wipe out the target database.",
-		"Continue? [y/N]: ",
+		i18n.T(i18n.DatabaseReplaceWarning, databaseName, inputPath, targetContext),
+		i18n.T(i18n.DatabaseWipeWarning),
+		i18n.T(i18n.ContinuePrompt),
 	}
 
 	input, err := config.GetInput(prompt...)
@@ -29,10 +29,5 @@ func ConfirmDatabaseReplacement(targetContext, databaseName, inputPath string, y
 		return false, err
 	}
 
-	switch strings.ToLower(strings.TrimSpace(input)) {
-	case "y", "yes":
-		return true, nil
-	default:
-		return false, nil
-	}
+	return i18n.IsYes(input), nil
 }
@@ -3,14 +3,10 @@ package job
 import (
 	"context"
 	"fmt"
-	"io"
-	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
 
 	"github.com/libops/sitectl/pkg/config"
-	"github.com/pkg/sftp"
 )
 
 func RemoveContextHostPath(runCtx context.Context, ctx *config.Context, path string) {
@@ -24,34 +20,7 @@ func DownloadContextFile(ctx *config.Context, sourcePath, localPath string) erro
 	if ctx == nil {
 		return fmt.Errorf("context is required")
 	}
-	if ctx.DockerHostType == config.ContextLocal {
-		sourceFile, err := os.Open(sourcePath) // #nosec G304 -- sitectl intentionally downloads a caller-selected local project file.
-		if err != nil {
-			return err
-		}
-		defer sourceFile.Close()
-		return writeLocalFile(localPath, sourceFile)
-	}
-
-	sshClient, err := ctx.DialSSH()
-	if err != nil {
-		return err
-	}
-	defer sshClient.Close()
-
-	sftpClient, err := sftp.NewClient(sshClient)
-	if err != nil {
-		return err
-	}
-	defer sftpClient.Close()
-
-	sourceFile, err := sftpClient.Open(sourcePath)
-	if err != nil {
-		return err
-	}
-	defer sourceFile.Close()
-
-	return writeLocalFile(localPath, sourceFile)
+	return ctx.Download(context.Background(), sourcePath, localPath, config.TransferOptions{})
 }
 
 func EnsureDirOnContext(ctx *config.Context, dir string) error {
@@ -65,16 +34,3 @@ func EnsureDirOnContext(ctx *config.Context, dir string) error {
 	defer accessor.Close()
 	return accessor.MkdirAll(dir)
 }
-
-func writeLocalFile(path string, r io.Reader) error {
-	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
-		return err
-	}
-	dst, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600) // #nosec G304 -- destination is an explicit caller-selected download target.
-	if err != nil {
-		return err
-	}
-	defer dst.Close()
-	_, err = io.Copy(dst, r)
-	return err
-}
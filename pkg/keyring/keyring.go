@@ -0,0 +1,134 @@
+// Package keyring stores secrets in the operating system credential store:
+// the login keychain on macOS and the Secret Service (GNOME Keyring, KWallet)
+// on Linux, through the security and secret-tool commands.
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Service is the service name sitectl secrets are stored under.
+const Service = "sitectl"
+
+// ErrNotFound is returned by Get and Delete when no secret is stored for the
+// account.
+var ErrNotFound = errors.New("secret not found in keyring")
+
+// goos selects the credential store; tests replace it.
+var goos = runtime.GOOS
+
+// run executes a credential store command with stdin and returns its stdout;
+// tests replace it.
+var run = func(stdin, name string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	command := exec.Command(name, args...) // #nosec G204 -- runs the fixed platform credential tool.
+	command.Stdin = strings.NewReader(stdin)
+	command.Stdout, command.Stderr = &stdout, &stderr
+	if err := command.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", &exitError{code: exitErr.ExitCode(), stderr: strings.TrimSpace(stderr.String())}
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+type exitError struct {
+	code   int
+	stderr string
+}
+
+func (e *exitError) Error() string {
+	if e.stderr == "" {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return fmt.Sprintf("exit status %d: %s", e.code, e.stderr)
+}
+
+// Set stores secret for account, replacing any existing secret.
+func Set(account, secret string) error {
+	switch goos {
+	case "darwin":
+		// security -i reads the command from stdin, keeping the secret out of
+		// the process list
+		if strings.ContainsAny(secret, "\r\n") {
+			return fmt.Errorf("store %s in keyring: the macOS keychain does not accept multi-line secrets", account)
+		}
+		command := securityCommand("add-generic-password", "-U", "-s", Service, "-a", account, "-w", secret)
+		_, err := run(command, "security", "-i")
+		return wrap("store", account, err)
+	case "linux":
+		_, err := run(secret, "secret-tool", "store", "--label", Service+" "+account, "service", Service, "account", account)
+		return wrap("store", account, err)
+	default:
+		return unsupported()
+	}
+}
+
+// Get returns the secret stored for account.
+func Get(account string) (string, error) {
+	switch goos {
+	case "darwin":
+		out, err := run("", "security", "find-generic-password", "-s", Service, "-a", account, "-w")
+		if notFound(err, 44) {
+			return "", ErrNotFound
+		}
+		return strings.TrimSuffix(out, "\n"), wrap("read", account, err)
+	case "linux":
+		out, err := run("", "secret-tool", "lookup", "service", Service, "account", account)
+		if notFound(err, 1) || err == nil && out == "" {
+			return "", ErrNotFound
+		}
+		return out, wrap("read", account, err)
+	default:
+		return "", unsupported()
+	}
+}
+
+// Delete removes the secret stored for account.
+func Delete(account string) error {
+	switch goos {
+	case "darwin":
+		_, err := run("", "security", "delete-generic-password", "-s", Service, "-a", account)
+		if notFound(err, 44) {
+			return ErrNotFound
+		}
+		return wrap("delete", account, err)
+	case "linux":
+		_, err := run("", "secret-tool", "clear", "service", Service, "account", account)
+		return wrap("delete", account, err)
+	default:
+		return unsupported()
+	}
+}
+
+// securityCommand quotes args as one line of security -i input.
+func securityCommand(args ...string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+	}
+	return strings.Join(quoted, " ") + "\n"
+}
+
+func notFound(err error, code int) bool {
+	var exitErr *exitError
+	return errors.As(err, &exitErr) && exitErr.code == code
+}
+
+func wrap(action, account string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s %s in keyring: %w", action, account, err)
+}
+
+func unsupported() error {
+	return fmt.Errorf("the system keyring is not supported on %s", goos)
+}
@@ -0,0 +1,110 @@
+package keyring
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func stubRun(t *testing.T, os string, fn func(stdin, name string, args ...string) (string, error)) {
+	t.Helper()
+	originalRun, originalGOOS := run, goos
+	run, goos = fn, os
+	t.Cleanup(func() { run, goos = originalRun, originalGOOS })
+}
+
+func TestLinux(t *testing.T) {
+	secrets := map[string]string{}
+	stubRun(t, "linux", func(stdin, name string, args ...string) (string, error) {
+		if name != "secret-tool" {
+			t.Fatalf("ran %s", name)
+		}
+		account := args[len(args)-1]
+		switch args[0] {
+		case "store":
+			secrets[account] = stdin
+		case "lookup":
+			if secret, ok := secrets[account]; ok {
+				return secret, nil
+			}
+			return "", &exitError{code: 1}
+		case "clear":
+			delete(secrets, account)
+		}
+		return "", nil
+	})
+
+	if err := Set("registry/prod/ghcr.io", "token value"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Get("registry/prod/ghcr.io"); err != nil || got != "token value" {
+		t.Errorf("Get() = %q, %v", got, err)
+	}
+	if err := Delete("registry/prod/ghcr.io"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Get("registry/prod/ghcr.io"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestDarwin(t *testing.T) {
+	var calls [][]string
+	var stdins []string
+	stubRun(t, "darwin", func(stdin, name string, args ...string) (string, error) {
+		calls = append(calls, append([]string{name}, args...))
+		stdins = append(stdins, stdin)
+		if args[0] == "find-generic-password" {
+			return "", &exitError{code: 44, stderr: "The specified item could not be found in the keychain."}
+		}
+		return "", nil
+	})
+
+	if err := Set("registry/prod/ghcr.io", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"security", "-i"}; !slices.Equal(calls[0], want) {
+		t.Errorf("Set ran %q, want %q", calls[0], want)
+	}
+	if want := `"add-generic-password" "-U" "-s" "sitectl" "-a" "registry/prod/ghcr.io" "-w" "secret"` + "\n"; stdins[0] != want {
+		t.Errorf("Set wrote %q to security, want %q", stdins[0], want)
+	}
+	if _, err := Get("registry/prod/ghcr.io"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() = %v, want ErrNotFound", err)
+	}
+}
+
+func TestDarwinSecretStaysOffTheCommandLine(t *testing.T) {
+	const secret = `p@ss "word" \ $(whoami)`
+	var stdin string
+	stubRun(t, "darwin", func(input, name string, args ...string) (string, error) {
+		for _, arg := range append([]string{name}, args...) {
+			if strings.Contains(arg, secret) || strings.Contains(arg, "word") {
+				t.Errorf("secret passed as argument %q", arg)
+			}
+		}
+		stdin = input
+		return "", nil
+	})
+
+	if err := Set("registry/prod/ghcr.io", secret); err != nil {
+		t.Fatal(err)
+	}
+	if want := `"-w" "p@ss \"word\" \\ $(whoami)"` + "\n"; !strings.HasSuffix(stdin, want) {
+		t.Errorf("stdin = %q, want it to end with %q", stdin, want)
+	}
+	if err := Set("registry/prod/ghcr.io", "two\nlines"); err == nil {
+		t.Error("Set() accepted a multi-line secret")
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	stubRun(t, "plan9", func(string, string, ...string) (string, error) {
+		t.Fatal("ran a command on an unsupported platform")
+		return "", nil
+	})
+	if err := Set("a", "b"); err == nil {
+		t.Error("expected an unsupported platform error")
+	}
+}
@@ -0,0 +1,311 @@
+// Package logfile captures streamed command output (such as docker compose
+// logs) into files that rotate by size and age, optionally gzipping and
+// pruning rotated files.
+package logfile
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	rotatedTimeFormat = "20060102T150405"
+	compressedSuffix  = ".gz"
+	megabyte          = 1024 * 1024
+)
+
+// Options configures a rotating log file.
+type Options struct {
+	// Path is the active log file. Rotated files are written next to it.
+	Path string
+	// MaxSize rotates the file before a write would grow it past this many
+	// bytes. Zero disables size rotation.
+	MaxSize int64
+	// MaxAge rotates the file once it has been open this long. Zero disables
+	// time rotation.
+	MaxAge time.Duration
+	// MaxFiles is the number of rotated files to keep. Zero keeps them all.
+	MaxFiles int
+	// Compress gzips rotated files.
+	Compress bool
+}
+
+// Enabled reports whether a log file was requested.
+func (o Options) Enabled() bool {
+	return strings.TrimSpace(o.Path) != ""
+}
+
+// Flags holds the values of the --log-* flags registered by AddFlags.
+type Flags struct {
+	path     string
+	maxSize  int64
+	maxAge   time.Duration
+	maxFiles int
+	compress bool
+}
+
+// AddFlags registers --log-file and its rotation flags on flags.
+func AddFlags(flags *pflag.FlagSet) *Flags {
+	f := &Flags{}
+	flags.StringVar(&f.path, "log-file", "", "Also write output to this file, rotating it by size and age")
+	flags.Int64Var(&f.maxSize, "log-max-size", 100, "Rotate the log file when it reaches this many megabytes (0 disables)")
+	flags.DurationVar(&f.maxAge, "log-rotate-every", 24*time.Hour, "Rotate the log file after this long (0 disables)")
+	flags.IntVar(&f.maxFiles, "log-max-files", 7, "Number of rotated log files to keep (0 keeps all)")
+	flags.BoolVar(&f.compress, "log-compress", false, "Gzip rotated log files")
+	return f
+}
+
+// Options returns the rotation options selected by the flags.
+func (f *Flags) Options() (Options, error) {
+	if f.maxSize < 0 || f.maxAge < 0 || f.maxFiles < 0 {
+		return Options{}, fmt.Errorf("--log-max-size, --log-rotate-every and --log-max-files must not be negative")
+	}
+	return Options{
+		Path:     strings.TrimSpace(f.path),
+		MaxSize:  f.maxSize * megabyte,
+		MaxAge:   f.maxAge,
+		MaxFiles: f.maxFiles,
+		Compress: f.compress,
+	}, nil
+}
+
+// Writer appends to a log file and rotates it according to its Options. It is
+// safe for concurrent use.
+type Writer struct {
+	opts Options
+	now  func() time.Time
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+}
+
+// Open opens or creates the log file at opts.Path for appending.
+func Open(opts Options) (*Writer, error) {
+	return open(opts, time.Now)
+}
+
+func open(opts Options, now func() time.Time) (*Writer, error) {
+	if !opts.Enabled() {
+		return nil, fmt.Errorf("log file path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(opts.Path), 0o750); err != nil {
+		return nil, fmt.Errorf("create log directory: %w", err)
+	}
+	w := &Writer{opts: opts, now: now}
+	if err := w.openFile(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Tee returns a writer that copies everything written to out into the log
+// file described by opts, and a function that closes the log file. When opts
+// is not enabled, out is returned unchanged.
+func Tee(out io.Writer, opts Options) (io.Writer, func() error, error) {
+	if !opts.Enabled() {
+		return out, func() error { return nil }, nil
+	}
+	w, err := Open(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return io.MultiWriter(out, w), w.Close, nil
+}
+
+func (w *Writer) openFile() error {
+	file, err := os.OpenFile(w.opts.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("stat log file: %w", err)
+	}
+	w.file = file
+	w.size = info.Size()
+	// An existing file keeps its age across restarts so a long-lived log is
+	// still rotated on schedule.
+	w.opened = w.now()
+	if w.size > 0 {
+		w.opened = info.ModTime()
+	}
+	return nil
+}
+
+// Write appends p to the log file, rotating first when p would exceed
+// MaxSize or the file is older than MaxAge.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	if w.shouldRotate(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *Writer) shouldRotate(next int64) bool {
+	if w.size == 0 {
+		return false
+	}
+	if w.opts.MaxSize > 0 && w.size+next > w.opts.MaxSize {
+		return true
+	}
+	return w.opts.MaxAge > 0 && w.now().Sub(w.opened) >= w.opts.MaxAge
+}
+
+// Close closes the active log file.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("close log file: %w", err)
+	}
+	w.file = nil
+	rotated, err := w.rotatedName()
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(w.opts.Path, rotated); err != nil {
+		return fmt.Errorf("rotate log file: %w", err)
+	}
+	if w.opts.Compress {
+		if err := compressFile(rotated); err != nil {
+			return err
+		}
+	}
+	if err := w.prune(); err != nil {
+		return err
+	}
+	return w.openFile()
+}
+
+// rotatedName returns "<name>-<timestamp><ext>" next to the active file,
+// adding a counter when several rotations happen within one second.
+func (w *Writer) rotatedName() (string, error) {
+	prefix, ext := w.rotatedParts()
+	stamp := w.now().UTC().Format(rotatedTimeFormat)
+	for i := 0; ; i++ {
+		name := prefix + stamp + ext
+		if i > 0 {
+			name = fmt.Sprintf("%s%s.%d%s", prefix, stamp, i, ext)
+		}
+		_, err := os.Stat(name)
+		_, gzErr := os.Stat(name + compressedSuffix)
+		if os.IsNotExist(err) && os.IsNotExist(gzErr) {
+			return name, nil
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("check rotated log file: %w", err)
+		}
+	}
+}
+
+func (w *Writer) rotatedParts() (string, string) {
+	ext := filepath.Ext(w.opts.Path)
+	return strings.TrimSuffix(w.opts.Path, ext) + "-", ext
+}
+
+// prune removes the oldest rotated files beyond MaxFiles.
+func (w *Writer) prune() error {
+	if w.opts.MaxFiles <= 0 {
+		return nil
+	}
+	rotated, err := w.rotatedFiles()
+	if err != nil {
+		return err
+	}
+	for len(rotated) > w.opts.MaxFiles {
+		if err := os.Remove(rotated[0]); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove old log file: %w", err)
+		}
+		rotated = rotated[1:]
+	}
+	return nil
+}
+
+// rotatedFiles lists rotated files oldest first. Names are ordered by their
+// UTC timestamp and counter, ignoring the extension and gzip suffix.
+func (w *Writer) rotatedFiles() ([]string, error) {
+	prefix, ext := w.rotatedParts()
+	entries, err := os.ReadDir(filepath.Dir(w.opts.Path))
+	if err != nil {
+		return nil, fmt.Errorf("list rotated log files: %w", err)
+	}
+	dir := filepath.Dir(w.opts.Path)
+	base := filepath.Base(prefix)
+	files := []string{}
+	keys := map[string]string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		trimmed := strings.TrimSuffix(strings.TrimSuffix(name, compressedSuffix), ext)
+		if entry.IsDir() || !strings.HasPrefix(trimmed, base) {
+			continue
+		}
+		stamp := strings.TrimPrefix(trimmed, base)
+		if len(stamp) < len(rotatedTimeFormat) {
+			continue
+		}
+		if _, err := time.Parse(rotatedTimeFormat, stamp[:len(rotatedTimeFormat)]); err != nil {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		files = append(files, path)
+		keys[path] = stamp
+	}
+	sort.Slice(files, func(i, j int) bool { return keys[files[i]] < keys[files[j]] })
+	return files, nil
+}
+
+func compressFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open rotated log file: %w", err)
+	}
+	defer in.Close()
+	out, err := os.OpenFile(path+compressedSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("create compressed log file: %w", err)
+	}
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		out.Close()
+		return fmt.Errorf("compress log file: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		out.Close()
+		return fmt.Errorf("compress log file: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("compress log file: %w", err)
+	}
+	in.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,140 @@
+package logfile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func readDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestWriterRotatesBySizeAndPrunes(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{now: time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)}
+	w, err := open(Options{Path: filepath.Join(dir, "site.log"), MaxSize: 10, MaxFiles: 2}, clock.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+		clock.now = clock.now.Add(time.Second)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"site-20260304T050609.log", "site-20260304T050610.log", "site.log"}
+	if got := readDir(t, dir); !slices.Equal(got, want) {
+		t.Fatalf("files = %q, want %q", got, want)
+	}
+	for name, content := range map[string]string{"site-20260304T050610.log": "third\n", "site.log": "fourth\n"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(data) != content {
+			t.Errorf("%s = %q, %v, want %q", name, data, err, content)
+		}
+	}
+}
+
+func TestWriterRotatesByAgeAndCompresses(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{now: time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)}
+	w, err := open(Options{Path: filepath.Join(dir, "site.log"), MaxAge: time.Hour, Compress: true}, clock.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("old\n")); err != nil {
+		t.Fatal(err)
+	}
+	clock.now = clock.now.Add(30 * time.Minute)
+	if _, err := w.Write([]byte("still old\n")); err != nil {
+		t.Fatal(err)
+	}
+	clock.now = clock.now.Add(time.Hour)
+	if _, err := w.Write([]byte("new\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"site-20260304T013000.log.gz", "site.log"}
+	if got := readDir(t, dir); !slices.Equal(got, want) {
+		t.Fatalf("files = %q, want %q", got, want)
+	}
+	file, err := os.Open(filepath.Join(dir, want[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil || string(data) != "old\nstill old\n" {
+		t.Errorf("rotated contents = %q, %v", data, err)
+	}
+}
+
+func TestTeeAndFlags(t *testing.T) {
+	flags := pflag.NewFlagSet("logs", pflag.ContinueOnError)
+	logFlags := AddFlags(flags)
+	path := filepath.Join(t.TempDir(), "nested", "compose.log")
+	if err := flags.Parse([]string{"--log-file", path, "--log-max-size=5", "--log-compress"}); err != nil {
+		t.Fatal(err)
+	}
+	opts, err := logFlags.Options()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.MaxSize != 5*megabyte || opts.MaxAge != 24*time.Hour || opts.MaxFiles != 7 || !opts.Compress {
+		t.Fatalf("options = %+v", opts)
+	}
+
+	var out bytes.Buffer
+	tee, closeLog, err := Tee(&out, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(tee, "drupal-1 | ready\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := closeLog(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != out.String() || out.String() != "drupal-1 | ready\n" {
+		t.Errorf("log file = %q, %v; output = %q", data, err, out.String())
+	}
+
+	passthrough, closeLog, err := Tee(&out, Options{})
+	if err != nil || passthrough != &out || closeLog() != nil {
+		t.Errorf("disabled Tee() = %v, %v", passthrough, err)
+	}
+}
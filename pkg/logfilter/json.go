@@ -0,0 +1,28 @@
+package logfilter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/libops/sitectl/pkg/docker"
+)
+
+// WriteJSON streams the logs of the compose project's services to out as one
+// docker.LogEntry JSON object per line. Entries whose message does not match
+// o.Grep are skipped; timestamps are always included.
+func WriteJSON(runCtx context.Context, api docker.LogsAPI, project string, services []string, follow bool, o Options, out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	return docker.StreamComposeLogs(runCtx, api, project, docker.LogsOptions{
+		Services: services,
+		Since:    o.Since,
+		Until:    o.Until,
+		Tail:     o.Tail,
+		Follow:   follow,
+	}, func(entry docker.LogEntry) error {
+		if !o.Matches(entry.Message) {
+			return nil
+		}
+		return encoder.Encode(entry)
+	})
+}
@@ -0,0 +1,231 @@
+// Package logfilter gives the log streaming commands one set of filters:
+// --since, --until, --tail and --timestamps are applied by Docker, and --grep
+// filters (and highlights) the streamed lines on the client. --output json
+// streams the same logs as one JSON object per line.
+package logfilter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+// Output formats selected with --output.
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+const (
+	highlightStart = "\x1b[1;31m"
+	highlightEnd   = "\x1b[0m"
+)
+
+// Options selects which log lines are streamed.
+type Options struct {
+	// Grep keeps only lines matching the expression. Nil keeps every line.
+	Grep *regexp.Regexp
+	// Since and Until bound the time window, as a duration relative to now
+	// (such as 30m), an RFC3339 timestamp, a date or a Unix timestamp.
+	Since string
+	Until string
+	// Tail is the number of lines to show from the end of each container's
+	// log, or "all".
+	Tail string
+	// Timestamps prefixes each line with its RFC3339 timestamp.
+	Timestamps bool
+	// Output is OutputText or OutputJSON.
+	Output string
+}
+
+// Flags holds the values of the flags registered by AddFlags.
+type Flags struct {
+	grep       string
+	since      string
+	until      string
+	tail       string
+	timestamps bool
+	output     string
+}
+
+// AddFlags registers --grep, --since, --until, --tail, --timestamps and
+// --output on flags. defaultTail is the --tail default, a line count or "all".
+func AddFlags(flags *pflag.FlagSet, defaultTail string) *Flags {
+	f := &Flags{}
+	flags.StringVar(&f.grep, "grep", "", "Only show lines matching this regular expression, highlighting the matches")
+	flags.StringVar(&f.since, "since", "", "Show logs since a relative duration (30m) or a timestamp (2026-01-02T15:04:05Z)")
+	flags.StringVar(&f.until, "until", "", "Show logs before a relative duration (10m) or a timestamp")
+	flags.StringVar(&f.tail, "tail", defaultTail, `Number of lines to show from the end of each container's log, or "all"`)
+	flags.BoolVar(&f.timestamps, "timestamps", false, "Prefix each line with its RFC3339 timestamp")
+	flags.StringVar(&f.output, "output", OutputText, "Output format: text, or json for one {service, container, timestamp, stream, message} object per line")
+	return f
+}
+
+// Options validates the flag values and returns the selected filters.
+func (f *Flags) Options() (Options, error) {
+	opts := Options{
+		Since:      strings.TrimSpace(f.since),
+		Until:      strings.TrimSpace(f.until),
+		Tail:       strings.TrimSpace(f.tail),
+		Timestamps: f.timestamps,
+		Output:     strings.TrimSpace(f.output),
+	}
+	if opts.Output != OutputText && opts.Output != OutputJSON {
+		return Options{}, fmt.Errorf("invalid --output %q: use %s or %s", opts.Output, OutputText, OutputJSON)
+	}
+	if f.grep != "" {
+		re, err := regexp.Compile(f.grep)
+		if err != nil {
+			return Options{}, fmt.Errorf("invalid --grep expression: %w", err)
+		}
+		opts.Grep = re
+	}
+	for name, value := range map[string]string{"since": opts.Since, "until": opts.Until} {
+		if value != "" && !validTime(value) {
+			return Options{}, fmt.Errorf("invalid --%s %q: use a duration such as 30m or a timestamp such as 2026-01-02T15:04:05Z", name, value)
+		}
+	}
+	if opts.Tail != "" && opts.Tail != "all" {
+		if n, err := strconv.Atoi(opts.Tail); err != nil || n < 0 {
+			return Options{}, fmt.Errorf(`invalid --tail %q: use a line count or "all"`, opts.Tail)
+		}
+	}
+	return opts, nil
+}
+
+// validTime reports whether value is a time Docker accepts for --since and
+// --until.
+func validTime(value string) bool {
+	if _, err := time.ParseDuration(value); err == nil {
+		return true
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return true
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if _, err := time.Parse(layout, value); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ComposeArgs returns the docker compose logs flags for the Docker-side
+// filters.
+func (o Options) ComposeArgs() []string {
+	args := []string{}
+	if o.Since != "" {
+		args = append(args, "--since", o.Since)
+	}
+	if o.Until != "" {
+		args = append(args, "--until", o.Until)
+	}
+	if o.Tail != "" {
+		args = append(args, "--tail", o.Tail)
+	}
+	if o.Timestamps {
+		args = append(args, "--timestamps")
+	}
+	return args
+}
+
+// Highlight reports whether grep matches written to out should be colored:
+// out is a terminal and NO_COLOR is unset.
+func Highlight(out io.Writer) bool {
+	file, ok := out.(*os.File)
+	return ok && os.Getenv("NO_COLOR") == "" && term.IsTerminal(int(file.Fd()))
+}
+
+// Writer passes through the lines matching a grep expression. It is safe for
+// concurrent use; each write is split into whole lines and a trailing partial
+// line is held until it is completed or the writer is closed.
+type Writer struct {
+	out       io.Writer
+	grep      *regexp.Regexp
+	highlight bool
+
+	mu      sync.Mutex
+	pending []byte
+}
+
+// Filter returns out filtered by o.Grep, and a function that flushes a final
+// unterminated line. When o.Grep is nil, out is returned unchanged.
+func Filter(out io.Writer, o Options, highlight bool) (io.Writer, func() error) {
+	if o.Grep == nil {
+		return out, func() error { return nil }
+	}
+	w := &Writer{out: out, grep: o.Grep, highlight: highlight}
+	return w, w.Close
+}
+
+// Write implements io.Writer.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := w.pending[:i+1]
+		if err := w.writeLine(line); err != nil {
+			return len(p), err
+		}
+		w.pending = w.pending[i+1:]
+	}
+}
+
+// Close writes a final line that did not end in a newline.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.pending) == 0 {
+		return nil
+	}
+	err := w.writeLine(w.pending)
+	w.pending = nil
+	return err
+}
+
+func (w *Writer) writeLine(line []byte) error {
+	if !w.grep.Match(line) {
+		return nil
+	}
+	if w.highlight {
+		line = highlightMatches(w.grep, line)
+	}
+	_, err := w.out.Write(line)
+	return err
+}
+
+// Matches reports whether message passes the --grep filter.
+func (o Options) Matches(message string) bool {
+	return o.Grep == nil || o.Grep.MatchString(message)
+}
+
+// HighlightMatches colors the --grep matches in message.
+func (o Options) HighlightMatches(message string) string {
+	if o.Grep == nil {
+		return message
+	}
+	return string(highlightMatches(o.Grep, []byte(message)))
+}
+
+func highlightMatches(grep *regexp.Regexp, line []byte) []byte {
+	return grep.ReplaceAllFunc(line, func(match []byte) []byte {
+		if len(match) == 0 {
+			return match
+		}
+		return []byte(highlightStart + string(match) + highlightEnd)
+	})
+}
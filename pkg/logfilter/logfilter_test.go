@@ -0,0 +1,90 @@
+package logfilter
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func parse(t *testing.T, args ...string) (Options, error) {
+	t.Helper()
+	flags := pflag.NewFlagSet("logs", pflag.ContinueOnError)
+	f := AddFlags(flags, "200")
+	if err := flags.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return f.Options()
+}
+
+func TestOptionsComposeArgs(t *testing.T) {
+	opts, err := parse(t, "--since", "30m", "--until=2026-10-16T09:00:00Z", "--timestamps", "--grep", "error|warn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"--since", "30m", "--until", "2026-10-16T09:00:00Z", "--tail", "200", "--timestamps"}
+	if got := opts.ComposeArgs(); !slices.Equal(got, want) {
+		t.Errorf("ComposeArgs() = %q, want %q", got, want)
+	}
+	if opts.Grep == nil || !opts.Grep.MatchString("a warning") {
+		t.Errorf("Grep = %v", opts.Grep)
+	}
+
+	for _, args := range [][]string{
+		{"--grep", "("},
+		{"--since", "yesterday"},
+		{"--until", "10 minutes"},
+		{"--tail", "-1"},
+		{"--tail", "some"},
+		{"--output", "yaml"},
+	} {
+		if _, err := parse(t, args...); err == nil {
+			t.Errorf("Options(%q) expected error", args)
+		}
+	}
+	if opts.Output != OutputText {
+		t.Errorf("Output = %q, want %q", opts.Output, OutputText)
+	}
+	if opts, err := parse(t, "--tail", "all", "--since", "1760605200", "--output", "json"); err != nil || opts.Tail != "all" || opts.Output != OutputJSON {
+		t.Errorf("Options(all, unix since) = %+v, %v", opts, err)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	opts, err := parse(t, "--grep", "err(or)?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	w, flush := Filter(&out, opts, false)
+	for _, chunk := range []string{"drupal-1  | ok\ndrupal-1  | an er", "ror\nsolr-1  | fine\n", "mariadb-1  | err"} {
+		if _, err := io.WriteString(w, chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := flush(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "drupal-1  | an error\nmariadb-1  | err"; out.String() != want {
+		t.Errorf("filtered = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	w, _ = Filter(&out, opts, true)
+	_, _ = io.WriteString(w, "drupal-1  | fatal error\n")
+	if want := "drupal-1  | fatal " + highlightStart + "error" + highlightEnd + "\n"; out.String() != want {
+		t.Errorf("highlighted = %q, want %q", out.String(), want)
+	}
+
+	if w, _ := Filter(&out, Options{}, true); w != &out {
+		t.Error("Filter without --grep should return out unchanged")
+	}
+	if !opts.Matches("an error") || opts.Matches("fine") || !(Options{}).Matches("fine") {
+		t.Error("Matches() disagrees with --grep")
+	}
+	if got, want := opts.HighlightMatches("fatal error"), "fatal "+highlightStart+"error"+highlightEnd; got != want {
+		t.Errorf("HighlightMatches() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,102 @@
+// Package notify sends a desktop notification when a long-running sitectl
+// command finishes, so users can switch away from the terminal while a build,
+// database sync or deploy runs.
+package notify
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Env holds the minimum duration, such as 2m, a command must run before its
+// completion is notified. Notifications are off when it is unset or zero.
+// sitectl sets it for itself and plugin subprocesses when --notify-after is
+// passed.
+const Env = "SITECTL_NOTIFY_AFTER"
+
+// Annotation marks a cobra command whose completion is worth a notification.
+// An empty value covers every invocation; otherwise the value lists the
+// space-separated arguments (such as compose subcommands) that qualify.
+const Annotation = "sitectl.notify"
+
+// run starts the notifier process; tests replace it.
+var run = func(name string, args ...string) error {
+	return exec.Command(name, args...).Run() // #nosec G204 -- runs the fixed platform notifier with a generated message.
+}
+
+// Threshold returns the configured notification threshold, or zero when
+// notifications are off or Env does not hold a valid duration.
+func Threshold() time.Duration {
+	value := strings.TrimSpace(os.Getenv(Env))
+	if value == "" {
+		return 0
+	}
+	threshold, err := time.ParseDuration(value)
+	if err != nil || threshold < 0 {
+		slog.Debug("ignoring invalid notification threshold", "env", Env, "value", value)
+		return 0
+	}
+	return threshold
+}
+
+// Finished notifies that command completed, successfully when err is nil,
+// after elapsed. It does nothing when elapsed is under the threshold, and
+// only logs when the platform notifier is missing or fails.
+func Finished(command string, elapsed time.Duration, err error) {
+	threshold := Threshold()
+	if threshold == 0 || elapsed < threshold {
+		return
+	}
+	message := fmt.Sprintf("%s finished in %s", command, elapsed.Round(time.Second))
+	if err != nil {
+		message = fmt.Sprintf("%s failed after %s", command, elapsed.Round(time.Second))
+	}
+	if err := Send("sitectl", message); err != nil {
+		slog.Debug("desktop notification failed", "err", err)
+	}
+}
+
+// Send shows a desktop notification with osascript on macOS, notify-send on
+// Linux and a PowerShell toast on Windows.
+func Send(title, message string) error {
+	name, args, err := notifierCommand(runtime.GOOS, title, message)
+	if err != nil {
+		return err
+	}
+	return run(name, args...)
+}
+
+func notifierCommand(goos, title, message string) (string, []string, error) {
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		return "osascript", []string{"-e", script}, nil
+	case "linux":
+		return "notify-send", []string{"--app-name=sitectl", title, message}, nil
+	case "windows":
+		script := strings.Join([]string{
+			"[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null",
+			"$toast = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)",
+			"$text = $toast.GetElementsByTagName('text')",
+			"$text.Item(0).AppendChild($toast.CreateTextNode(" + powerShellString(title) + ")) > $null",
+			"$text.Item(1).AppendChild($toast.CreateTextNode(" + powerShellString(message) + ")) > $null",
+			"[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('sitectl').Show([Windows.UI.Notifications.ToastNotification]::new($toast))",
+		}, "; ")
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", script}, nil
+	default:
+		return "", nil, fmt.Errorf("desktop notifications are not supported on %s", goos)
+	}
+}
+
+func appleScriptString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+func powerShellString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
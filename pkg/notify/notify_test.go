@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestThreshold(t *testing.T) {
+	for value, want := range map[string]time.Duration{"": 0, "2m": 2 * time.Minute, "soon": 0, "-1s": 0} {
+		t.Setenv(Env, value)
+		if got := Threshold(); got != want {
+			t.Errorf("Threshold() with %q = %s, want %s", value, got, want)
+		}
+	}
+}
+
+func TestFinished(t *testing.T) {
+	var calls [][]string
+	original := run
+	run = func(name string, args ...string) error {
+		calls = append(calls, append([]string{name}, args...))
+		return nil
+	}
+	t.Cleanup(func() { run = original })
+
+	t.Setenv(Env, "")
+	Finished("deploy", time.Hour, nil)
+	t.Setenv(Env, "1m")
+	Finished("deploy", 30*time.Second, nil)
+	if len(calls) != 0 {
+		t.Fatalf("notified below threshold or while disabled: %q", calls)
+	}
+
+	Finished("deploy", 3*time.Minute+400*time.Millisecond, nil)
+	Finished("compose build", 90*time.Second, errors.New("exit status 1"))
+	if len(calls) != 2 {
+		t.Fatalf("calls = %q", calls)
+	}
+	joined := strings.Join(calls[0], " ") + "\n" + strings.Join(calls[1], " ")
+	for _, want := range []string{"deploy finished in 3m0s", "compose build failed after 1m30s"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("notifications %q missing %q", joined, want)
+		}
+	}
+}
+
+func TestNotifierCommand(t *testing.T) {
+	name, args, err := notifierCommand("darwin", "sitectl", `db "sync" done`)
+	if err != nil || name != "osascript" || args[1] != `display notification "db \"sync\" done" with title "sitectl"` {
+		t.Errorf("darwin = %s %q, %v", name, args, err)
+	}
+	name, args, err = notifierCommand("linux", "sitectl", "deploy finished")
+	if err != nil || name != "notify-send" || !slices.Equal(args[1:], []string{"sitectl", "deploy finished"}) {
+		t.Errorf("linux = %s %q, %v", name, args, err)
+	}
+	name, args, err = notifierCommand("windows", "sitectl", "it's done")
+	if err != nil || name != "powershell" || !strings.Contains(args[len(args)-1], "CreateTextNode('it''s done')") {
+		t.Errorf("windows = %s %q, %v", name, args, err)
+	}
+	if _, _, err := notifierCommand("plan9", "sitectl", "done"); err == nil {
+		t.Error("plan9: expected error")
+	}
+}
@@ -10,12 +10,16 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 
 	corecomponent "github.com/libops/sitectl/pkg/component"
 	"github.com/libops/sitectl/pkg/config"
 	"github.com/libops/sitectl/pkg/helpers"
+	"github.com/libops/sitectl/pkg/logfile"
+	"github.com/libops/sitectl/pkg/logfilter"
+	"github.com/libops/sitectl/pkg/profile"
 	"github.com/spf13/cobra"
 )
 
@@ -51,6 +55,10 @@ type StandardComposeCommandOptions struct {
 
 var runComposeProjectRemoteShellCommandContext = runRemoteShellCommandContext
 
+// uploadComposeProjectEnv stages compose-env for remote project commands;
+// tests replace it.
+var uploadComposeProjectEnv = (*config.Context).UploadComposeEnv
+
 // StandardComposeTemplateOptions configures the SDK's standard Compose
 // template create runner and lifecycle commands from one application spec.
 type StandardComposeTemplateOptions struct {
@@ -496,18 +504,30 @@ func (s *SDK) RunComposeProjectCommandContext(runCtx context.Context, ctx *confi
 	composeUp := isComposeProjectUpCommand(command)
 	command = ctx.DockerComposeShellCommand(command)
 	if ctx.DockerHostType == config.ContextRemote {
+		envFile, removeEnvFile, err := uploadComposeProjectEnv(ctx)
+		if err != nil {
+			return err
+		}
+		defer removeEnvFile()
+		if envFile != "" {
+			command = config.ComposeEnvCommand(envFile, "sh", "-c", command)
+		}
 		remoteCommand := command
 		if strings.TrimSpace(projectDir) != "" {
 			remoteCommand = fmt.Sprintf("cd %s && %s", shellQuote(projectDir), command)
 		}
-		_, err := runComposeProjectRemoteShellCommandContext(runCtx, ctx, stdout, stderr, remoteCommand)
+		_, err = runComposeProjectRemoteShellCommandContext(runCtx, ctx, stdout, stderr, remoteCommand)
+		return err
+	}
+	composeEnv, err := ctx.ComposeEnvironment()
+	if err != nil {
 		return err
 	}
 	localCmd := exec.CommandContext(runCtx, "bash", "-lc", command) // #nosec G204 -- command text is assembled from template-owned command lists and shell-quoted inputs.
 	localCmd.Dir = projectDir
 	localCmd.Stdout = stdout
 	localCmd.Stderr = stderr
-	localCmd.Env = os.Environ()
+	localCmd.Env = config.AppendEnvOverrides(os.Environ(), composeEnv)
 	if composeUp {
 		envValues, messages, err := ctx.PrepareComposeUpPortOverride()
 		if err != nil {
@@ -554,6 +574,7 @@ func runRemoteShellCommandContext(runCtx context.Context, ctx *config.Context, s
 	if err := runCtx.Err(); err != nil {
 		return "", err
 	}
+	defer profile.Start(profile.Remote, command)()
 	client, err := ctx.DialSSH()
 	if err != nil {
 		return "", fmt.Errorf("error establishing SSH connection: %w", err)
@@ -689,18 +710,49 @@ func AddStandardComposeCommands(s *SDK, opts StandardComposeCommandOptions) {
 			return s.RunActiveComposeProjectCommand(cmd, "docker compose ps")
 		},
 	})
-	s.AddCommand(&cobra.Command{
+	logsCmd := &cobra.Command{
 		Use:   "logs [SERVICE...]",
 		Short: fmt.Sprintf("Show recent Docker Compose logs for the active %s stack", displayName),
 		Args:  cobra.ArbitraryArgs,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			command := fmt.Sprintf("docker compose logs --tail=%d", tail)
-			if len(args) > 0 {
-				command += " " + shellJoin(args)
+	}
+	logFile := logfile.AddFlags(logsCmd.Flags())
+	logFilter := logfilter.AddFlags(logsCmd.Flags(), strconv.Itoa(tail))
+	logsCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		opts, err := logFile.Options()
+		if err != nil {
+			return err
+		}
+		filterOpts, err := logFilter.Options()
+		if err != nil {
+			return err
+		}
+		command := "docker compose logs " + shellJoin(append(filterOpts.ComposeArgs(), args...))
+		ctx, err := s.ContextFromCommand(cmd)
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(ctx.ProjectDir) == "" {
+			return fmt.Errorf("active context does not define a project directory")
+		}
+		tee, closeLog, err := logfile.Tee(cmd.OutOrStdout(), opts)
+		if err != nil {
+			return err
+		}
+		if filterOpts.Output == logfilter.OutputJSON {
+			err = s.writeJSONLogs(cmd, ctx, args, filterOpts, tee)
+		} else {
+			stdout, flush := logfilter.Filter(tee, filterOpts, logfilter.Highlight(cmd.OutOrStdout()))
+			err = s.RunComposeProjectCommandContext(cmd.Context(), ctx, ctx.ProjectDir, stdout, cmd.ErrOrStderr(), command)
+			if flushErr := flush(); err == nil {
+				err = flushErr
 			}
-			return s.RunActiveComposeProjectCommand(cmd, command)
-		},
-	})
+		}
+		if closeErr := closeLog(); err == nil {
+			err = closeErr
+		}
+		return err
+	}
+	s.AddCommand(logsCmd)
 	s.AddCommand(&cobra.Command{
 		Use:   "rollout",
 		Short: fmt.Sprintf("Roll out the active %s stack", displayName),
@@ -710,6 +762,21 @@ func AddStandardComposeCommands(s *SDK, opts StandardComposeCommandOptions) {
 	})
 }
 
+// writeJSONLogs streams the logs of the context's compose services to out as
+// JSON lines, reading them from the Docker API instead of docker compose.
+func (s *SDK) writeJSONLogs(cmd *cobra.Command, ctx *config.Context, services []string, opts logfilter.Options, out io.Writer) error {
+	cli, err := s.GetDockerClient()
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+	api, err := cli.Logs()
+	if err != nil {
+		return err
+	}
+	return logfilter.WriteJSON(cmd.Context(), api, ctx.EffectiveComposeProjectName(), services, false, opts, out)
+}
+
 // AddStandardComposeCommands registers standard lifecycle, status, logs, and
 // rollout commands for the receiver plugin.
 func (s *SDK) AddStandardComposeCommands(opts StandardComposeCommandOptions) {
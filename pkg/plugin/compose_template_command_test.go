@@ -40,3 +40,52 @@ func TestRunComposeProjectCommandContextHonorsRemoteComposeFiles(t *testing.T) {
 		}
 	}
 }
+
+func TestRunComposeProjectCommandContextExportsComposeEnv(t *testing.T) {
+	originalRun, originalUpload := runComposeProjectRemoteShellCommandContext, uploadComposeProjectEnv
+	t.Cleanup(func() {
+		runComposeProjectRemoteShellCommandContext, uploadComposeProjectEnv = originalRun, originalUpload
+	})
+
+	var gotCommand string
+	runComposeProjectRemoteShellCommandContext = func(_ context.Context, _ *config.Context, _, _ io.Writer, command string) (string, error) {
+		gotCommand = command
+		return "", nil
+	}
+	uploadComposeProjectEnv = func(ctx *config.Context) (string, func(), error) {
+		if _, err := ctx.ComposeEnvironment(); err != nil {
+			return "", nil, err
+		}
+		return "/tmp/.sitectl-compose-env-test", func() {}, nil
+	}
+	ctx := &config.Context{
+		DockerHostType: config.ContextRemote,
+		ProjectDir:     "/srv/app",
+		ComposeEnv:     map[string]string{"DB_PASSWORD": "s3cr3t", "BUILD_TAG": "v1"},
+	}
+	sdk := &SDK{}
+	if err := sdk.RunComposeProjectCommandContext(context.Background(), ctx, ctx.ProjectDir, io.Discard, io.Discard, "docker compose up -d"); err != nil {
+		t.Fatalf("RunComposeProjectCommandContext() error = %v", err)
+	}
+	if strings.Contains(gotCommand, "s3cr3t") {
+		t.Fatalf("remote command contains a compose-env value: %q", gotCommand)
+	}
+	for _, expected := range []string{"cd '/srv/app' && sh -c", "/tmp/.sitectl-compose-env-test", "docker compose"} {
+		if !strings.Contains(gotCommand, expected) {
+			t.Fatalf("remote command = %q, want %q", gotCommand, expected)
+		}
+	}
+
+	local := &config.Context{
+		DockerHostType: config.ContextLocal,
+		ProjectDir:     t.TempDir(),
+		ComposeEnv:     map[string]string{"BUILD_TAG": "v1"},
+	}
+	var stdout strings.Builder
+	if err := sdk.RunComposeProjectCommandContext(context.Background(), local, local.ProjectDir, &stdout, io.Discard, `printf '%s' "$BUILD_TAG"`); err != nil {
+		t.Fatalf("RunComposeProjectCommandContext() error = %v", err)
+	}
+	if stdout.String() != "v1" {
+		t.Fatalf("local command saw BUILD_TAG=%q, want v1", stdout.String())
+	}
+}
@@ -79,7 +79,7 @@ func (s *SDK) EnsureRemoteCreatePrerequisitesContext(runCtx context.Context, out
 		return fmt.Errorf("remote host %s is missing %s, but the SSH user is not root and sudo is unavailable", remoteHostLabel(ctx), strings.Join(status.Missing, ", "))
 	}
 
-	if !opts.Yolo {
+	if !config.AutoConfirm("install remote prerequisites on "+remoteHostLabel(ctx), opts.Yolo) {
 		input := opts.Input
 		if input == nil {
 			input = config.GetInput
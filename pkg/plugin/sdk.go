@@ -21,6 +21,7 @@ import (
 	"github.com/libops/sitectl/pkg/component"
 	"github.com/libops/sitectl/pkg/config"
 	"github.com/libops/sitectl/pkg/docker"
+	"github.com/libops/sitectl/pkg/profile"
 	"github.com/libops/sitectl/pkg/validate"
 	"github.com/spf13/cobra"
 	"golang.org/x/crypto/ssh"
@@ -144,6 +145,7 @@ func (s *SDK) setupLogging(cmd *cobra.Command) error {
 	s.mu.Lock()
 	s.Config.LogLevel = ll
 	s.Config.Context = contextName
+	s.Config.APIUrl = strings.TrimRight(strings.TrimSpace(os.Getenv(config.APIURLEnv)), "/")
 	s.mu.Unlock()
 
 	return nil
@@ -190,11 +192,17 @@ func (s *SDK) Execute() {
 		<-runCtx.Done()
 		_ = s.Close()
 	}()
-	if err := fang.Execute(
+	err := fang.Execute(
 		runCtx,
 		s.RootCmd,
 		fang.WithVersion(s.RootCmd.Version),
-	); err != nil {
+	)
+	// RPC handlers are timed by the calling sitectl process; a report here
+	// would end up inside the captured RPC stderr.
+	if os.Getenv("SITECTL_RPC") == "" {
+		profile.Report(os.Stderr)
+	}
+	if err != nil {
 		os.Exit(1)
 	}
 }
@@ -229,6 +237,23 @@ func (s *SDK) GetSSHClient() (*ssh.Client, error) {
 	return s.getSSHClient()
 }
 
+// APIURL returns the libops API endpoint for the plugin's context: the
+// global --api-url when sitectl was given one, otherwise the api-url pinned on
+// the context. An empty result means the plugin should use its default API.
+func (s *SDK) APIURL() (string, error) {
+	s.mu.Lock()
+	override := s.Config.APIUrl
+	s.mu.Unlock()
+	if override != "" {
+		return override, nil
+	}
+	ctx, err := s.GetContext()
+	if err != nil {
+		return "", err
+	}
+	return ctx.ResolveAPIURL(), nil
+}
+
 // GetContext loads the sitectl context configuration
 // This is useful for plugins that need to access context-specific settings
 // If no context is specified, returns the current context from config
@@ -551,6 +576,7 @@ func (s *SDK) defaultRPCRequest(req RPCRequest) RPCRequest {
 
 func runPluginRPCPath(pluginName, pluginPath string, req RPCRequest, opts pluginRPCPathOptions) (RPCResponse, error) {
 	req.ProtocolVersion = normalizeRPCProtocolVersion(req.ProtocolVersion)
+	defer profile.Start(profile.RPC, pluginName+" "+req.Method)()
 	execCtx := opts.Context
 	if execCtx == nil {
 		execCtx = context.Background()
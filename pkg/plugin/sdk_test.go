@@ -792,3 +792,39 @@ func TestDockerComposeExecCommandQuotesArgs(t *testing.T) {
 		t.Fatalf("DockerComposeExecCommand() = %q, want %q", got, want)
 	}
 }
+
+func TestAPIURLPrefersOverrideOverContext(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv(config.APIURLEnv, "")
+
+	ctx := config.Context{
+		Name:           "staging",
+		Site:           "museum",
+		Plugin:         "drupal",
+		DockerHostType: config.ContextLocal,
+		Environment:    "staging",
+		APIURL:         "https://api.staging.example.com",
+		DockerSocket:   "/var/run/docker.sock",
+		ProjectName:    "museum",
+		ProjectDir:     tempHome,
+	}
+	if err := config.SaveContext(&ctx, true); err != nil {
+		t.Fatalf("SaveContext() error = %v", err)
+	}
+
+	sdk := NewSDK(Metadata{Name: "drupal"})
+	sdk.Config.Context = "staging"
+	got, err := sdk.APIURL()
+	if err != nil {
+		t.Fatalf("APIURL() error = %v", err)
+	}
+	if got != "https://api.staging.example.com" {
+		t.Fatalf("APIURL() = %q, want the context api-url", got)
+	}
+
+	sdk.Config.APIUrl = "https://api.example.com"
+	if got, _ := sdk.APIURL(); got != "https://api.example.com" {
+		t.Fatalf("APIURL() = %q, want the --api-url override", got)
+	}
+}
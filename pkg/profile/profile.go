@@ -0,0 +1,181 @@
+// Package profile records where a sitectl command spends its time (config
+// loading, SSH dials, Docker API calls, remote commands, plugin RPCs and
+// output formatting) and prints a breakdown when the command finishes.
+package profile
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Env enables profiling when set to 1/true. sitectl sets it for itself and
+// plugin subprocesses when --profile-cli is passed.
+const Env = "SITECTL_PROFILE"
+
+// Span categories, in the order they are reported.
+const (
+	Config = "config"
+	SSH    = "ssh"
+	Docker = "docker"
+	Remote = "remote"
+	RPC    = "rpc"
+	Format = "format"
+)
+
+var categoryOrder = []string{Config, SSH, Docker, Remote, RPC, Format}
+
+// dockerAPIVersion strips the version prefix from Docker API paths so calls
+// to the same endpoint are grouped together.
+var dockerAPIVersion = regexp.MustCompile(`^/v[0-9.]+/`)
+
+type span struct {
+	category string
+	name     string
+	duration time.Duration
+}
+
+var (
+	started = time.Now()
+	mu      sync.Mutex
+	spans   []span
+)
+
+// Enabled reports whether profiling is on.
+func Enabled() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(Env))) {
+	case "", "0", "false", "no", "off":
+		return false
+	}
+	return true
+}
+
+// Start begins timing name in category and returns the function that stops
+// the timer. It is a no-op when profiling is off.
+func Start(category, name string) func() {
+	if !Enabled() {
+		return func() {}
+	}
+	begin := time.Now()
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		spans = append(spans, span{category: category, name: name, duration: time.Since(begin)})
+	}
+}
+
+// Transport times each round trip through Base as a Docker API call.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// Wrap returns base wrapped in a timing Transport when profiling is enabled,
+// and base unchanged otherwise.
+func Wrap(base http.RoundTripper) http.RoundTripper {
+	if !Enabled() {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{Base: base}
+}
+
+// RoundTrip implements http.RoundTripper. Streamed responses are timed until
+// their headers arrive.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	stop := Start(Docker, req.Method+" "+dockerAPIVersion.ReplaceAllString(req.URL.Path, "/"))
+	defer stop()
+	return t.Base.RoundTrip(req)
+}
+
+// CloseIdleConnections forwards to Base so Docker clients can release
+// connections.
+func (t *Transport) CloseIdleConnections() {
+	if closer, ok := t.Base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+type row struct {
+	category string
+	name     string
+	count    int
+	total    time.Duration
+	max      time.Duration
+}
+
+// Report writes the timing breakdown to w. It writes nothing when profiling
+// is off.
+func Report(w io.Writer) {
+	if !Enabled() {
+		return
+	}
+	mu.Lock()
+	rows := summarize(spans)
+	mu.Unlock()
+
+	fmt.Fprintf(w, "\nsitectl profile: %s total\n", round(time.Since(started)))
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "  no config, SSH, Docker, remote command, RPC or formatting work was recorded")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  CATEGORY\tCALLS\tTOTAL\tMAX\tOPERATION")
+	for _, r := range rows {
+		fmt.Fprintf(tw, "  %s\t%d\t%s\t%s\t%s\n", r.category, r.count, round(r.total), round(r.max), r.name)
+	}
+	_ = tw.Flush()
+}
+
+// summarize groups spans by category and name, ordering categories as in
+// categoryOrder and the slowest operations first within each category.
+func summarize(spans []span) []row {
+	byKey := map[[2]string]*row{}
+	rows := []*row{}
+	for _, s := range spans {
+		key := [2]string{s.category, s.name}
+		r, ok := byKey[key]
+		if !ok {
+			r = &row{category: s.category, name: s.name}
+			byKey[key] = r
+			rows = append(rows, r)
+		}
+		r.count++
+		r.total += s.duration
+		r.max = max(r.max, s.duration)
+	}
+	rank := func(category string) int {
+		for i, c := range categoryOrder {
+			if c == category {
+				return i
+			}
+		}
+		return len(categoryOrder)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if ri, rj := rank(rows[i].category), rank(rows[j].category); ri != rj {
+			return ri < rj
+		}
+		return rows[i].total > rows[j].total
+	})
+	out := make([]row, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, *r)
+	}
+	return out
+}
+
+func round(d time.Duration) time.Duration {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond)
+	}
+	return d.Round(time.Millisecond)
+}
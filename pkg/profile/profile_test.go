@@ -0,0 +1,81 @@
+package profile
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func reset(t *testing.T) {
+	t.Helper()
+	mu.Lock()
+	spans = nil
+	mu.Unlock()
+}
+
+func TestStartDisabledRecordsNothing(t *testing.T) {
+	t.Setenv(Env, "")
+	reset(t)
+	Start(SSH, "dial example.org:22")()
+	var out bytes.Buffer
+	Report(&out)
+	if len(spans) != 0 || out.Len() != 0 {
+		t.Fatalf("disabled profile recorded %d spans and wrote %q", len(spans), out.String())
+	}
+	base := http.DefaultTransport
+	if Wrap(base) != base {
+		t.Fatal("Wrap() wrapped the transport while profiling is off")
+	}
+}
+
+func TestSummarizeGroupsAndOrders(t *testing.T) {
+	rows := summarize([]span{
+		{category: Format, name: "table", duration: time.Millisecond},
+		{category: Docker, name: "GET /containers/json", duration: 20 * time.Millisecond},
+		{category: Docker, name: "GET /containers/json", duration: 30 * time.Millisecond},
+		{category: Docker, name: "GET /_ping", duration: 10 * time.Millisecond},
+		{category: Config, name: "load config", duration: 2 * time.Millisecond},
+	})
+	want := []row{
+		{category: Config, name: "load config", count: 1, total: 2 * time.Millisecond, max: 2 * time.Millisecond},
+		{category: Docker, name: "GET /containers/json", count: 2, total: 50 * time.Millisecond, max: 30 * time.Millisecond},
+		{category: Docker, name: "GET /_ping", count: 1, total: 10 * time.Millisecond, max: 10 * time.Millisecond},
+		{category: Format, name: "table", count: 1, total: time.Millisecond, max: time.Millisecond},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("rows = %+v", rows)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i], want[i])
+		}
+	}
+}
+
+func TestTransportAndReport(t *testing.T) {
+	t.Setenv(Env, "1")
+	reset(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer server.Close()
+	client := &http.Client{Transport: Wrap(http.DefaultTransport)}
+	for range 2 {
+		resp, err := client.Get(server.URL + "/v1.47/containers/json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	Start(RPC, "isle component.list")()
+
+	var out bytes.Buffer
+	Report(&out)
+	report := out.String()
+	for _, want := range []string{"sitectl profile:", "CATEGORY", "docker    2", "GET /containers/json", "rpc", "isle component.list"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	corecomponent "github.com/libops/sitectl/pkg/component"
+	"github.com/libops/sitectl/pkg/httplog"
 	yaml "gopkg.in/yaml.v3"
 )
 
@@ -930,7 +931,7 @@ func downloadCaptchaProtectArchive(ctx context.Context, sourceURL string) ([]byt
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	client := &http.Client{Timeout: 60 * time.Second}
+	client := httplog.WrapClient(&http.Client{Timeout: 60 * time.Second})
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil) // #nosec G107 -- source URL is a pinned constant with hash verification.
 	if err != nil {
 		return nil, fmt.Errorf("create captcha-protect archive request: %w", err)
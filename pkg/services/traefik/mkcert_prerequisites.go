@@ -55,7 +55,7 @@ func ensureIngressMkcertPrerequisites(runCtx context.Context, ctx *config.Contex
 		return fmt.Errorf("mkcert is missing on %s, but the user is not root and sudo is unavailable", ingressHostLabel(ctx))
 	}
 
-	if !opts.Yolo && !opts.AutoApprove {
+	if !config.AutoConfirm("install mkcert on "+ingressHostLabel(ctx), opts.Yolo || opts.AutoApprove) {
 		ok, err := confirmIngressPackageInstall(opts, ctx, installer, probe)
 		if err != nil {
 			return err
@@ -0,0 +1,69 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	corecomponent "github.com/libops/sitectl/pkg/component"
+	"github.com/libops/sitectl/pkg/config"
+	"github.com/libops/sitectl/pkg/helpers"
+)
+
+// TrustMkcertCertificates installs the mkcert root CA into the trust store of
+// the context host and writes a certificate covering domains to
+// certs/cert.pem and certs/privkey.pem, which is where the https-mkcert
+// ingress mode points Traefik. It returns the hostnames on the certificate.
+func TrustMkcertCertificates(runCtx context.Context, ctx *config.Context, domains []string, applyOpts corecomponent.ApplyOptions) ([]string, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("context is required to trust mkcert certificates")
+	}
+	if !mkcertAllowedForContext(ctx) {
+		return nil, fmt.Errorf("mkcert certificates are limited to local, dev, development, test, testing, qa, or sandbox contexts; context %q has environment %q", ctx.Name, helpers.FirstNonEmpty(ctx.Environment, "-"))
+	}
+	hosts, err := mkcertTrustHosts(domains)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ingressEnsureMkcertPrerequisites(runCtx, ctx, applyOpts); err != nil {
+		return nil, err
+	}
+	if output, err := ingressRunHostCommand(runCtx, ctx, []string{"mkcert", "-install"}); err != nil {
+		if detail := strings.TrimSpace(output); detail != "" {
+			return nil, fmt.Errorf("install mkcert root CA: %w: %s", err, detail)
+		}
+		return nil, fmt.Errorf("install mkcert root CA: %w", err)
+	}
+
+	certPath := ctx.ResolveProjectPath(filepath.Join("certs", "cert.pem"))
+	keyPath := ctx.ResolveProjectPath(filepath.Join("certs", "privkey.pem"))
+	if err := ensureIngressCertDir(runCtx, ctx, certPath); err != nil {
+		return nil, err
+	}
+	if err := ingressMkcertRunner(runCtx, ctx, certPath, keyPath, hosts); err != nil {
+		return nil, err
+	}
+	return hosts, nil
+}
+
+// mkcertTrustHosts validates domains and adds the loopback names every local
+// certificate should cover.
+func mkcertTrustHosts(domains []string) ([]string, error) {
+	var hosts []string
+	for _, domain := range domains {
+		domain = strings.TrimSpace(domain)
+		if domain == "" {
+			continue
+		}
+		if err := validateIngressDomain(domain); err != nil {
+			return nil, fmt.Errorf("invalid domain %q: %w", domain, err)
+		}
+		hosts = appendUniqueStrings(hosts, mkcertHosts(ingressSettings{Domain: domain})...)
+	}
+	if len(hosts) == 0 {
+		hosts = mkcertHosts(ingressSettings{Domain: DefaultIngressDomain})
+	}
+	return hosts, nil
+}
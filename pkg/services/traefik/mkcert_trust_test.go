@@ -0,0 +1,68 @@
+package traefik
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	corecomponent "github.com/libops/sitectl/pkg/component"
+	"github.com/libops/sitectl/pkg/config"
+)
+
+func TestTrustMkcertCertificatesInstallsCAAndWritesCert(t *testing.T) {
+	projectDir := t.TempDir()
+	ctx := &config.Context{Name: "local", DockerHostType: config.ContextLocal, ProjectDir: projectDir}
+
+	var commands []string
+	originalRunner := ingressRunHostCommand
+	ingressRunHostCommand = func(_ context.Context, _ *config.Context, args []string) (string, error) {
+		commands = append(commands, strings.Join(args, " "))
+		return "", nil
+	}
+	t.Cleanup(func() { ingressRunHostCommand = originalRunner })
+
+	var gotCert, gotKey string
+	var gotHosts []string
+	originalMkcert := ingressMkcertRunner
+	ingressMkcertRunner = func(_ context.Context, _ *config.Context, certPath, keyPath string, hosts []string) error {
+		gotCert, gotKey, gotHosts = certPath, keyPath, hosts
+		return nil
+	}
+	t.Cleanup(func() { ingressMkcertRunner = originalMkcert })
+
+	hosts, err := TrustMkcertCertificates(context.Background(), ctx, []string{"mysite.local", "mysite.local"}, corecomponent.ApplyOptions{Yolo: true})
+	if err != nil {
+		t.Fatalf("TrustMkcertCertificates() error = %v", err)
+	}
+	wantHosts := []string{"mysite.local", "localhost", "127.0.0.1", "::1"}
+	if !reflect.DeepEqual(hosts, wantHosts) || !reflect.DeepEqual(gotHosts, wantHosts) {
+		t.Fatalf("hosts = %v (runner %v), want %v", hosts, gotHosts, wantHosts)
+	}
+	if gotCert != projectDir+"/certs/cert.pem" || gotKey != projectDir+"/certs/privkey.pem" {
+		t.Fatalf("cert paths = %q, %q", gotCert, gotKey)
+	}
+	if !reflect.DeepEqual(commands, []string{"mkcert -version", "mkcert -install"}) {
+		t.Fatalf("host commands = %v", commands)
+	}
+}
+
+func TestTrustMkcertCertificatesRejectsProductionContext(t *testing.T) {
+	ctx := &config.Context{Name: "prod", DockerHostType: config.ContextRemote, Environment: "prod"}
+	if _, err := TrustMkcertCertificates(context.Background(), ctx, nil, corecomponent.ApplyOptions{}); err == nil {
+		t.Fatal("expected production contexts to be rejected")
+	}
+}
+
+func TestMkcertTrustHostsRejectsInvalidDomain(t *testing.T) {
+	if _, err := mkcertTrustHosts([]string{"bad domain"}); err == nil {
+		t.Fatal("expected invalid domain error")
+	}
+	hosts, err := mkcertTrustHosts(nil)
+	if err != nil {
+		t.Fatalf("mkcertTrustHosts(nil) error = %v", err)
+	}
+	if !reflect.DeepEqual(hosts, []string{"localhost", "127.0.0.1", "::1"}) {
+		t.Fatalf("mkcertTrustHosts(nil) = %v", hosts)
+	}
+}
@@ -36,7 +36,10 @@ func requiredFieldsValidator(ctx *config.Context) ([]Result, error) {
 		requiredStringResult("compose-project-name", ctx.EffectiveComposeProjectName()),
 		requiredStringResult("compose-network", ctx.EffectiveComposeNetwork()),
 	}
-	if ctx.DockerHostType == config.ContextRemote {
+	if ctx.DockerHostType == config.ContextRemote && strings.TrimSpace(ctx.SSHConfigHost) != "" {
+		// host, port, user and key come from ~/.ssh/config
+		results = append(results, requiredStringResult("docker-socket", ctx.DockerSocket))
+	} else if ctx.DockerHostType == config.ContextRemote {
 		results = append(results,
 			requiredStringResult("ssh-hostname", ctx.SSHHostname),
 			requiredStringResult("ssh-user", ctx.SSHUser),